@@ -11,10 +11,11 @@ require (
 	github.com/bxcodec/faker v2.0.1+incompatible
 	github.com/cloudhut/common v0.3.1-0.20200223165657-be7d32e836fc
 	github.com/deathowl/go-metrics-prometheus v0.0.0-20190530215645-35bace25558f
+	github.com/eapache/go-xerial-snappy v0.0.0-20180814174437-776d5712da21
 	github.com/go-chi/chi v4.1.2+incompatible
 	github.com/golang/protobuf v1.3.3 // indirect
 	github.com/gorilla/websocket v1.4.2
-	github.com/klauspost/compress v1.10.0 // indirect
+	github.com/klauspost/compress v1.10.0
 	github.com/konsorten/go-windows-terminal-sequences v1.0.2 // indirect
 	github.com/kr/text v0.2.0 // indirect
 	github.com/pkg/errors v0.9.1 // indirect
@@ -1,11 +1,14 @@
 package api
 
 import (
+	"context"
+
 	"github.com/Shopify/sarama"
 	"github.com/cloudhut/common/logging"
 	"github.com/cloudhut/common/rest"
 	"github.com/cloudhut/kowl/backend/pkg/kafka"
 	"github.com/cloudhut/kowl/backend/pkg/owl"
+	"github.com/cloudhut/kowl/backend/pkg/schema"
 	"github.com/prometheus/common/log"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
@@ -19,6 +22,10 @@ type API struct {
 	KafkaSvc *kafka.Service
 	OwlSvc   *owl.Service
 
+	// Clusters serves requests which select a cluster, OwlSvc is the service of the default cluster
+	Clusters            *owl.Clusters
+	additionalKafkaSvcs []*kafka.Service
+
 	Hooks *Hooks // Hooks to add additional functionality from the outside at different places (used by Kafka Owl Business)
 }
 
@@ -33,34 +40,69 @@ func New(cfg *Config) *API {
 	}
 	sarama.Logger = saramaLogger
 
+	kafkaSvc := newKafkaService(&cfg.Kafka, logger, cfg.MetricsNamespace)
+	owlSvc := owl.NewService(cfg.Owl, kafkaSvc, logger)
+
+	clusters := owl.NewClusters(owlSvc)
+	additionalKafkaSvcs := make([]*kafka.Service, 0, len(cfg.AdditionalClusters))
+	for _, cluster := range cfg.AdditionalClusters {
+		clusterLogger := logger.With(zap.String("cluster", cluster.Name))
+		clusterKafkaSvc := newKafkaService(&cluster.Kafka, clusterLogger, cfg.MetricsNamespace)
+		additionalKafkaSvcs = append(additionalKafkaSvcs, clusterKafkaSvc)
+		err := clusters.Add(cluster.Name, owl.NewService(cfg.Owl, clusterKafkaSvc, clusterLogger))
+		if err != nil {
+			logger.Fatal("failed to add additional cluster", zap.Error(err))
+		}
+	}
+
+	return &API{
+		Cfg:                 cfg,
+		Logger:              logger,
+		KafkaSvc:            kafkaSvc,
+		OwlSvc:              owlSvc,
+		Clusters:            clusters,
+		additionalKafkaSvcs: additionalKafkaSvcs,
+		Hooks:               newDefaultHooks(),
+	}
+}
+
+// newKafkaService connects to the configured Kafka cluster
+func newKafkaService(cfg *kafka.Config, logger *zap.Logger, metricsNamespace string) *kafka.Service {
 	// Sarama Config
-	saramaConfig, err := kafka.NewSaramaConfig(&cfg.Kafka)
+	saramaConfig, err := kafka.NewSaramaConfig(cfg)
 	if err != nil {
 		log.Fatal("failed to create a valid sarama config", zap.Error(err))
 	}
 
 	// Sarama Client
 	logger.Info("connecting to Kafka cluster")
-	client, err := sarama.NewClient(cfg.Kafka.Brokers, saramaConfig)
+	client, err := sarama.NewClient(cfg.Brokers, saramaConfig)
 	if err != nil {
 		logger.Fatal("failed to create kafka client", zap.Error(err))
 	}
 
-	kafkaSvc := &kafka.Service{Client: client, Logger: logger, MetricsNamespace: cfg.MetricsNamespace}
-
-	return &API{
-		Cfg:      cfg,
-		Logger:   logger,
-		KafkaSvc: kafkaSvc,
-		OwlSvc:   owl.NewService(kafkaSvc, logger),
-		Hooks:    newDefaultHooks(),
+	kafkaSvc := &kafka.Service{Client: client, Logger: logger, MetricsNamespace: metricsNamespace}
+	if cfg.Schema.Enabled {
+		logger.Info("schema registry is enabled", zap.Strings("urls", cfg.Schema.URLs))
+		kafkaSvc.SchemaService = schema.NewService(cfg.Schema, logger)
 	}
+
+	return kafkaSvc
 }
 
-// Start the API server and block
+// Start the API server and block. The background tasks are stopped once the server has been shut down.
 func (api *API) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
 	api.KafkaSvc.RegisterMetrics()
 	api.KafkaSvc.Start()
+	for _, kafkaSvc := range api.additionalKafkaSvcs {
+		kafkaSvc.Start()
+	}
+	api.OwlSvc.StartLagSampler(ctx)
+	api.OwlSvc.StartWaterMarkWarmup(ctx)
+	api.Clusters.Start(ctx)
 
 	// Server
 	server := rest.NewServer(&api.Cfg.REST, api.Logger, api.routes())
@@ -0,0 +1,76 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/cloudhut/common/rest"
+	"github.com/cloudhut/kowl/backend/pkg/kafka"
+	"github.com/cloudhut/kowl/backend/pkg/owl"
+)
+
+// ClusterConfig is an additional Kafka cluster which can be selected per request, e.g. to browse a cluster that
+// requires different TLS or SASL credentials than the default cluster
+type ClusterConfig struct {
+	Name  string       `yaml:"name"`
+	Kafka kafka.Config `yaml:"kafka"`
+}
+
+// UnmarshalYAML sets the Kafka defaults before the cluster config is parsed, as the defaults of list entries can't
+// be set upfront
+func (c *ClusterConfig) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	c.Kafka.SetDefaults()
+	type plain ClusterConfig
+	return unmarshal((*plain)(c))
+}
+
+// Validate the additional cluster config
+func (c *ClusterConfig) Validate() error {
+	if c.Name == "" {
+		return fmt.Errorf("cluster name must be set")
+	}
+	if err := c.Kafka.Validate(); err != nil {
+		return fmt.Errorf("failed to validate Kafka config: %w", err)
+	}
+
+	return nil
+}
+
+// owlServiceForCluster returns the owl service of the requested cluster, if the requester is allowed to access it.
+// An empty cluster name refers to the default cluster.
+func (api *API) owlServiceForCluster(ctx context.Context, clusterName string) (*owl.Service, *rest.Error) {
+	canAccess := true
+	if clusterHooks, ok := api.Hooks.Owl.(ClusterHooks); ok {
+		var restErr *rest.Error
+		canAccess, restErr = clusterHooks.CanAccessCluster(ctx, clusterName)
+		if restErr != nil {
+			return nil, restErr
+		}
+	}
+	if !canAccess {
+		return nil, &rest.Error{
+			Err:      fmt.Errorf("requester is not allowed to access cluster '%v'", clusterName),
+			Status:   http.StatusForbidden,
+			Message:  "You don't have permissions to access this cluster",
+			IsSilent: false,
+		}
+	}
+
+	svc, err := api.Clusters.Get(clusterName)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, owl.ErrUnknownCluster) {
+			status = http.StatusNotFound
+		}
+		return nil, &rest.Error{
+			Err:      err,
+			Status:   status,
+			Message:  fmt.Sprintf("Cluster '%v' does not exist", clusterName),
+			IsSilent: false,
+		}
+	}
+
+	return svc, nil
+}
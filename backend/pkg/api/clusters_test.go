@@ -0,0 +1,94 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cloudhut/common/rest"
+	"github.com/cloudhut/kowl/backend/pkg/kafka"
+	"github.com/cloudhut/kowl/backend/pkg/owl"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// clusterAccessHooks records the requested clusters and denies the access to the restricted cluster
+type clusterAccessHooks struct {
+	defaultHooks
+	requested []string
+}
+
+func (h *clusterAccessHooks) CanAccessCluster(_ context.Context, clusterName string) (bool, *rest.Error) {
+	h.requested = append(h.requested, clusterName)
+	return clusterName != "restricted", nil
+}
+
+func newClustersTestAPI(t *testing.T, owlHooks OwlHooks) (*API, *owl.Service, *owl.Service) {
+	defaultSvc := owl.NewService(owl.Config{}, &kafka.Service{}, zap.NewNop())
+	stagingSvc := owl.NewService(owl.Config{}, &kafka.Service{}, zap.NewNop())
+	clusters := owl.NewClusters(defaultSvc)
+	require.NoError(t, clusters.Add("staging", stagingSvc))
+	require.NoError(t, clusters.Add("restricted", owl.NewService(owl.Config{}, &kafka.Service{}, zap.NewNop())))
+
+	api := &API{
+		Logger:   zap.NewNop(),
+		OwlSvc:   defaultSvc,
+		Clusters: clusters,
+		Hooks:    &Hooks{Route: &defaultHooks{}, Owl: owlHooks},
+	}
+	return api, defaultSvc, stagingSvc
+}
+
+func TestOwlServiceForCluster(t *testing.T) {
+	// All clusters are accessible if the hooks don't implement the cluster hooks
+	api, defaultSvc, stagingSvc := newClustersTestAPI(t, &defaultHooks{})
+
+	svc, restErr := api.owlServiceForCluster(context.Background(), "")
+	require.Nil(t, restErr)
+	assert.Same(t, defaultSvc, svc)
+	svc, restErr = api.owlServiceForCluster(context.Background(), "staging")
+	require.Nil(t, restErr)
+	assert.Same(t, stagingSvc, svc)
+	_, restErr = api.owlServiceForCluster(context.Background(), "restricted")
+	assert.Nil(t, restErr)
+
+	_, restErr = api.owlServiceForCluster(context.Background(), "production")
+	require.NotNil(t, restErr)
+	assert.Equal(t, http.StatusNotFound, restErr.Status)
+
+	hooks := &clusterAccessHooks{}
+	api, _, _ = newClustersTestAPI(t, hooks)
+	_, restErr = api.owlServiceForCluster(context.Background(), "restricted")
+	require.NotNil(t, restErr)
+	assert.Equal(t, http.StatusForbidden, restErr.Status)
+	assert.Equal(t, []string{"restricted"}, hooks.requested)
+}
+
+func TestHandlers_SelectCluster(t *testing.T) {
+	hooks := &clusterAccessHooks{}
+	api, _, _ := newClustersTestAPI(t, hooks)
+
+	handlers := map[string]http.HandlerFunc{
+		"describeCluster":   api.handleDescribeCluster(),
+		"getConsumerGroups": api.handleGetConsumerGroups(),
+		"getTopics":         api.handleGetTopics(),
+		"getPartitions":     api.handleGetPartitions(),
+		"getTopicConfig":    api.handleGetTopicConfig(),
+		"getTopicConsumers": api.handleGetTopicConsumers(),
+	}
+	for name, handler := range handlers {
+		hooks.requested = nil
+
+		// Unknown clusters are rejected before the request is served by any cluster
+		rec := httptest.NewRecorder()
+		handler(rec, httptest.NewRequest(http.MethodGet, "/api/resource?cluster=production", nil))
+		assert.Equal(t, http.StatusNotFound, rec.Code, name)
+
+		rec = httptest.NewRecorder()
+		handler(rec, httptest.NewRequest(http.MethodGet, "/api/resource?cluster=restricted", nil))
+		assert.Equal(t, http.StatusForbidden, rec.Code, name)
+		assert.Equal(t, []string{"production", "restricted"}, hooks.requested, name)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/cloudhut/common/logging"
 	"github.com/cloudhut/common/rest"
 	"github.com/cloudhut/kowl/backend/pkg/kafka"
+	"github.com/cloudhut/kowl/backend/pkg/owl"
 	"gopkg.in/yaml.v2"
 	"io/ioutil"
 )
@@ -19,7 +20,11 @@ type Config struct {
 
 	REST   rest.Config    `yaml:"server"`
 	Kafka  kafka.Config   `yaml:"kafka"`
+	Owl    owl.Config     `yaml:"owl"`
 	Logger logging.Config `yaml:"logger"`
+
+	// AdditionalClusters can be selected per request, requests without a cluster are served by the Kafka cluster
+	AdditionalClusters []ClusterConfig `yaml:"additionalClusters"`
 }
 
 // RegisterFlags for all (sub)configs
@@ -42,6 +47,23 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("failed to validate Kafka config: %w", err)
 	}
 
+	err = c.Owl.Validate()
+	if err != nil {
+		return fmt.Errorf("failed to validate Owl config: %w", err)
+	}
+
+	clusterNames := make(map[string]struct{}, len(c.AdditionalClusters))
+	for i, cluster := range c.AdditionalClusters {
+		err = cluster.Validate()
+		if err != nil {
+			return fmt.Errorf("failed to validate additional cluster %d: %w", i, err)
+		}
+		if _, exists := clusterNames[cluster.Name]; exists {
+			return fmt.Errorf("additional cluster '%v' is configured more than once", cluster.Name)
+		}
+		clusterNames[cluster.Name] = struct{}{}
+	}
+
 	return nil
 }
 
@@ -54,6 +76,7 @@ func (c *Config) SetDefaults() {
 	c.Logger.SetDefaults()
 	c.REST.SetDefaults()
 	c.Kafka.SetDefaults()
+	c.Owl.SetDefaults()
 }
 
 // LoadConfig read YAML-formatted config from filename into cfg.
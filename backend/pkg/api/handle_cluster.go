@@ -13,7 +13,13 @@ func (api *API) handleDescribeCluster() http.HandlerFunc {
 	}
 
 	return func(w http.ResponseWriter, r *http.Request) {
-		clusterInfo, err := api.OwlSvc.GetClusterInfo(r.Context())
+		owlSvc, restErr := api.owlServiceForCluster(r.Context(), r.URL.Query().Get("cluster"))
+		if restErr != nil {
+			rest.SendRESTError(w, r, api.Logger, restErr)
+			return
+		}
+
+		clusterInfo, err := owlSvc.GetClusterInfo(r.Context())
 		if err != nil {
 			restErr := &rest.Error{
 				Err:      err,
@@ -14,7 +14,13 @@ type GetConsumerGroupsResponse struct {
 
 func (api *API) handleGetConsumerGroups() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		describedGroups, err := api.OwlSvc.GetConsumerGroupsOverview(r.Context())
+		owlSvc, restErr := api.owlServiceForCluster(r.Context(), r.URL.Query().Get("cluster"))
+		if restErr != nil {
+			rest.SendRESTError(w, r, api.Logger, restErr)
+			return
+		}
+
+		describedGroups, err := owlSvc.GetConsumerGroupsOverview(r.Context())
 		if err != nil {
 			restErr := &rest.Error{
 				Err:      err,
@@ -0,0 +1,145 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/cloudhut/common/rest"
+	"github.com/go-chi/chi"
+	"go.uber.org/zap"
+)
+
+// requesterPrincipal identifies the requester for per user state and limits, i.e. the owner of message bookmarks
+// and consume quotas. That's the logged in user if the hooks identify users. Without authentication the requester IP
+// is used, so that users behind the same IP share their bookmarks and quota.
+func (api *API) requesterPrincipal(r *http.Request) (string, *rest.Error) {
+	userHooks, ok := api.Hooks.Owl.(UserHooks)
+	if !ok {
+		return requesterIP(r), nil
+	}
+
+	user, restErr := userHooks.AuthenticatedUser(r.Context())
+	if restErr != nil {
+		return "", restErr
+	}
+	if user == "" {
+		return "", &rest.Error{
+			Err:      fmt.Errorf("requester is not logged in"),
+			Status:   http.StatusUnauthorized,
+			Message:  "You must be logged in",
+			IsSilent: false,
+		}
+	}
+	return user, nil
+}
+
+// canViewTopicMessages returns a rest error if the requester is not allowed to view the messages of the topic
+func (api *API) canViewTopicMessages(ctx context.Context, topicName string) *rest.Error {
+	canView, restErr := api.Hooks.Owl.CanViewTopicMessages(ctx, topicName)
+	if restErr != nil {
+		return restErr
+	}
+	if !canView {
+		return &rest.Error{
+			Err:      fmt.Errorf("requester has no permissions to view messages in the requested topic"),
+			Status:   http.StatusForbidden,
+			Message:  "You don't have permissions to view messages in this topic",
+			IsSilent: false,
+		}
+	}
+	return nil
+}
+
+// handleGetMessageBookmark returns the requester's bookmark for the given topic
+func (api *API) handleGetMessageBookmark() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		topicName := chi.URLParam(r, "topicName")
+		logger := api.Logger.With(zap.String("topic_name", topicName))
+
+		owlSvc, restErr := api.owlServiceForCluster(r.Context(), r.URL.Query().Get("cluster"))
+		if restErr != nil {
+			rest.SendRESTError(w, r, logger, restErr)
+			return
+		}
+		if restErr := api.canViewTopicMessages(r.Context(), topicName); restErr != nil {
+			rest.SendRESTError(w, r, logger, restErr)
+			return
+		}
+		owner, restErr := api.requesterPrincipal(r)
+		if restErr != nil {
+			rest.SendRESTError(w, r, logger, restErr)
+			return
+		}
+
+		bookmark := owlSvc.GetMessageBookmark(owner, topicName)
+		if bookmark == nil {
+			restErr := &rest.Error{
+				Err:      fmt.Errorf("no bookmark has been set for topic '%v'", topicName),
+				Status:   http.StatusNotFound,
+				Message:  "No bookmark has been set for this topic",
+				IsSilent: true,
+			}
+			rest.SendRESTError(w, r, logger, restErr)
+			return
+		}
+		rest.SendResponse(w, r, logger, http.StatusOK, bookmark)
+	}
+}
+
+// setMessageBookmarkRequest are the next offsets to view per partition, i.e. the offsets after the last viewed
+// messages
+type setMessageBookmarkRequest struct {
+	Offsets map[int32]int64 `json:"offsets"`
+}
+
+func (s *setMessageBookmarkRequest) OK() error {
+	if len(s.Offsets) == 0 {
+		return fmt.Errorf("at least one partition offset must be set")
+	}
+	for partitionID, offset := range s.Offsets {
+		if partitionID < 0 || offset < 0 {
+			return fmt.Errorf("partition ids and offsets must not be negative")
+		}
+	}
+	return nil
+}
+
+// handleSetMessageBookmark replaces the requester's bookmark for the given topic, consume requests with the bookmark
+// start offset resume from it
+func (api *API) handleSetMessageBookmark() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		topicName := chi.URLParam(r, "topicName")
+		logger := api.Logger.With(zap.String("topic_name", topicName))
+
+		var req setMessageBookmarkRequest
+		if err := rest.Decode(r, &req); err != nil {
+			restErr := &rest.Error{
+				Err:      err,
+				Status:   http.StatusBadRequest,
+				Message:  fmt.Sprintf("Failed to parse request: %v", err.Error()),
+				IsSilent: false,
+			}
+			rest.SendRESTError(w, r, logger, restErr)
+			return
+		}
+
+		owlSvc, restErr := api.owlServiceForCluster(r.Context(), r.URL.Query().Get("cluster"))
+		if restErr != nil {
+			rest.SendRESTError(w, r, logger, restErr)
+			return
+		}
+		if restErr := api.canViewTopicMessages(r.Context(), topicName); restErr != nil {
+			rest.SendRESTError(w, r, logger, restErr)
+			return
+		}
+		owner, restErr := api.requesterPrincipal(r)
+		if restErr != nil {
+			rest.SendRESTError(w, r, logger, restErr)
+			return
+		}
+
+		owlSvc.SetMessageBookmark(owner, topicName, req.Offsets)
+		rest.SendResponse(w, r, logger, http.StatusOK, owlSvc.GetMessageBookmark(owner, topicName))
+	}
+}
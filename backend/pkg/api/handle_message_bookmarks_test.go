@@ -0,0 +1,101 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cloudhut/common/rest"
+	"github.com/cloudhut/kowl/backend/pkg/kafka"
+	"github.com/cloudhut/kowl/backend/pkg/owl"
+	"github.com/go-chi/chi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type userContextKey struct{}
+
+// userHooks identify the logged in user by the user which has been attached to the request context
+type userHooks struct {
+	defaultHooks
+}
+
+func (*userHooks) AuthenticatedUser(ctx context.Context) (string, *rest.Error) {
+	user, _ := ctx.Value(userContextKey{}).(string)
+	return user, nil
+}
+
+func TestHandleMessageBookmark(t *testing.T) {
+	newRouter := func(owlHooks OwlHooks) chi.Router {
+		api := &API{
+			Logger:   zap.NewNop(),
+			Clusters: owl.NewClusters(owl.NewService(owl.Config{}, &kafka.Service{}, zap.NewNop())),
+			Hooks:    &Hooks{Route: &defaultHooks{}, Owl: owlHooks},
+		}
+		router := chi.NewRouter()
+		router.Get("/api/topics/{topicName}/bookmark", api.handleGetMessageBookmark())
+		router.Put("/api/topics/{topicName}/bookmark", api.handleSetMessageBookmark())
+		return router
+	}
+	serve := func(router chi.Router, method string, user string, remoteAddr string, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/api/topics/orders/bookmark", strings.NewReader(body))
+		req = req.WithContext(context.WithValue(req.Context(), userContextKey{}, user))
+		req.RemoteAddr = remoteAddr
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// Bookmarks belong to the logged in user, regardless of the requester IP
+	router := newRouter(&userHooks{})
+	rec := serve(router, http.MethodPut, "alice", "10.0.0.1:1234", `{"offsets":{"0":50,"1":99}}`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	rec = serve(router, http.MethodGet, "alice", "10.0.0.2:1234", "")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var bookmark owl.MessageBookmark
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &bookmark))
+	assert.Equal(t, map[int32]int64{0: 50, 1: 99}, bookmark.Offsets)
+
+	assert.Equal(t, http.StatusNotFound, serve(router, http.MethodGet, "bob", "10.0.0.1:1234", "").Code)
+	assert.Equal(t, http.StatusUnauthorized, serve(router, http.MethodGet, "", "10.0.0.1:1234", "").Code)
+	assert.Equal(t, http.StatusBadRequest, serve(router, http.MethodPut, "alice", "10.0.0.1:1234", `{"offsets":{"0":-1}}`).Code)
+
+	// Without authentication the bookmarks are kept per requester IP
+	router = newRouter(&defaultHooks{})
+	rec = serve(router, http.MethodPut, "", "10.0.0.1:1234", `{"offsets":{"0":50}}`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, http.StatusOK, serve(router, http.MethodGet, "", "10.0.0.1:4321", "").Code)
+	assert.Equal(t, http.StatusNotFound, serve(router, http.MethodGet, "", "10.0.0.2:1234", "").Code)
+}
+
+func TestRequesterPrincipal(t *testing.T) {
+	newRequest := func(user string, remoteAddr string) *http.Request {
+		req := httptest.NewRequest(http.MethodGet, "/api/topics/orders/messages", nil)
+		req = req.WithContext(context.WithValue(req.Context(), userContextKey{}, user))
+		req.RemoteAddr = remoteAddr
+		return req
+	}
+
+	// Logged in users keep their principal, regardless of the (possibly forwarded) requester IP
+	api := &API{Hooks: &Hooks{Owl: &userHooks{}}}
+	principal, restErr := api.requesterPrincipal(newRequest("alice", "10.0.0.1:1234"))
+	require.Nil(t, restErr)
+	assert.Equal(t, "alice", principal)
+	principal, restErr = api.requesterPrincipal(newRequest("alice", "10.0.0.2:1234"))
+	require.Nil(t, restErr)
+	assert.Equal(t, "alice", principal)
+	_, restErr = api.requesterPrincipal(newRequest("", "10.0.0.1:1234"))
+	require.NotNil(t, restErr)
+	assert.Equal(t, http.StatusUnauthorized, restErr.Status)
+
+	// Without authentication the requester IP is the principal
+	api = &API{Hooks: &Hooks{Owl: &defaultHooks{}}}
+	principal, restErr = api.requesterPrincipal(newRequest("", "10.0.0.1:1234"))
+	require.Nil(t, restErr)
+	assert.Equal(t, "10.0.0.1", principal)
+}
@@ -23,6 +23,9 @@ func (api *API) handleStartupProbe() http.HandlerFunc {
 	type response struct {
 		IsHTTPOk  bool `json:"isHttpOk"`
 		IsKafkaOk bool `json:"isKafkaOk"`
+
+		// IsWaterMarkCacheWarm is only true if the water mark warmup is enabled and has completed
+		IsWaterMarkCacheWarm bool `json:"isWaterMarkCacheWarm"`
 	}
 
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -34,8 +37,9 @@ func (api *API) handleStartupProbe() http.HandlerFunc {
 		}
 
 		res := &response{
-			IsHTTPOk:  true,
-			IsKafkaOk: isKafkaOK,
+			IsHTTPOk:             true,
+			IsKafkaOk:            isKafkaOK,
+			IsWaterMarkCacheWarm: api.OwlSvc.IsWaterMarkWarmupComplete(),
 		}
 
 		rest.SendResponse(w, r, api.Logger, http.StatusOK, res)
@@ -4,10 +4,12 @@ import (
 	"context"
 	"encoding/base64"
 	"fmt"
+	"net"
 	"net/http"
 	"sync"
 	"time"
 
+	"github.com/cloudhut/kowl/backend/pkg/kafka"
 	"github.com/cloudhut/kowl/backend/pkg/owl"
 
 	"github.com/cloudhut/common/rest"
@@ -21,11 +23,42 @@ type GetTopicMessagesResponse struct {
 // ListMessageRequest represents a search message request with all search parameter. This must be public as it's
 // used in Kowl business to implement the hooks.
 type ListMessagesRequest struct {
-	TopicName             string `json:"topicName"`
-	StartOffset           int64  `json:"startOffset"` // -1 for recent (newest - results), -2 for oldest offset, -3 for newest
-	PartitionID           int32  `json:"partitionId"` // -1 for all partition ids
-	MaxResults            uint16 `json:"maxResults"`
-	FilterInterpreterCode string `json:"filterInterpreterCode"` // Base64 encoded code
+	Cluster               string   `json:"cluster"` // Optional: one of the additional clusters, default cluster if empty
+	TopicName             string   `json:"topicName"`
+	StartOffset           int64    `json:"startOffset"`    // -1 for recent (newest - results), -2 for oldest offset, -3 for newest, -4 for timestamp, -5 for bookmark
+	StartTimestamp        int64    `json:"startTimestamp"` // Unix timestamp in ms, only used if StartOffset is -4
+	MaxAgeMs              int64    `json:"maxAgeMs"`       // Optional: only return messages of the last n ms
+	PartitionID           int32    `json:"partitionId"`    // -1 for all partition ids
+	MaxResults            uint16   `json:"maxResults"`
+	FilterInterpreterCode string   `json:"filterInterpreterCode"` // Base64 encoded code
+	SessionID             string   `json:"sessionId"`             // Optional: remembers the detected formats across pages
+	KeyFormat             string   `json:"keyFormat"`             // Optional: json, xml, text or binary
+	ValueFormat           string   `json:"valueFormat"`           // Optional: json, xml, text or binary
+	Flatten               bool     `json:"flatten"`               // Flatten JSON values into dot-notation paths
+	FlattenPaths          []string `json:"flattenPaths"`          // Optional: only return these paths
+	ProjectionPaths       []string `json:"projectionPaths"`       // Optional: only return these JSON paths of each value
+	ProjectKeys           bool     `json:"projectKeys"`           // Apply the projection to JSON keys as well
+	ExtractJSONPath       string   `json:"extractJsonPath"`       // Optional: only return the value at this JSONPath
+	ConnectTopicType      string   `json:"connectTopicType"`      // Optional: offsets, status or configs
+	ProducerID            *int64   `json:"producerId"`            // Optional: -1 for non idempotent producers
+	DedupByKey            bool     `json:"dedupByKey"`            // Only return the latest message per key of the window
+	DedupOrder            string   `json:"dedupOrder"`            // Optional: first or last (default) appearance of the key
+	WithSchemaVersions    bool     `json:"withSchemaVersions"`    // Resolve the schema version of each message
+	ParseDeadLetters      bool     `json:"parseDeadLetters"`      // Surface the error headers of dead-letter records
+	StableOrder           bool     `json:"stableOrder"`           // Order by timestamp, then partition, then offset
+	WithBinaryPreview     bool     `json:"withBinaryPreview"`     // Add the length and a hex preview to binary values
+	WithPartitionKeyHash  bool     `json:"withPartitionKeyHash"`  // Add the partition each key hashes to by default
+	WithBatchCompression  bool     `json:"withBatchCompression"`  // Add the compression codec of each record's batch
+	RecordVisibility      string   `json:"recordVisibility"`      // Optional: data (default), control, aborted or all
+	SamplingRate          int64    `json:"samplingRate"`          // Optional: only return 1 in n messages of each partition
+	SampleSize            int      `json:"sampleSize"`            // Optional: return about n messages of the window
+	SamplingSeed          int64    `json:"samplingSeed"`          // Optional: shifts the sampled offsets
+	GlobalRecordCap       int      `json:"globalRecordCap"`       // Optional: records across all partitions, taken in turns
+	UpdateBookmark        bool     `json:"updateBookmark"`        // Advance the requester's bookmark past each message
+	FetchMaxWaitMs        int32    `json:"fetchMaxWaitMs"`        // Optional: max.wait.ms, defaults to 250ms
+	FetchMinBytes         int32    `json:"fetchMinBytes"`         // Optional: fetch.min.bytes, defaults to 1 byte
+	FetchMaxBytes         int32    `json:"fetchMaxBytes"`         // Optional: fetch.max.bytes per partition, defaults to 1MiB
+	MaxParallelPartitions int      `json:"maxParallelPartitions"` // Optional: partitions consumed concurrently, defaults to 32
 }
 
 func (l *ListMessagesRequest) OK() error {
@@ -33,16 +66,102 @@ func (l *ListMessagesRequest) OK() error {
 		return fmt.Errorf("topic name is required")
 	}
 
-	if l.StartOffset < -3 {
-		return fmt.Errorf("start offset is smaller than -3")
+	if l.StartOffset < -5 {
+		return fmt.Errorf("start offset is smaller than -5")
+	}
+
+	if l.StartOffset == owl.StartOffsetTimestamp && l.StartTimestamp < 0 {
+		return fmt.Errorf("start timestamp must not be negative")
+	}
+
+	if l.MaxAgeMs < 0 {
+		return fmt.Errorf("max age must not be negative")
 	}
 
 	if l.PartitionID < -1 {
 		return fmt.Errorf("partitionID is smaller than -1")
 	}
 
-	if l.MaxResults <= 0 || l.MaxResults > 500 {
-		return fmt.Errorf("max results must be between 1 and 500")
+	if l.SamplingRate < 0 || l.SampleSize < 0 {
+		return fmt.Errorf("sampling rate and sample size must not be negative")
+	}
+	if l.SamplingRate > 1 && l.SampleSize > 0 {
+		return fmt.Errorf("sampling rate and sample size can not be combined")
+	}
+
+	// With sampling enabled max results is the window of consumed messages, only the sample is returned
+	if l.MaxResults <= 0 {
+		return fmt.Errorf("max results must be greater than 0")
+	}
+	switch {
+	case l.SampleSize > 0:
+		if l.SampleSize > 500 {
+			return fmt.Errorf("sample size must be between 1 and 500")
+		}
+		if l.StartOffset == owl.StartOffsetNewest {
+			return fmt.Errorf("a sample size can not be used in live tail mode, use a sampling rate instead")
+		}
+	case l.SamplingRate > 1:
+		if (int64(l.MaxResults)+l.SamplingRate-1)/l.SamplingRate > 500 {
+			return fmt.Errorf("max results divided by the sampling rate must not exceed 500")
+		}
+	default:
+		if l.MaxResults > 500 {
+			return fmt.Errorf("max results must be between 1 and 500")
+		}
+	}
+
+	if !kafka.IsValidMessageFormat(l.KeyFormat) || !kafka.IsValidMessageFormat(l.ValueFormat) {
+		return fmt.Errorf("key format '%v' or value format '%v' is not supported", l.KeyFormat, l.ValueFormat)
+	}
+
+	if l.ProducerID != nil && *l.ProducerID < kafka.NoProducerID {
+		return fmt.Errorf("producer id must not be smaller than -1")
+	}
+
+	if l.DedupByKey && l.StartOffset == owl.StartOffsetNewest {
+		return fmt.Errorf("messages can not be deduplicated in live tail mode")
+	}
+
+	if l.StableOrder && l.StartOffset == owl.StartOffsetNewest {
+		return fmt.Errorf("messages can not be ordered in live tail mode")
+	}
+
+	if !owl.IsValidDedupOrder(l.DedupOrder) {
+		return fmt.Errorf("dedup order '%v' is not supported", l.DedupOrder)
+	}
+
+	if !kafka.IsValidConnectTopicType(l.ConnectTopicType) {
+		return fmt.Errorf("connect topic type '%v' is not supported", l.ConnectTopicType)
+	}
+
+	if !kafka.IsValidRecordVisibility(l.RecordVisibility) {
+		return fmt.Errorf("record visibility '%v' is not supported", l.RecordVisibility)
+	}
+
+	if l.MaxParallelPartitions < 0 {
+		return fmt.Errorf("max parallel partitions must not be negative")
+	}
+
+	if l.GlobalRecordCap < 0 || l.GlobalRecordCap > int(l.MaxResults) {
+		return fmt.Errorf("global record cap must be between 0 and max results")
+	}
+
+	if err := l.FetchTuning().Validate(); err != nil {
+		return fmt.Errorf("invalid fetch tuning: %w", err)
+	}
+
+	if err := kafka.ValidateProjectionPaths(l.ProjectionPaths); err != nil {
+		return fmt.Errorf("invalid projection: %w", err)
+	}
+
+	if l.ExtractJSONPath != "" {
+		if len(l.ProjectionPaths) > 0 {
+			return fmt.Errorf("json path extraction can not be combined with a projection")
+		}
+		if err := kafka.ValidateJSONPath(l.ExtractJSONPath); err != nil {
+			return fmt.Errorf("invalid json path: %w", err)
+		}
 	}
 
 	if _, err := l.DecodeInterpreterCode(); err != nil {
@@ -52,6 +171,15 @@ func (l *ListMessagesRequest) OK() error {
 	return nil
 }
 
+// FetchTuning returns the requested fetch settings, unset values keep the consumer defaults
+func (l *ListMessagesRequest) FetchTuning() kafka.FetchTuning {
+	return kafka.FetchTuning{
+		MaxWait:  time.Duration(l.FetchMaxWaitMs) * time.Millisecond,
+		MinBytes: l.FetchMinBytes,
+		MaxBytes: l.FetchMaxBytes,
+	}
+}
+
 func (l *ListMessagesRequest) DecodeInterpreterCode() (string, error) {
 	code, err := base64.StdEncoding.DecodeString(l.FilterInterpreterCode)
 	if err != nil {
@@ -106,6 +234,12 @@ func (api *API) handleGetMessages() http.HandlerFunc {
 			return
 		}
 
+		owlSvc, restErr := api.owlServiceForCluster(r.Context(), req.Cluster)
+		if restErr != nil {
+			sendError(restErr.Message)
+			return
+		}
+
 		// Check if logged in user is allowed to list messages for the given request
 		canViewMessages, restErr := api.Hooks.Owl.CanViewTopicMessages(r.Context(), req.TopicName)
 		if restErr != nil {
@@ -129,6 +263,12 @@ func (api *API) handleGetMessages() http.HandlerFunc {
 			}
 		}
 
+		principal, restErr := api.requesterPrincipal(r)
+		if restErr != nil {
+			sendError(restErr.Message)
+			return
+		}
+
 		interpreterCode, _ := req.DecodeInterpreterCode() // Error has been checked in validation function
 
 		// Request messages from kafka and return them once we got all the messages or the context is done
@@ -136,8 +276,38 @@ func (api *API) handleGetMessages() http.HandlerFunc {
 			TopicName:             req.TopicName,
 			PartitionID:           req.PartitionID,
 			StartOffset:           req.StartOffset,
+			StartTimestamp:        req.StartTimestamp,
+			MaxAge:                time.Duration(req.MaxAgeMs) * time.Millisecond,
 			MessageCount:          req.MaxResults,
 			FilterInterpreterCode: interpreterCode,
+			SessionID:             req.SessionID,
+			KeyFormat:             req.KeyFormat,
+			ValueFormat:           req.ValueFormat,
+			Flatten:               req.Flatten,
+			FlattenPaths:          req.FlattenPaths,
+			ProjectionPaths:       req.ProjectionPaths,
+			ProjectKeys:           req.ProjectKeys,
+			ExtractJSONPath:       req.ExtractJSONPath,
+			ConnectTopicType:      req.ConnectTopicType,
+			ProducerID:            req.ProducerID,
+			DedupByKey:            req.DedupByKey,
+			DedupOrder:            req.DedupOrder,
+			WithSchemaVersions:    req.WithSchemaVersions,
+			ParseDeadLetters:      req.ParseDeadLetters,
+			StableOrder:           req.StableOrder,
+			WithBinaryPreview:     req.WithBinaryPreview,
+			WithPartitionKeyHash:  req.WithPartitionKeyHash,
+			WithBatchCompression:  req.WithBatchCompression,
+			RecordVisibility:      req.RecordVisibility,
+			SamplingRate:          req.SamplingRate,
+			SampleSize:            req.SampleSize,
+			SamplingSeed:          req.SamplingSeed,
+			GlobalRecordCap:       req.GlobalRecordCap,
+			UpdateBookmark:        req.UpdateBookmark,
+			BookmarkOwner:         principal,
+			FetchTuning:           req.FetchTuning(),
+			MaxParallelPartitions: req.MaxParallelPartitions,
+			Principal:             principal,
 		}
 		api.Hooks.Owl.PrintListMessagesAuditLog(r, &listReq)
 
@@ -160,9 +330,19 @@ func (api *API) handleGetMessages() http.HandlerFunc {
 		}
 		progress.Start()
 
-		err = api.OwlSvc.ListMessages(childCtx, listReq, progress)
+		err = owlSvc.ListMessages(childCtx, listReq, progress)
 		if err != nil {
 			progress.OnError(err.Error())
 		}
 	}
 }
+
+// requesterIP returns the IP of the requester, which is used as principal without authentication. The RealIP
+// middleware has already replaced the remote address with the forwarded IP if there is one.
+func requesterIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
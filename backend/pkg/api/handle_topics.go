@@ -4,6 +4,7 @@ import (
 	_ "context"
 	"fmt"
 	"net/http"
+	"strconv"
 	_ "time"
 
 	"go.uber.org/zap"
@@ -19,7 +20,13 @@ func (api *API) handleGetTopics() http.HandlerFunc {
 	}
 
 	return func(w http.ResponseWriter, r *http.Request) {
-		topics, err := api.OwlSvc.GetTopicsOverview()
+		owlSvc, restErr := api.owlServiceForCluster(r.Context(), r.URL.Query().Get("cluster"))
+		if restErr != nil {
+			rest.SendRESTError(w, r, api.Logger, restErr)
+			return
+		}
+
+		topics, err := owlSvc.GetTopicsOverview()
 		if err != nil {
 			restErr := &rest.Error{
 				Err:      err,
@@ -70,6 +77,12 @@ func (api *API) handleGetPartitions() http.HandlerFunc {
 		topicName := chi.URLParam(r, "topicName")
 		logger := api.Logger.With(zap.String("topic_name", topicName))
 
+		owlSvc, restErr := api.owlServiceForCluster(r.Context(), r.URL.Query().Get("cluster"))
+		if restErr != nil {
+			rest.SendRESTError(w, r, logger, restErr)
+			return
+		}
+
 		// Check if logged in user is allowed to view partitions for the given topic
 		canView, restErr := api.Hooks.Owl.CanViewTopicPartitions(r.Context(), topicName)
 		if restErr != nil {
@@ -87,7 +100,25 @@ func (api *API) handleGetPartitions() http.HandlerFunc {
 			return
 		}
 
-		partitions, err := api.OwlSvc.ListTopicPartitions(topicName)
+		// Optionally the watermarks can be fetched from a specific (e.g. rack local) replica instead of the leader
+		var partitions []owl.TopicPartition
+		var err error
+		if replicaBrokerID := r.URL.Query().Get("replicaBrokerId"); replicaBrokerID != "" {
+			brokerID, parseErr := strconv.ParseInt(replicaBrokerID, 10, 32)
+			if parseErr != nil {
+				restErr := &rest.Error{
+					Err:      fmt.Errorf("failed to parse replicaBrokerId: %w", parseErr),
+					Status:   http.StatusBadRequest,
+					Message:  "The given replicaBrokerId is not a valid broker id",
+					IsSilent: false,
+				}
+				rest.SendRESTError(w, r, logger, restErr)
+				return
+			}
+			partitions, err = owlSvc.ListTopicPartitionsFromReplica(topicName, int32(brokerID))
+		} else {
+			partitions, err = owlSvc.ListTopicPartitions(topicName)
+		}
 		if err != nil {
 			restErr := &rest.Error{
 				Err:      err,
@@ -117,6 +148,12 @@ func (api *API) handleGetTopicConfig() http.HandlerFunc {
 		topicName := chi.URLParam(r, "topicName")
 		logger := api.Logger.With(zap.String("topic_name", topicName))
 
+		owlSvc, restErr := api.owlServiceForCluster(r.Context(), r.URL.Query().Get("cluster"))
+		if restErr != nil {
+			rest.SendRESTError(w, r, logger, restErr)
+			return
+		}
+
 		// Check if logged in user is allowed to view partitions for the given topic
 		canView, restErr := api.Hooks.Owl.CanViewTopicConfig(r.Context(), topicName)
 		if restErr != nil {
@@ -134,7 +171,7 @@ func (api *API) handleGetTopicConfig() http.HandlerFunc {
 			return
 		}
 
-		description, err := api.OwlSvc.GetTopicConfigs(topicName, []string{})
+		description, err := owlSvc.GetTopicConfigs(topicName, []string{})
 		if err != nil {
 			restErr := &rest.Error{
 				Err:      err,
@@ -164,6 +201,12 @@ func (api *API) handleGetTopicConsumers() http.HandlerFunc {
 		topicName := chi.URLParam(r, "topicName")
 		logger := api.Logger.With(zap.String("topic_name", topicName))
 
+		owlSvc, restErr := api.owlServiceForCluster(r.Context(), r.URL.Query().Get("cluster"))
+		if restErr != nil {
+			rest.SendRESTError(w, r, logger, restErr)
+			return
+		}
+
 		// Check if logged in user is allowed to view partitions for the given topic
 		canView, restErr := api.Hooks.Owl.CanViewTopicConsumers(r.Context(), topicName)
 		if restErr != nil {
@@ -181,7 +224,7 @@ func (api *API) handleGetTopicConsumers() http.HandlerFunc {
 			return
 		}
 
-		consumers, err := api.OwlSvc.ListTopicConsumers(r.Context(), topicName)
+		consumers, err := owlSvc.ListTopicConsumers(r.Context(), topicName)
 		if err != nil {
 			restErr := &rest.Error{
 				Err:      err,
@@ -48,6 +48,20 @@ type OwlHooks interface {
 	AllowedConsumerGroupActions(ctx context.Context, groupName string) ([]string, *rest.Error)
 }
 
+// ClusterHooks can optionally be implemented by the OwlHooks to restrict the access to the configured clusters. All
+// clusters are accessible if they are not implemented.
+type ClusterHooks interface {
+	// CanAccessCluster is called for every request, an empty cluster name refers to the default cluster
+	CanAccessCluster(ctx context.Context, clusterName string) (bool, *rest.Error)
+}
+
+// UserHooks can optionally be implemented by the OwlHooks to identify the logged in user. Per user state and limits,
+// such as the message bookmarks and consume quotas, are kept per requester IP if they are not implemented.
+type UserHooks interface {
+	// AuthenticatedUser returns a unique name of the logged in user, e.g. its login
+	AuthenticatedUser(ctx context.Context) (string, *rest.Error)
+}
+
 // defaultHooks is the default hook which is used if you don't attach your own hooks
 type defaultHooks struct{}
 
@@ -64,6 +64,8 @@ func (api *API) routes() *chi.Mux {
 				r.Get("/topics/{topicName}/partitions", api.handleGetPartitions())
 				r.Get("/topics/{topicName}/configuration", api.handleGetTopicConfig())
 				r.Get("/topics/{topicName}/consumers", api.handleGetTopicConsumers())
+				r.Get("/topics/{topicName}/bookmark", api.handleGetMessageBookmark())
+				r.Put("/topics/{topicName}/bookmark", api.handleSetMessageBookmark())
 				r.Get("/consumer-groups", api.handleGetConsumerGroups())
 			})
 		})
@@ -90,6 +90,46 @@ func (p *progressReporter) OnComplete(elapsedMs int64, isCancelled bool) {
 	}{"done", elapsedMs, isCancelled, p.messagesConsumed, p.bytesConsumed})
 }
 
+func (p *progressReporter) OnThrottled(delayMs int64, reason string) {
+	_ = p.websocket.writeJSON(struct {
+		Type    string `json:"type"`
+		DelayMs int64  `json:"delayMs"`
+		Reason  string `json:"reason"`
+	}{"throttled", delayMs, reason})
+}
+
+func (p *progressReporter) OnStartOffsetAdjusted(partitionID int32, plannedOffset, adjustedOffset int64) {
+	_ = p.websocket.writeJSON(struct {
+		Type           string `json:"type"`
+		PartitionID    int32  `json:"partitionId"`
+		PlannedOffset  int64  `json:"plannedOffset"`
+		AdjustedOffset int64  `json:"adjustedOffset"`
+	}{"startOffsetAdjusted", partitionID, plannedOffset, adjustedOffset})
+}
+
+func (p *progressReporter) OnBatchCorrupted(partitionID int32, offset int64, reason string) {
+	_ = p.websocket.writeJSON(struct {
+		Type        string `json:"type"`
+		PartitionID int32  `json:"partitionId"`
+		Offset      int64  `json:"offset"`
+		Reason      string `json:"reason"`
+	}{"batchCorrupted", partitionID, offset, reason})
+}
+
+func (p *progressReporter) OnSampled(rate int64, consumedCount int64, sampledCount int64) {
+	effectiveRate := 0.0
+	if consumedCount > 0 {
+		effectiveRate = float64(sampledCount) / float64(consumedCount)
+	}
+	_ = p.websocket.writeJSON(struct {
+		Type          string  `json:"type"`
+		SamplingRate  int64   `json:"samplingRate"` // 1 in n messages of each partition
+		ConsumedCount int64   `json:"consumedCount"`
+		SampledCount  int64   `json:"sampledCount"`
+		EffectiveRate float64 `json:"effectiveRate"` // Sampled / consumed messages
+	}{"sampled", rate, consumedCount, sampledCount, effectiveRate})
+}
+
 func (p *progressReporter) OnError(message string) {
 	_ = p.websocket.writeJSON(struct {
 		Type    string `json:"type"`
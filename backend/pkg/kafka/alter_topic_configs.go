@@ -0,0 +1,38 @@
+package kafka
+
+import (
+	"fmt"
+
+	"github.com/Shopify/sarama"
+)
+
+// AlterTopicConfigs sets the given config entries for a topic. Take note that the AlterConfigs API is not
+// incremental, all dynamic config entries which are not part of the request will be reset to their defaults.
+func (s *Service) AlterTopicConfigs(topicName string, entries map[string]*string) error {
+	controller, err := s.Client.Controller()
+	if err != nil {
+		return fmt.Errorf("failed to get cluster controller from client: %w", err)
+	}
+
+	req := &sarama.AlterConfigsRequest{
+		Resources: []*sarama.AlterConfigsResource{
+			{
+				Type:          sarama.TopicResource,
+				Name:          topicName,
+				ConfigEntries: entries,
+			},
+		},
+	}
+	res, err := controller.AlterConfigs(req)
+	if err != nil {
+		return err
+	}
+
+	for _, resource := range res.Resources {
+		if resource.ErrorCode != int16(sarama.ErrNoError) {
+			return fmt.Errorf("failed to alter configs of topic '%v': %v - %v", resource.Name, sarama.KError(resource.ErrorCode), resource.ErrorMsg)
+		}
+	}
+
+	return nil
+}
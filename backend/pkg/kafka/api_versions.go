@@ -0,0 +1,30 @@
+package kafka
+
+import (
+	"fmt"
+
+	"github.com/Shopify/sarama"
+	"go.uber.org/zap"
+)
+
+// GetAPIVersions returns the API keys along with their supported versions as advertised by any broker
+func (s *Service) GetAPIVersions() (*sarama.ApiVersionsResponse, error) {
+	broker, err := s.findAnyBroker()
+	if err != nil {
+		return nil, err
+	}
+	err = broker.Open(s.Client.Config())
+	if err != nil && err != sarama.ErrAlreadyConnected {
+		s.Logger.Warn("opening the broker connection failed", zap.Error(err))
+	}
+
+	res, err := broker.ApiVersions(&sarama.ApiVersionsRequest{})
+	if err != nil {
+		return nil, err
+	}
+	if res.Err != sarama.ErrNoError {
+		return nil, fmt.Errorf("failed to get api versions: %w", res.Err)
+	}
+
+	return res, nil
+}
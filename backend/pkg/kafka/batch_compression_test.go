@@ -0,0 +1,80 @@
+package kafka
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestPartitionConsumer_WithBatchCompression(t *testing.T) {
+	broker := sarama.NewMockBroker(t, 1)
+	defer broker.Close()
+
+	// The producers of the topic have switched from snappy to zstd
+	fetchResponse := &sarama.FetchResponse{Version: 10}
+	codecs := []sarama.CompressionCodec{sarama.CompressionSnappy, sarama.CompressionSnappy, sarama.CompressionZSTD, sarama.CompressionZSTD}
+	values := []string{`{"id":0}`, `{"id":1}`, `{"id":2}`, `{"id":3}`}
+	for i, codec := range codecs {
+		fetchResponse.AddRecordBatchWithTimestamp("orders", 0, nil, sarama.StringEncoder(values[i]), int64(i), NoProducerID, false, time.Now())
+		fetchResponse.GetBlock("orders", 0).RecordsSet[i].RecordBatch.Codec = codec
+	}
+
+	broker.SetHandlerByMap(map[string]sarama.MockResponse{
+		"MetadataRequest": sarama.NewMockMetadataResponse(t).
+			SetBroker(broker.Addr(), broker.BrokerID()).
+			SetLeader("orders", 0, broker.BrokerID()),
+		"OffsetRequest": sarama.NewMockOffsetResponse(t).
+			SetVersion(1).
+			SetOffset("orders", 0, sarama.OffsetOldest, 0).
+			SetOffset("orders", 0, sarama.OffsetNewest, 4),
+		"FetchRequest": sarama.NewMockWrapper(fetchResponse),
+	})
+
+	cfg := sarama.NewConfig()
+	cfg.Version = sarama.V2_1_0_0
+	client, err := sarama.NewClient([]string{broker.Addr()}, cfg)
+	require.NoError(t, err)
+	defer client.Close()
+	consumer, err := sarama.NewConsumerFromClient(client)
+	require.NoError(t, err)
+	defer consumer.Close()
+
+	doneCh := make(chan struct{}, 1)
+	messageCh := make(chan *TopicMessage, 10)
+	progress := &recordingProgress{}
+	p := &PartitionConsumer{
+		Logger:               zap.NewNop(),
+		DoneCh:               doneCh,
+		MessageCh:            messageCh,
+		Progress:             progress,
+		Consumer:             consumer,
+		TopicName:            "orders",
+		Req:                  &PartitionConsumeRequest{PartitionID: 0, StartOffset: 0, EndOffset: 3, MaxMessageCount: 4},
+		WithBatchCompression: true,
+		FetchRecordBatches: func(offset int64) ([]*RecordBatchInfo, error) {
+			return recordBatchInfos(fetchResponse.GetBlock("orders", 0).RecordsSet), nil
+		},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	p.Run(ctx)
+	<-doneCh
+	close(messageCh)
+
+	compressions := make([]string, 0)
+	decodedValues := make([]string, 0)
+	for msg := range messageCh {
+		compressions = append(compressions, msg.BatchCompression)
+		decodedValues = append(decodedValues, string(msg.Value.Value))
+		assert.Equal(t, valueTypeJSON, msg.Value.ValueType)
+	}
+	assert.Empty(t, progress.errors)
+	assert.Equal(t, []string{"snappy", "snappy", "zstd", "zstd"}, compressions)
+	assert.Equal(t, values, decodedValues)
+}
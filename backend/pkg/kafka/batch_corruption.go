@@ -0,0 +1,69 @@
+package kafka
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/Shopify/sarama"
+)
+
+// corruptBatchReason returns a description of the corruption if the consumer error indicates that a fetched record
+// batch failed its CRC check. The check either fails while sarama decodes the fetch response (corrupted in transport
+// or on disk), or is reported by the broker itself (CORRUPT_MESSAGE).
+func corruptBatchReason(err error) (string, bool) {
+	var decodingErr sarama.PacketDecodingError
+	if errors.As(err, &decodingErr) && strings.HasPrefix(decodingErr.Info, "CRC didn't match") {
+		return decodingErr.Info, true
+	}
+	if errors.Is(err, sarama.ErrInvalidMessage) {
+		return err.Error(), true
+	}
+
+	return "", false
+}
+
+// LocateCorruptedBatch returns the offset of the first record batch between offset and endOffset which fails its
+// CRC check, and false if there is none within the range. Sarama reports a fetch response which fails to decode to
+// all partitions of the broker, therefore the partition is refetched on its own first. The corrupted batch is then
+// located by fetching one batch at a time, because a fetch fails entirely if any of its batches is corrupted.
+func (s *Service) LocateCorruptedBatch(topic string, partitionID int32, offset int64, endOffset int64) (int64, string, bool, error) {
+	_, err := s.FetchRecordBatchInfos(topic, partitionID, offset)
+	if err == nil {
+		return 0, "", false, nil
+	}
+	reason, isCorrupt := corruptBatchReason(err)
+	if !isCorrupt {
+		return 0, "", false, err
+	}
+
+	for offset <= endOffset {
+		// Brokers return at least the first batch, even if it is larger than the maximum size
+		block, err := s.fetchBlock(topic, partitionID, offset, sarama.ReadUncommitted, 1)
+		if err != nil {
+			if batchReason, isCorrupt := corruptBatchReason(err); isCorrupt {
+				return offset, batchReason, true, nil
+			}
+			return 0, "", false, err
+		}
+		var batches []*RecordBatchInfo
+		if block != nil {
+			batches = recordBatchInfos(block.RecordsSet)
+		}
+		if len(batches) == 0 {
+			// The batch can't be narrowed down any further, it's at or after this offset
+			return offset, reason, true, nil
+		}
+		offset = batches[len(batches)-1].LastOffset + 1
+	}
+
+	return 0, "", false, nil
+}
+
+// locateCorruptedBatch confirms the CRC error of the consumer, the corrupted batch follows the consumed messages if
+// the consumer has no locator
+func (p *PartitionConsumer) locateCorruptedBatch(nextOffset int64, reason string) (int64, string, bool, error) {
+	if p.LocateCorruptedBatch == nil {
+		return nextOffset, reason, true, nil
+	}
+	return p.LocateCorruptedBatch(nextOffset, p.Req.EndOffset)
+}
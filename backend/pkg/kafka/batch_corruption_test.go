@@ -0,0 +1,207 @@
+package kafka
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"github.com/Shopify/sarama/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// consumeMockPartition consumes partition 0 of the mocked topic "orders" and returns the offsets of all messages
+func consumeMockPartition(t *testing.T, consumer sarama.Consumer, progress IListMessagesProgress, endOffset int64) []int64 {
+	doneCh := make(chan struct{}, 1)
+	messageCh := make(chan *TopicMessage, 10)
+	p := &PartitionConsumer{
+		Logger:    zap.NewNop(),
+		DoneCh:    doneCh,
+		MessageCh: messageCh,
+		Progress:  progress,
+		Consumer:  consumer,
+		TopicName: "orders",
+		Req:       &PartitionConsumeRequest{PartitionID: 0, StartOffset: 0, EndOffset: endOffset, MaxMessageCount: 100},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	p.Run(ctx)
+	<-doneCh
+	close(messageCh)
+	require.NoError(t, ctx.Err())
+
+	offsets := make([]int64, 0)
+	for msg := range messageCh {
+		offsets = append(offsets, msg.Offset)
+	}
+	return offsets
+}
+
+// corruptingProxy forwards connections to the broker and corrupts every occurrence of "corrupt" in its responses,
+// so that the CRC of the records which contain it doesn't match anymore
+func corruptingProxy(t *testing.T, brokerAddr string) net.Listener {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	go func() {
+		for {
+			clientConn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			brokerConn, err := net.Dial("tcp", brokerAddr)
+			if err != nil {
+				clientConn.Close()
+				continue
+			}
+			go func() {
+				_, _ = io.Copy(brokerConn, clientConn)
+				brokerConn.Close()
+			}()
+			go func() {
+				defer clientConn.Close()
+				sizeBuf := make([]byte, 4)
+				for {
+					if _, err := io.ReadFull(brokerConn, sizeBuf); err != nil {
+						return
+					}
+					res := make([]byte, binary.BigEndian.Uint32(sizeBuf))
+					if _, err := io.ReadFull(brokerConn, res); err != nil {
+						return
+					}
+					res = bytes.ReplaceAll(res, []byte("corrupt"), []byte("CORRUPT"))
+					if _, err := clientConn.Write(append(sizeBuf, res...)); err != nil {
+						return
+					}
+				}
+			}()
+		}
+	}()
+
+	return listener
+}
+
+func TestPartitionConsumer_CorruptedBatch(t *testing.T) {
+	broker := sarama.NewMockBroker(t, 1)
+	defer broker.Close()
+	proxy := corruptingProxy(t, broker.Addr())
+	defer proxy.Close()
+
+	// Both partitions are fetched from the same broker, the message at offset 2 of partition 1 fails its CRC check.
+	// Each fetch returns a single message.
+	broker.SetHandlerByMap(map[string]sarama.MockResponse{
+		"MetadataRequest": sarama.NewMockMetadataResponse(t).
+			SetBroker(proxy.Addr().String(), broker.BrokerID()).
+			SetLeader("orders", 0, broker.BrokerID()).
+			SetLeader("orders", 1, broker.BrokerID()),
+		"OffsetRequest": sarama.NewMockOffsetResponse(t).
+			SetVersion(1).
+			SetOffset("orders", 0, sarama.OffsetOldest, 0).
+			SetOffset("orders", 0, sarama.OffsetNewest, 3).
+			SetOffset("orders", 1, sarama.OffsetOldest, 0).
+			SetOffset("orders", 1, sarama.OffsetNewest, 3),
+		"FetchRequest": sarama.NewMockFetchResponse(t, 1).
+			SetVersion(4).
+			SetMessage("orders", 0, 0, sarama.StringEncoder("a")).
+			SetMessage("orders", 0, 1, sarama.StringEncoder("b")).
+			SetMessage("orders", 0, 2, sarama.StringEncoder("c")).
+			SetMessage("orders", 1, 0, sarama.StringEncoder("d")).
+			SetMessage("orders", 1, 1, sarama.StringEncoder("e")).
+			SetMessage("orders", 1, 2, sarama.StringEncoder("corrupt")).
+			SetHighWaterMark("orders", 0, 3).
+			SetHighWaterMark("orders", 1, 3),
+	})
+
+	cfg := sarama.NewConfig()
+	cfg.Version = sarama.V0_11_0_0
+	cfg.Consumer.Return.Errors = true
+	cfg.Consumer.Retry.Backoff = 10 * time.Millisecond
+	client, err := sarama.NewClient([]string{proxy.Addr().String()}, cfg)
+	require.NoError(t, err)
+	defer client.Close()
+	consumer, err := sarama.NewConsumerFromClient(client)
+	require.NoError(t, err)
+	defer consumer.Close()
+	svc := &Service{Client: client, Logger: zap.NewNop()}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	doneCh := make(chan struct{}, 2)
+	messageChs := make([]chan *TopicMessage, 2)
+	progresses := make([]*recordingProgress, 2)
+	for i := range messageChs {
+		partitionID := int32(i)
+		messageChs[i] = make(chan *TopicMessage, 10)
+		progresses[i] = &recordingProgress{}
+		p := &PartitionConsumer{
+			Logger:    zap.NewNop(),
+			DoneCh:    doneCh,
+			MessageCh: messageChs[i],
+			Progress:  progresses[i],
+			Consumer:  consumer,
+			TopicName: "orders",
+			Req:       &PartitionConsumeRequest{PartitionID: partitionID, StartOffset: 0, EndOffset: 2, MaxMessageCount: 100},
+			LocateCorruptedBatch: func(offset int64, endOffset int64) (int64, string, bool, error) {
+				return svc.LocateCorruptedBatch("orders", partitionID, offset, endOffset)
+			},
+		}
+		go p.Run(ctx)
+	}
+	<-doneCh
+	<-doneCh
+	require.NoError(t, ctx.Err())
+
+	offsets := make([][]int64, 2)
+	for i, messageCh := range messageChs {
+		close(messageCh)
+		offsets[i] = make([]int64, 0)
+		for msg := range messageCh {
+			offsets[i] = append(offsets[i], msg.Offset)
+		}
+	}
+
+	// The messages of the valid batches are returned and the corrupted batch following them is reported. Partition
+	// 0 on the same broker gets the same consumer error, but it is not corrupted.
+	assert.Equal(t, []int64{0, 1}, offsets[1])
+	assert.Equal(t, []int64{2}, progresses[1].corruptions)
+	assert.Equal(t, []int64{0, 1, 2}, offsets[0])
+	assert.Empty(t, progresses[0].corruptions)
+	assert.Empty(t, progresses[0].errors)
+	assert.Empty(t, progresses[1].errors)
+}
+
+func TestPartitionConsumer_ValidBatches(t *testing.T) {
+	config := sarama.NewConfig()
+	config.Consumer.Return.Errors = true
+	consumer := mocks.NewConsumer(t, config)
+	defer consumer.Close()
+
+	pConsumer := consumer.ExpectConsumePartition("orders", 0, 0)
+	pConsumer.YieldError(sarama.ErrNotLeaderForPartition)
+	pConsumer.YieldMessage(&sarama.ConsumerMessage{Value: []byte("a")})
+	pConsumer.YieldMessage(&sarama.ConsumerMessage{Value: []byte("b")})
+
+	progress := &recordingProgress{}
+	offsets := consumeMockPartition(t, consumer, progress, 2)
+
+	assert.Equal(t, []int64{1, 2}, offsets)
+	assert.Empty(t, progress.corruptions)
+}
+
+func TestCorruptBatchReason(t *testing.T) {
+	_, isCorrupt := corruptBatchReason(sarama.PacketDecodingError{Info: "CRC didn't match expected 0x1 got 0x2"})
+	assert.True(t, isCorrupt)
+	_, isCorrupt = corruptBatchReason(sarama.ErrInvalidMessage)
+	assert.True(t, isCorrupt)
+	_, isCorrupt = corruptBatchReason(sarama.PacketDecodingError{Info: "unknown magic byte (3)"})
+	assert.False(t, isCorrupt)
+	_, isCorrupt = corruptBatchReason(sarama.ErrNotLeaderForPartition)
+	assert.False(t, isCorrupt)
+}
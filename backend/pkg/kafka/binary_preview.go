@@ -0,0 +1,46 @@
+package kafka
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+)
+
+const (
+	// BinaryEncodingBase64 is the encoding of binary values in the browse output
+	BinaryEncodingBase64 = "base64"
+
+	// binaryPreviewLength is the number of leading bytes which are rendered as hex in the binary preview
+	binaryPreviewLength = 16
+)
+
+// BinaryPreview describes a value which could not be decoded as text, JSON or XML and is therefore returned base64
+// encoded. The hex preview of the leading bytes helps to identify opaque payloads, e.g. by their magic bytes.
+type BinaryPreview struct {
+	Encoding    string `json:"encoding"`
+	Length      int    `json:"length"` // Length of the raw value in bytes
+	HexPreview  string `json:"hexPreview"`
+	IsTruncated bool   `json:"isTruncated"` // True if the value is longer than the hex preview
+}
+
+// newBinaryPreview returns the preview of a binary value or nil if the value is not binary
+func newBinaryPreview(value DirectEmbedding) *BinaryPreview {
+	if value.ValueType != valueTypeBinary {
+		return nil
+	}
+	raw, err := base64.StdEncoding.DecodeString(string(value.Value))
+	if err != nil {
+		return nil
+	}
+
+	preview := raw
+	if len(preview) > binaryPreviewLength {
+		preview = preview[:binaryPreviewLength]
+	}
+
+	return &BinaryPreview{
+		Encoding:    BinaryEncodingBase64,
+		Length:      len(raw),
+		HexPreview:  hex.EncodeToString(preview),
+		IsTruncated: len(raw) > binaryPreviewLength,
+	}
+}
@@ -0,0 +1,31 @@
+package kafka
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewBinaryPreview(t *testing.T) {
+	raw := []byte{0xde, 0xad, 0xbe, 0xef, 0x00, 0xff, 0x10, 0x20, 0x30, 0x40, 0x50, 0x60, 0x70, 0x80, 0x90, 0xa0, 0xb0, 0xc0}
+
+	vType, value := decodeValue(raw, MessageFormatAuto)
+	require.Equal(t, valueTypeBinary, vType)
+	assert.Equal(t, "3q2+7wD/ECAwQFBgcICQoLDA", string(value.Value))
+
+	preview := newBinaryPreview(value)
+	require.NotNil(t, preview)
+	assert.Equal(t, &BinaryPreview{
+		Encoding:    BinaryEncodingBase64,
+		Length:      18,
+		HexPreview:  "deadbeef00ff102030405060708090a0",
+		IsTruncated: true,
+	}, preview)
+
+	_, short := decodeValue([]byte{0xca, 0xfe}, string(valueTypeBinary))
+	assert.Equal(t, &BinaryPreview{Encoding: BinaryEncodingBase64, Length: 2, HexPreview: "cafe"}, newBinaryPreview(short))
+
+	_, text := decodeValue([]byte("hello"), MessageFormatAuto)
+	assert.Nil(t, newBinaryPreview(text))
+}
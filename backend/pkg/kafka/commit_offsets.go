@@ -0,0 +1,90 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"go.uber.org/zap"
+)
+
+const (
+	commitOffsetsMaxAttempts  = 5
+	commitOffsetsRetryBackoff = 250 * time.Millisecond
+)
+
+// CommitConsumerGroupOffsets commits the given offsets (topic -> partitionID -> offset) for a consumer group which
+// has no active members. If the group coordinator has moved or is still loading the group's offsets (e.g. during
+// a coordinator failover) the coordinator will be rediscovered and the commit will be retried with a backoff.
+// Partition errors other than coordinator errors are returned as part of the response.
+func (s *Service) CommitConsumerGroupOffsets(ctx context.Context, group string, offsets map[string]map[int32]int64) (*sarama.OffsetCommitResponse, error) {
+	req := &sarama.OffsetCommitRequest{
+		Version:                 2,
+		ConsumerGroup:           group,
+		ConsumerGroupGeneration: sarama.GroupGenerationUndefined,
+		ConsumerID:              "",
+		RetentionTime:           -1, // Use broker's default offset retention time
+	}
+	for topic, partitions := range offsets {
+		for partitionID, offset := range partitions {
+			req.AddBlock(topic, partitionID, offset, sarama.ReceiveTime, "")
+		}
+	}
+
+	for attempt := 1; ; attempt++ {
+		res, err := s.commitOffsets(group, req)
+		if err == nil {
+			return res, nil
+		}
+		if !isCoordinatorMovedError(err) || attempt == commitOffsetsMaxAttempts {
+			return nil, fmt.Errorf("failed to commit offsets after %v attempt(s): %w", attempt, err)
+		}
+
+		s.Logger.Debug("group coordinator is not available, rediscovering coordinator before retrying to commit offsets",
+			zap.String("group", group), zap.Int("attempt", attempt), zap.Error(err))
+		if err := s.Client.RefreshCoordinator(group); err != nil {
+			s.Logger.Warn("failed to refresh group coordinator", zap.String("group", group), zap.Error(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(time.Duration(attempt) * commitOffsetsRetryBackoff):
+		}
+	}
+}
+
+// commitOffsets sends the commit request to the group's current coordinator. Coordinator errors which are reported
+// for single partitions are returned as error so that the whole request can be retried.
+func (s *Service) commitOffsets(group string, req *sarama.OffsetCommitRequest) (*sarama.OffsetCommitResponse, error) {
+	coordinator, err := s.Client.Coordinator(group)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := coordinator.CommitOffset(req)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, partitions := range res.Errors {
+		for _, kErr := range partitions {
+			if isCoordinatorMovedError(kErr) {
+				return nil, kErr
+			}
+		}
+	}
+
+	return res, nil
+}
+
+// isCoordinatorMovedError returns true for all errors which can be resolved by retrying against the (newly
+// discovered) group coordinator.
+func isCoordinatorMovedError(err error) bool {
+	switch err {
+	case sarama.ErrNotCoordinatorForConsumer, sarama.ErrOffsetsLoadInProgress, sarama.ErrConsumerCoordinatorNotAvailable:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,48 @@
+package kafka
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Shopify/sarama"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestCommitConsumerGroupOffsets_RetryOnNotCoordinator(t *testing.T) {
+	broker := sarama.NewMockBroker(t, 0)
+	defer broker.Close()
+
+	notCoordinator := &sarama.OffsetCommitResponse{}
+	notCoordinator.AddError("orders", 0, sarama.ErrNotCoordinatorForConsumer)
+	committed := &sarama.OffsetCommitResponse{}
+	committed.AddError("orders", 0, sarama.ErrNoError)
+
+	broker.SetHandlerByMap(map[string]sarama.MockResponse{
+		"MetadataRequest": sarama.NewMockMetadataResponse(t).
+			SetBroker(broker.Addr(), broker.BrokerID()),
+		"FindCoordinatorRequest": sarama.NewMockFindCoordinatorResponse(t).
+			SetCoordinator(sarama.CoordinatorGroup, "my-group", broker),
+		"OffsetCommitRequest": sarama.NewMockSequence(notCoordinator, committed),
+	})
+
+	cfg := sarama.NewConfig()
+	cfg.Version = sarama.V1_0_0_0
+	client, err := sarama.NewClient([]string{broker.Addr()}, cfg)
+	require.NoError(t, err)
+	defer client.Close()
+
+	svc := &Service{Client: client, Logger: zap.NewNop()}
+	res, err := svc.CommitConsumerGroupOffsets(context.Background(), "my-group", map[string]map[int32]int64{"orders": {0: 42}})
+	require.NoError(t, err)
+	assert.Equal(t, sarama.ErrNoError, res.Errors["orders"][0])
+
+	commitRequests := 0
+	for _, req := range broker.History() {
+		if _, ok := req.Request.(*sarama.OffsetCommitRequest); ok {
+			commitRequests++
+		}
+	}
+	assert.Equal(t, 2, commitRequests)
+}
@@ -3,7 +3,9 @@ package kafka
 import (
 	"flag"
 	"fmt"
+
 	"github.com/Shopify/sarama"
+	"github.com/cloudhut/kowl/backend/pkg/schema"
 )
 
 // Config required for opening a connection to Kafka
@@ -13,14 +15,16 @@ type Config struct {
 	ClientID       string   `yaml:"clientId"`
 	ClusterVersion string   `yaml:"clusterVersion"`
 
-	TLS  TLSConfig  `yaml:"tls"`
-	SASL SASLConfig `yaml:"sasl"`
+	TLS    TLSConfig     `yaml:"tls"`
+	SASL   SASLConfig    `yaml:"sasl"`
+	Schema schema.Config `yaml:"schemaRegistry"`
 }
 
 // RegisterFlags registers all nested config flags.
 func (c *Config) RegisterFlags(f *flag.FlagSet) {
 	c.TLS.RegisterFlags(f)
 	c.SASL.RegisterFlags(f)
+	c.Schema.RegisterFlags(f)
 }
 
 // Validate the Kafka config
@@ -34,6 +38,11 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("failed to parse the given clusterVersion for Kafka: %w", err)
 	}
 
+	err = c.Schema.Validate()
+	if err != nil {
+		return fmt.Errorf("failed to validate schema registry config: %w", err)
+	}
+
 	return nil
 }
 
@@ -0,0 +1,280 @@
+package kafka
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	ConnectTopicTypeNone    = ""
+	ConnectTopicTypeOffsets = "offsets"
+	ConnectTopicTypeStatus  = "status"
+	ConnectTopicTypeConfigs = "configs"
+)
+
+const (
+	ConnectRecordTypeSourceOffset    = "sourceOffset"
+	ConnectRecordTypeConnectorStatus = "connectorStatus"
+	ConnectRecordTypeTaskStatus      = "taskStatus"
+	ConnectRecordTypeTopicStatus     = "topicStatus"
+	ConnectRecordTypeConnectorConfig = "connectorConfig"
+	ConnectRecordTypeTaskConfig      = "taskConfig"
+	ConnectRecordTypeTasksCommit     = "tasksCommit"
+	ConnectRecordTypeTargetState     = "targetState"
+	ConnectRecordTypeUnknown         = "unknown"
+)
+
+// ConnectRecord is the decoded representation of a record in one of Kafka Connect's internal topics
+type ConnectRecord struct {
+	Type      string `json:"type"`
+	Connector string `json:"connector,omitempty"`
+	TaskID    *int32 `json:"taskId,omitempty"`
+	Topic     string `json:"topic,omitempty"` // Only set for topic status records
+
+	// Offset records
+	SourcePartition interface{} `json:"sourcePartition,omitempty"`
+	SourceOffset    interface{} `json:"sourceOffset,omitempty"`
+
+	// Status records
+	State      string `json:"state,omitempty"`
+	Trace      string `json:"trace,omitempty"`
+	WorkerID   string `json:"workerId,omitempty"`
+	Generation *int64 `json:"generation,omitempty"`
+
+	// Config records
+	Config interface{} `json:"config,omitempty"`
+
+	// IsTombstone is true if the record has a null value, e.g. the offsets have been reset or the connector deleted
+	IsTombstone bool   `json:"isTombstone"`
+	Error       string `json:"error,omitempty"`
+}
+
+// IsValidConnectTopicType returns true if the given type is a supported Kafka Connect internal topic type
+func IsValidConnectTopicType(topicType string) bool {
+	switch topicType {
+	case ConnectTopicTypeNone, ConnectTopicTypeOffsets, ConnectTopicTypeStatus, ConnectTopicTypeConfigs:
+		return true
+	default:
+		return false
+	}
+}
+
+// GuessConnectTopicType returns the Connect topic type based on the topic name. Connect's internal topic names are
+// configurable, thus only the default names and their commonly used prefixed variants (e.g. "my-cluster-connect-offsets")
+// are detected.
+func GuessConnectTopicType(topicName string) string {
+	switch {
+	case strings.HasSuffix(topicName, "connect-offsets"):
+		return ConnectTopicTypeOffsets
+	case strings.HasSuffix(topicName, "connect-status"):
+		return ConnectTopicTypeStatus
+	case strings.HasSuffix(topicName, "connect-configs"):
+		return ConnectTopicTypeConfigs
+	default:
+		return ConnectTopicTypeNone
+	}
+}
+
+// decodeConnectRecord decodes the Connect record of a consumed message. If processors are configured, the processed
+// JSON value is decoded instead of the raw value, so that fields which have been redacted or removed are not sent to
+// the user as part of the Connect record. Values which could not be processed have been removed, hence no record is
+// decoded for them.
+func (p *PartitionConsumer) decodeConnectRecord(key []byte, rawValue []byte, value DirectEmbedding) *ConnectRecord {
+	connectValue := rawValue
+	if len(p.Processors) > 0 && rawValue != nil && value.ValueType == valueTypeJSON {
+		if value.Value == nil {
+			return nil
+		}
+		connectValue = value.Value
+	}
+
+	return decodeConnectRecord(p.ConnectTopicType, key, connectValue)
+}
+
+// decodeConnectRecord decodes a record of the given Connect topic type. Records which can't be decoded are returned
+// with the type unknown and an error description, so that the raw key and value are still shown.
+func decodeConnectRecord(topicType string, key []byte, value []byte) *ConnectRecord {
+	var record *ConnectRecord
+	var err error
+	switch topicType {
+	case ConnectTopicTypeOffsets:
+		record, err = decodeConnectOffsetRecord(key, value)
+	case ConnectTopicTypeStatus:
+		record, err = decodeConnectStatusRecord(key, value)
+	case ConnectTopicTypeConfigs:
+		record, err = decodeConnectConfigRecord(key, value)
+	default:
+		return nil
+	}
+	if err != nil {
+		return &ConnectRecord{Type: ConnectRecordTypeUnknown, Error: err.Error(), IsTombstone: value == nil}
+	}
+
+	return record
+}
+
+// decodeConnectOffsetRecord decodes a source connector offset. The key is a JSON array consisting of the connector
+// name and the source partition, the value is the source offset.
+func decodeConnectOffsetRecord(key []byte, value []byte) (*ConnectRecord, error) {
+	var k []interface{}
+	if err := decodeConnectJSON(key, &k); err != nil {
+		return nil, fmt.Errorf("failed to decode offset key: %w", err)
+	}
+	if len(k) != 2 {
+		return nil, fmt.Errorf("expected offset key to be an array of connector name and source partition")
+	}
+	connector, ok := k[0].(string)
+	if !ok {
+		return nil, fmt.Errorf("expected connector name in offset key to be a string")
+	}
+
+	record := &ConnectRecord{
+		Type:            ConnectRecordTypeSourceOffset,
+		Connector:       connector,
+		SourcePartition: k[1],
+		IsTombstone:     value == nil,
+	}
+	if value == nil {
+		return record, nil
+	}
+
+	var offset interface{}
+	if err := decodeConnectJSON(value, &offset); err != nil {
+		return nil, fmt.Errorf("failed to decode offset value: %w", err)
+	}
+	record.SourceOffset = offset
+
+	return record, nil
+}
+
+// decodeConnectStatusRecord decodes a connector, task or topic status record. The key has one of the formats
+// "status-connector-<name>", "status-task-<name>-<id>" or "status-topic-<topic>:connector-<name>".
+func decodeConnectStatusRecord(key []byte, value []byte) (*ConnectRecord, error) {
+	k := string(key)
+	record := &ConnectRecord{IsTombstone: value == nil}
+
+	switch {
+	case strings.HasPrefix(k, "status-connector-"):
+		record.Type = ConnectRecordTypeConnectorStatus
+		record.Connector = strings.TrimPrefix(k, "status-connector-")
+	case strings.HasPrefix(k, "status-task-"):
+		connector, taskID, err := parseConnectTaskID(strings.TrimPrefix(k, "status-task-"))
+		if err != nil {
+			return nil, err
+		}
+		record.Type = ConnectRecordTypeTaskStatus
+		record.Connector = connector
+		record.TaskID = &taskID
+	case strings.HasPrefix(k, "status-topic-"):
+		parts := strings.SplitN(strings.TrimPrefix(k, "status-topic-"), ":connector-", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("unexpected topic status key '%v'", k)
+		}
+		record.Type = ConnectRecordTypeTopicStatus
+		record.Topic = parts[0]
+		record.Connector = parts[1]
+	default:
+		return nil, fmt.Errorf("unexpected status key '%v'", k)
+	}
+	if value == nil || record.Type == ConnectRecordTypeTopicStatus {
+		return record, nil
+	}
+
+	var status struct {
+		State      string  `json:"state"`
+		Trace      *string `json:"trace"`
+		WorkerID   string  `json:"worker_id"`
+		Generation *int64  `json:"generation"`
+	}
+	if err := decodeConnectJSON(value, &status); err != nil {
+		return nil, fmt.Errorf("failed to decode status value: %w", err)
+	}
+	record.State = status.State
+	record.WorkerID = status.WorkerID
+	record.Generation = status.Generation
+	if status.Trace != nil {
+		record.Trace = *status.Trace
+	}
+
+	return record, nil
+}
+
+// decodeConnectConfigRecord decodes a record of the configs topic. The key has one of the formats "connector-<name>",
+// "task-<name>-<id>", "commit-<name>" or "target-state-<name>".
+func decodeConnectConfigRecord(key []byte, value []byte) (*ConnectRecord, error) {
+	k := string(key)
+	record := &ConnectRecord{IsTombstone: value == nil}
+
+	switch {
+	case strings.HasPrefix(k, "connector-"):
+		record.Type = ConnectRecordTypeConnectorConfig
+		record.Connector = strings.TrimPrefix(k, "connector-")
+	case strings.HasPrefix(k, "task-"):
+		connector, taskID, err := parseConnectTaskID(strings.TrimPrefix(k, "task-"))
+		if err != nil {
+			return nil, err
+		}
+		record.Type = ConnectRecordTypeTaskConfig
+		record.Connector = connector
+		record.TaskID = &taskID
+	case strings.HasPrefix(k, "commit-"):
+		record.Type = ConnectRecordTypeTasksCommit
+		record.Connector = strings.TrimPrefix(k, "commit-")
+	case strings.HasPrefix(k, "target-state-"):
+		record.Type = ConnectRecordTypeTargetState
+		record.Connector = strings.TrimPrefix(k, "target-state-")
+	default:
+		return nil, fmt.Errorf("unexpected config key '%v'", k)
+	}
+	if value == nil {
+		return record, nil
+	}
+
+	var config interface{}
+	if err := decodeConnectJSON(value, &config); err != nil {
+		return nil, fmt.Errorf("failed to decode config value: %w", err)
+	}
+	if m, ok := config.(map[string]interface{}); ok && record.Type == ConnectRecordTypeTargetState {
+		if state, ok := m["state"].(string); ok {
+			record.State = state
+		}
+	}
+	record.Config = config
+
+	return record, nil
+}
+
+// parseConnectTaskID splits "<connector>-<task id>" into the connector name and the task id. Connector names may
+// contain dashes themselves, hence the last dash is the separator.
+func parseConnectTaskID(s string) (string, int32, error) {
+	idx := strings.LastIndex(s, "-")
+	if idx <= 0 {
+		return "", 0, fmt.Errorf("failed to parse task id from '%v'", s)
+	}
+	taskID, err := strconv.ParseInt(s[idx+1:], 10, 32)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to parse task id from '%v': %w", s, err)
+	}
+
+	return s[:idx], int32(taskID), nil
+}
+
+// decodeConnectJSON decodes JSON serialized by Connect's JsonConverter. If schemas are enabled the actual data is
+// wrapped in an envelope with the fields "schema" and "payload", which is unwrapped transparently.
+func decodeConnectJSON(data []byte, v interface{}) error {
+	var envelope map[string]json.RawMessage
+	if err := json.Unmarshal(data, &envelope); err == nil && len(envelope) == 2 {
+		_, hasSchema := envelope["schema"]
+		payload, hasPayload := envelope["payload"]
+		if hasSchema && hasPayload {
+			data = payload
+		}
+	}
+
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	return d.Decode(v)
+}
@@ -0,0 +1,48 @@
+package kafka
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeConnectRecord_SourceOffset(t *testing.T) {
+	key := []byte(`["jdbc-source",{"table":"orders"}]`)
+	value := []byte(`{"schema":{"type":"struct"},"payload":{"incrementing":42}}`)
+
+	record := decodeConnectRecord(ConnectTopicTypeOffsets, key, value)
+	require.NotNil(t, record)
+	assert.Empty(t, record.Error)
+	assert.Equal(t, ConnectRecordTypeSourceOffset, record.Type)
+	assert.Equal(t, "jdbc-source", record.Connector)
+	assert.Equal(t, map[string]interface{}{"table": "orders"}, record.SourcePartition)
+	assert.Equal(t, map[string]interface{}{"incrementing": json.Number("42")}, record.SourceOffset)
+	assert.False(t, record.IsTombstone)
+}
+
+func TestDecodeConnectRecord_TaskStatus(t *testing.T) {
+	key := []byte("status-task-my-sink-connector-3")
+	value := []byte(`{"state":"FAILED","trace":"java.lang.NullPointerException","worker_id":"10.0.0.1:8083","generation":7}`)
+
+	record := decodeConnectRecord(ConnectTopicTypeStatus, key, value)
+	require.NotNil(t, record)
+	assert.Empty(t, record.Error)
+	assert.Equal(t, ConnectRecordTypeTaskStatus, record.Type)
+	assert.Equal(t, "my-sink-connector", record.Connector)
+	require.NotNil(t, record.TaskID)
+	assert.Equal(t, int32(3), *record.TaskID)
+	assert.Equal(t, "FAILED", record.State)
+	assert.Equal(t, "java.lang.NullPointerException", record.Trace)
+	assert.Equal(t, "10.0.0.1:8083", record.WorkerID)
+	require.NotNil(t, record.Generation)
+	assert.Equal(t, int64(7), *record.Generation)
+}
+
+func TestDecodeConnectRecord_Invalid(t *testing.T) {
+	record := decodeConnectRecord(ConnectTopicTypeStatus, []byte("something-else"), []byte(`{}`))
+	require.NotNil(t, record)
+	assert.Equal(t, ConnectRecordTypeUnknown, record.Type)
+	assert.NotEmpty(t, record.Error)
+}
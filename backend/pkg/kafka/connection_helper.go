@@ -25,6 +25,9 @@ func NewSaramaConfig(cfg *Config) (*sarama.Config, error) {
 	sConfig.Version = version
 	sConfig.Net.KeepAlive = 30 * time.Second
 
+	// Consumer errors are required to detect corrupted record batches
+	sConfig.Consumer.Return.Errors = true
+
 	// Configure TLS
 	if cfg.TLS.Enabled {
 		sConfig.Net.TLS.Enable = true
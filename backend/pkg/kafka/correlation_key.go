@@ -0,0 +1,81 @@
+package kafka
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/Shopify/sarama"
+)
+
+const (
+	// CorrelationKeySourceHeader reads the correlation key from a record header
+	CorrelationKeySourceHeader = "header"
+	// CorrelationKeySourceJSONField reads the correlation key from a field of the JSON value
+	CorrelationKeySourceJSONField = "jsonField"
+)
+
+// CorrelationKey defines where the key is read from which correlates messages across topics, e.g. a request with
+// its response
+type CorrelationKey struct {
+	Source string `json:"source"`
+
+	// Name is the header key or the path of the JSON field in the projection's path notation, e.g. "meta.requestId"
+	Name string `json:"name"`
+}
+
+// Validate the correlation key definition
+func (c *CorrelationKey) Validate() error {
+	if c.Name == "" {
+		return fmt.Errorf("correlation key name must be set")
+	}
+
+	switch c.Source {
+	case CorrelationKeySourceHeader:
+		return nil
+	case CorrelationKeySourceJSONField:
+		if err := ValidateProjectionPaths([]string{c.Name}); err != nil {
+			return fmt.Errorf("invalid correlation key path: %w", err)
+		}
+		return nil
+	default:
+		return fmt.Errorf("correlation key source '%v' is not supported", c.Source)
+	}
+}
+
+// extract returns the message's correlation key. The second return value is false if the message has no key.
+// Non string JSON fields are returned as their JSON representation.
+func (c *CorrelationKey) extract(headers []*sarama.RecordHeader, value DirectEmbedding) (string, bool) {
+	if c.Source == CorrelationKeySourceHeader {
+		for _, header := range headers {
+			if string(header.Key) == c.Name {
+				return string(header.Value), len(header.Value) > 0
+			}
+		}
+		return "", false
+	}
+
+	if value.ValueType != valueTypeJSON {
+		return "", false
+	}
+	doc, err := decodeJSON(value.Value)
+	if err != nil {
+		return "", false
+	}
+	segments, err := parseProjectionPath(c.Name)
+	if err != nil {
+		return "", false
+	}
+	match, ok := selectJSONPath(doc, segments)
+	if !ok || match == nil {
+		return "", false
+	}
+	if str, isString := match.(string); isString {
+		return str, str != ""
+	}
+	encoded, err := json.Marshal(match)
+	if err != nil {
+		return "", false
+	}
+
+	return string(encoded), true
+}
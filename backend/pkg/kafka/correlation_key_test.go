@@ -0,0 +1,35 @@
+package kafka
+
+import (
+	"testing"
+
+	"github.com/Shopify/sarama"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCorrelationKey_Extract(t *testing.T) {
+	headers := []*sarama.RecordHeader{{Key: []byte("correlation-id"), Value: []byte("req-42")}}
+	value := DirectEmbedding{ValueType: valueTypeJSON, Value: []byte(`{"meta":{"requestId":"req-7","attempt":2}}`)}
+
+	header := &CorrelationKey{Source: CorrelationKeySourceHeader, Name: "correlation-id"}
+	key, ok := header.extract(headers, value)
+	assert.True(t, ok)
+	assert.Equal(t, "req-42", key)
+
+	field := &CorrelationKey{Source: CorrelationKeySourceJSONField, Name: "meta.requestId"}
+	key, ok = field.extract(headers, value)
+	assert.True(t, ok)
+	assert.Equal(t, "req-7", key)
+
+	numeric := &CorrelationKey{Source: CorrelationKeySourceJSONField, Name: "meta.attempt"}
+	key, _ = numeric.extract(nil, value)
+	assert.Equal(t, "2", key)
+
+	_, ok = (&CorrelationKey{Source: CorrelationKeySourceHeader, Name: "missing"}).extract(headers, value)
+	assert.False(t, ok)
+	_, ok = field.extract(nil, DirectEmbedding{ValueType: valueTypeText, Value: []byte("req-7")})
+	assert.False(t, ok)
+
+	assert.Error(t, (&CorrelationKey{Source: "body", Name: "id"}).Validate())
+	assert.Error(t, (&CorrelationKey{Source: CorrelationKeySourceHeader}).Validate())
+}
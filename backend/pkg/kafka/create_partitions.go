@@ -0,0 +1,32 @@
+package kafka
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Shopify/sarama"
+)
+
+// CreatePartitions increases the partition count of the given topic to the new total partition count.
+// Replica assignments for the new partitions are left to the controller.
+func (s *Service) CreatePartitions(topicName string, totalCount int32) error {
+	controller, err := s.Client.Controller()
+	if err != nil {
+		return fmt.Errorf("failed to get cluster controller from client: %w", err)
+	}
+
+	req := &sarama.CreatePartitionsRequest{
+		TopicPartitions: map[string]*sarama.TopicPartition{topicName: {Count: totalCount}},
+		Timeout:         10 * time.Second,
+	}
+	res, err := controller.CreatePartitions(req)
+	if err != nil {
+		return err
+	}
+
+	if partitionErr, ok := res.TopicPartitionErrors[topicName]; ok && partitionErr.Err != sarama.ErrNoError {
+		return partitionErr
+	}
+
+	return nil
+}
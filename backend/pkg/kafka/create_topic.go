@@ -0,0 +1,31 @@
+package kafka
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Shopify/sarama"
+)
+
+// CreateTopic creates a new topic with the given topic details. Requests must be sent to the controller broker.
+func (s *Service) CreateTopic(topicName string, detail *sarama.TopicDetail) error {
+	controller, err := s.Client.Controller()
+	if err != nil {
+		return fmt.Errorf("failed to get cluster controller from client: %w", err)
+	}
+
+	req := &sarama.CreateTopicsRequest{
+		TopicDetails: map[string]*sarama.TopicDetail{topicName: detail},
+		Timeout:      10 * time.Second,
+	}
+	res, err := controller.CreateTopics(req)
+	if err != nil {
+		return err
+	}
+
+	if topicErr, ok := res.TopicErrors[topicName]; ok && topicErr.Err != sarama.ErrNoError {
+		return topicErr
+	}
+
+	return nil
+}
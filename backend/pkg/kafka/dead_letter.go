@@ -0,0 +1,131 @@
+package kafka
+
+import (
+	"encoding/binary"
+	"strconv"
+	"strings"
+
+	"github.com/Shopify/sarama"
+)
+
+const (
+	DeadLetterConventionSpring  = "spring"
+	DeadLetterConventionConnect = "connect"
+)
+
+const (
+	springDeadLetterHeaderPrefix  = "kafka_dlt-"
+	connectDeadLetterHeaderPrefix = "__connect.errors."
+)
+
+// DeadLetterInfo is the error context of a record in a dead-letter topic, as written by Spring Kafka's
+// DeadLetterPublishingRecoverer or Kafka Connect's dead letter queue (errors.deadletterqueue.context.headers.enable).
+// The record's value is the original payload and is decoded as usual.
+type DeadLetterInfo struct {
+	Convention string `json:"convention"` // spring or connect
+
+	SourceTopic     string `json:"sourceTopic,omitempty"`
+	SourcePartition *int32 `json:"sourcePartition,omitempty"`
+	SourceOffset    *int64 `json:"sourceOffset,omitempty"`
+
+	ExceptionClass   string `json:"exceptionClass,omitempty"`
+	ExceptionMessage string `json:"exceptionMessage,omitempty"`
+	StackTrace       string `json:"stackTrace,omitempty"`
+
+	// Connector, TaskID and Stage are only set by Kafka Connect
+	Connector string `json:"connector,omitempty"`
+	TaskID    *int32 `json:"taskId,omitempty"`
+	Stage     string `json:"stage,omitempty"`
+}
+
+// decodeDeadLetterHeaders returns the error context if the headers follow one of the known dead letter
+// conventions, nil otherwise
+func decodeDeadLetterHeaders(headers []*sarama.RecordHeader) *DeadLetterInfo {
+	for _, header := range headers {
+		if header == nil {
+			continue
+		}
+		switch key := string(header.Key); {
+		case strings.HasPrefix(key, springDeadLetterHeaderPrefix):
+			return decodeSpringDeadLetterHeaders(headers)
+		case strings.HasPrefix(key, connectDeadLetterHeaderPrefix):
+			return decodeConnectDeadLetterHeaders(headers)
+		}
+	}
+
+	return nil
+}
+
+// decodeSpringDeadLetterHeaders decodes Spring Kafka's headers. The original partition and offset are binary
+// encoded (big endian int32 and int64), all other headers are strings.
+func decodeSpringDeadLetterHeaders(headers []*sarama.RecordHeader) *DeadLetterInfo {
+	info := &DeadLetterInfo{Convention: DeadLetterConventionSpring}
+	for _, header := range headers {
+		if header == nil {
+			continue
+		}
+		switch strings.TrimPrefix(string(header.Key), springDeadLetterHeaderPrefix) {
+		case "original-topic":
+			info.SourceTopic = string(header.Value)
+		case "original-partition":
+			if len(header.Value) == 4 {
+				partitionID := int32(binary.BigEndian.Uint32(header.Value))
+				info.SourcePartition = &partitionID
+			}
+		case "original-offset":
+			if len(header.Value) == 8 {
+				offset := int64(binary.BigEndian.Uint64(header.Value))
+				info.SourceOffset = &offset
+			}
+		case "exception-fqcn":
+			info.ExceptionClass = string(header.Value)
+		case "exception-message":
+			info.ExceptionMessage = string(header.Value)
+		case "exception-stacktrace":
+			info.StackTrace = string(header.Value)
+		}
+	}
+
+	return info
+}
+
+// decodeConnectDeadLetterHeaders decodes Kafka Connect's headers, which are all strings
+func decodeConnectDeadLetterHeaders(headers []*sarama.RecordHeader) *DeadLetterInfo {
+	info := &DeadLetterInfo{Convention: DeadLetterConventionConnect}
+	for _, header := range headers {
+		if header == nil {
+			continue
+		}
+		value := string(header.Value)
+		switch strings.TrimPrefix(string(header.Key), connectDeadLetterHeaderPrefix) {
+		case "topic":
+			info.SourceTopic = value
+		case "partition":
+			if partitionID, err := strconv.ParseInt(value, 10, 32); err == nil {
+				p := int32(partitionID)
+				info.SourcePartition = &p
+			}
+		case "offset":
+			if offset, err := strconv.ParseInt(value, 10, 64); err == nil {
+				info.SourceOffset = &offset
+			}
+		case "exception.class.name":
+			info.ExceptionClass = value
+		case "exception.message":
+			info.ExceptionMessage = value
+		case "exception.stacktrace":
+			info.StackTrace = value
+		case "connector.name":
+			info.Connector = value
+		case "task.id":
+			if taskID, err := strconv.ParseInt(value, 10, 32); err == nil {
+				t := int32(taskID)
+				info.TaskID = &t
+			}
+		case "stage":
+			info.Stage = value
+		}
+	}
+
+	return info
+}
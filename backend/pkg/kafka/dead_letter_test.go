@@ -0,0 +1,69 @@
+package kafka
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/Shopify/sarama"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeDeadLetterHeaders_Spring(t *testing.T) {
+	partition := make([]byte, 4)
+	binary.BigEndian.PutUint32(partition, 3)
+	offset := make([]byte, 8)
+	binary.BigEndian.PutUint64(offset, 1234)
+
+	headers := []*sarama.RecordHeader{
+		{Key: []byte("traceparent"), Value: []byte("00-abc-def-01")},
+		{Key: []byte("kafka_dlt-original-topic"), Value: []byte("orders")},
+		{Key: []byte("kafka_dlt-original-partition"), Value: partition},
+		{Key: []byte("kafka_dlt-original-offset"), Value: offset},
+		{Key: []byte("kafka_dlt-exception-fqcn"), Value: []byte("org.springframework.kafka.listener.ListenerExecutionFailedException")},
+		{Key: []byte("kafka_dlt-exception-message"), Value: []byte("Listener failed; nested exception is java.lang.NullPointerException")},
+		{Key: []byte("kafka_dlt-exception-stacktrace"), Value: []byte("java.lang.NullPointerException\n\tat com.acme.OrderListener.onOrder(OrderListener.java:42)")},
+	}
+
+	info := decodeDeadLetterHeaders(headers)
+	require.NotNil(t, info)
+	assert.Equal(t, DeadLetterConventionSpring, info.Convention)
+	assert.Equal(t, "orders", info.SourceTopic)
+	require.NotNil(t, info.SourcePartition)
+	assert.Equal(t, int32(3), *info.SourcePartition)
+	require.NotNil(t, info.SourceOffset)
+	assert.Equal(t, int64(1234), *info.SourceOffset)
+	assert.Equal(t, "org.springframework.kafka.listener.ListenerExecutionFailedException", info.ExceptionClass)
+	assert.Contains(t, info.ExceptionMessage, "NullPointerException")
+	assert.Contains(t, info.StackTrace, "OrderListener.java:42")
+	assert.Empty(t, info.Connector)
+}
+
+func TestDecodeDeadLetterHeaders_Connect(t *testing.T) {
+	headers := []*sarama.RecordHeader{
+		{Key: []byte("__connect.errors.topic"), Value: []byte("orders")},
+		{Key: []byte("__connect.errors.partition"), Value: []byte("1")},
+		{Key: []byte("__connect.errors.offset"), Value: []byte("99")},
+		{Key: []byte("__connect.errors.connector.name"), Value: []byte("orders-sink")},
+		{Key: []byte("__connect.errors.task.id"), Value: []byte("2")},
+		{Key: []byte("__connect.errors.stage"), Value: []byte("VALUE_CONVERTER")},
+		{Key: []byte("__connect.errors.exception.class.name"), Value: []byte("org.apache.kafka.connect.errors.DataException")},
+		{Key: []byte("__connect.errors.exception.message"), Value: []byte("Converting byte[] to Kafka Connect data failed")},
+	}
+
+	info := decodeDeadLetterHeaders(headers)
+	require.NotNil(t, info)
+	assert.Equal(t, DeadLetterConventionConnect, info.Convention)
+	assert.Equal(t, "orders", info.SourceTopic)
+	assert.Equal(t, int32(1), *info.SourcePartition)
+	assert.Equal(t, int64(99), *info.SourceOffset)
+	assert.Equal(t, "orders-sink", info.Connector)
+	assert.Equal(t, int32(2), *info.TaskID)
+	assert.Equal(t, "VALUE_CONVERTER", info.Stage)
+	assert.Equal(t, "org.apache.kafka.connect.errors.DataException", info.ExceptionClass)
+}
+
+func TestDecodeDeadLetterHeaders_Unrecognized(t *testing.T) {
+	assert.Nil(t, decodeDeadLetterHeaders(nil))
+	assert.Nil(t, decodeDeadLetterHeaders([]*sarama.RecordHeader{{Key: []byte("traceparent"), Value: []byte("00-abc")}}))
+}
@@ -0,0 +1,49 @@
+package kafka
+
+import (
+	"fmt"
+
+	"github.com/Shopify/sarama"
+	"go.uber.org/zap"
+)
+
+// DescribeTopicACLs returns all ACLs which are set on topic resources, regardless of their pattern type
+func (s *Service) DescribeTopicACLs() ([]*sarama.ResourceAcls, error) {
+	return s.describeACLs(sarama.AclResourceTopic)
+}
+
+// DescribeAllACLs returns all ACLs of the cluster, regardless of their resource and pattern type
+func (s *Service) DescribeAllACLs() ([]*sarama.ResourceAcls, error) {
+	return s.describeACLs(sarama.AclResourceAny)
+}
+
+func (s *Service) describeACLs(resourceType sarama.AclResourceType) ([]*sarama.ResourceAcls, error) {
+	broker, err := s.findAnyBroker()
+	if err != nil {
+		return nil, err
+	}
+	err = broker.Open(s.Client.Config())
+	if err != nil && err != sarama.ErrAlreadyConnected {
+		s.Logger.Warn("opening the broker connection failed", zap.Error(err))
+	}
+
+	req := &sarama.DescribeAclsRequest{
+		Version: 1,
+		AclFilter: sarama.AclFilter{
+			Version:                   1,
+			ResourceType:              resourceType,
+			ResourcePatternTypeFilter: sarama.AclPatternAny,
+			Operation:                 sarama.AclOperationAny,
+			PermissionType:            sarama.AclPermissionAny,
+		},
+	}
+	res, err := broker.DescribeAcls(req)
+	if err != nil {
+		return nil, err
+	}
+	if res.Err != sarama.ErrNoError {
+		return nil, fmt.Errorf("failed to describe acls: %w", res.Err)
+	}
+
+	return res.ResourceAcls, nil
+}
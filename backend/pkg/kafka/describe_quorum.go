@@ -0,0 +1,137 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Shopify/sarama"
+)
+
+// APIKeyDescribeQuorum is the Kafka API key of the DescribeQuorum request (KIP-595), it's only supported by KRaft
+// clusters
+const APIKeyDescribeQuorum int16 = 55
+
+// maxDescribeQuorumVersion is the highest version of the DescribeQuorum request which can be decoded
+const maxDescribeQuorumVersion int16 = 1
+
+// metadataTopicName is the topic of the KRaft metadata log, which is replicated by the metadata quorum
+const metadataTopicName = "__cluster_metadata"
+
+// DescribeQuorumResponse is the state of the metadata quorum as reported by the DescribeQuorum API
+type DescribeQuorumResponse struct {
+	LeaderID      int32
+	LeaderEpoch   int32
+	HighWatermark int64
+	Voters        []*QuorumReplicaState
+	Observers     []*QuorumReplicaState
+}
+
+// QuorumReplicaState is the replication progress of a single voter or observer of the metadata quorum
+type QuorumReplicaState struct {
+	ReplicaID    int32
+	LogEndOffset int64
+}
+
+// DescribeQuorum describes the metadata quorum using the highest version of the DescribeQuorum request which is
+// supported by both the broker and Kowl. Brokers forward the request to the active controller. Sarama does not
+// implement the request, therefore it's sent using a separate connection.
+func (s *Service) DescribeQuorum(ctx context.Context, maxBrokerVersion int16) (*DescribeQuorumResponse, error) {
+	version := maxBrokerVersion
+	if version > maxDescribeQuorumVersion {
+		version = maxDescribeQuorumVersion
+	}
+
+	broker, err := s.findAnyBroker()
+	if err != nil {
+		return nil, err
+	}
+
+	return describeQuorum(ctx, broker.Addr(), s.Client.Config(), version)
+}
+
+func describeQuorum(ctx context.Context, addr string, cfg *sarama.Config, version int16) (*DescribeQuorumResponse, error) {
+	conn, err := openRawBrokerConn(ctx, addr, cfg)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	d, err := conn.roundTrip(APIKeyDescribeQuorum, version, true, encodeDescribeQuorumRequest())
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe quorum: %w", err)
+	}
+
+	return decodeDescribeQuorumResponse(d, version)
+}
+
+// encodeDescribeQuorumRequest encodes the request for the only partition of the metadata topic. All versions share
+// the same request schema.
+func encodeDescribeQuorumRequest() []byte {
+	e := rawEncoder{}
+	e.putCompactArrayLen(1)
+	e.putCompactString(metadataTopicName)
+	e.putCompactArrayLen(1)
+	e.putInt32(0)
+	e.putEmptyTaggedFields() // Partition
+	e.putEmptyTaggedFields() // Topic
+	e.putEmptyTaggedFields() // Request
+	return e.buf
+}
+
+func decodeDescribeQuorumResponse(d *rawDecoder, version int16) (*DescribeQuorumResponse, error) {
+	if kErr := sarama.KError(d.getInt16()); d.err == nil && kErr != sarama.ErrNoError {
+		return nil, fmt.Errorf("failed to describe quorum: %w", kErr)
+	}
+
+	var res *DescribeQuorumResponse
+	for topicCount := d.getCompactArrayLen(); topicCount > 0 && d.err == nil; topicCount-- {
+		topicName := d.getCompactString()
+		for partitionCount := d.getCompactArrayLen(); partitionCount > 0 && d.err == nil; partitionCount-- {
+			partitionID := d.getInt32()
+			kErr := sarama.KError(d.getInt16())
+			partition := &DescribeQuorumResponse{
+				LeaderID:      d.getInt32(),
+				LeaderEpoch:   d.getInt32(),
+				HighWatermark: d.getInt64(),
+				Voters:        decodeQuorumReplicaStates(d, version),
+				Observers:     decodeQuorumReplicaStates(d, version),
+			}
+			d.skipTaggedFields()
+			if d.err != nil {
+				break
+			}
+			if topicName != metadataTopicName || partitionID != 0 {
+				continue
+			}
+			if kErr != sarama.ErrNoError {
+				return nil, fmt.Errorf("failed to describe quorum of the metadata partition: %w", kErr)
+			}
+			res = partition
+		}
+		d.skipTaggedFields()
+	}
+	d.skipTaggedFields()
+
+	if d.err != nil {
+		return nil, fmt.Errorf("failed to decode describe quorum response: %w", d.err)
+	}
+	if res == nil {
+		return nil, fmt.Errorf("describe quorum response does not contain the metadata partition")
+	}
+	return res, nil
+}
+
+func decodeQuorumReplicaStates(d *rawDecoder, version int16) []*QuorumReplicaState {
+	count := d.getCompactArrayLen()
+	states := make([]*QuorumReplicaState, 0)
+	for i := 0; i < count && d.err == nil; i++ {
+		state := &QuorumReplicaState{ReplicaID: d.getInt32(), LogEndOffset: d.getInt64()}
+		if version >= 1 {
+			d.getInt64() // LastFetchTimestamp
+			d.getInt64() // LastCaughtUpTimestamp
+		}
+		d.skipTaggedFields()
+		states = append(states, state)
+	}
+	return states
+}
@@ -0,0 +1,96 @@
+package kafka
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/Shopify/sarama"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDescribeQuorum(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	// A fake broker answering a single DescribeQuorum v1 request. Voter 3 and observer 4 lag behind the leader.
+	type receivedRequest struct {
+		apiKey  int16
+		version int16
+		topic   string
+	}
+	requestCh := make(chan receivedRequest, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		sizeBuf := make([]byte, 4)
+		if _, err := io.ReadFull(conn, sizeBuf); err != nil {
+			return
+		}
+		req := make([]byte, binary.BigEndian.Uint32(sizeBuf))
+		if _, err := io.ReadFull(conn, req); err != nil {
+			return
+		}
+		d := &rawDecoder{buf: req}
+		received := receivedRequest{apiKey: d.getInt16(), version: d.getInt16()}
+		correlationID := d.getInt32()
+		d.getNullableString() // Client ID
+		d.skipTaggedFields()
+		d.getCompactArrayLen()
+		received.topic = d.getCompactString()
+		requestCh <- received
+
+		body := rawEncoder{}
+		body.putInt32(correlationID)
+		body.putEmptyTaggedFields()
+		body.putInt16(0)
+		body.putCompactArrayLen(1)
+		body.putCompactString(metadataTopicName)
+		body.putCompactArrayLen(1)
+		body.putInt32(0) // Partition
+		body.putInt16(0) // Error code
+		body.putInt32(1) // Leader
+		body.putInt32(7) // Leader epoch
+		body.putInt64(1000)
+		putReplicaStates := func(states map[int32]int64, order []int32) {
+			body.putCompactArrayLen(len(order))
+			for _, id := range order {
+				body.putInt32(id)
+				body.putInt64(states[id])
+				body.putInt64(-1) // Last fetch timestamp
+				body.putInt64(-1) // Last caught up timestamp
+				body.putEmptyTaggedFields()
+			}
+		}
+		putReplicaStates(map[int32]int64{1: 1000, 2: 1000, 3: 880}, []int32{1, 2, 3})
+		putReplicaStates(map[int32]int64{4: 995}, []int32{4})
+		body.putEmptyTaggedFields() // Partition
+		body.putEmptyTaggedFields() // Topic
+		body.putEmptyTaggedFields() // Response
+
+		res := rawEncoder{}
+		res.putBytes(body.buf)
+		conn.Write(res.buf)
+	}()
+
+	cfg := sarama.NewConfig()
+	cfg.ClientID = "kowl"
+	res, err := describeQuorum(context.Background(), listener.Addr().String(), cfg, 1)
+	require.NoError(t, err)
+	assert.Equal(t, receivedRequest{apiKey: APIKeyDescribeQuorum, version: 1, topic: metadataTopicName}, <-requestCh)
+
+	assert.Equal(t, int32(1), res.LeaderID)
+	assert.Equal(t, int32(7), res.LeaderEpoch)
+	assert.Equal(t, int64(1000), res.HighWatermark)
+	require.Len(t, res.Voters, 3)
+	assert.Equal(t, &QuorumReplicaState{ReplicaID: 3, LogEndOffset: 880}, res.Voters[2])
+	assert.Equal(t, []*QuorumReplicaState{{ReplicaID: 4, LogEndOffset: 995}}, res.Observers)
+}
@@ -1,6 +1,9 @@
 package kafka
 
 import (
+	"fmt"
+	"strconv"
+
 	"github.com/Shopify/sarama"
 	"go.uber.org/zap"
 )
@@ -36,3 +39,101 @@ func (s *Service) DescribeTopicsConfigs(topicNames []string, configNames []strin
 
 	return response, nil
 }
+
+// DescribeTopicsDynamicConfigs returns the config entries which have been set for the given topics, excluding
+// broker level and default configs. The values of sensitive entries can't be described, they are nil. Clusters
+// older than v1.1.0 don't report the source of config entries, all non default entries are returned instead.
+func (s *Service) DescribeTopicsDynamicConfigs(topicNames []string) (map[string]map[string]*string, error) {
+	resources := make([]*sarama.ConfigResource, len(topicNames))
+	for i, topicName := range topicNames {
+		resources[i] = &sarama.ConfigResource{Type: sarama.TopicResource, Name: topicName}
+	}
+	res, err := s.describeConfigs(resources)
+	if err != nil {
+		return nil, err
+	}
+
+	configs := make(map[string]map[string]*string, len(topicNames))
+	for _, resource := range res.Resources {
+		if resource.ErrorCode != int16(sarama.ErrNoError) {
+			return nil, fmt.Errorf("failed to describe configs of topic '%v': %v - %v", resource.Name, sarama.KError(resource.ErrorCode), resource.ErrorMsg)
+		}
+		entries := make(map[string]*string)
+		for _, entry := range resource.Configs {
+			if !isDynamicTopicConfig(entry, res.Version) {
+				continue
+			}
+			var value *string
+			if !entry.Sensitive {
+				v := entry.Value
+				value = &v
+			}
+			entries[entry.Name] = value
+		}
+		configs[resource.Name] = entries
+	}
+
+	return configs, nil
+}
+
+func isDynamicTopicConfig(entry *sarama.ConfigEntry, version int16) bool {
+	if version == 0 {
+		return !entry.Default && !entry.ReadOnly
+	}
+	return entry.Source == sarama.SourceTopic
+}
+
+// DefaultReplicationFactor returns the replication factor of topics which are created without one, as configured
+// on the controller
+func (s *Service) DefaultReplicationFactor() (int16, error) {
+	controller, err := s.Client.Controller()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get cluster controller from client: %w", err)
+	}
+
+	brokerID := strconv.Itoa(int(controller.ID()))
+	res, err := s.describeConfigs([]*sarama.ConfigResource{
+		{Type: sarama.BrokerResource, Name: brokerID, ConfigNames: []string{"default.replication.factor"}},
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for _, resource := range res.Resources {
+		if resource.Type != sarama.BrokerResource || resource.Name != brokerID {
+			continue
+		}
+		for _, entry := range resource.Configs {
+			if entry.Name != "default.replication.factor" {
+				continue
+			}
+			replicationFactor, err := strconv.ParseInt(entry.Value, 10, 16)
+			if err != nil {
+				return 0, fmt.Errorf("failed to parse the default replication factor: %w", err)
+			}
+			return int16(replicationFactor), nil
+		}
+	}
+
+	return 0, fmt.Errorf("the controller did not describe its default replication factor")
+}
+
+// describeConfigs describes the resources using the controller. Version 1 is used where supported, because it
+// reports the source of each config entry.
+func (s *Service) describeConfigs(resources []*sarama.ConfigResource) (*sarama.DescribeConfigsResponse, error) {
+	controller, err := s.Client.Controller()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cluster controller from client: %w", err)
+	}
+
+	req := &sarama.DescribeConfigsRequest{Resources: resources}
+	if s.Client.Config().Version.IsAtLeast(sarama.V1_1_0_0) {
+		req.Version = 1
+	}
+	res, err := controller.DescribeConfigs(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe configs: %w", err)
+	}
+
+	return res, nil
+}
@@ -0,0 +1,26 @@
+package kafka
+
+import (
+	"github.com/Shopify/sarama"
+	"go.uber.org/zap"
+)
+
+// DescribeTopics returns the metadata for the given topics. Topics which do not exist will have the
+// sarama.ErrUnknownTopicOrPartition error set on their topic metadata.
+func (s *Service) DescribeTopics(topicNames []string) ([]*sarama.TopicMetadata, error) {
+	broker, err := s.findAnyBroker()
+	if err != nil {
+		return nil, err
+	}
+	err = broker.Open(s.Client.Config())
+	if err != nil && err != sarama.ErrAlreadyConnected {
+		s.Logger.Warn("opening the broker connection failed", zap.Error(err))
+	}
+
+	metadata, err := broker.GetMetadata(&sarama.MetadataRequest{Version: 1, Topics: topicNames})
+	if err != nil {
+		return nil, err
+	}
+
+	return metadata.Topics, nil
+}
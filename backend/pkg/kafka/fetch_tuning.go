@@ -0,0 +1,116 @@
+package kafka
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Shopify/sarama"
+)
+
+// FetchTuningMaxBytesLimit is the upper bound of the fetched bytes for a single consume request
+const FetchTuningMaxBytesLimit = 64 * 1024 * 1024
+
+// fetchMaxWaitMargin is the minimum time between the max wait of tuned fetches and the client's read timeout. Tuned
+// consumers share the client's broker connections, a fetch which is answered after the read timeout would make
+// sarama close the connection for all users of the client.
+const fetchMaxWaitMargin = 5 * time.Second
+
+// FetchTuning overrides the fetch settings of the consumer for a single consume request, so that large scans can
+// trade latency for throughput. Zero values keep the client's configured defaults (250ms max wait, 1 byte min bytes
+// and 1MiB max bytes unless configured otherwise).
+type FetchTuning struct {
+	// MaxWait is the maximum time the broker waits for MinBytes to become available (max.wait.ms). It must stay
+	// below the client's read timeout, see maxFetchWait.
+	MaxWait time.Duration
+	// MinBytes is the minimum number of bytes the broker should return for a fetch request (fetch.min.bytes)
+	MinBytes int32
+	// MaxBytes is the number of bytes fetched per partition and request (fetch.max.bytes). It's the preferred fetch
+	// size, records which are larger are still fetched by growing the fetch size.
+	MaxBytes int32
+
+	// ReadCommitted fetches with read_committed isolation, so that records of aborted transactions and control
+	// records are skipped. Consumers read uncommitted by default.
+	ReadCommitted bool
+}
+
+// IsSet returns true if at least one of the fetch settings has been overridden
+func (f FetchTuning) IsSet() bool {
+	return f.MaxWait != 0 || f.MinBytes != 0 || f.MaxBytes != 0 || f.ReadCommitted
+}
+
+// Validate returns an error if one of the fetch settings is out of range. The upper bound of the max wait depends on
+// the client's read timeout, hence it's validated once the consumer is created.
+func (f FetchTuning) Validate() error {
+	if f.MaxWait < 0 {
+		return fmt.Errorf("max wait must not be negative")
+	}
+	if f.MaxWait != 0 && f.MaxWait < time.Millisecond {
+		return fmt.Errorf("max wait must be at least 1ms")
+	}
+	if f.MinBytes < 0 || f.MinBytes > FetchTuningMaxBytesLimit {
+		return fmt.Errorf("min bytes must be between 0 and %v", FetchTuningMaxBytesLimit)
+	}
+	if f.MaxBytes < 0 || f.MaxBytes > FetchTuningMaxBytesLimit {
+		return fmt.Errorf("max bytes must be between 0 and %v", FetchTuningMaxBytesLimit)
+	}
+	if f.MinBytes != 0 && f.MaxBytes != 0 && f.MinBytes > f.MaxBytes {
+		return fmt.Errorf("min bytes must not be greater than max bytes")
+	}
+
+	return nil
+}
+
+// apply returns a copy of the given config with the overridden fetch settings. The copy shares the config's
+// pointers (e.g. TLS config and metric registry), it must only be read by consumers of the service's client.
+func (f FetchTuning) apply(cfg *sarama.Config) *sarama.Config {
+	tuned := *cfg
+	if f.MaxWait != 0 {
+		tuned.Consumer.MaxWaitTime = f.MaxWait
+	}
+	if f.MinBytes != 0 {
+		tuned.Consumer.Fetch.Min = f.MinBytes
+	}
+	if f.MaxBytes != 0 {
+		tuned.Consumer.Fetch.Default = f.MaxBytes
+	}
+	if f.ReadCommitted {
+		tuned.Consumer.IsolationLevel = sarama.ReadCommitted
+	}
+	return &tuned
+}
+
+// configuredClient is the service's client with a different config. Consumers and producers read their settings
+// from the client's config, but share the client's metadata and broker connections.
+type configuredClient struct {
+	sarama.Client
+	cfg *sarama.Config
+}
+
+func (c *configuredClient) Config() *sarama.Config {
+	return c.cfg
+}
+
+// NewConsumer returns a new consumer of the service's client using the given fetch tuning
+func (s *Service) NewConsumer(tuning FetchTuning) (sarama.Consumer, error) {
+	if !tuning.IsSet() {
+		return sarama.NewConsumerFromClient(s.Client)
+	}
+	if err := tuning.Validate(); err != nil {
+		return nil, err
+	}
+	if limit := maxFetchWait(s.Client.Config().Net.ReadTimeout); tuning.MaxWait > limit {
+		return nil, fmt.Errorf("max wait must not be greater than %v", limit)
+	}
+
+	return sarama.NewConsumerFromClient(&configuredClient{Client: s.Client, cfg: tuning.apply(s.Client.Config())})
+}
+
+// maxFetchWait returns the highest max wait of tuned fetches, which leaves a margin to the client's read timeout.
+// Short read timeouts leave at least half of the timeout as margin.
+func maxFetchWait(readTimeout time.Duration) time.Duration {
+	limit := readTimeout - fetchMaxWaitMargin
+	if limit < readTimeout/2 {
+		limit = readTimeout / 2
+	}
+	return limit
+}
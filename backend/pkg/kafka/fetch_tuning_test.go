@@ -0,0 +1,127 @@
+package kafka
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// observedFetch is the fetch request as observed by the mock broker
+type observedFetch struct {
+	MaxWaitMs int32
+	MinBytes  int32
+	MaxBytes  int32 // Requested fetch size of the partition
+}
+
+// decodeFetchRequest decodes the settings of a fetch request (v0 - v4) for the first partition
+func decodeFetchRequest(t *testing.T, req *capturedRequest) observedFetch {
+	require.True(t, req.Version <= 4, "fetch request version %v is not supported", req.Version)
+	d := req.Body
+	d.getInt32() // Replica ID
+	res := observedFetch{MaxWaitMs: d.getInt32(), MinBytes: d.getInt32()}
+	if req.Version >= 3 {
+		d.getInt32() // Max bytes of the response
+	}
+	if req.Version >= 4 {
+		d.read(1) // Isolation level
+	}
+	require.Equal(t, 1, d.getArrayLen())
+	assert.Equal(t, "orders", d.getNullableString())
+	require.Equal(t, 1, d.getArrayLen())
+	assert.Equal(t, int32(0), d.getInt32())
+	d.getInt64() // Fetch offset
+	res.MaxBytes = d.getInt32()
+	require.NoError(t, d.err)
+	return res
+}
+
+// consumeOnce consumes a single message through a consumer with the given fetch tuning and returns the first fetch
+// request the mock broker has received.
+func consumeOnce(t *testing.T, tuning FetchTuning) observedFetch {
+	broker := sarama.NewMockBroker(t, 1)
+	defer broker.Close()
+	capture := newRequestCapture(t, broker.Addr())
+	defer capture.Close()
+
+	broker.SetHandlerByMap(map[string]sarama.MockResponse{
+		"MetadataRequest": sarama.NewMockMetadataResponse(t).
+			SetBroker(capture.Addr().String(), broker.BrokerID()).
+			SetLeader("orders", 0, broker.BrokerID()),
+		"OffsetRequest": sarama.NewMockOffsetResponse(t).
+			SetOffset("orders", 0, sarama.OffsetOldest, 0).
+			SetOffset("orders", 0, sarama.OffsetNewest, 1),
+		"FetchRequest": sarama.NewMockFetchResponse(t, 1).
+			SetMessage("orders", 0, 0, sarama.StringEncoder("hello")),
+	})
+
+	client, err := sarama.NewClient([]string{capture.Addr().String()}, sarama.NewConfig())
+	require.NoError(t, err)
+	defer client.Close()
+
+	svc := &Service{Client: client, Logger: zap.NewNop()}
+	consumer, err := svc.NewConsumer(tuning)
+	require.NoError(t, err)
+	defer consumer.Close()
+
+	pConsumer, err := consumer.ConsumePartition("orders", 0, 0)
+	require.NoError(t, err)
+	defer pConsumer.Close()
+
+	select {
+	case <-pConsumer.Messages():
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for message")
+	}
+
+	fetches := capture.Requests(apiKeyFetch)
+	require.NotEmpty(t, fetches, "broker did not receive a fetch request")
+	return decodeFetchRequest(t, fetches[0])
+}
+
+func TestNewConsumer_FetchTuning(t *testing.T) {
+	defaults := consumeOnce(t, FetchTuning{})
+	assert.Equal(t, observedFetch{MaxWaitMs: 250, MinBytes: 1, MaxBytes: 1024 * 1024}, defaults)
+
+	tuned := consumeOnce(t, FetchTuning{MaxWait: 2 * time.Second, MinBytes: 512, MaxBytes: 8 * 1024 * 1024})
+	assert.Equal(t, observedFetch{MaxWaitMs: 2000, MinBytes: 512, MaxBytes: 8 * 1024 * 1024}, tuned)
+
+	onlyMaxBytes := consumeOnce(t, FetchTuning{MaxBytes: 64 * 1024})
+	assert.Equal(t, observedFetch{MaxWaitMs: 250, MinBytes: 1, MaxBytes: 64 * 1024}, onlyMaxBytes)
+}
+
+func TestFetchTuningValidate(t *testing.T) {
+	assert.NoError(t, FetchTuning{}.Validate())
+	assert.NoError(t, FetchTuning{MaxWait: time.Second, MinBytes: 1, MaxBytes: 1024}.Validate())
+	assert.Error(t, FetchTuning{MaxWait: -time.Second}.Validate())
+	assert.Error(t, FetchTuning{MaxWait: time.Microsecond}.Validate())
+	assert.Error(t, FetchTuning{MaxBytes: -1}.Validate())
+	assert.Error(t, FetchTuning{MinBytes: 2048, MaxBytes: 1024}.Validate())
+}
+
+func TestNewConsumer_MaxWaitBelowReadTimeout(t *testing.T) {
+	broker := sarama.NewMockBroker(t, 1)
+	defer broker.Close()
+	broker.SetHandlerByMap(map[string]sarama.MockResponse{
+		"MetadataRequest": sarama.NewMockMetadataResponse(t).SetBroker(broker.Addr(), broker.BrokerID()),
+	})
+
+	cfg := sarama.NewConfig()
+	cfg.Net.ReadTimeout = 30 * time.Second
+	client, err := sarama.NewClient([]string{broker.Addr()}, cfg)
+	require.NoError(t, err)
+	defer client.Close()
+	svc := &Service{Client: client, Logger: zap.NewNop()}
+
+	// A fetch which waits as long as the read timeout would race the read deadline of the shared connection
+	_, err = svc.NewConsumer(FetchTuning{MaxWait: 30 * time.Second})
+	assert.Error(t, err)
+	consumer, err := svc.NewConsumer(FetchTuning{MaxWait: 25 * time.Second})
+	require.NoError(t, err)
+	require.NoError(t, consumer.Close())
+
+	assert.Equal(t, 5*time.Second, maxFetchWait(10*time.Second))
+}
@@ -0,0 +1,68 @@
+package kafka
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// flattenJSON flattens a JSON document into a flat map whose keys are the dot-notation paths of all leaf values,
+// e.g. {"customer":{"tags":["a"]}} becomes {"customer.tags[0]": "a"}. If paths are given, only leaf values at or
+// below one of these paths are returned.
+func flattenJSON(value []byte, paths []string) (map[string]interface{}, error) {
+	doc, err := decodeJSON(value)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make(map[string]interface{})
+	flattenJSONValue("", doc, paths, res)
+	return res, nil
+}
+
+// decodeJSON decodes a JSON document and keeps numbers as they are, so that big integers do not lose their precision
+func decodeJSON(value []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(value))
+	decoder.UseNumber()
+
+	var doc interface{}
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+func flattenJSONValue(path string, value interface{}, paths []string, res map[string]interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			childPath := key
+			if path != "" {
+				childPath = path + "." + key
+			}
+			flattenJSONValue(childPath, child, paths, res)
+		}
+	case []interface{}:
+		for i, child := range v {
+			flattenJSONValue(path+"["+strconv.Itoa(i)+"]", child, paths, res)
+		}
+	default:
+		if isProjectedPath(path, paths) {
+			res[path] = v
+		}
+	}
+}
+
+// isProjectedPath returns true if no projection is given or the path is equal to or nested below a projected path
+func isProjectedPath(path string, paths []string) bool {
+	if len(paths) == 0 {
+		return true
+	}
+	for _, projected := range paths {
+		if path == projected || strings.HasPrefix(path, projected+".") || strings.HasPrefix(path, projected+"[") {
+			return true
+		}
+	}
+	return false
+}
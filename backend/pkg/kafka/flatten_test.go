@@ -0,0 +1,32 @@
+package kafka
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFlattenJSON(t *testing.T) {
+	value := []byte(`{"id":7,"customer":{"name":"Jane","address":{"city":"Berlin"}},"items":[{"sku":"a1"},{"sku":"b2"}],"tags":["new"]}`)
+
+	flattened, err := flattenJSON(value, nil)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"id":                    json.Number("7"),
+		"customer.name":         "Jane",
+		"customer.address.city": "Berlin",
+		"items[0].sku":          "a1",
+		"items[1].sku":          "b2",
+		"tags[0]":               "new",
+	}, flattened)
+
+	projected, err := flattenJSON(value, []string{"customer.address", "items"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"customer.address.city": "Berlin",
+		"items[0].sku":          "a1",
+		"items[1].sku":          "b2",
+	}, projected)
+}
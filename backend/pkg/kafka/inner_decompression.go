@@ -0,0 +1,88 @@
+package kafka
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	snappy "github.com/eapache/go-xerial-snappy"
+	"github.com/klauspost/compress/zstd"
+	"go.uber.org/zap"
+)
+
+// Inner compression codecs, which some producers apply to the value payload themselves in addition to Kafka's
+// batch compression.
+const (
+	InnerCompressionNone   = ""
+	InnerCompressionGzip   = "gzip"
+	InnerCompressionSnappy = "snappy"
+	InnerCompressionZstd   = "zstd"
+)
+
+// IsValidInnerCompression returns true if the given codec is a supported inner compression codec
+func IsValidInnerCompression(codec string) bool {
+	switch codec {
+	case InnerCompressionNone, InnerCompressionGzip, InnerCompressionSnappy, InnerCompressionZstd:
+		return true
+	}
+	return false
+}
+
+// maxInnerDecompressedSize caps the size of a decompressed value, so that a small, highly compressed value can't
+// exhaust the backend's memory. Larger values are shown as their raw bytes.
+const maxInnerDecompressedSize = 16 * 1024 * 1024
+
+// zstdDecoder is shared by all partition consumers, DecodeAll can be used concurrently
+var zstdDecoder, _ = zstd.NewReader(nil, zstd.WithDecoderMaxMemory(maxInnerDecompressedSize))
+
+// decompressInnerValue decompresses the value with the configured inner compression codec. The raw value is
+// returned if no codec is configured or the value can not be decompressed.
+func (p *PartitionConsumer) decompressInnerValue(value []byte) []byte {
+	if p.InnerCompression == InnerCompressionNone || len(value) == 0 {
+		return value
+	}
+
+	decompressed, err := decompressValue(p.InnerCompression, value)
+	if err != nil {
+		p.Logger.Debug("failed to decompress message value, falling back to the raw value",
+			zap.String("codec", p.InnerCompression), zap.Error(err))
+		return value
+	}
+	return decompressed
+}
+
+var errDecompressedSizeExceeded = fmt.Errorf("decompressed value exceeds the max size of %d bytes", maxInnerDecompressedSize)
+
+func decompressValue(codec string, value []byte) ([]byte, error) {
+	switch codec {
+	case InnerCompressionGzip:
+		reader, err := gzip.NewReader(bytes.NewReader(value))
+		if err != nil {
+			return nil, err
+		}
+		defer reader.Close()
+		decompressed, err := ioutil.ReadAll(io.LimitReader(reader, maxInnerDecompressedSize+1))
+		if err != nil {
+			return nil, err
+		}
+		if len(decompressed) > maxInnerDecompressedSize {
+			return nil, errDecompressedSizeExceeded
+		}
+		return decompressed, nil
+	case InnerCompressionSnappy:
+		decompressed, err := snappy.Decode(value)
+		if err != nil {
+			return nil, err
+		}
+		if len(decompressed) > maxInnerDecompressedSize {
+			return nil, errDecompressedSizeExceeded
+		}
+		return decompressed, nil
+	case InnerCompressionZstd:
+		return zstdDecoder.DecodeAll(value, nil)
+	}
+
+	return nil, fmt.Errorf("unknown inner compression codec '%v'", codec)
+}
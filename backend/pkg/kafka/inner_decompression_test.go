@@ -0,0 +1,73 @@
+package kafka
+
+import (
+	"bytes"
+	"compress/gzip"
+	"sync"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestDecompressInnerValue(t *testing.T) {
+	payload := []byte(`{"orderId":42}`)
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	_, err := w.Write(payload)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	p := &PartitionConsumer{Logger: zap.NewNop(), InnerCompression: InnerCompressionGzip}
+	vType, embedding, _ := p.getValue(p.decompressInnerValue(buf.Bytes()), MessageFormatAuto)
+	assert.Equal(t, valueTypeJSON, vType)
+	assert.Equal(t, payload, embedding.Value)
+
+	// Values which aren't compressed are passed through as is
+	assert.Equal(t, payload, p.decompressInnerValue(payload))
+
+	p.InnerCompression = InnerCompressionNone
+	assert.Equal(t, payload, p.decompressInnerValue(payload))
+
+	// The shared zstd decoder is used by all partition consumers concurrently
+	encoder, err := zstd.NewWriter(nil)
+	require.NoError(t, err)
+	compressed := encoder.EncodeAll(payload, nil)
+	p.InnerCompression = InnerCompressionZstd
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.Equal(t, payload, p.decompressInnerValue(compressed))
+		}()
+	}
+	wg.Wait()
+}
+
+func TestDecompressInnerValue_Oversized(t *testing.T) {
+	oversized := make([]byte, maxInnerDecompressedSize+1)
+
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	_, err := w.Write(oversized)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	gzipped := buf.Bytes()
+
+	encoder, err := zstd.NewWriter(nil)
+	require.NoError(t, err)
+	zstdCompressed := encoder.EncodeAll(oversized, nil)
+
+	// Values which would exceed the max decompressed size fall back to the raw bytes
+	p := &PartitionConsumer{Logger: zap.NewNop(), InnerCompression: InnerCompressionGzip}
+	assert.Equal(t, gzipped, p.decompressInnerValue(gzipped))
+	p.InnerCompression = InnerCompressionZstd
+	assert.Equal(t, zstdCompressed, p.decompressInnerValue(zstdCompressed))
+
+	// Values right at the limit are still decompressed
+	atLimit := oversized[:maxInnerDecompressedSize]
+	assert.Len(t, p.decompressInnerValue(encoder.EncodeAll(atLimit, nil)), maxInnerDecompressedSize)
+}
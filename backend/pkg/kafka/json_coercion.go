@@ -0,0 +1,217 @@
+package kafka
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Target types of coercion hints for schemaless JSON values
+const (
+	CoercionTypeNumber       = "number"
+	CoercionTypeBoolean      = "boolean"
+	CoercionTypeISOTimestamp = "isoTimestamp" // Epoch millis are converted to RFC 3339 timestamps (UTC)
+)
+
+// CoercionHint converts the JSON value at the given path to the target type after the value has been decoded, e.g.
+// because the producer encodes numbers as strings. Paths use the projection syntax, e.g. "items[*].price".
+type CoercionHint struct {
+	Path string `yaml:"path"`
+	Type string `yaml:"type"`
+}
+
+// CoercionError is a field which could not be coerced to the hinted type, the field is left unchanged
+type CoercionError struct {
+	Path  string `json:"path"`
+	Error string `json:"error"`
+}
+
+// jsonNumberPattern matches the JSON number syntax. Unlike strconv.ParseFloat it rejects NaN, Inf and hex floats, which
+// can't be marshalled as a JSON number.
+var jsonNumberPattern = regexp.MustCompile(`^-?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][+-]?[0-9]+)?$`)
+
+// CoercionRule is a coercion hint whose path has been parsed, so that it can be applied to many messages
+type CoercionRule struct {
+	segments   []projectionSegment
+	targetType string
+}
+
+// NewCoercionRules parses the paths of the coercion hints. It returns an error if a hint has an invalid path or an
+// unsupported target type.
+func NewCoercionRules(hints []CoercionHint) ([]*CoercionRule, error) {
+	rules := make([]*CoercionRule, len(hints))
+	for i, hint := range hints {
+		switch hint.Type {
+		case CoercionTypeNumber, CoercionTypeBoolean, CoercionTypeISOTimestamp:
+		default:
+			return nil, fmt.Errorf("coercion type '%v' of path '%v' is not supported", hint.Type, hint.Path)
+		}
+		segments, err := parseProjectionPath(hint.Path)
+		if err != nil {
+			return nil, err
+		}
+		rules[i] = &CoercionRule{segments: segments, targetType: hint.Type}
+	}
+	return rules, nil
+}
+
+// ValidateCoercionHints returns an error if a hint has an invalid path or an unsupported target type
+func ValidateCoercionHints(hints []CoercionHint) error {
+	_, err := NewCoercionRules(hints)
+	return err
+}
+
+// coerceJSON applies the coercion rules to a JSON document. Paths which do not match are left untouched, fields
+// which can't be coerced are reported without failing the whole document. The original value is returned if no
+// field has been changed, so that its formatting is preserved.
+func coerceJSON(value []byte, rules []*CoercionRule) ([]byte, []*CoercionError, error) {
+	doc, err := decodeJSON(value)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	coercionErrors := make([]*CoercionError, 0)
+	isChanged := false
+	for _, rule := range rules {
+		doc = coerceJSONPath(doc, rule.segments, "", rule.targetType, &isChanged, &coercionErrors)
+	}
+	if !isChanged {
+		return value, coercionErrors, nil
+	}
+
+	coerced, err := json.Marshal(doc)
+	if err != nil {
+		return nil, nil, err
+	}
+	return coerced, coercionErrors, nil
+}
+
+// coerceJSONPath replaces all values at the given path with their coerced value. The path of every coerced field
+// is built along the way, so that failures can be reported for the concrete field, e.g. "items[2].price". isChanged
+// is set if a value has been converted to a different value.
+func coerceJSONPath(value interface{}, segments []projectionSegment, path string, targetType string, isChanged *bool, coercionErrors *[]*CoercionError) interface{} {
+	if len(segments) == 0 {
+		coerced, err := coerceJSONValue(value, targetType)
+		if err != nil {
+			*coercionErrors = append(*coercionErrors, &CoercionError{Path: path, Error: err.Error()})
+			return value
+		}
+		// Successfully coerced values are scalars, hence they can be compared
+		if coerced != value {
+			*isChanged = true
+		}
+		return coerced
+	}
+	segment := segments[0]
+
+	if !segment.IsIndex {
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			return value
+		}
+		child, exists := obj[segment.Key]
+		if !exists {
+			return value
+		}
+		childPath := segment.Key
+		if path != "" {
+			childPath = path + "." + segment.Key
+		}
+		obj[segment.Key] = coerceJSONPath(child, segments[1:], childPath, targetType, isChanged, coercionErrors)
+		return obj
+	}
+
+	arr, ok := value.([]interface{})
+	if !ok {
+		return value
+	}
+	for i := range arr {
+		if !segment.IsWildcard && i != segment.Index {
+			continue
+		}
+		arr[i] = coerceJSONPath(arr[i], segments[1:], fmt.Sprintf("%v[%d]", path, i), targetType, isChanged, coercionErrors)
+	}
+	return arr
+}
+
+// coerceJSONValue converts a single decoded JSON value to the target type. Null values are not coerced.
+func coerceJSONValue(value interface{}, targetType string) (interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+
+	switch targetType {
+	case CoercionTypeNumber:
+		switch v := value.(type) {
+		case json.Number:
+			return v, nil
+		case string:
+			if !jsonNumberPattern.MatchString(v) {
+				return nil, fmt.Errorf("'%v' is not a number", v)
+			}
+			return json.Number(v), nil
+		}
+	case CoercionTypeBoolean:
+		switch v := value.(type) {
+		case bool:
+			return v, nil
+		case string:
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return nil, fmt.Errorf("'%v' is not a boolean", v)
+			}
+			return b, nil
+		case json.Number:
+			switch v.String() {
+			case "0":
+				return false, nil
+			case "1":
+				return true, nil
+			}
+			return nil, fmt.Errorf("number %v is not a boolean", v)
+		}
+	case CoercionTypeISOTimestamp:
+		var millis string
+		switch v := value.(type) {
+		case json.Number:
+			millis = v.String()
+		case string:
+			if _, err := time.Parse(time.RFC3339Nano, v); err == nil {
+				return v, nil
+			}
+			millis = v
+		default:
+			return nil, fmt.Errorf("%T can not be converted to a timestamp", value)
+		}
+		ms, err := strconv.ParseInt(millis, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("'%v' is neither an epoch millis nor an ISO timestamp", millis)
+		}
+		return time.Unix(0, ms*int64(time.Millisecond)).UTC().Format(time.RFC3339Nano), nil
+	default:
+		return nil, fmt.Errorf("coercion type '%v' is not supported", targetType)
+	}
+
+	return nil, fmt.Errorf("%T can not be converted to a %v", value, targetType)
+}
+
+// coerceEmbedding applies the configured coercion rules to a JSON embedding. Non JSON values are returned unchanged.
+func (p *PartitionConsumer) coerceEmbedding(embedding DirectEmbedding) (DirectEmbedding, []*CoercionError) {
+	if embedding.ValueType != valueTypeJSON {
+		return embedding, nil
+	}
+
+	coerced, coercionErrors, err := coerceJSON(embedding.Value, p.CoercionRules)
+	if err != nil {
+		p.Logger.Debug("failed to coerce json value", zap.Error(err))
+		return embedding, nil
+	}
+	if len(coercionErrors) == 0 {
+		coercionErrors = nil
+	}
+	return DirectEmbedding{ValueType: valueTypeJSON, Value: coerced}, coercionErrors
+}
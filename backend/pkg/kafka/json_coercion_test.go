@@ -0,0 +1,63 @@
+package kafka
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCoerceJSON(t *testing.T) {
+	value := []byte(`{"total":"42.5","createdAt":1593590400123,"items":[{"qty":"2"},{"qty":"many"}],"note":"7"}`)
+	hints := []CoercionHint{
+		{Path: "total", Type: CoercionTypeNumber},
+		{Path: "createdAt", Type: CoercionTypeISOTimestamp},
+		{Path: "items[*].qty", Type: CoercionTypeNumber},
+		{Path: "missing", Type: CoercionTypeBoolean},
+	}
+
+	rules, err := NewCoercionRules(hints)
+	require.NoError(t, err)
+
+	coerced, coercionErrors, err := coerceJSON(value, rules)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"total":42.5,"createdAt":"2020-07-01T08:00:00.123Z","items":[{"qty":2},{"qty":"many"}],"note":"7"}`, string(coerced))
+	assert.Equal(t, []*CoercionError{{Path: "items[1].qty", Error: "'many' is not a number"}}, coercionErrors)
+
+	// Values which are not changed by any rule keep their original formatting
+	unchanged := []byte(`{ "total": 42.5, "createdAt": "2020-07-01T08:00:00Z", "items": [] }`)
+	coerced, coercionErrors, err = coerceJSON(unchanged, rules)
+	require.NoError(t, err)
+	assert.Equal(t, unchanged, coerced)
+	assert.Empty(t, coercionErrors)
+
+	// Numbers which aren't valid JSON numbers are reported per field and don't drop the other coercions
+	invalid := []byte(`{"total":"NaN","items":[{"qty":"Inf"},{"qty":"0x1p-2"},{"qty":"-1.5e3"}]}`)
+	coerced, coercionErrors, err = coerceJSON(invalid, rules)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"total":"NaN","items":[{"qty":"Inf"},{"qty":"0x1p-2"},{"qty":-1.5e3}]}`, string(coerced))
+	assert.Equal(t, []*CoercionError{
+		{Path: "total", Error: "'NaN' is not a number"},
+		{Path: "items[0].qty", Error: "'Inf' is not a number"},
+		{Path: "items[1].qty", Error: "'0x1p-2' is not a number"},
+	}, coercionErrors)
+}
+
+func TestCoerceJSONValue(t *testing.T) {
+	b, err := coerceJSONValue("true", CoercionTypeBoolean)
+	require.NoError(t, err)
+	assert.Equal(t, true, b)
+
+	ts, err := coerceJSONValue("2020-07-01T08:00:00Z", CoercionTypeISOTimestamp)
+	require.NoError(t, err)
+	assert.Equal(t, "2020-07-01T08:00:00Z", ts)
+
+	null, err := coerceJSONValue(nil, CoercionTypeNumber)
+	require.NoError(t, err)
+	assert.Nil(t, null)
+
+	_, err = coerceJSONValue(true, CoercionTypeNumber)
+	assert.Error(t, err)
+
+	assert.Error(t, ValidateCoercionHints([]CoercionHint{{Path: "total", Type: "date"}}))
+}
@@ -0,0 +1,102 @@
+package kafka
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// jsonNull is returned as extracted value if the JSON path does not match
+var jsonNull = json.RawMessage("null")
+
+// ValidateJSONPath returns an error if the given JSON path can not be parsed
+func ValidateJSONPath(path string) error {
+	_, err := parseJSONPath(path)
+	return err
+}
+
+// parseJSONPath parses the commonly used subset of JSONPath into projection segments: the root "$" followed by
+// child keys in dot notation ("$.event.type") or bracket notation ("$['event']"), array indexes ("$.items[0]") and
+// the array wildcard ("$.items[*]"). Recursive descent and filter expressions are not supported.
+func parseJSONPath(path string) ([]projectionSegment, error) {
+	if !strings.HasPrefix(path, "$") {
+		return nil, fmt.Errorf("json path '%v' must start with '$'", path)
+	}
+
+	segments := make([]projectionSegment, 0)
+	rest := path[1:]
+	for rest != "" {
+		switch {
+		case strings.HasPrefix(rest, ".."):
+			return nil, fmt.Errorf("recursive descent is not supported in json path '%v'", path)
+		case rest[0] == '.':
+			end := strings.IndexAny(rest[1:], ".[")
+			if end < 0 {
+				end = len(rest) - 1
+			}
+			key := rest[1 : end+1]
+			if key == "" || key == "*" {
+				return nil, fmt.Errorf("invalid key in json path '%v'", path)
+			}
+			segments = append(segments, projectionSegment{Key: key})
+			rest = rest[end+1:]
+		case rest[0] == '[':
+			end := strings.Index(rest, "]")
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated bracket in json path '%v'", path)
+			}
+			selector := rest[1:end]
+			rest = rest[end+1:]
+
+			if len(selector) >= 2 && (selector[0] == '\'' || selector[0] == '"') && selector[len(selector)-1] == selector[0] {
+				segments = append(segments, projectionSegment{Key: selector[1 : len(selector)-1]})
+				continue
+			}
+			if selector == "*" {
+				segments = append(segments, projectionSegment{IsIndex: true, IsWildcard: true})
+				continue
+			}
+			index, err := strconv.Atoi(selector)
+			if err != nil || index < 0 {
+				return nil, fmt.Errorf("invalid selector '%v' in json path '%v'", selector, path)
+			}
+			segments = append(segments, projectionSegment{IsIndex: true, Index: index})
+		default:
+			return nil, fmt.Errorf("unexpected character '%c' in json path '%v'", rest[0], path)
+		}
+	}
+
+	return segments, nil
+}
+
+// extractJSONPath returns the value at the given path of a JSON document or null if the path does not match
+func extractJSONPath(value []byte, segments []projectionSegment) (json.RawMessage, error) {
+	doc, err := decodeJSON(value)
+	if err != nil {
+		return nil, err
+	}
+
+	match, ok := selectJSONPath(doc, segments)
+	if !ok {
+		return jsonNull, nil
+	}
+	return json.Marshal(match)
+}
+
+// extractEmbedding returns the value at the configured JSON path. Messages which are not JSON or don't contain the
+// path are extracted as null.
+func (p *PartitionConsumer) extractEmbedding(embedding DirectEmbedding, segments []projectionSegment) json.RawMessage {
+	if embedding.ValueType != valueTypeJSON {
+		return jsonNull
+	}
+
+	extracted, err := extractJSONPath(embedding.Value, segments)
+	if err != nil {
+		p.Logger.Debug("failed to extract json path", zap.Error(err))
+		return jsonNull
+	}
+	return extracted
+}
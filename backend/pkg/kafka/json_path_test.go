@@ -0,0 +1,35 @@
+package kafka
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtractJSONPath(t *testing.T) {
+	value := []byte(`{"event":{"type":"login","tags":["web","eu"],"user.name":"jane"},"level":3}`)
+	extract := func(path string) string {
+		segments, err := parseJSONPath(path)
+		require.NoError(t, err)
+		extracted, err := extractJSONPath(value, segments)
+		require.NoError(t, err)
+		return string(extracted)
+	}
+
+	assert.Equal(t, `"login"`, extract("$.event.type"))
+	assert.Equal(t, `"eu"`, extract("$.event.tags[1]"))
+	assert.Equal(t, `"jane"`, extract("$.event['user.name']"))
+	assert.Equal(t, `["web","eu"]`, extract("$['event'].tags[*]"))
+	assert.Equal(t, `3`, extract("$.level"))
+	assert.JSONEq(t, string(value), extract("$"))
+
+	// Absent paths are extracted as null
+	assert.Equal(t, "null", extract("$.event.session.id"))
+	assert.Equal(t, "null", extract("$.event.tags[5]"))
+
+	assert.Error(t, ValidateJSONPath("event.type"))
+	assert.Error(t, ValidateJSONPath("$..type"))
+	assert.Error(t, ValidateJSONPath("$.event[x]"))
+	assert.Error(t, ValidateJSONPath("$.event["))
+}
@@ -0,0 +1,50 @@
+package kafka
+
+import (
+	"github.com/Shopify/sarama"
+)
+
+// NoLeaderEpoch is reported if the leader epoch is unknown, e.g. because the client committed an offset without it
+const NoLeaderEpoch int32 = -1
+
+// ListConsumerGroupOffsetsWithLeaderEpochs returns the committed group offsets along with the leader epochs they
+// have been committed with. Committed leader epochs require Kafka 2.1+, the returned bool is false if the cluster
+// doesn't support them, in which case all leader epochs are reported as 0.
+func (s *Service) ListConsumerGroupOffsetsWithLeaderEpochs(group string) (*sarama.OffsetFetchResponse, bool, error) {
+	if !s.Client.Config().Version.IsAtLeast(sarama.V2_1_0_0) {
+		offsets, err := s.ListConsumerGroupOffsets(group)
+		return offsets, false, err
+	}
+
+	coordinator, err := s.Client.Coordinator(group)
+	if err != nil {
+		return nil, false, err
+	}
+
+	req := &sarama.OffsetFetchRequest{
+		Version:       5,
+		ConsumerGroup: group,
+	}
+	offsets, err := coordinator.FetchOffset(req)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return offsets, true, nil
+}
+
+// LeaderEpochAt returns the epoch of the leader which has written the record batch containing the given offset, or
+// NoLeaderEpoch if the offset is not part of a record batch (e.g. it has been deleted or uses the legacy format).
+func (s *Service) LeaderEpochAt(topic string, partitionID int32, offset int64) (int32, error) {
+	batches, err := s.FetchRecordBatchInfos(topic, partitionID, offset)
+	if err != nil {
+		return NoLeaderEpoch, err
+	}
+
+	for _, batch := range batches {
+		if offset >= batch.BaseOffset && offset <= batch.LastOffset {
+			return batch.PartitionLeaderEpoch, nil
+		}
+	}
+	return NoLeaderEpoch, nil
+}
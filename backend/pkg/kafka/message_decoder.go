@@ -0,0 +1,50 @@
+package kafka
+
+import (
+	"encoding/json"
+
+	"github.com/Shopify/sarama"
+	"go.uber.org/zap"
+)
+
+// MessageDecoder decodes message values of custom serialization formats, e.g. proprietary formats which can't be
+// decoded by the built-in decoders. Decoders are registered when the owl service is constructed.
+type MessageDecoder interface {
+	// Decode returns the decoded value along with the name of its encoding (e.g. "acme-v2"). Decoders return an
+	// error for topics or values they don't support, in that case the built-in decoders are used instead.
+	Decode(topic string, data []byte) (interface{}, string, error)
+}
+
+// getMessageValue decodes the value using the registered decoders and falls back to the built-in decoders. The
+// encoding is only returned if a registered decoder has decoded the value.
+func (p *PartitionConsumer) getMessageValue(value []byte, headers []*sarama.RecordHeader) (valueType, DirectEmbedding, *SchemaInfo, string) {
+	if embedding, encoding, ok := p.decodeWithCustomDecoders(value); ok {
+		return embedding.ValueType, embedding, nil, encoding
+	}
+
+	vType, embedding, info := p.getRecordValue(value, p.ValueFormat, headers, false)
+	return vType, embedding, info, ""
+}
+
+// decodeWithCustomDecoders returns the value decoded by the first registered decoder which supports it, embedded
+// as JSON, along with the decoder's encoding name. Decoders are only consulted if no value format has been set.
+func (p *PartitionConsumer) decodeWithCustomDecoders(value []byte) (DirectEmbedding, string, bool) {
+	if len(p.Decoders) == 0 || len(value) == 0 || p.ValueFormat != MessageFormatAuto {
+		return DirectEmbedding{}, "", false
+	}
+
+	for _, decoder := range p.Decoders {
+		decoded, encoding, err := decoder.Decode(p.TopicName, value)
+		if err != nil {
+			continue
+		}
+		marshalled, err := json.Marshal(decoded)
+		if err != nil {
+			p.Logger.Debug("failed to marshal value of custom decoder", zap.String("encoding", encoding), zap.Error(err))
+			continue
+		}
+		return DirectEmbedding{ValueType: valueTypeJSON, Value: marshalled}, encoding, true
+	}
+
+	return DirectEmbedding{}, "", false
+}
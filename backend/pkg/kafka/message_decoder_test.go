@@ -0,0 +1,59 @@
+package kafka
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+// pipeDecoder decodes pipe separated values ("id|name") of the legacy-orders topic
+type pipeDecoder struct {
+	calls int
+}
+
+func (d *pipeDecoder) Decode(topic string, data []byte) (interface{}, string, error) {
+	d.calls++
+	if topic != "legacy-orders" {
+		return nil, "", fmt.Errorf("unsupported topic '%v'", topic)
+	}
+	parts := strings.Split(string(data), "|")
+	if len(parts) != 2 {
+		return nil, "", fmt.Errorf("expected 2 fields, got %d", len(parts))
+	}
+	return map[string]string{"id": parts[0], "name": parts[1]}, "legacy-pipe", nil
+}
+
+func TestPartitionConsumer_GetMessageValue(t *testing.T) {
+	decoder := &pipeDecoder{}
+	p := &PartitionConsumer{Logger: zap.NewNop(), TopicName: "legacy-orders", Decoders: []MessageDecoder{decoder}}
+
+	vType, value, _, encoding := p.getMessageValue([]byte("42|book"), nil)
+	assert.Equal(t, 1, decoder.calls)
+	assert.Equal(t, valueTypeJSON, vType)
+	assert.JSONEq(t, `{"id":"42","name":"book"}`, string(value.Value))
+	assert.Equal(t, "legacy-pipe", encoding)
+
+	// Values the decoder can't decode fall back to the built-in decoders
+	vType, value, _, encoding = p.getMessageValue([]byte("not a pipe value"), nil)
+	assert.Equal(t, valueTypeText, vType)
+	assert.Equal(t, "not a pipe value", string(value.Value))
+	assert.Empty(t, encoding)
+
+	// Other topics fall back to the built-in decoders as well
+	p.TopicName = "orders"
+	vType, value, _, encoding = p.getMessageValue([]byte(`{"id":42}`), nil)
+	assert.Equal(t, 3, decoder.calls)
+	assert.Equal(t, valueTypeJSON, vType)
+	assert.Equal(t, `{"id":42}`, string(value.Value))
+	assert.Empty(t, encoding)
+
+	// Decoders are skipped if the value format has been set explicitly
+	p.TopicName = "legacy-orders"
+	p.ValueFormat = string(valueTypeText)
+	_, _, _, encoding = p.getMessageValue([]byte("42|book"), nil)
+	assert.Equal(t, 3, decoder.calls)
+	assert.Empty(t, encoding)
+}
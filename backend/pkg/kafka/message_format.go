@@ -0,0 +1,65 @@
+package kafka
+
+import (
+	"bytes"
+	"encoding/base64"
+	"strings"
+	"unicode/utf8"
+
+	xj "github.com/basgys/goxml2json"
+	"github.com/valyala/fastjson"
+)
+
+// MessageFormatAuto detects the format for every single message
+const MessageFormatAuto = ""
+
+// IsValidMessageFormat returns true if the given format can be used to decode keys or values
+func IsValidMessageFormat(format string) bool {
+	switch valueType(format) {
+	case MessageFormatAuto, valueTypeJSON, valueTypeXML, valueTypeText, valueTypeBinary:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsRedetectedMessageFormat returns true if values which don't match the given format are detected again when
+// decoding with it. Binary always succeeds and therefore never falls back to the detection.
+func IsRedetectedMessageFormat(format string) bool {
+	switch valueType(format) {
+	case valueTypeJSON, valueTypeXML, valueTypeText:
+		return true
+	default:
+		return false
+	}
+}
+
+// decodeValue decodes the value in the given format. If no format is given or the value can't be decoded in the
+// given format (e.g. a single non JSON message in a JSON topic), the format is detected instead.
+func decodeValue(value []byte, format string) (valueType, DirectEmbedding) {
+	if format == MessageFormatAuto || len(value) == 0 {
+		return detectValue(value)
+	}
+
+	switch vType := valueType(format); vType {
+	case valueTypeJSON:
+		trimmed := bytes.TrimLeft(value, " \t\r\n")
+		if fastjson.ValidateBytes(trimmed) == nil {
+			return vType, DirectEmbedding{ValueType: vType, Value: trimmed}
+		}
+	case valueTypeXML:
+		json, err := xj.Convert(strings.NewReader(string(value)))
+		if err == nil {
+			return vType, DirectEmbedding{ValueType: vType, Value: json.Bytes()}
+		}
+	case valueTypeText:
+		if utf8.Valid(value) {
+			return vType, DirectEmbedding{ValueType: vType, Value: value}
+		}
+	case valueTypeBinary:
+		b64 := []byte(base64.StdEncoding.EncodeToString(value))
+		return vType, DirectEmbedding{ValueType: vType, Value: b64}
+	}
+
+	return detectValue(value)
+}
@@ -0,0 +1,22 @@
+package kafka
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeValue(t *testing.T) {
+	value := []byte(`{"id": 5}`)
+
+	vType, _ := decodeValue(value, MessageFormatAuto)
+	assert.Equal(t, valueTypeJSON, vType)
+
+	vType, embedding := decodeValue(value, string(valueTypeText))
+	assert.Equal(t, valueTypeText, vType)
+	assert.Equal(t, value, embedding.Value)
+
+	// Values which can't be decoded in the given format fall back to the detection
+	vType, _ = decodeValue([]byte("plain text"), string(valueTypeJSON))
+	assert.Equal(t, valueTypeText, vType)
+}
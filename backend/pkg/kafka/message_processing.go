@@ -0,0 +1,326 @@
+package kafka
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Types of message processors
+const (
+	ProcessorTypeRedact   = "redact"   // Replaces the fields at Path with the Replacement
+	ProcessorTypeAddField = "addField" // Adds the field To, derived from a field or property of the message
+	ProcessorTypeDrop     = "drop"     // Drops messages which have a field at Path (equal to Equals, if set)
+	ProcessorTypeRename   = "rename"   // Renames the fields at Path to To
+)
+
+// Message properties which can be the source of added fields
+const (
+	processorSourceKey       = "$key"
+	processorSourcePartition = "$partition"
+	processorSourceOffset    = "$offset"
+	processorSourceTimestamp = "$timestamp" // RFC 3339 (UTC)
+)
+
+const defaultRedactionReplacement = "[REDACTED]"
+
+// MessageProcessor is a single step of a processing pipeline, which is applied to the JSON values of a topic after
+// they have been decoded. Message keys are not processed, they are only a source of added fields. Paths use the
+// projection syntax, e.g. "customer.email" or "items[*].card".
+type MessageProcessor struct {
+	Type string `yaml:"type"`
+	Path string `yaml:"path"`
+
+	// To is the new name of renamed fields (e.g. "mail") and the path of added fields (e.g. "meta.offset")
+	To string `yaml:"to"`
+
+	// From is the source of added fields, either a path of the value or one of the message properties $key,
+	// $partition, $offset and $timestamp
+	From string `yaml:"from"`
+
+	// Replacement of redacted fields, defaults to "[REDACTED]"
+	Replacement string `yaml:"replacement"`
+
+	// Equals only drops messages whose field at Path has the given value, e.g. "true" or "test"
+	Equals string `yaml:"equals"`
+}
+
+// ProcessingError is a processor which failed on a message. The processor's changes are skipped for the failed
+// fields, all other processors are still applied.
+type ProcessingError struct {
+	Processor int    `json:"processor"` // Index of the processor within the pipeline, -1 if the pipeline has failed
+	Type      string `json:"type"`
+	Error     string `json:"error"`
+}
+
+// messageProperties are the properties of a message which can be the source of added fields
+type messageProperties struct {
+	Key         DirectEmbedding
+	PartitionID int32
+	Offset      int64
+	Timestamp   time.Time
+}
+
+// ValidateMessageProcessors returns an error if a processor has an unsupported type or misses a required path
+func ValidateMessageProcessors(processors []MessageProcessor) error {
+	for i, processor := range processors {
+		if err := processor.validate(); err != nil {
+			return fmt.Errorf("invalid %v processor at index %v: %w", processor.Type, i, err)
+		}
+	}
+	return nil
+}
+
+func (m *MessageProcessor) validate() error {
+	switch m.Type {
+	case ProcessorTypeRedact, ProcessorTypeRename:
+		if _, err := parseFieldPath(m.Path); err != nil {
+			return err
+		}
+		if m.Type == ProcessorTypeRename && (m.To == "" || strings.ContainsAny(m.To, ".[]")) {
+			return fmt.Errorf("the new name of renamed fields must be a plain field name")
+		}
+	case ProcessorTypeDrop:
+		if _, err := parseProjectionPath(m.Path); err != nil {
+			return err
+		}
+	case ProcessorTypeAddField:
+		segments, err := parseFieldPath(m.To)
+		if err != nil {
+			return err
+		}
+		for _, segment := range segments {
+			if segment.IsWildcard {
+				return fmt.Errorf("the path of added fields must not contain wildcards")
+			}
+		}
+		if m.From == "" {
+			return fmt.Errorf("the source of added fields is required")
+		}
+		if !isMessagePropertySource(m.From) {
+			if _, err := parseProjectionPath(m.From); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("processor type '%v' is not supported", m.Type)
+	}
+	return nil
+}
+
+// parseFieldPath parses a projection path which must end with a field name, because processors change the field
+// within its parent object
+func parseFieldPath(path string) ([]projectionSegment, error) {
+	segments, err := parseProjectionPath(path)
+	if err != nil {
+		return nil, err
+	}
+	if segments[len(segments)-1].IsIndex {
+		return nil, fmt.Errorf("path '%v' must end with a field name", path)
+	}
+	return segments, nil
+}
+
+func isMessagePropertySource(source string) bool {
+	switch source {
+	case processorSourceKey, processorSourcePartition, processorSourceOffset, processorSourceTimestamp:
+		return true
+	default:
+		return false
+	}
+}
+
+// processJSON applies the processors in order to a JSON document. It returns true if a processor has dropped the
+// message, in that case the following processors are not applied. The original value is returned if no processor
+// has changed the document, so that its formatting is preserved.
+func processJSON(value []byte, props messageProperties, processors []MessageProcessor) ([]byte, bool, []*ProcessingError, error) {
+	doc, err := decodeJSON(value)
+	if err != nil {
+		return nil, false, nil, err
+	}
+
+	processingErrors := make([]*ProcessingError, 0)
+	isChanged := false
+	for i, processor := range processors {
+		errs, isProcessorChanged, isDropped := processor.apply(doc, props)
+		if isDropped {
+			return nil, true, nil, nil
+		}
+		isChanged = isChanged || isProcessorChanged
+		for _, err := range errs {
+			processingErrors = append(processingErrors, &ProcessingError{Processor: i, Type: processor.Type, Error: err.Error()})
+		}
+	}
+	if !isChanged {
+		return value, false, processingErrors, nil
+	}
+
+	processed, err := json.Marshal(doc)
+	if err != nil {
+		return nil, false, nil, err
+	}
+	return processed, false, processingErrors, nil
+}
+
+// apply changes the document in place and returns the errors of the fields which could not be processed, whether
+// the document has been changed and whether the message is dropped. Paths have been validated already.
+func (m *MessageProcessor) apply(doc interface{}, props messageProperties) ([]error, bool, bool) {
+	errs := make([]error, 0)
+	isChanged := false
+
+	switch m.Type {
+	case ProcessorTypeRedact:
+		replacement := m.Replacement
+		if replacement == "" {
+			replacement = defaultRedactionReplacement
+		}
+		segments, _ := parseFieldPath(m.Path)
+		forEachJSONParent(doc, segments, func(parent map[string]interface{}, key string) {
+			if child, exists := parent[key]; exists && child != replacement {
+				parent[key] = replacement
+				isChanged = true
+			}
+		})
+	case ProcessorTypeRename:
+		segments, _ := parseFieldPath(m.Path)
+		forEachJSONParent(doc, segments, func(parent map[string]interface{}, key string) {
+			child, exists := parent[key]
+			if !exists {
+				return
+			}
+			if _, exists := parent[m.To]; exists {
+				errs = append(errs, fmt.Errorf("can't rename '%v' to '%v', because the field exists already", key, m.To))
+				return
+			}
+			delete(parent, key)
+			parent[m.To] = child
+			isChanged = true
+		})
+	case ProcessorTypeDrop:
+		segments, _ := parseProjectionPath(m.Path)
+		match, exists := selectJSONPath(doc, segments)
+		if exists && (m.Equals == "" || fmt.Sprint(match) == m.Equals) {
+			return nil, false, true
+		}
+	case ProcessorTypeAddField:
+		source, err := m.sourceValue(doc, props)
+		if err != nil {
+			return []error{err}, false, false
+		}
+		segments, _ := parseFieldPath(m.To)
+		isAdded := false
+		forEachJSONParent(doc, segments, func(parent map[string]interface{}, key string) {
+			parent[key] = source
+			isAdded = true
+		})
+		isChanged = isChanged || isAdded
+		if !isAdded {
+			errs = append(errs, fmt.Errorf("can't add '%v', because its parent does not exist or is not an object", m.To))
+		}
+	}
+
+	return errs, isChanged, false
+}
+
+// sourceValue returns a copy of the value which is added as derived field
+func (m *MessageProcessor) sourceValue(doc interface{}, props messageProperties) (interface{}, error) {
+	switch m.From {
+	case processorSourceKey:
+		if props.Key.ValueType == valueTypeJSON {
+			return decodeJSON(props.Key.Value)
+		}
+		return string(props.Key.Value), nil
+	case processorSourcePartition:
+		return props.PartitionID, nil
+	case processorSourceOffset:
+		return props.Offset, nil
+	case processorSourceTimestamp:
+		return props.Timestamp.UTC().Format(time.RFC3339Nano), nil
+	}
+
+	segments, _ := parseProjectionPath(m.From)
+	match, exists := selectJSONPath(doc, segments)
+	if !exists {
+		return nil, fmt.Errorf("source field '%v' does not exist", m.From)
+	}
+	// The source must not share nested objects with the added field, otherwise later processors would change both
+	return copyJSONValue(match), nil
+}
+
+// forEachJSONParent calls fn with every object at the parent path of the given segments, along with the key of the
+// last segment
+func forEachJSONParent(value interface{}, segments []projectionSegment, fn func(parent map[string]interface{}, key string)) {
+	segment := segments[0]
+	if len(segments) == 1 {
+		if obj, ok := value.(map[string]interface{}); ok {
+			fn(obj, segment.Key)
+		}
+		return
+	}
+
+	if !segment.IsIndex {
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			return
+		}
+		if child, exists := obj[segment.Key]; exists {
+			forEachJSONParent(child, segments[1:], fn)
+		}
+		return
+	}
+
+	arr, ok := value.([]interface{})
+	if !ok {
+		return
+	}
+	for i := range arr {
+		if segment.IsWildcard || i == segment.Index {
+			forEachJSONParent(arr[i], segments[1:], fn)
+		}
+	}
+}
+
+func copyJSONValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		copied := make(map[string]interface{}, len(v))
+		for key, child := range v {
+			copied[key] = copyJSONValue(child)
+		}
+		return copied
+	case []interface{}:
+		copied := make([]interface{}, len(v))
+		for i, child := range v {
+			copied[i] = copyJSONValue(child)
+		}
+		return copied
+	default:
+		return v
+	}
+}
+
+// processEmbedding applies the configured processors to a JSON value embedding. Non JSON values are returned
+// unchanged, because the processors' paths can't be applied to them. JSON values which can't be processed are
+// removed, because they might contain fields which the processors would have redacted.
+func (p *PartitionConsumer) processEmbedding(embedding DirectEmbedding, props messageProperties) (DirectEmbedding, bool, []*ProcessingError) {
+	if embedding.ValueType != valueTypeJSON {
+		return embedding, false, nil
+	}
+
+	processed, isDropped, processingErrors, err := processJSON(embedding.Value, props, p.Processors)
+	if err != nil {
+		p.Logger.Debug("failed to process json value, the value has been removed", zap.Int64("offset", props.Offset), zap.Error(err))
+		processingError := &ProcessingError{Processor: -1, Error: fmt.Sprintf("the value has been removed, because it could not be processed: %v", err)}
+		return DirectEmbedding{ValueType: valueTypeJSON}, false, []*ProcessingError{processingError}
+	}
+	if isDropped {
+		return embedding, true, nil
+	}
+	if len(processingErrors) == 0 {
+		processingErrors = nil
+	}
+	return DirectEmbedding{ValueType: valueTypeJSON, Value: processed}, false, processingErrors
+}
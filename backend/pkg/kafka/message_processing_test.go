@@ -0,0 +1,83 @@
+package kafka
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestProcessJSON(t *testing.T) {
+	processors := []MessageProcessor{
+		{Type: ProcessorTypeRedact, Path: "customer.email"},
+		{Type: ProcessorTypeAddField, To: "displayName", From: "customer.name"},
+		{Type: ProcessorTypeAddField, To: "offset", From: "$offset"},
+	}
+	require.NoError(t, ValidateMessageProcessors(processors))
+	props := messageProperties{PartitionID: 2, Offset: 17, Timestamp: time.Unix(0, 0)}
+
+	processed, isDropped, processingErrors, err := processJSON([]byte(`{"customer":{"email":"jane@example.com","name":"Jane"}}`), props, processors)
+	require.NoError(t, err)
+	assert.False(t, isDropped)
+	assert.Empty(t, processingErrors)
+	assert.JSONEq(t, `{"customer":{"email":"[REDACTED]","name":"Jane"},"displayName":"Jane","offset":17}`, string(processed))
+
+	// Errors are isolated to the failed processor, the other processors are still applied
+	processed, _, processingErrors, err = processJSON([]byte(`{"customer":"jane"}`), props, processors)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"customer":"jane","offset":17}`, string(processed))
+	assert.Equal(t, []*ProcessingError{{Processor: 1, Type: ProcessorTypeAddField, Error: "source field 'customer.name' does not exist"}},
+		processingErrors)
+
+	// Values which are not changed by any processor keep their original formatting
+	unchanged := []byte(`{ "customer": {"email": "[REDACTED]"}, "offset": 17 }`)
+	processed, _, _, err = processJSON(unchanged, props, []MessageProcessor{
+		{Type: ProcessorTypeRedact, Path: "customer.email"},
+		{Type: ProcessorTypeRename, Path: "missing", To: "renamed"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, unchanged, processed)
+
+	// Dropped messages are not processed any further
+	processors = append([]MessageProcessor{{Type: ProcessorTypeDrop, Path: "isTest", Equals: "true"}}, processors...)
+	_, isDropped, _, err = processJSON([]byte(`{"isTest":true,"customer":{"email":"test@example.com"}}`), props, processors)
+	require.NoError(t, err)
+	assert.True(t, isDropped)
+	_, isDropped, _, err = processJSON([]byte(`{"isTest":false,"customer":{"email":"test@example.com"}}`), props, processors)
+	require.NoError(t, err)
+	assert.False(t, isDropped)
+
+	assert.Error(t, ValidateMessageProcessors([]MessageProcessor{{Type: ProcessorTypeRename, Path: "amt", To: "a.b"}}))
+	assert.Error(t, ValidateMessageProcessors([]MessageProcessor{{Type: ProcessorTypeRedact, Path: "cards[*]"}}))
+}
+
+func TestPartitionConsumer_ProcessEmbedding(t *testing.T) {
+	p := &PartitionConsumer{
+		Logger:           zap.NewNop(),
+		Processors:       []MessageProcessor{{Type: ProcessorTypeRedact, Path: "properties.password"}},
+		ConnectTopicType: ConnectTopicTypeConfigs,
+	}
+	key := []byte("connector-jdbc-sink")
+	rawValue := []byte(`{"properties":{"password":"secret","table":"orders"}}`)
+
+	// The connect record is decoded from the processed value
+	value, _, processingErrors := p.processEmbedding(DirectEmbedding{ValueType: valueTypeJSON, Value: rawValue}, messageProperties{})
+	assert.Empty(t, processingErrors)
+	record := p.decodeConnectRecord(key, rawValue, value)
+	require.NotNil(t, record)
+	assert.Equal(t, ConnectRecordTypeConnectorConfig, record.Type)
+	assert.NotContains(t, fmt.Sprint(record.Config), "secret")
+	assert.Contains(t, fmt.Sprint(record.Config), "[REDACTED]")
+
+	// Values which can't be processed are removed and the failure is reported
+	invalidValue := []byte(`{"properties":{"password":"secret"`)
+	value, isDropped, processingErrors := p.processEmbedding(DirectEmbedding{ValueType: valueTypeJSON, Value: invalidValue}, messageProperties{})
+	assert.False(t, isDropped)
+	assert.Nil(t, value.Value)
+	require.Len(t, processingErrors, 1)
+	assert.Equal(t, -1, processingErrors[0].Processor)
+	assert.Nil(t, p.decodeConnectRecord(key, invalidValue, value))
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"github.com/robertkrimen/otto"
 	"strings"
@@ -12,6 +13,7 @@ import (
 
 	"github.com/Shopify/sarama"
 	xj "github.com/basgys/goxml2json"
+	"github.com/cloudhut/kowl/backend/pkg/schema"
 	"github.com/valyala/fastjson"
 	"go.uber.org/zap"
 )
@@ -31,9 +33,21 @@ type IListMessagesProgress interface {
 	OnMessage(message *TopicMessage)
 	OnMessageConsumed(size int64)
 	OnComplete(elapsedMs int64, isCancelled bool)
+	OnThrottled(delayMs int64, reason string)
+	OnStartOffsetAdjusted(partitionID int32, plannedOffset, adjustedOffset int64)
+	OnBatchCorrupted(partitionID int32, offset int64, reason string)
+	OnSampled(rate int64, consumedCount int64, sampledCount int64)
 	OnError(msg string)
 }
 
+// IRecordCap is shared by all partition consumers of a request to cap the number of records across all partitions.
+// All partitions are registered before any of them is consumed. Acquire blocks until the partition may take another
+// record and returns false once the cap has been reached.
+type IRecordCap interface {
+	Acquire(ctx context.Context, partitionID int32) bool
+	Done(partitionID int32)
+}
+
 // TopicMessage represents a single message from a given Kafka topic/partition
 type TopicMessage struct {
 	PartitionID int32 `json:"partitionID"`
@@ -45,7 +59,57 @@ type TopicMessage struct {
 	Value     DirectEmbedding `json:"value"`
 	ValueType string          `json:"valueType"`
 
-	Size        int  `json:"size"`
+	// ValueEncoding is the encoding reported by the custom decoder which has decoded the value, if any
+	ValueEncoding string `json:"valueEncoding,omitempty"`
+
+	// KeySchema and ValueSchema are only set if the key/value has been serialized using Confluent's wire format
+	KeySchema   *SchemaInfo `json:"keySchema,omitempty"`
+	ValueSchema *SchemaInfo `json:"valueSchema,omitempty"`
+
+	// CoercionErrors are the fields which could not be coerced to their hinted type, only set if there are any
+	CoercionErrors []*CoercionError `json:"coercionErrors,omitempty"`
+
+	// ProcessingErrors are the processors which failed on the value, only set if there are any
+	ProcessingErrors []*ProcessingError `json:"processingErrors,omitempty"`
+
+	// FlattenedValue is the JSON value flattened into dot-notation paths, only set if flattening has been requested
+	FlattenedValue map[string]interface{} `json:"flattenedValue,omitempty"`
+
+	// ProducerID of the record's batch, only set if the messages are filtered by producer ID
+	ProducerID *int64 `json:"producerId,omitempty"`
+
+	// BatchCompression is the compression codec of the record's batch, only set if it has been requested
+	BatchCompression string `json:"batchCompression,omitempty"`
+
+	// RecordKind is data, control or aborted and ControlRecord is the decoded transaction marker of control records,
+	// both are only set if control or aborted records have been requested
+	RecordKind    string             `json:"recordKind,omitempty"`
+	ControlRecord *ControlRecordInfo `json:"controlRecord,omitempty"`
+
+	// ConnectRecord is the decoded record, only set when browsing one of Kafka Connect's internal topics
+	ConnectRecord *ConnectRecord `json:"connectRecord,omitempty"`
+
+	// DeadLetter is the error context of a dead-letter record, only set if dead letter parsing has been requested
+	// and the headers follow a known convention
+	DeadLetter *DeadLetterInfo `json:"deadLetter,omitempty"`
+
+	// BinaryPreview describes base64 encoded binary values, only set if binary previews have been requested
+	BinaryPreview *BinaryPreview `json:"binaryPreview,omitempty"`
+
+	// CorrelationKey is the key which correlates the message with messages of other topics, only set if a
+	// correlation key has been requested and the message has one
+	CorrelationKey string `json:"correlationKey,omitempty"`
+
+	// PartitionKeyHash is the partition the key hashes to with the default partitioner, only set if the partition
+	// key hash has been requested and the message has a key
+	PartitionKeyHash *PartitionKeyHash `json:"partitionKeyHash,omitempty"`
+
+	// ExtractedValue is the value at the requested JSON path (null if absent), only set if a JSON path extraction
+	// has been requested. The value itself is omitted in that case.
+	ExtractedValue json.RawMessage `json:"extractedValue,omitempty"`
+
+	Size        int  `json:"size"`    // Size of the raw value in bytes
+	KeySize     int  `json:"keySize"` // Size of the raw key in bytes
 	IsValueNull bool `json:"isValueNull"`
 }
 
@@ -59,6 +123,10 @@ type PartitionConsumeRequest struct {
 	StartOffset     int64
 	EndOffset       int64
 	MaxMessageCount int64 // If either EndOffset or MaxMessageCount is reached the Consumer will stop.
+
+	// IsStartOffsetAdjusted is true if the StartOffset has been moved to the low water mark because the planned
+	// start offset has been deleted by retention in the meantime.
+	IsStartOffsetAdjusted bool
 }
 
 type interpreterArguments struct {
@@ -82,6 +150,91 @@ type PartitionConsumer struct {
 	TopicName string
 	Req       *PartitionConsumeRequest
 
+	SchemaService *schema.Service // Optional, nil if no schema registry is configured
+
+	// WireFormat in which schema IDs are embedded in keys and values, it's detected if empty or auto
+	WireFormat string
+
+	// Decoders are consulted for each value before the built-in decoders, unless a ValueFormat is set
+	Decoders []MessageDecoder
+
+	// WithSchemaVersions resolves the subject version of each message's schema, not just the schema ID
+	WithSchemaVersions bool
+
+	// KeyFormat and ValueFormat skip the format detection if set, e.g. because the format is already known
+	KeyFormat   string
+	ValueFormat string
+
+	// InnerCompression is the codec used to decompress message values before they are deserialized
+	InnerCompression string
+
+	// CoercionRules convert fields of schemaless JSON values to the hinted types after the value has been decoded
+	CoercionRules []*CoercionRule
+
+	// Processors are applied in order to JSON values after they have been decoded and coerced, so that the filter
+	// code and all other options only see the processed values. Keys are never processed. Dropped messages are
+	// treated like filtered messages.
+	Processors []MessageProcessor
+
+	// Flatten JSON values into a flat path -> value map, optionally projected to the FlattenPaths
+	Flatten      bool
+	FlattenPaths []string
+
+	// ProjectionPaths reduce JSON values (and keys if ProjectKeys is set) to the given paths
+	ProjectionPaths []string
+	ProjectKeys     bool
+
+	// ExtractJSONPath returns only the value at the given JSON path instead of the whole value, e.g. "$.event.type"
+	ExtractJSONPath string
+
+	// ConnectTopicType decodes the records as Kafka Connect offsets, status or configs records if set
+	ConnectTopicType string
+
+	// ParseDeadLetters surfaces the error headers of dead-letter records (Spring Kafka and Kafka Connect conventions)
+	ParseDeadLetters bool
+
+	// WithBinaryPreview adds the length and a hex preview of the leading bytes to binary values
+	WithBinaryPreview bool
+
+	// CorrelationKey is extracted from each message's headers or JSON value if set
+	CorrelationKey *CorrelationKey
+
+	// WithPartitionKeyHash computes the partition each key hashes to with the default partitioner, based on the
+	// topic's PartitionCount
+	WithPartitionKeyHash bool
+	PartitionCount       int32
+
+	// ProducerIDFilter only returns records from batches written by this producer (-1 for non idempotent producers).
+	// WithBatchCompression reports the compression codec of each record's batch. Batches are always decompressed
+	// with the codec from their own attributes, so that topics whose producers have changed the compression over
+	// time are decoded regardless of the topic's compression type. FetchRecordBatches is required to read the batch
+	// metadata if the filter or the batch compression is set.
+	ProducerIDFilter     *int64
+	WithBatchCompression bool
+	FetchRecordBatches   func(offset int64) ([]*RecordBatchInfo, error)
+
+	// RecordVisibility additionally presents the control records and/or the records of aborted transactions, which
+	// a read_committed consumer skips. The Consumer must read with read_committed isolation if a visibility is set
+	// and FetchSkippedRecords is required to read the skipped records.
+	RecordVisibility    string
+	FetchSkippedRecords func(offset int64) ([]*TransactionalRecord, error)
+
+	// LocateCorruptedBatch returns the offset of the first batch between offset and endOffset which fails its CRC
+	// check. It confirms that a CRC error returned by the Consumer belongs to this partition, if it's not set the
+	// error is trusted and the corrupted batch is assumed to follow the consumed messages.
+	LocateCorruptedBatch func(offset int64, endOffset int64) (int64, string, bool, error)
+
+	// LowWaterMark returns the current low water mark of the partition. If set, a start offset which is out of range
+	// because retention has deleted the data since the consume request was planned is moved to the low water mark.
+	LowWaterMark func() (int64, error)
+
+	// MinTimestamp skips all messages with an older timestamp, it is ignored if it's the zero value
+	MinTimestamp time.Time
+
+	// RecordCap limits the records across all partitions of the request if set, each record which passes the
+	// filters must be acquired from it before it's sent
+	RecordCap IRecordCap
+
 	VM                    *otto.Otto
 	FilterInterpreterCode string
 }
@@ -90,9 +243,12 @@ func (p *PartitionConsumer) Run(ctx context.Context) {
 	defer func() {
 		p.DoneCh <- struct{}{}
 	}()
+	if p.RecordCap != nil {
+		defer p.RecordCap.Done(p.Req.PartitionID)
+	}
 
 	// Create PartitionConsumer
-	pConsumer, err := p.Consumer.ConsumePartition(p.TopicName, p.Req.PartitionID, p.Req.StartOffset)
+	pConsumer, err := p.consumePartition()
 	if err != nil {
 		p.Logger.Error("couldn't consume partition", zap.Error(err))
 		p.Progress.OnError(fmt.Sprintf("couldn't consume partition %v: %v", p.Req.PartitionID, err.Error()))
@@ -103,6 +259,17 @@ func (p *PartitionConsumer) Run(ctx context.Context) {
 			p.Logger.Error("failed to close partition consumer", zap.Error(errC))
 		}
 	}()
+	if p.Req.StartOffset > p.Req.EndOffset {
+		// All planned messages have been deleted by retention
+		return
+	}
+
+	var visibilityConsumer *recordVisibilityConsumer
+	if p.RecordVisibility != "" && p.RecordVisibility != RecordVisibilityData {
+		visibilityConsumer = newRecordVisibilityConsumer(pConsumer, p.RecordVisibility, p.Req.StartOffset, p.Req.EndOffset,
+			p.FetchSkippedRecords, p.reportSkippedRecordsError)
+		pConsumer = visibilityConsumer // Closed by the deferred close above
+	}
 
 	// Setup JS interpreter
 	isMessageOK, err := p.SetupInterpreter()
@@ -112,21 +279,93 @@ func (p *PartitionConsumer) Run(ctx context.Context) {
 		return
 	}
 
+	var extractSegments []projectionSegment
+	if p.ExtractJSONPath != "" {
+		extractSegments, err = parseJSONPath(p.ExtractJSONPath)
+		if err != nil {
+			p.Progress.OnError(fmt.Sprintf("invalid json path: %v", err.Error()))
+			return
+		}
+	}
+
+	var batchResolver *recordBatchResolver
+	if p.ProducerIDFilter != nil || p.WithBatchCompression {
+		batchResolver = &recordBatchResolver{fetch: p.FetchRecordBatches}
+	}
+
+	// A record batch which fails its CRC check can't be consumed, sarama would retry fetching it forever. Messages
+	// before the corrupted batch are consumed before reporting it. Once sarama has retried the failed fetch, all
+	// messages it has been able to fetch are buffered, so that the partition is done when the buffer is empty.
+	errorsCh := pConsumer.Errors()
+	corruptedOffset := int64(-1)
+	var corruptionReason string
+	isCorruptionRetried := false
+	nextOffset := p.Req.StartOffset
+
 	messageCount := int64(0)
 	for {
+		if corruptedOffset >= 0 && (nextOffset >= corruptedOffset || isCorruptionRetried && len(pConsumer.Messages()) == 0) {
+			p.Logger.Warn("record batch failed its crc check",
+				zap.Int64("offset", corruptedOffset),
+				zap.String("reason", corruptionReason))
+			p.Progress.OnBatchCorrupted(p.Req.PartitionID, corruptedOffset, corruptionReason)
+			return
+		}
+
 		select {
+		case cErr, ok := <-errorsCh:
+			if !ok {
+				errorsCh = nil
+				continue
+			}
+			reason, isCorrupt := corruptBatchReason(cErr.Err)
+			if !isCorrupt {
+				// Other errors are retried by sarama
+				p.Logger.Debug("partition consumer returned an error", zap.Error(cErr.Err))
+				continue
+			}
+			if corruptedOffset >= 0 {
+				isCorruptionRetried = true
+				continue
+			}
+			offset, reason, isCorrupt, err := p.locateCorruptedBatch(nextOffset, reason)
+			if err != nil {
+				p.Logger.Warn("failed to locate corrupted record batch", zap.Error(err))
+				continue
+			}
+			if !isCorrupt {
+				// The fetch has failed because of a corrupted batch of another partition on the same broker
+				p.Logger.Debug("partition consumer returned a crc error of another partition", zap.Error(cErr.Err))
+				continue
+			}
+			corruptedOffset, corruptionReason = offset, reason
 		case m, ok := <-pConsumer.Messages():
 			if !ok {
 				p.Logger.Error("partition Consumer message channel has unexpectedly closed")
 				p.Progress.OnError(fmt.Sprintf("partition Consumer (partitionId=%v) failed to get the next message (see server log)", p.Req.PartitionID))
 				return
 			}
+			nextOffset = m.Offset + 1
 			messageSize := len(m.Key) + len(m.Value)
 			p.Progress.OnMessageConsumed(int64(messageSize))
 
 			// Run Interpreter filter and check if message passes the filter
-			vType, value := p.getValue(m.Value)
-			kType, key := p.getValue(m.Key)
+			vType, value, vSchema, valueEncoding := p.getMessageValue(p.decompressInnerValue(m.Value), m.Headers)
+			var coercionErrors []*CoercionError
+			if len(p.CoercionRules) > 0 {
+				value, coercionErrors = p.coerceEmbedding(value)
+			}
+			kType, key, kSchema := p.getRecordValue(m.Key, p.KeyFormat, m.Headers, true)
+			var processingErrors []*ProcessingError
+			isDropped := false
+			if len(p.Processors) > 0 {
+				props := messageProperties{Key: key, PartitionID: m.Partition, Offset: m.Offset, Timestamp: m.Timestamp}
+				value, isDropped, processingErrors = p.processEmbedding(value, props)
+			}
+			if p.WithSchemaVersions {
+				p.annotateSchemaVersion(kSchema, true)
+				p.annotateSchemaVersion(vSchema, false)
+			}
 
 			topicMessage := &TopicMessage{
 				PartitionID: m.Partition,
@@ -136,9 +375,40 @@ func (p *PartitionConsumer) Run(ctx context.Context) {
 				KeyType:     string(kType),
 				Value:       value,
 				ValueType:   string(vType),
+				KeySchema:   kSchema,
+				ValueSchema: vSchema,
 				Size:        len(m.Value),
+				KeySize:     len(m.Key),
 				IsValueNull: m.Value == nil,
 			}
+			topicMessage.ValueEncoding = valueEncoding
+			topicMessage.CoercionErrors = coercionErrors
+			topicMessage.ProcessingErrors = processingErrors
+			if visibilityConsumer != nil {
+				topicMessage.RecordKind, topicMessage.ControlRecord = visibilityConsumer.recordInfo(m.Offset)
+			}
+			if p.Flatten && vType == valueTypeJSON {
+				flattened, err := flattenJSON(value.Value, p.FlattenPaths)
+				if err != nil {
+					p.Logger.Debug("failed to flatten json value", zap.Int64("offset", m.Offset), zap.Error(err))
+				}
+				topicMessage.FlattenedValue = flattened
+			}
+			if p.ConnectTopicType != ConnectTopicTypeNone {
+				topicMessage.ConnectRecord = p.decodeConnectRecord(m.Key, m.Value, value)
+			}
+			if p.ParseDeadLetters {
+				topicMessage.DeadLetter = decodeDeadLetterHeaders(m.Headers)
+			}
+			if p.WithBinaryPreview {
+				topicMessage.BinaryPreview = newBinaryPreview(value)
+			}
+			if p.CorrelationKey != nil {
+				topicMessage.CorrelationKey, _ = p.CorrelationKey.extract(m.Headers, value)
+			}
+			if p.WithPartitionKeyHash {
+				topicMessage.PartitionKeyHash = newPartitionKeyHash(m.Key, m.Partition, p.PartitionCount)
+			}
 
 			// Check if message passes filter code
 			args := interpreterArguments{
@@ -156,9 +426,45 @@ func (p *PartitionConsumer) Run(ctx context.Context) {
 				p.Progress.OnError(fmt.Sprintf("failed to check if message is ok (partition: '%v', offset: '%v')", m.Partition, m.Offset))
 				return
 			}
+			if isOK && isDropped {
+				isOK = false
+			}
+			if isOK && p.isBeforeMinTimestamp(m.Timestamp) {
+				isOK = false
+			}
+			if isOK && batchResolver != nil {
+				batch, err := batchResolver.batch(m.Offset)
+				if err != nil {
+					p.Logger.Error("failed to resolve record batch", zap.Int64("offset", m.Offset), zap.Error(err))
+					p.Progress.OnError(fmt.Sprintf("failed to resolve record batch (partition: '%v', offset: '%v')", m.Partition, m.Offset))
+					return
+				}
+				if p.WithBatchCompression {
+					topicMessage.BatchCompression = batch.Compression
+				}
+				if p.ProducerIDFilter != nil {
+					producerID := batch.ProducerID
+					topicMessage.ProducerID = &producerID
+					isOK = producerID == *p.ProducerIDFilter
+				}
+			}
+			if isOK && p.RecordCap != nil && !p.RecordCap.Acquire(ctx, p.Req.PartitionID) {
+				return // reached the cap across all partitions
+			}
 			if isOK {
 				messageCount++
 
+				if len(p.ProjectionPaths) > 0 {
+					topicMessage.Value = p.projectEmbedding(topicMessage.Value)
+					if p.ProjectKeys {
+						topicMessage.Key = p.projectEmbedding(topicMessage.Key)
+					}
+				}
+				if extractSegments != nil {
+					topicMessage.ExtractedValue = p.extractEmbedding(topicMessage.Value, extractSegments)
+					topicMessage.Value = DirectEmbedding{ValueType: topicMessage.Value.ValueType}
+				}
+
 				// This is necessary because receiver might have quit before we processed the ctx.Done() and therefore
 				// the channel might be blocked which would eventually mean a goroutine leak.
 				select {
@@ -179,10 +485,83 @@ func (p *PartitionConsumer) Run(ctx context.Context) {
 	}
 }
 
+// consumePartition starts consuming the partition at the requested start offset. If the start offset is below the
+// partition's current low water mark, consumption starts at the low water mark instead.
+func (p *PartitionConsumer) consumePartition() (sarama.PartitionConsumer, error) {
+	pConsumer, err := p.Consumer.ConsumePartition(p.TopicName, p.Req.PartitionID, p.Req.StartOffset)
+	if err != sarama.ErrOffsetOutOfRange || p.LowWaterMark == nil {
+		return pConsumer, err
+	}
+
+	lowWaterMark, lErr := p.LowWaterMark()
+	if lErr != nil {
+		return nil, fmt.Errorf("%v and failed to get low water mark: %w", err, lErr)
+	}
+	if lowWaterMark <= p.Req.StartOffset {
+		// The start offset is out of range for another reason, e.g. because it's beyond the high water mark
+		return nil, err
+	}
+
+	plannedOffset := p.Req.StartOffset
+	p.Req.StartOffset = lowWaterMark
+	p.Req.IsStartOffsetAdjusted = true
+	p.Logger.Debug("start offset is out of range, consuming from low water mark instead",
+		zap.Int64("planned_offset", plannedOffset),
+		zap.Int64("low_water_mark", lowWaterMark))
+	p.Progress.OnStartOffsetAdjusted(p.Req.PartitionID, plannedOffset, lowWaterMark)
+
+	return p.Consumer.ConsumePartition(p.TopicName, p.Req.PartitionID, lowWaterMark)
+}
+
 // getValue returns the valueType along with it's DirectEmbedding which implements a custom Marshaller,
 // so that it can return a string in the desired representation, regardless whether it's binary, text, xml
-// or JSON data.
-func (p *PartitionConsumer) getValue(value []byte) (valueType, DirectEmbedding) {
+// or JSON data. If the value has been serialized using Confluent's or Apicurio's wire format, the respective
+// schema info is returned as well. The format is detected unless a format is given.
+func (p *PartitionConsumer) getValue(value []byte, format string) (valueType, DirectEmbedding, *SchemaInfo) {
+	return p.getRecordValue(value, format, nil, false)
+}
+
+// getRecordValue is getValue for a key or value of a record, whose Apicurio global ID may be passed in the headers
+func (p *PartitionConsumer) getRecordValue(value []byte, format string, headers []*sarama.RecordHeader, isKey bool) (valueType, DirectEmbedding, *SchemaInfo) {
+	if len(value) == 0 {
+		return "", DirectEmbedding{ValueType: "", Value: value}, nil
+	}
+
+	// Only check for the wire format if a schema registry is configured, because the magic byte alone is too weak
+	// of an indicator.
+	if p.SchemaService != nil {
+		wireFormat := p.resolveWireFormat()
+		if wireFormat != WireFormatConfluent {
+			if globalID, ok := parseApicurioGlobalIDHeader(headers, isKey); ok {
+				return p.getSchemaValue(value, value, globalID, WireFormatApicurio, format)
+			}
+		}
+		if schemaID, payload, wireFormat, ok := parseWireFormat(value, wireFormat); ok {
+			return p.getSchemaValue(value, payload, schemaID, wireFormat, format)
+		}
+	}
+
+	vType, embedding := decodeValue(value, format)
+	return vType, embedding, nil
+}
+
+// getSchemaValue decodes the payload of a value which has been serialized with the given schema
+func (p *PartitionConsumer) getSchemaValue(value []byte, payload []byte, schemaID uint32, wireFormat string, format string) (valueType, DirectEmbedding, *SchemaInfo) {
+	info := p.getSchemaInfo(schemaID, payload)
+	info.WireFormat = wireFormat
+	if !info.IsRegistered {
+		// We can't decode the payload without it's schema, therefore we return the raw value
+		b64 := []byte(base64.StdEncoding.EncodeToString(value))
+		return valueTypeBinary, DirectEmbedding{ValueType: valueTypeBinary, Value: b64}, info
+	}
+
+	vType, embedding := decodeValue(payload, format)
+	return vType, embedding, info
+}
+
+// detectValue tries to detect the value's format (JSON, XML, text or binary) and returns the DirectEmbedding
+// in the respective representation.
+func detectValue(value []byte) (valueType, DirectEmbedding) {
 	if len(value) == 0 {
 		return "", DirectEmbedding{ValueType: "", Value: value}
 	}
@@ -303,3 +682,8 @@ func (p *PartitionConsumer) SetupInterpreter() (func(args interpreterArguments)
 
 	return isMessageOk, nil
 }
+
+// isBeforeMinTimestamp returns true if a minimum timestamp is set and the given timestamp is older
+func (p *PartitionConsumer) isBeforeMinTimestamp(timestamp time.Time) bool {
+	return !p.MinTimestamp.IsZero() && timestamp.Before(p.MinTimestamp)
+}
@@ -0,0 +1,130 @@
+package kafka
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// recordingProgress records the start offset adjustments, corrupted batches and errors of a consume request
+type recordingProgress struct {
+	adjustments [][3]int64 // PartitionID, planned offset, adjusted offset
+	corruptions []int64    // Offsets of the corrupted batches
+	errors      []string
+}
+
+func (r *recordingProgress) OnPhase(name string)                          {}
+func (r *recordingProgress) OnMessage(message *TopicMessage)              {}
+func (r *recordingProgress) OnMessageConsumed(size int64)                 {}
+func (r *recordingProgress) OnComplete(elapsedMs int64, isCancelled bool) {}
+func (r *recordingProgress) OnThrottled(delayMs int64, reason string)     {}
+func (r *recordingProgress) OnError(msg string)                           { r.errors = append(r.errors, msg) }
+func (r *recordingProgress) OnBatchCorrupted(partitionID int32, offset int64, reason string) {
+	r.corruptions = append(r.corruptions, offset)
+}
+func (r *recordingProgress) OnSampled(rate int64, consumedCount int64, sampledCount int64) {
+}
+func (r *recordingProgress) OnStartOffsetAdjusted(partitionID int32, plannedOffset, adjustedOffset int64) {
+	r.adjustments = append(r.adjustments, [3]int64{int64(partitionID), plannedOffset, adjustedOffset})
+}
+
+func TestPartitionConsumer_StartOffsetOutOfRange(t *testing.T) {
+	broker := sarama.NewMockBroker(t, 1)
+	defer broker.Close()
+
+	// Retention has advanced the low water mark from 10 (when the request was planned) to 50
+	broker.SetHandlerByMap(map[string]sarama.MockResponse{
+		"MetadataRequest": sarama.NewMockMetadataResponse(t).
+			SetBroker(broker.Addr(), broker.BrokerID()).
+			SetLeader("orders", 0, broker.BrokerID()),
+		"OffsetRequest": sarama.NewMockOffsetResponse(t).
+			SetOffset("orders", 0, sarama.OffsetOldest, 50).
+			SetOffset("orders", 0, sarama.OffsetNewest, 52),
+		"FetchRequest": sarama.NewMockFetchResponse(t, 2).
+			SetMessage("orders", 0, 50, sarama.StringEncoder("a")).
+			SetMessage("orders", 0, 51, sarama.StringEncoder("b")),
+	})
+
+	client, err := sarama.NewClient([]string{broker.Addr()}, sarama.NewConfig())
+	require.NoError(t, err)
+	defer client.Close()
+	consumer, err := sarama.NewConsumerFromClient(client)
+	require.NoError(t, err)
+	defer consumer.Close()
+
+	doneCh := make(chan struct{}, 1)
+	messageCh := make(chan *TopicMessage, 10)
+	progress := &recordingProgress{}
+	req := &PartitionConsumeRequest{PartitionID: 0, StartOffset: 10, EndOffset: 51, MaxMessageCount: 42}
+	p := &PartitionConsumer{
+		Logger:    zap.NewNop(),
+		DoneCh:    doneCh,
+		MessageCh: messageCh,
+		Progress:  progress,
+		Consumer:  consumer,
+		TopicName: "orders",
+		Req:       req,
+		LowWaterMark: func() (int64, error) {
+			return client.GetOffset("orders", 0, sarama.OffsetOldest)
+		},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	p.Run(ctx)
+	<-doneCh
+	close(messageCh)
+
+	offsets := make([]int64, 0)
+	for msg := range messageCh {
+		offsets = append(offsets, msg.Offset)
+	}
+	assert.Empty(t, progress.errors)
+	assert.Equal(t, []int64{50, 51}, offsets)
+	assert.True(t, req.IsStartOffsetAdjusted)
+	assert.Equal(t, int64(50), req.StartOffset)
+	assert.Equal(t, [][3]int64{{0, 10, 50}}, progress.adjustments)
+}
+
+func TestPartitionConsumer_StartOffsetOutOfRangeWithoutResolver(t *testing.T) {
+	broker := sarama.NewMockBroker(t, 1)
+	defer broker.Close()
+
+	broker.SetHandlerByMap(map[string]sarama.MockResponse{
+		"MetadataRequest": sarama.NewMockMetadataResponse(t).
+			SetBroker(broker.Addr(), broker.BrokerID()).
+			SetLeader("orders", 0, broker.BrokerID()),
+		"OffsetRequest": sarama.NewMockOffsetResponse(t).
+			SetOffset("orders", 0, sarama.OffsetOldest, 50).
+			SetOffset("orders", 0, sarama.OffsetNewest, 52),
+	})
+
+	client, err := sarama.NewClient([]string{broker.Addr()}, sarama.NewConfig())
+	require.NoError(t, err)
+	defer client.Close()
+	consumer, err := sarama.NewConsumerFromClient(client)
+	require.NoError(t, err)
+	defer consumer.Close()
+
+	doneCh := make(chan struct{}, 1)
+	progress := &recordingProgress{}
+	p := &PartitionConsumer{
+		Logger:    zap.NewNop(),
+		DoneCh:    doneCh,
+		MessageCh: make(chan *TopicMessage),
+		Progress:  progress,
+		Consumer:  consumer,
+		TopicName: "orders",
+		Req:       &PartitionConsumeRequest{PartitionID: 0, StartOffset: 10, EndOffset: 51, MaxMessageCount: 42},
+	}
+	p.Run(context.Background())
+	<-doneCh
+
+	assert.Len(t, progress.errors, 1)
+	assert.Empty(t, progress.adjustments)
+}
@@ -0,0 +1,62 @@
+package kafka
+
+// PartitionKeyHash is the partition a message's key is assigned to by the default partitioner of the Java client
+// (murmur2 hash of the serialized key modulo the partition count). A mismatch with the actual partition indicates a
+// custom partitioner, a producer which explicitly selected the partition or a partition count that has changed.
+type PartitionKeyHash struct {
+	ComputedPartitionID int32 `json:"computedPartitionId"`
+	IsMatching          bool  `json:"isMatching"`
+}
+
+// newPartitionKeyHash returns the computed partition of the raw key or nil if the message has no key, because the
+// default partitioner doesn't hash null keys.
+func newPartitionKeyHash(key []byte, actualPartitionID int32, partitionCount int32) *PartitionKeyHash {
+	if key == nil || partitionCount <= 0 {
+		return nil
+	}
+
+	computed := (murmur2(key) & 0x7fffffff) % partitionCount
+	return &PartitionKeyHash{
+		ComputedPartitionID: computed,
+		IsMatching:          computed == actualPartitionID,
+	}
+}
+
+// murmur2 is the 32 bit murmur2 hash as implemented by the Java client's org.apache.kafka.common.utils.Utils
+func murmur2(data []byte) int32 {
+	const (
+		seed uint32 = 0x9747b28c
+		m    uint32 = 0x5bd1e995
+		r           = 24
+	)
+
+	length := len(data)
+	h := seed ^ uint32(length)
+	for i := 0; i+4 <= length; i += 4 {
+		k := uint32(data[i]) | uint32(data[i+1])<<8 | uint32(data[i+2])<<16 | uint32(data[i+3])<<24
+		k *= m
+		k ^= k >> r
+		k *= m
+		h *= m
+		h ^= k
+	}
+
+	tail := length &^ 3
+	switch length % 4 {
+	case 3:
+		h ^= uint32(data[tail+2]) << 16
+		fallthrough
+	case 2:
+		h ^= uint32(data[tail+1]) << 8
+		fallthrough
+	case 1:
+		h ^= uint32(data[tail])
+		h *= m
+	}
+
+	h ^= h >> 13
+	h *= m
+	h ^= h >> 15
+
+	return int32(h)
+}
@@ -0,0 +1,21 @@
+package kafka
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewPartitionKeyHash(t *testing.T) {
+	// Reference values of the Java client's Utils.murmur2
+	assert.Equal(t, int32(-973932308), murmur2([]byte("21")))
+	assert.Equal(t, int32(-790332482), murmur2([]byte("foobar")))
+	assert.Equal(t, int32(-985981536), murmur2([]byte("a-little-bit-long-string")))
+	assert.Equal(t, int32(275646681), murmur2([]byte("")))
+
+	// toPositive(-790332482) = 1357151166, which is assigned to partition 0 of 3
+	assert.Equal(t, &PartitionKeyHash{ComputedPartitionID: 0, IsMatching: true}, newPartitionKeyHash([]byte("foobar"), 0, 3))
+	assert.Equal(t, &PartitionKeyHash{ComputedPartitionID: 0, IsMatching: false}, newPartitionKeyHash([]byte("foobar"), 2, 3))
+
+	assert.Nil(t, newPartitionKeyHash(nil, 0, 3))
+}
@@ -0,0 +1,77 @@
+package kafka
+
+import (
+	"fmt"
+
+	"github.com/Shopify/sarama"
+)
+
+// ProduceRecord produces a single record and returns the partition and offset it has been written to. A partition
+// ID of -1 lets the hash partitioner choose the partition based on the key. A nil value is produced as null value
+// (tombstone), which is different from an empty value.
+func (s *Service) ProduceRecord(topic string, partitionID int32, key []byte, value []byte) (int32, int64, error) {
+	producer, err := s.syncProducer()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return producer.SendMessage(newProducerMessage(topic, partitionID, key, value))
+}
+
+// syncProducer returns the producer of the service's client, which is created on first use. The client's config is
+// not configured to return successes, hence the producer reads its settings from an adjusted copy.
+func (s *Service) syncProducer() (sarama.SyncProducer, error) {
+	s.producerMutex.Lock()
+	defer s.producerMutex.Unlock()
+	if s.producer != nil {
+		return s.producer, nil
+	}
+
+	cfg := *s.Client.Config()
+	cfg.Producer.Return.Successes = true
+	cfg.Producer.Return.Errors = true
+	cfg.Producer.RequiredAcks = sarama.WaitForAll
+	cfg.Producer.Partitioner = newManualOrHashPartitioner
+	producer, err := sarama.NewSyncProducerFromClient(&configuredClient{Client: s.Client, cfg: &cfg})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create producer: %w", err)
+	}
+	s.producer = producer
+
+	return producer, nil
+}
+
+// manualOrHashPartitioner produces to the partition of the message if one is set and otherwise chooses the
+// partition based on the key
+type manualOrHashPartitioner struct {
+	hash sarama.Partitioner
+}
+
+func newManualOrHashPartitioner(topic string) sarama.Partitioner {
+	return &manualOrHashPartitioner{hash: sarama.NewHashPartitioner(topic)}
+}
+
+func (p *manualOrHashPartitioner) Partition(msg *sarama.ProducerMessage, numPartitions int32) (int32, error) {
+	if msg.Partition >= 0 {
+		return msg.Partition, nil
+	}
+	return p.hash.Partition(msg, numPartitions)
+}
+
+func (p *manualOrHashPartitioner) RequiresConsistency() bool {
+	return true
+}
+
+// newProducerMessage creates the message to produce. Nil keys and values are encoded as null, empty ones as
+// zero length bytes.
+func newProducerMessage(topic string, partitionID int32, key []byte, value []byte) *sarama.ProducerMessage {
+	msg := &sarama.ProducerMessage{Topic: topic, Partition: partitionID}
+	if key != nil {
+		msg.Key = sarama.ByteEncoder(key)
+	}
+	if value != nil {
+		msg.Value = sarama.ByteEncoder(value)
+	}
+
+	return msg
+}
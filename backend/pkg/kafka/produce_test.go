@@ -0,0 +1,98 @@
+package kafka
+
+import (
+	"testing"
+
+	"github.com/Shopify/sarama"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// producedRecord is the first record of a produce request, nil keys and values have been produced as null
+type producedRecord struct {
+	PartitionID int32
+	Key         []byte
+	Value       []byte
+}
+
+// decodeProduceRequest decodes the first record of an uncompressed produce request (v3 - v7) with a single
+// record batch (magic 2)
+func decodeProduceRequest(t *testing.T, req *capturedRequest) producedRecord {
+	require.True(t, req.Version >= 3 && req.Version <= 7, "produce request version %v is not supported", req.Version)
+	d := req.Body
+	d.getNullableString() // Transactional ID
+	d.getInt16()          // Acks
+	d.getInt32()          // Timeout
+	require.Equal(t, 1, d.getArrayLen())
+	d.getNullableString() // Topic
+	require.Equal(t, 1, d.getArrayLen())
+	res := producedRecord{PartitionID: d.getInt32()}
+	d.getInt32() // Size of the record batch
+	d.getInt64() // Base offset
+	d.getInt32() // Batch length
+	d.getInt32() // Partition leader epoch
+	require.Equal(t, int8(2), getInt8(d))
+	d.getInt32() // CRC
+	require.Equal(t, int16(0), d.getInt16()&0x7, "record batch must not be compressed")
+	d.read(4 + 8 + 8 + 8 + 2 + 4) // Last offset delta, timestamps, producer ID and epoch, base sequence
+	require.Equal(t, int32(1), d.getInt32())
+
+	getVarint(d) // Record length
+	getInt8(d)   // Attributes
+	getVarint(d) // Timestamp delta
+	getVarint(d) // Offset delta
+	if keyLen := getVarint(d); keyLen >= 0 {
+		res.Key = d.read(int(keyLen))
+	}
+	if valueLen := getVarint(d); valueLen >= 0 {
+		res.Value = d.read(int(valueLen))
+	}
+	require.NoError(t, d.err)
+	return res
+}
+
+func TestService_ProduceRecord(t *testing.T) {
+	broker := sarama.NewMockBroker(t, 1)
+	defer broker.Close()
+	capture := newRequestCapture(t, broker.Addr())
+	defer capture.Close()
+	broker.SetHandlerByMap(map[string]sarama.MockResponse{
+		"MetadataRequest": sarama.NewMockMetadataResponse(t).
+			SetBroker(capture.Addr().String(), broker.BrokerID()).
+			SetLeader("customers", 0, broker.BrokerID()).
+			SetLeader("customers", 1, broker.BrokerID()),
+		"ProduceRequest": sarama.NewMockProduceResponse(t).SetVersion(3),
+	})
+
+	cfg := sarama.NewConfig()
+	cfg.Version = sarama.V1_0_0_0
+	client, err := sarama.NewClient([]string{capture.Addr().String()}, cfg)
+	require.NoError(t, err)
+	defer client.Close()
+	svc := &Service{Client: client, Logger: zap.NewNop()}
+
+	// A tombstone has a null value, whereas an empty value is produced as zero length bytes
+	partitionID, _, err := svc.ProduceRecord("customers", 1, []byte("customer-42"), nil)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), partitionID)
+	producer := svc.producer
+	_, _, err = svc.ProduceRecord("customers", 0, []byte("customer-43"), []byte{})
+	require.NoError(t, err)
+
+	// Records without a partition are partitioned by their key, the same producer is used for all records
+	_, _, err = svc.ProduceRecord("customers", -1, []byte("customer-44"), []byte("active"))
+	require.NoError(t, err)
+	assert.Same(t, producer, svc.producer)
+
+	requests := capture.Requests(apiKeyProduce)
+	require.Len(t, requests, 3)
+	assert.Equal(t, producedRecord{PartitionID: 1, Key: []byte("customer-42")}, decodeProduceRequest(t, requests[0]))
+	assert.Equal(t, producedRecord{PartitionID: 0, Key: []byte("customer-43"), Value: []byte{}}, decodeProduceRequest(t, requests[1]))
+	keyed := decodeProduceRequest(t, requests[2])
+	assert.Equal(t, []byte("active"), keyed.Value)
+	expectedPartition, err := sarama.NewHashPartitioner("customers").Partition(&sarama.ProducerMessage{Key: sarama.StringEncoder("customer-44")}, 2)
+	require.NoError(t, err)
+	assert.Equal(t, expectedPartition, keyed.PartitionID)
+	assert.False(t, client.Closed())
+}
@@ -0,0 +1,141 @@
+package kafka
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// projectJSON returns a JSON object which only contains the requested paths of the given JSON document, using the
+// paths as keys. Paths use dot-notation for nested fields and brackets for array elements, e.g. "items[0].sku".
+// The wildcard "[*]" selects all elements of an array. Paths that do not match are omitted.
+func projectJSON(value []byte, paths []string) ([]byte, error) {
+	doc, err := decodeJSON(value)
+	if err != nil {
+		return nil, err
+	}
+
+	projected := make(map[string]interface{}, len(paths))
+	for _, path := range paths {
+		segments, err := parseProjectionPath(path)
+		if err != nil {
+			return nil, err
+		}
+		if match, ok := selectJSONPath(doc, segments); ok {
+			projected[path] = match
+		}
+	}
+
+	return json.Marshal(projected)
+}
+
+// ValidateProjectionPaths returns an error if any of the given projection paths can not be parsed
+func ValidateProjectionPaths(paths []string) error {
+	for _, path := range paths {
+		if _, err := parseProjectionPath(path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// projectionSegment is a single step of a projection path, either an object key or an array index
+type projectionSegment struct {
+	Key        string
+	Index      int
+	IsIndex    bool
+	IsWildcard bool
+}
+
+// parseProjectionPath splits a path such as "customer.addresses[*].city" into its segments
+func parseProjectionPath(path string) ([]projectionSegment, error) {
+	segments := make([]projectionSegment, 0)
+	for _, part := range strings.Split(path, ".") {
+		key := part
+		indexes := ""
+		if i := strings.Index(part, "["); i >= 0 {
+			key = part[:i]
+			indexes = part[i:]
+		}
+		if key != "" {
+			segments = append(segments, projectionSegment{Key: key})
+		}
+
+		for indexes != "" {
+			end := strings.Index(indexes, "]")
+			if !strings.HasPrefix(indexes, "[") || end < 0 {
+				return nil, fmt.Errorf("invalid array selector in projection path '%v'", path)
+			}
+			selector := indexes[1:end]
+			indexes = indexes[end+1:]
+			if selector == "*" {
+				segments = append(segments, projectionSegment{IsIndex: true, IsWildcard: true})
+				continue
+			}
+			index, err := strconv.Atoi(selector)
+			if err != nil || index < 0 {
+				return nil, fmt.Errorf("invalid array index '%v' in projection path '%v'", selector, path)
+			}
+			segments = append(segments, projectionSegment{IsIndex: true, Index: index})
+		}
+	}
+	if len(segments) == 0 {
+		return nil, fmt.Errorf("projection path must not be empty")
+	}
+
+	return segments, nil
+}
+
+func selectJSONPath(value interface{}, segments []projectionSegment) (interface{}, bool) {
+	if len(segments) == 0 {
+		return value, true
+	}
+	segment := segments[0]
+
+	if !segment.IsIndex {
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		child, exists := obj[segment.Key]
+		if !exists {
+			return nil, false
+		}
+		return selectJSONPath(child, segments[1:])
+	}
+
+	arr, ok := value.([]interface{})
+	if !ok {
+		return nil, false
+	}
+	if segment.IsWildcard {
+		matches := make([]interface{}, 0, len(arr))
+		for _, element := range arr {
+			if match, ok := selectJSONPath(element, segments[1:]); ok {
+				matches = append(matches, match)
+			}
+		}
+		return matches, true
+	}
+	if segment.Index >= len(arr) {
+		return nil, false
+	}
+	return selectJSONPath(arr[segment.Index], segments[1:])
+}
+
+// projectEmbedding replaces a JSON embedding with its projection. Non JSON values are returned unchanged.
+func (p *PartitionConsumer) projectEmbedding(embedding DirectEmbedding) DirectEmbedding {
+	if embedding.ValueType != valueTypeJSON {
+		return embedding
+	}
+
+	projected, err := projectJSON(embedding.Value, p.ProjectionPaths)
+	if err != nil {
+		p.Logger.Debug("failed to project json value", zap.Error(err))
+		return embedding
+	}
+	return DirectEmbedding{ValueType: valueTypeJSON, Value: projected}
+}
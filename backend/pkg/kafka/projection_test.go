@@ -0,0 +1,22 @@
+package kafka
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProjectJSON(t *testing.T) {
+	value := []byte(`{"id":7,"status":"paid","customer":{"name":"Jane","email":"jane@example.com"},"items":[{"sku":"a1"},{"sku":"b2"}],"total":12.5}`)
+
+	projected, err := projectJSON(value, []string{"customer.name", "total", "doesNotExist"})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"customer.name":"Jane","total":12.5}`, string(projected))
+
+	projected, err = projectJSON(value, []string{"items[1].sku", "items[*].sku"})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"items[1].sku":"b2","items[*].sku":["a1","b2"]}`, string(projected))
+
+	assert.Error(t, ValidateProjectionPaths([]string{"items[x]"}))
+}
@@ -0,0 +1,296 @@
+package kafka
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"time"
+
+	"github.com/Shopify/sarama"
+)
+
+// The raw protocol implementation is used for requests which are not implemented by sarama. It only supports
+// plaintext and TLS connections, as well as the SASL PLAIN mechanism.
+const (
+	apiKeySaslHandshake    int16 = 17
+	apiKeySaslAuthenticate int16 = 36
+)
+
+// rawEncoder encodes the primitive types of the Kafka protocol
+type rawEncoder struct {
+	buf []byte
+}
+
+func (e *rawEncoder) putInt16(v int16) {
+	e.buf = append(e.buf, byte(uint16(v)>>8), byte(v))
+}
+
+func (e *rawEncoder) putInt32(v int32) {
+	e.buf = append(e.buf, 0, 0, 0, 0)
+	binary.BigEndian.PutUint32(e.buf[len(e.buf)-4:], uint32(v))
+}
+
+func (e *rawEncoder) putInt64(v int64) {
+	e.buf = append(e.buf, 0, 0, 0, 0, 0, 0, 0, 0)
+	binary.BigEndian.PutUint64(e.buf[len(e.buf)-8:], uint64(v))
+}
+
+func (e *rawEncoder) putUvarint(v uint64) {
+	var tmp [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(tmp[:], v)
+	e.buf = append(e.buf, tmp[:n]...)
+}
+
+func (e *rawEncoder) putString(v string) {
+	e.putInt16(int16(len(v)))
+	e.buf = append(e.buf, v...)
+}
+
+func (e *rawEncoder) putCompactString(v string) {
+	e.putUvarint(uint64(len(v) + 1))
+	e.buf = append(e.buf, v...)
+}
+
+func (e *rawEncoder) putBytes(v []byte) {
+	e.putInt32(int32(len(v)))
+	e.buf = append(e.buf, v...)
+}
+
+// putCompactArrayLen encodes the length of a compact array which is followed by its elements
+func (e *rawEncoder) putCompactArrayLen(n int) {
+	e.putUvarint(uint64(n + 1))
+}
+
+// putEmptyTaggedFields encodes the tagged fields of flexible versions, no tagged fields are sent
+func (e *rawEncoder) putEmptyTaggedFields() {
+	e.putUvarint(0)
+}
+
+// rawDecoder decodes the primitive types of the Kafka protocol. The first error is kept, all following reads
+// return zero values, so that the error only has to be checked once at the end.
+type rawDecoder struct {
+	buf []byte
+	err error
+}
+
+func (d *rawDecoder) read(n int) []byte {
+	if d.err != nil {
+		return nil
+	}
+	if n < 0 || len(d.buf) < n {
+		d.err = fmt.Errorf("insufficient data to decode packet, %v more bytes expected", n-len(d.buf))
+		return nil
+	}
+	b := d.buf[:n]
+	d.buf = d.buf[n:]
+	return b
+}
+
+func (d *rawDecoder) getInt16() int16 {
+	if b := d.read(2); b != nil {
+		return int16(binary.BigEndian.Uint16(b))
+	}
+	return 0
+}
+
+func (d *rawDecoder) getInt32() int32 {
+	if b := d.read(4); b != nil {
+		return int32(binary.BigEndian.Uint32(b))
+	}
+	return 0
+}
+
+func (d *rawDecoder) getInt64() int64 {
+	if b := d.read(8); b != nil {
+		return int64(binary.BigEndian.Uint64(b))
+	}
+	return 0
+}
+
+func (d *rawDecoder) getUvarint() uint64 {
+	if d.err != nil {
+		return 0
+	}
+	v, n := binary.Uvarint(d.buf)
+	if n <= 0 {
+		d.err = fmt.Errorf("invalid varint")
+		return 0
+	}
+	d.buf = d.buf[n:]
+	return v
+}
+
+// getNullableString decodes a non compact string, -1 is null
+func (d *rawDecoder) getNullableString() string {
+	n := d.getInt16()
+	if n < 0 {
+		return ""
+	}
+	return string(d.read(int(n)))
+}
+
+// getCompactString decodes a compact (nullable) string
+func (d *rawDecoder) getCompactString() string {
+	n := d.getUvarint()
+	if n == 0 {
+		return ""
+	}
+	return string(d.read(int(n - 1)))
+}
+
+// getCompactArrayLen returns the number of elements of a compact array, null arrays have no elements
+func (d *rawDecoder) getCompactArrayLen() int {
+	n := d.getUvarint()
+	if n == 0 {
+		return 0
+	}
+	return int(n - 1)
+}
+
+func (d *rawDecoder) getArrayLen() int {
+	n := d.getInt32()
+	if n < 0 {
+		return 0
+	}
+	return int(n)
+}
+
+// skipTaggedFields skips the tagged fields of flexible versions, none of them are used
+func (d *rawDecoder) skipTaggedFields() {
+	count := d.getUvarint()
+	for i := uint64(0); i < count && d.err == nil; i++ {
+		d.getUvarint() // Tag
+		d.read(int(d.getUvarint()))
+	}
+}
+
+// rawBrokerConn is a connection to a single broker for requests which are not implemented by sarama
+type rawBrokerConn struct {
+	conn          net.Conn
+	clientID      string
+	correlationID int32
+}
+
+// openRawBrokerConn connects and authenticates to the broker using the connection settings of the sarama config
+func openRawBrokerConn(ctx context.Context, addr string, cfg *sarama.Config) (*rawBrokerConn, error) {
+	if cfg.Net.SASL.Enable && cfg.Net.SASL.Mechanism != sarama.SASLTypePlaintext && cfg.Net.SASL.Mechanism != "" {
+		return nil, fmt.Errorf("sasl mechanism '%v' is not supported for this request", cfg.Net.SASL.Mechanism)
+	}
+
+	dialer := net.Dialer{Timeout: cfg.Net.DialTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to broker '%v': %w", addr, err)
+	}
+	if cfg.Net.TLS.Enable {
+		tlsCfg := cfg.Net.TLS.Config
+		if tlsCfg == nil {
+			tlsCfg = &tls.Config{}
+		}
+		if tlsCfg.ServerName == "" && !tlsCfg.InsecureSkipVerify {
+			tlsCfg = tlsCfg.Clone()
+			tlsCfg.ServerName, _, _ = net.SplitHostPort(addr)
+		}
+		conn = tls.Client(conn, tlsCfg)
+	}
+
+	deadline := time.Now().Add(cfg.Net.ReadTimeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	if err := conn.SetDeadline(deadline); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	c := &rawBrokerConn{conn: conn, clientID: cfg.ClientID}
+	if cfg.Net.SASL.Enable {
+		if err := c.authenticatePlain(cfg.Net.SASL.User, cfg.Net.SASL.Password); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("failed to authenticate: %w", err)
+		}
+	}
+
+	return c, nil
+}
+
+func (c *rawBrokerConn) Close() error {
+	return c.conn.Close()
+}
+
+// roundTrip sends the request body and returns the response body. Flexible versions use the request header v2 and
+// response header v1, which contain tagged fields.
+func (c *rawBrokerConn) roundTrip(apiKey int16, version int16, isFlexible bool, body []byte) (*rawDecoder, error) {
+	c.correlationID++
+
+	header := rawEncoder{}
+	header.putInt16(apiKey)
+	header.putInt16(version)
+	header.putInt32(c.correlationID)
+	header.putString(c.clientID)
+	if isFlexible {
+		header.putEmptyTaggedFields()
+	}
+
+	req := rawEncoder{}
+	req.putInt32(int32(len(header.buf) + len(body)))
+	req.buf = append(req.buf, header.buf...)
+	req.buf = append(req.buf, body...)
+	if _, err := c.conn.Write(req.buf); err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+
+	sizeBuf := make([]byte, 4)
+	if _, err := io.ReadFull(c.conn, sizeBuf); err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	res := make([]byte, binary.BigEndian.Uint32(sizeBuf))
+	if _, err := io.ReadFull(c.conn, res); err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	d := &rawDecoder{buf: res}
+	if correlationID := d.getInt32(); d.err == nil && correlationID != c.correlationID {
+		return nil, fmt.Errorf("correlation id mismatch, expected %v but got %v", c.correlationID, correlationID)
+	}
+	if isFlexible {
+		d.skipTaggedFields()
+	}
+	if d.err != nil {
+		return nil, d.err
+	}
+	return d, nil
+}
+
+// authenticatePlain authenticates using SaslHandshake v1 and SaslAuthenticate v0 with the PLAIN mechanism
+func (c *rawBrokerConn) authenticatePlain(user string, password string) error {
+	handshake := rawEncoder{}
+	handshake.putString(sarama.SASLTypePlaintext)
+	d, err := c.roundTrip(apiKeySaslHandshake, 1, false, handshake.buf)
+	if err != nil {
+		return err
+	}
+	if kErr := sarama.KError(d.getInt16()); kErr != sarama.ErrNoError {
+		return fmt.Errorf("sasl handshake failed: %w", kErr)
+	}
+
+	authenticate := rawEncoder{}
+	authenticate.putBytes([]byte("\x00" + user + "\x00" + password))
+	d, err = c.roundTrip(apiKeySaslAuthenticate, 0, false, authenticate.buf)
+	if err != nil {
+		return err
+	}
+	kErr := sarama.KError(d.getInt16())
+	message := d.getNullableString()
+	if d.err != nil {
+		return d.err
+	}
+	if kErr != sarama.ErrNoError {
+		return fmt.Errorf("sasl authentication failed: %v: %w", message, kErr)
+	}
+
+	return nil
+}
@@ -0,0 +1,157 @@
+package kafka
+
+import (
+	"fmt"
+
+	"github.com/Shopify/sarama"
+)
+
+// NoProducerID is the producer ID of records which have been written by non idempotent producers
+const NoProducerID int64 = -1
+
+// RecordBatchInfo is the batch level metadata of a record batch
+type RecordBatchInfo struct {
+	BaseOffset      int64
+	LastOffset      int64
+	ProducerID      int64
+	ProducerEpoch   int16
+	IsTransactional bool
+
+	// PartitionLeaderEpoch is the epoch of the leader which has written the batch, NoLeaderEpoch for message sets
+	PartitionLeaderEpoch int32
+
+	// Compression is the codec from the batch attributes (none, gzip, snappy, lz4 or zstd)
+	Compression string
+}
+
+// FetchRecordBatchInfos returns the batch metadata of all complete batches that are returned by a single fetch
+// starting at the given offset.
+func (s *Service) FetchRecordBatchInfos(topic string, partitionID int32, offset int64) ([]*RecordBatchInfo, error) {
+	recordsSet, err := s.fetchRecords(topic, partitionID, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	return recordBatchInfos(recordsSet), nil
+}
+
+// fetchRecords sends a single fetch request for the given offset to the partition leader
+func (s *Service) fetchRecords(topic string, partitionID int32, offset int64) ([]*sarama.Records, error) {
+	block, err := s.fetchBlock(topic, partitionID, offset, sarama.ReadUncommitted, fetchMaxBytes)
+	if err != nil || block == nil {
+		return nil, err
+	}
+
+	return block.RecordsSet, nil
+}
+
+// fetchMaxBytes is the maximum size of the records returned by a single fetch
+const fetchMaxBytes int32 = 1024 * 1024
+
+// fetchBlock sends a single fetch request for the given offset to the partition leader. Read_committed responses
+// list the aborted transactions of the fetched range. It returns nil if the partition is not in the response.
+func (s *Service) fetchBlock(topic string, partitionID int32, offset int64, isolation sarama.IsolationLevel, maxBytes int32) (*sarama.FetchResponseBlock, error) {
+	broker, err := s.Client.Leader(topic, partitionID)
+	if err != nil {
+		return nil, err
+	}
+
+	req := &sarama.FetchRequest{MaxWaitTime: 0, MinBytes: 1}
+	version := s.Client.Config().Version
+	switch {
+	case version.IsAtLeast(sarama.V0_11_0_0):
+		req.Version = 4
+		req.MaxBytes = maxBytes
+		req.Isolation = isolation
+	case version.IsAtLeast(sarama.V0_10_0_0):
+		req.Version = 2
+	}
+	req.AddBlock(topic, partitionID, offset, maxBytes)
+
+	res, err := broker.Fetch(req)
+	if err != nil {
+		return nil, err
+	}
+	block := res.GetBlock(topic, partitionID)
+	if block == nil {
+		return nil, nil
+	}
+	if block.Err != sarama.ErrNoError {
+		return nil, block.Err
+	}
+
+	return block, nil
+}
+
+// recordBatchInfos converts the fetched records into batch infos. Legacy message sets don't carry a producer ID,
+// hence their messages are reported as a batch without producer ID and with the codec of the first message.
+func recordBatchInfos(recordsSet []*sarama.Records) []*RecordBatchInfo {
+	infos := make([]*RecordBatchInfo, 0, len(recordsSet))
+	for _, records := range recordsSet {
+		if batch := records.RecordBatch; batch != nil && !batch.PartialTrailingRecord {
+			infos = append(infos, &RecordBatchInfo{
+				BaseOffset:      batch.FirstOffset,
+				LastOffset:      batch.FirstOffset + int64(batch.LastOffsetDelta),
+				ProducerID:      batch.ProducerID,
+				ProducerEpoch:   batch.ProducerEpoch,
+				IsTransactional: batch.IsTransactional,
+
+				PartitionLeaderEpoch: batch.PartitionLeaderEpoch,
+				Compression:          compressionCodecName(batch.Codec),
+			})
+		}
+		if msgSet := records.MsgSet; msgSet != nil && len(msgSet.Messages) > 0 {
+			infos = append(infos, &RecordBatchInfo{
+				BaseOffset: msgSet.Messages[0].Offset,
+				LastOffset: msgSet.Messages[len(msgSet.Messages)-1].Offset,
+				ProducerID: NoProducerID,
+
+				PartitionLeaderEpoch: NoLeaderEpoch,
+				Compression:          compressionCodecName(msgSet.Messages[0].Msg.Codec),
+			})
+		}
+	}
+
+	return infos
+}
+
+// compressionCodecName returns the name of a batch's compression codec, sarama's String() panics on unknown codecs
+func compressionCodecName(codec sarama.CompressionCodec) string {
+	if codec < sarama.CompressionNone || codec > sarama.CompressionZSTD {
+		return fmt.Sprintf("unknown (%d)", codec)
+	}
+	return codec.String()
+}
+
+// recordBatchResolver resolves the record batch of consumed records. Batches are fetched once and cached, as the
+// records are consumed in order.
+type recordBatchResolver struct {
+	fetch   func(offset int64) ([]*RecordBatchInfo, error)
+	batches []*RecordBatchInfo
+}
+
+func (r *recordBatchResolver) batch(offset int64) (*RecordBatchInfo, error) {
+	if batch := r.lookup(offset); batch != nil {
+		return batch, nil
+	}
+
+	batches, err := r.fetch(offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch record batch: %w", err)
+	}
+	r.batches = batches
+	if batch := r.lookup(offset); batch != nil {
+		return batch, nil
+	}
+
+	return nil, fmt.Errorf("no record batch has been returned for offset %v", offset)
+}
+
+func (r *recordBatchResolver) lookup(offset int64) *RecordBatchInfo {
+	for _, batch := range r.batches {
+		if offset >= batch.BaseOffset && offset <= batch.LastOffset {
+			return batch
+		}
+	}
+	return nil
+}
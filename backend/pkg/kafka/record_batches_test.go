@@ -0,0 +1,59 @@
+package kafka
+
+import (
+	"testing"
+
+	"github.com/Shopify/sarama"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordBatchInfos(t *testing.T) {
+	recordsSet := []*sarama.Records{
+		{RecordBatch: &sarama.RecordBatch{FirstOffset: 10, LastOffsetDelta: 4, ProducerID: 7, ProducerEpoch: 1, IsTransactional: true, Codec: sarama.CompressionLZ4}},
+		{RecordBatch: &sarama.RecordBatch{FirstOffset: 15, LastOffsetDelta: 2, ProducerID: -1, PartialTrailingRecord: true}},
+	}
+
+	infos := recordBatchInfos(recordsSet)
+	require.Len(t, infos, 1)
+	assert.Equal(t, &RecordBatchInfo{BaseOffset: 10, LastOffset: 14, ProducerID: 7, ProducerEpoch: 1, IsTransactional: true, Compression: "lz4"}, infos[0])
+}
+
+func TestRecordBatchResolver(t *testing.T) {
+	fetchCount := 0
+	batches := []*RecordBatchInfo{
+		{BaseOffset: 0, LastOffset: 2, ProducerID: 1000},
+		{BaseOffset: 3, LastOffset: 3, ProducerID: NoProducerID},
+		{BaseOffset: 4, LastOffset: 6, ProducerID: 2000},
+	}
+	resolver := &recordBatchResolver{fetch: func(offset int64) ([]*RecordBatchInfo, error) {
+		fetchCount++
+		res := make([]*RecordBatchInfo, 0)
+		for _, b := range batches {
+			if b.LastOffset >= offset {
+				res = append(res, b)
+			}
+		}
+		return res, nil
+	}}
+
+	// Only the records of producer 2000 pass a filter on that producer ID
+	filter := int64(2000)
+	matchingOffsets := make([]int64, 0)
+	for offset := int64(0); offset <= 6; offset++ {
+		batch, err := resolver.batch(offset)
+		require.NoError(t, err)
+		if batch.ProducerID == filter {
+			matchingOffsets = append(matchingOffsets, offset)
+		}
+	}
+	assert.Equal(t, []int64{4, 5, 6}, matchingOffsets)
+	assert.Equal(t, 1, fetchCount)
+
+	batch, err := resolver.batch(3)
+	require.NoError(t, err)
+	assert.Equal(t, NoProducerID, batch.ProducerID)
+
+	_, err = resolver.batch(100)
+	assert.Error(t, err)
+}
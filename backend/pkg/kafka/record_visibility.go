@@ -0,0 +1,360 @@
+package kafka
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"go.uber.org/zap"
+)
+
+// Record visibilities select which records of transactional topics are presented. If a visibility is requested,
+// the consumer reads with read_committed isolation and the visibility governs which of the records it skips are
+// shown in addition. Without a visibility all records are presented as data, including those of aborted transactions.
+const (
+	RecordVisibilityData    = "data"    // Data records only (default)
+	RecordVisibilityControl = "control" // Data records and transaction markers
+	RecordVisibilityAborted = "aborted" // Data records and records of aborted transactions
+	RecordVisibilityAll     = "all"     // Data records, transaction markers and records of aborted transactions
+)
+
+// Record kinds as reported in TopicMessage.RecordKind
+const (
+	RecordKindData    = "data"
+	RecordKindControl = "control"
+	RecordKindAborted = "aborted"
+)
+
+// IsValidRecordVisibility returns true if the given visibility is supported, an empty visibility defaults to data
+func IsValidRecordVisibility(visibility string) bool {
+	switch visibility {
+	case "", RecordVisibilityData, RecordVisibilityControl, RecordVisibilityAborted, RecordVisibilityAll:
+		return true
+	default:
+		return false
+	}
+}
+
+// isRecordKindVisible returns true if records of the given kind are presented with the given visibility
+func isRecordKindVisible(visibility string, kind string) bool {
+	switch kind {
+	case RecordKindControl:
+		return visibility == RecordVisibilityControl || visibility == RecordVisibilityAll
+	case RecordKindAborted:
+		return visibility == RecordVisibilityAborted || visibility == RecordVisibilityAll
+	default:
+		return true
+	}
+}
+
+// ControlRecordInfo is the decoded transaction marker of a control record
+type ControlRecordInfo struct {
+	Type             string `json:"type"` // commit, abort or unknown
+	CoordinatorEpoch int32  `json:"coordinatorEpoch"`
+	ProducerID       int64  `json:"producerId"`
+}
+
+// TransactionalRecord is a record along with its kind, as classified by a read_committed consumer
+type TransactionalRecord struct {
+	Offset    int64
+	Kind      string
+	Timestamp time.Time
+	Key       []byte
+	Value     []byte
+	Headers   []*sarama.RecordHeader
+
+	// Control is only set for control records
+	Control *ControlRecordInfo
+}
+
+// FetchTransactionalRecords returns all records of a single read_committed fetch starting at the given offset,
+// including the control records and the records of aborted transactions which consumers skip.
+func (s *Service) FetchTransactionalRecords(topic string, partitionID int32, offset int64) ([]*TransactionalRecord, error) {
+	block, err := s.fetchBlock(topic, partitionID, offset, sarama.ReadCommitted, fetchMaxBytes)
+	if err != nil {
+		return nil, err
+	}
+	if block == nil {
+		return nil, nil
+	}
+
+	return classifyTransactionalRecords(block.RecordsSet, block.AbortedTransactions), nil
+}
+
+// LastStableOffset returns the partition's last stable offset, which is the first offset of the oldest open
+// transaction or the high water mark if there is none. Read_committed consumers don't get any records beyond it.
+func (s *Service) LastStableOffset(topic string, partitionID int32, highWaterMark int64) (int64, error) {
+	if !s.Client.Config().Version.IsAtLeast(sarama.V0_11_0_0) {
+		return highWaterMark, nil
+	}
+
+	// Fetching at the high water mark returns no records, but the response still reports the last stable offset
+	block, err := s.fetchBlock(topic, partitionID, highWaterMark, sarama.ReadCommitted, fetchMaxBytes)
+	if err != nil {
+		return 0, err
+	}
+	if block == nil || block.LastStableOffset < 0 || block.LastStableOffset > highWaterMark {
+		return highWaterMark, nil
+	}
+	return block.LastStableOffset, nil
+}
+
+// classifyTransactionalRecords classifies the fetched records the same way sarama's read_committed consumer does.
+// Producers are considered aborted from the first offset of their aborted transaction until their abort marker.
+func classifyTransactionalRecords(recordsSet []*sarama.Records, abortedTxns []*sarama.AbortedTransaction) []*TransactionalRecord {
+	pending := make([]*sarama.AbortedTransaction, len(abortedTxns))
+	copy(pending, abortedTxns)
+	sort.Slice(pending, func(i, j int) bool { return pending[i].FirstOffset < pending[j].FirstOffset })
+	abortedProducerIDs := make(map[int64]struct{})
+
+	records := make([]*TransactionalRecord, 0)
+	for _, set := range recordsSet {
+		if msgSet := set.MsgSet; msgSet != nil {
+			for _, msgBlock := range msgSet.Messages {
+				records = append(records, &TransactionalRecord{
+					Offset:    msgBlock.Offset,
+					Kind:      RecordKindData,
+					Timestamp: msgBlock.Msg.Timestamp,
+					Key:       msgBlock.Msg.Key,
+					Value:     msgBlock.Msg.Value,
+				})
+			}
+		}
+
+		batch := set.RecordBatch
+		if batch == nil {
+			continue
+		}
+		for len(pending) > 0 && pending[0].FirstOffset <= batch.LastOffset() {
+			abortedProducerIDs[pending[0].ProducerID] = struct{}{}
+			pending = pending[1:]
+		}
+
+		kind := RecordKindData
+		var control *ControlRecordInfo
+		if batch.Control {
+			kind = RecordKindControl
+			if len(batch.Records) > 0 {
+				control = decodeControlRecord(batch.Records[0].Key, batch.Records[0].Value)
+				control.ProducerID = batch.ProducerID
+				if control.Type == "abort" {
+					delete(abortedProducerIDs, batch.ProducerID)
+				}
+			}
+		} else if _, isAborted := abortedProducerIDs[batch.ProducerID]; isAborted && batch.IsTransactional {
+			kind = RecordKindAborted
+		}
+
+		for _, record := range batch.Records {
+			timestamp := batch.FirstTimestamp.Add(record.TimestampDelta)
+			if batch.LogAppendTime {
+				timestamp = batch.MaxTimestamp
+			}
+			records = append(records, &TransactionalRecord{
+				Offset:    batch.FirstOffset + record.OffsetDelta,
+				Kind:      kind,
+				Timestamp: timestamp,
+				Key:       record.Key,
+				Value:     record.Value,
+				Headers:   record.Headers,
+				Control:   control,
+			})
+		}
+	}
+
+	return records
+}
+
+// decodeControlRecord decodes a transaction marker. The key holds the version and the type, the value holds the
+// version and the coordinator epoch.
+func decodeControlRecord(key []byte, value []byte) *ControlRecordInfo {
+	info := &ControlRecordInfo{Type: "unknown"}
+	if len(key) >= 4 {
+		switch binary.BigEndian.Uint16(key[2:4]) {
+		case 0:
+			info.Type = "abort"
+		case 1:
+			info.Type = "commit"
+		}
+	}
+	if len(value) >= 6 {
+		info.CoordinatorEpoch = int32(binary.BigEndian.Uint32(value[2:6]))
+	}
+	return info
+}
+
+// recordVisibilityConsumer inserts the records which the underlying read_committed partition consumer skips into its
+// message stream, as far as they are visible. Skipped records are detected as offset gaps between consecutive
+// messages and at the end of the stream, and are resolved by fetching the raw records of the gap.
+type recordVisibilityConsumer struct {
+	sarama.PartitionConsumer
+
+	visibility string
+	endOffset  int64
+	fetch      func(offset int64) ([]*TransactionalRecord, error)
+	onError    func(err error)
+
+	messages chan *sarama.ConsumerMessage
+	doneCh   chan struct{}
+	stopped  chan struct{}
+
+	// kinds holds the records which have been inserted, until their kind has been looked up
+	kindsMutex sync.Mutex
+	kinds      map[int64]*TransactionalRecord
+
+	// fetched are the records of the last raw fetch, no further fetches are sent once a fetch has failed
+	fetched  []*TransactionalRecord
+	isFailed bool
+}
+
+func newRecordVisibilityConsumer(pConsumer sarama.PartitionConsumer, visibility string, startOffset int64, endOffset int64,
+	fetch func(offset int64) ([]*TransactionalRecord, error), onError func(err error)) *recordVisibilityConsumer {
+	c := &recordVisibilityConsumer{
+		PartitionConsumer: pConsumer,
+		visibility:        visibility,
+		endOffset:         endOffset,
+		fetch:             fetch,
+		onError:           onError,
+		messages:          make(chan *sarama.ConsumerMessage, cap(pConsumer.Messages())),
+		doneCh:            make(chan struct{}),
+		stopped:           make(chan struct{}),
+		kinds:             make(map[int64]*TransactionalRecord),
+	}
+	go c.run(startOffset)
+	return c
+}
+
+func (c *recordVisibilityConsumer) Messages() <-chan *sarama.ConsumerMessage {
+	return c.messages
+}
+
+func (c *recordVisibilityConsumer) Close() error {
+	close(c.doneCh)
+	err := c.PartitionConsumer.Close()
+	<-c.stopped
+	return err
+}
+
+// recordInfo returns the kind of the message at the given offset and its control record info, if any
+func (c *recordVisibilityConsumer) recordInfo(offset int64) (string, *ControlRecordInfo) {
+	c.kindsMutex.Lock()
+	defer c.kindsMutex.Unlock()
+
+	record, exists := c.kinds[offset]
+	if !exists {
+		return RecordKindData, nil
+	}
+	delete(c.kinds, offset)
+	return record.Kind, record.Control
+}
+
+func (c *recordVisibilityConsumer) run(nextOffset int64) {
+	defer close(c.stopped)
+	defer close(c.messages)
+
+	// nextOffset is the offset after the last delivered or skipped record, so that skipped records which have been
+	// inserted after a message are not inserted again before the following message.
+	ok := true
+	for m := range c.PartitionConsumer.Messages() {
+		if m.Offset > nextOffset {
+			if nextOffset, ok = c.sendSkipped(m, nextOffset, m.Offset); !ok {
+				return
+			}
+		}
+		if !c.send(m) {
+			return
+		}
+		nextOffset = m.Offset + 1
+
+		// The skipped records following the message are inserted right away, up to the next data record which is
+		// delivered by the underlying consumer. Otherwise the skipped records at the end of the consumed range would
+		// never be revealed, because no further message follows them.
+		if nextOffset <= c.endOffset {
+			if nextOffset, ok = c.sendSkipped(m, nextOffset, c.endOffset+1); !ok {
+				return
+			}
+		}
+	}
+}
+
+// sendSkipped sends the visible records which have been skipped in the range [from, until), next to the given
+// message of the underlying consumer. It stops at the first data record, as data records are delivered by the
+// underlying consumer. It returns the offset at which it has stopped and false if the consumer has been closed.
+func (c *recordVisibilityConsumer) sendSkipped(neighbour *sarama.ConsumerMessage, from int64, until int64) (int64, bool) {
+	for from < until && !c.isFailed {
+		record, err := c.recordAt(from)
+		if err != nil {
+			// Don't report the same failure for every following message
+			c.isFailed = true
+			c.onError(err)
+			return from, true
+		}
+		if record == nil || record.Offset >= until || record.Kind == RecordKindData {
+			return from, true
+		}
+		from = record.Offset + 1
+		if !isRecordKindVisible(c.visibility, record.Kind) {
+			continue
+		}
+
+		c.kindsMutex.Lock()
+		c.kinds[record.Offset] = record
+		c.kindsMutex.Unlock()
+		m := &sarama.ConsumerMessage{
+			Topic:     neighbour.Topic,
+			Partition: neighbour.Partition,
+			Offset:    record.Offset,
+			Timestamp: record.Timestamp,
+			Key:       record.Key,
+			Value:     record.Value,
+			Headers:   record.Headers,
+		}
+		if !c.send(m) {
+			return from, false
+		}
+	}
+	return from, true
+}
+
+// recordAt returns the first record at or after the given offset, nil if there is none
+func (c *recordVisibilityConsumer) recordAt(offset int64) (*TransactionalRecord, error) {
+	lookup := func() *TransactionalRecord {
+		for _, record := range c.fetched {
+			if record.Offset >= offset {
+				return record
+			}
+		}
+		return nil
+	}
+	if len(c.fetched) > 0 && offset >= c.fetched[0].Offset {
+		if record := lookup(); record != nil {
+			return record, nil
+		}
+	}
+
+	records, err := c.fetch(offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch skipped records: %w", err)
+	}
+	c.fetched = records
+	return lookup(), nil
+}
+
+func (c *recordVisibilityConsumer) send(m *sarama.ConsumerMessage) bool {
+	select {
+	case <-c.doneCh:
+		return false
+	case c.messages <- m:
+		return true
+	}
+}
+
+// reportSkippedRecordsError reports a failure to resolve the records skipped by the consumer. The messages of the
+// underlying consumer are still delivered in that case.
+func (p *PartitionConsumer) reportSkippedRecordsError(err error) {
+	p.Logger.Warn("failed to resolve records skipped by the consumer", zap.Error(err))
+	p.Progress.OnError(fmt.Sprintf("failed to resolve transactional records (partition: '%v'): %v", p.Req.PartitionID, err.Error()))
+}
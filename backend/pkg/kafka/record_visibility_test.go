@@ -0,0 +1,148 @@
+package kafka
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestPartitionConsumer_RecordVisibility(t *testing.T) {
+	broker := sarama.NewMockBroker(t, 1)
+	defer broker.Close()
+
+	// Producer 1 commits its transaction, producer 2 aborts its transaction, followed by a non transactional record
+	now := time.Now()
+	fetchResponse := &sarama.FetchResponse{Version: 10}
+	fetchResponse.AddRecordBatchWithTimestamp("orders", 0, nil, sarama.StringEncoder("committed"), 0, 1, true, now)
+	fetchResponse.AddControlRecordWithTimestamp("orders", 0, 1, 1, sarama.ControlRecordCommit, now)
+	fetchResponse.AddRecordBatchWithTimestamp("orders", 0, nil, sarama.StringEncoder("aborted-1"), 2, 2, true, now)
+	fetchResponse.AddRecordBatchWithTimestamp("orders", 0, nil, sarama.StringEncoder("aborted-2"), 3, 2, true, now)
+	fetchResponse.AddControlRecordWithTimestamp("orders", 0, 4, 2, sarama.ControlRecordAbort, now)
+	fetchResponse.AddRecordBatchWithTimestamp("orders", 0, nil, sarama.StringEncoder("plain"), 5, NoProducerID, false, now)
+	block := fetchResponse.GetBlock("orders", 0)
+	block.AbortedTransactions = []*sarama.AbortedTransaction{{ProducerID: 2, FirstOffset: 2}}
+	block.LastStableOffset = 6
+
+	broker.SetHandlerByMap(map[string]sarama.MockResponse{
+		"MetadataRequest": sarama.NewMockMetadataResponse(t).
+			SetBroker(broker.Addr(), broker.BrokerID()).
+			SetLeader("orders", 0, broker.BrokerID()),
+		"OffsetRequest": sarama.NewMockOffsetResponse(t).
+			SetVersion(1).
+			SetOffset("orders", 0, sarama.OffsetOldest, 0).
+			SetOffset("orders", 0, sarama.OffsetNewest, 6),
+		"FetchRequest": sarama.NewMockWrapper(fetchResponse),
+	})
+
+	cfg := sarama.NewConfig()
+	cfg.Version = sarama.V2_1_0_0
+	cfg.Consumer.IsolationLevel = sarama.ReadCommitted
+	client, err := sarama.NewClient([]string{broker.Addr()}, cfg)
+	require.NoError(t, err)
+	defer client.Close()
+
+	type presentedRecord struct {
+		Offset int64
+		Kind   string
+	}
+	consume := func(visibility string) []presentedRecord {
+		consumer, err := sarama.NewConsumerFromClient(client)
+		require.NoError(t, err)
+		defer consumer.Close()
+
+		doneCh := make(chan struct{}, 1)
+		messageCh := make(chan *TopicMessage, 10)
+		progress := &recordingProgress{}
+		p := &PartitionConsumer{
+			Logger:           zap.NewNop(),
+			DoneCh:           doneCh,
+			MessageCh:        messageCh,
+			Progress:         progress,
+			Consumer:         consumer,
+			TopicName:        "orders",
+			Req:              &PartitionConsumeRequest{PartitionID: 0, StartOffset: 0, EndOffset: 5, MaxMessageCount: 6},
+			RecordVisibility: visibility,
+			FetchSkippedRecords: func(offset int64) ([]*TransactionalRecord, error) {
+				return classifyTransactionalRecords(block.RecordsSet, block.AbortedTransactions), nil
+			},
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		p.Run(ctx)
+		<-doneCh
+		close(messageCh)
+		assert.Empty(t, progress.errors)
+
+		records := make([]presentedRecord, 0)
+		for msg := range messageCh {
+			records = append(records, presentedRecord{Offset: msg.Offset, Kind: msg.RecordKind})
+			if msg.RecordKind == RecordKindControl {
+				require.NotNil(t, msg.ControlRecord)
+			}
+		}
+		return records
+	}
+
+	assert.Equal(t, []presentedRecord{{0, ""}, {5, ""}}, consume(RecordVisibilityData))
+	assert.Equal(t, []presentedRecord{{0, RecordKindData}, {1, RecordKindControl}, {4, RecordKindControl}, {5, RecordKindData}},
+		consume(RecordVisibilityControl))
+	assert.Equal(t, []presentedRecord{{0, RecordKindData}, {2, RecordKindAborted}, {3, RecordKindAborted}, {5, RecordKindData}},
+		consume(RecordVisibilityAborted))
+	assert.Equal(t, []presentedRecord{{0, RecordKindData}, {1, RecordKindControl}, {2, RecordKindAborted},
+		{3, RecordKindAborted}, {4, RecordKindControl}, {5, RecordKindData}}, consume(RecordVisibilityAll))
+}
+
+func TestClassifyTransactionalRecords_ControlRecords(t *testing.T) {
+	fetchResponse := &sarama.FetchResponse{Version: 10}
+	fetchResponse.AddControlRecord("orders", 0, 7, 3, sarama.ControlRecordCommit)
+	fetchResponse.AddControlRecord("orders", 0, 8, 4, sarama.ControlRecordAbort)
+
+	records := classifyTransactionalRecords(fetchResponse.GetBlock("orders", 0).RecordsSet, nil)
+	require.Len(t, records, 2)
+	assert.Equal(t, &ControlRecordInfo{Type: "commit", ProducerID: 3}, records[0].Control)
+	assert.Equal(t, &ControlRecordInfo{Type: "abort", ProducerID: 4}, records[1].Control)
+}
+
+func TestLastStableOffset(t *testing.T) {
+	broker := sarama.NewMockBroker(t, 1)
+	defer broker.Close()
+
+	// A transaction starting at offset 4 is still open, the high water mark is 9
+	fetchResponse := &sarama.FetchResponse{Version: 4}
+	fetchResponse.SetLastStableOffset("orders", 0, 4)
+	broker.SetHandlerByMap(map[string]sarama.MockResponse{
+		"MetadataRequest": sarama.NewMockMetadataResponse(t).
+			SetBroker(broker.Addr(), broker.BrokerID()).
+			SetLeader("orders", 0, broker.BrokerID()),
+		"FetchRequest": sarama.NewMockWrapper(fetchResponse),
+	})
+
+	cfg := sarama.NewConfig()
+	cfg.Version = sarama.V2_1_0_0
+	client, err := sarama.NewClient([]string{broker.Addr()}, cfg)
+	require.NoError(t, err)
+	defer client.Close()
+
+	svc := &Service{Client: client, Logger: zap.NewNop()}
+	lastStableOffset, err := svc.LastStableOffset("orders", 0, 9)
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), lastStableOffset)
+
+	// The record batch lookups of other features don't use read_committed isolation
+	_, err = svc.fetchRecords("orders", 0, 0)
+	require.NoError(t, err)
+	requests := broker.History()
+	isolations := make([]sarama.IsolationLevel, 0)
+	for _, req := range requests {
+		if fetchReq, ok := req.Request.(*sarama.FetchRequest); ok {
+			isolations = append(isolations, fetchReq.Isolation)
+		}
+	}
+	assert.Equal(t, []sarama.IsolationLevel{sarama.ReadCommitted, sarama.ReadUncommitted}, isolations)
+}
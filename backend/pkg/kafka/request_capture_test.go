@@ -0,0 +1,118 @@
+package kafka
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// API keys of the captured requests
+const (
+	apiKeyProduce int16 = 0
+	apiKeyFetch   int16 = 1
+)
+
+// capturedRequest is a request which has been sent to the broker, the decoder is positioned at the request body
+type capturedRequest struct {
+	APIKey  int16
+	Version int16
+	Body    *rawDecoder
+}
+
+// requestCapture is a TCP proxy in front of a mock broker, which records all requests sent to the broker. Sarama
+// doesn't export the decoded fields of all requests, therefore the tests decode the request bodies themselves.
+type requestCapture struct {
+	net.Listener
+
+	mutex    sync.Mutex
+	requests [][]byte
+}
+
+func newRequestCapture(t *testing.T, brokerAddr string) *requestCapture {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	c := &requestCapture{Listener: listener}
+
+	go func() {
+		for {
+			clientConn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			brokerConn, err := net.Dial("tcp", brokerAddr)
+			if err != nil {
+				clientConn.Close()
+				continue
+			}
+			go func() {
+				_, _ = io.Copy(clientConn, brokerConn)
+				clientConn.Close()
+			}()
+			go func() {
+				defer brokerConn.Close()
+				sizeBuf := make([]byte, 4)
+				for {
+					if _, err := io.ReadFull(clientConn, sizeBuf); err != nil {
+						return
+					}
+					req := make([]byte, binary.BigEndian.Uint32(sizeBuf))
+					if _, err := io.ReadFull(clientConn, req); err != nil {
+						return
+					}
+					c.mutex.Lock()
+					c.requests = append(c.requests, req)
+					c.mutex.Unlock()
+					if _, err := brokerConn.Write(append(sizeBuf, req...)); err != nil {
+						return
+					}
+				}
+			}()
+		}
+	}()
+
+	return c
+}
+
+// Requests returns the captured requests with the given API key in the order they have been sent
+func (c *requestCapture) Requests(apiKey int16) []*capturedRequest {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	res := make([]*capturedRequest, 0)
+	for _, req := range c.requests {
+		d := &rawDecoder{buf: req}
+		captured := &capturedRequest{APIKey: d.getInt16(), Version: d.getInt16(), Body: d}
+		d.getInt32()          // Correlation ID
+		d.getNullableString() // Client ID
+		if d.err == nil && captured.APIKey == apiKey {
+			res = append(res, captured)
+		}
+	}
+	return res
+}
+
+// getInt8 and getVarint decode types of the request bodies which the raw protocol implementation doesn't use
+func getInt8(d *rawDecoder) int8 {
+	if b := d.read(1); b != nil {
+		return int8(b[0])
+	}
+	return 0
+}
+
+func getVarint(d *rawDecoder) int64 {
+	if d.err != nil {
+		return 0
+	}
+	v, n := binary.Varint(d.buf)
+	if n <= 0 {
+		d.err = fmt.Errorf("invalid varint")
+		return 0
+	}
+	d.buf = d.buf[n:]
+	return v
+}
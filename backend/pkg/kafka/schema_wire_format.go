@@ -0,0 +1,190 @@
+package kafka
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/Shopify/sarama"
+	"github.com/cloudhut/kowl/backend/pkg/schema"
+	"github.com/valyala/fastjson"
+	"go.uber.org/zap"
+)
+
+const confluentMagicByte byte = 0
+
+const (
+	// WireFormatAuto detects the wire format by the length of the schema ID following the magic byte. Apicurio's
+	// wire format is only detected if an Apicurio registry is configured, because Confluent registries can't
+	// resolve Apicurio's global IDs.
+	WireFormatAuto = "auto"
+	// WireFormatConfluent prefixes the payload with the magic byte and a 4 byte schema ID
+	WireFormatConfluent = "confluent"
+	// WireFormatApicurio prefixes the payload with the magic byte and an 8 byte global ID, which is the default of
+	// Apicurio's serializers. Apicurio's legacy 4 byte ID handler produces Confluent's wire format.
+	WireFormatApicurio = "apicurio"
+)
+
+// IsValidWireFormat returns true if the given wire format is supported. An empty wire format is detected as well.
+func IsValidWireFormat(wireFormat string) bool {
+	switch wireFormat {
+	case "", WireFormatAuto, WireFormatConfluent, WireFormatApicurio:
+		return true
+	default:
+		return false
+	}
+}
+
+// SchemaInfo describes the schema which has been used to serialize a key or value in Confluent's or Apicurio's
+// wire format
+type SchemaInfo struct {
+	ID uint32 `json:"id"`
+
+	// WireFormat is the format (confluent or apicurio) in which the schema ID has been embedded
+	WireFormat string `json:"wireFormat"`
+
+	// Type is the schema type (AVRO, JSON, PROTOBUF) as reported by the registry. If the schema could not be
+	// fetched, this is a guess based on the payload.
+	Type         string `json:"type"`
+	IsRegistered bool   `json:"isRegistered"`
+
+	// Subject and Version are only set if the schema versions are annotated. They are empty if the version
+	// couldn't be resolved unambiguously.
+	Subject string `json:"subject,omitempty"`
+	Version int    `json:"version,omitempty"`
+
+	// Error is set if the schema could not be resolved, e.g. "schema ID 5 not found in registry"
+	Error string `json:"error,omitempty"`
+}
+
+// parseConfluentWireFormat returns the schema ID along with the serialized payload, if the value is
+// prefixed with the magic byte and a 4 byte schema ID.
+func parseConfluentWireFormat(value []byte) (uint32, []byte, bool) {
+	if len(value) < 5 || value[0] != confluentMagicByte {
+		return 0, nil, false
+	}
+
+	return binary.BigEndian.Uint32(value[1:5]), value[5:], true
+}
+
+// parseApicurioWireFormat returns the global ID along with the serialized payload, if the value is prefixed with
+// the magic byte and an 8 byte global ID. Global IDs which don't fit into 4 bytes are not supported.
+func parseApicurioWireFormat(value []byte) (uint32, []byte, bool) {
+	if len(value) < 9 || value[0] != confluentMagicByte || binary.BigEndian.Uint32(value[1:5]) != 0 {
+		return 0, nil, false
+	}
+
+	return binary.BigEndian.Uint32(value[5:9]), value[9:], true
+}
+
+// parseWireFormat returns the schema ID, the serialized payload and the wire format of the value. Unless the wire
+// format is given, it's detected: the upper 4 bytes of Apicurio's 8 byte global IDs are zero, whereas Confluent's
+// schema IDs start at 1, so that the first 4 bytes after the magic byte tell both formats apart.
+func parseWireFormat(value []byte, wireFormat string) (uint32, []byte, string, bool) {
+	if wireFormat != WireFormatConfluent {
+		if schemaID, payload, ok := parseApicurioWireFormat(value); ok {
+			return schemaID, payload, WireFormatApicurio, true
+		}
+		if wireFormat == WireFormatApicurio {
+			return 0, nil, "", false
+		}
+	}
+
+	schemaID, payload, ok := parseConfluentWireFormat(value)
+	return schemaID, payload, WireFormatConfluent, ok
+}
+
+// Headers in which Apicurio's serializers pass the global ID if they are configured to use headers instead of
+// prefixing the payload
+const (
+	apicurioKeyGlobalIDHeader   = "apicurio.key.globalId"
+	apicurioValueGlobalIDHeader = "apicurio.value.globalId"
+)
+
+// parseApicurioGlobalIDHeader returns the global ID of the key or value, if it's passed as 8 byte header. Global IDs
+// which don't fit into 4 bytes are not supported.
+func parseApicurioGlobalIDHeader(headers []*sarama.RecordHeader, isKey bool) (uint32, bool) {
+	headerKey := apicurioValueGlobalIDHeader
+	if isKey {
+		headerKey = apicurioKeyGlobalIDHeader
+	}
+	for _, header := range headers {
+		if header == nil || string(header.Key) != headerKey {
+			continue
+		}
+		if len(header.Value) != 8 || binary.BigEndian.Uint32(header.Value[:4]) != 0 {
+			return 0, false
+		}
+		return binary.BigEndian.Uint32(header.Value[4:]), true
+	}
+	return 0, false
+}
+
+// resolveWireFormat returns the wire format of the topic, WireFormatAuto is only kept for Apicurio registries
+func (p *PartitionConsumer) resolveWireFormat() string {
+	if p.WireFormat != "" && p.WireFormat != WireFormatAuto {
+		return p.WireFormat
+	}
+	if p.SchemaService.IsApicurio() {
+		return WireFormatAuto
+	}
+	return WireFormatConfluent
+}
+
+// guessSerializationType is used to give users an idea of the serialization type when the schema is unknown.
+func guessSerializationType(payload []byte) string {
+	if fastjson.ValidateBytes(payload) == nil {
+		return "JSON"
+	}
+
+	// Most payloads in the wire format are Avro serialized, protobuf payloads can't be told apart reliably
+	return "AVRO"
+}
+
+// getSchemaInfo resolves the schema ID using the schema registry. Registry misses (e.g. schema has been deleted
+// or the wrong registry is configured) are not returned as error, but are described in the returned SchemaInfo.
+func (p *PartitionConsumer) getSchemaInfo(schemaID uint32, payload []byte) *SchemaInfo {
+	info := &SchemaInfo{ID: schemaID}
+
+	res, err := p.SchemaService.GetSchemaByID(schemaID)
+	if err != nil {
+		info.Type = guessSerializationType(payload)
+		if schema.IsSchemaNotFound(err) {
+			info.Error = fmt.Sprintf("schema ID %v not found in registry", schemaID)
+		} else {
+			info.Error = fmt.Sprintf("failed to fetch schema ID %v from registry: %v", schemaID, err)
+		}
+		return info
+	}
+
+	info.IsRegistered = true
+	info.Type = res.SchemaType
+	if info.Type == "" {
+		// The schema registry omits the schema type for Avro schemas
+		info.Type = "AVRO"
+	}
+
+	return info
+}
+
+// annotateSchemaVersion resolves the subject version of a registered schema. The subject is expected to follow the
+// TopicNameStrategy (<topic>-key or <topic>-value).
+func (p *PartitionConsumer) annotateSchemaVersion(info *SchemaInfo, isKey bool) {
+	if info == nil || !info.IsRegistered {
+		return
+	}
+
+	subject := p.TopicName + "-value"
+	if isKey {
+		subject = p.TopicName + "-key"
+	}
+	version, err := p.SchemaService.GetSchemaVersion(info.ID, subject)
+	if err != nil {
+		p.Logger.Debug("failed to resolve schema version", zap.Uint32("schema_id", info.ID), zap.Error(err))
+		return
+	}
+	if version == nil {
+		return
+	}
+	info.Subject = version.Subject
+	info.Version = version.Version
+}
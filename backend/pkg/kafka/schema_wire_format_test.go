@@ -0,0 +1,237 @@
+package kafka
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/Shopify/sarama"
+	"github.com/cloudhut/kowl/backend/pkg/schema"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestGetValue_UnregisteredSchemaID(t *testing.T) {
+	// Mock registry which doesn't know any schema
+	requestCount := 0
+	registry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestCount++
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error_code":40403,"message":"Schema not found"}`))
+	}))
+	defer registry.Close()
+
+	p := &PartitionConsumer{
+		SchemaService: schema.NewService(schema.Config{Enabled: true, URLs: []string{registry.URL}}, zap.NewNop()),
+	}
+
+	payload := []byte(`{"hello":"world"}`)
+	value := make([]byte, 5, 5+len(payload))
+	binary.BigEndian.PutUint32(value[1:5], 1234)
+	value = append(value, payload...)
+
+	vType, embedding, info := p.getValue(value, MessageFormatAuto)
+	assert.Equal(t, valueTypeBinary, vType)
+	assert.Equal(t, base64.StdEncoding.EncodeToString(value), string(embedding.Value))
+	assert.Equal(t, &SchemaInfo{ID: 1234, WireFormat: WireFormatConfluent, Type: "JSON", IsRegistered: false,
+		Error: "schema ID 1234 not found in registry"}, info)
+
+	// The miss is cached, so that further messages with the same schema ID don't query the registry again
+	_, _, info = p.getValue(value, MessageFormatAuto)
+	assert.Equal(t, "schema ID 1234 not found in registry", info.Error)
+	assert.Equal(t, 1, requestCount)
+
+	// Without a configured registry the magic byte must not be interpreted
+	p.SchemaService = nil
+	_, _, info = p.getValue(value, MessageFormatAuto)
+	assert.Nil(t, info)
+}
+
+func TestAnnotateSchemaVersion(t *testing.T) {
+	// Schema 7 is version 1 and schema 9 is version 2 of the orders-value subject. Schema 7 is also registered
+	// under another subject, which must not be used for the annotation.
+	versionLookups := 0
+	registry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/schemas/ids/7", "/schemas/ids/9":
+			w.Write([]byte(`{"schema":"{}"}`))
+		case "/schemas/ids/7/versions":
+			versionLookups++
+			w.Write([]byte(`[{"subject":"legacy-value","version":4},{"subject":"orders-value","version":1}]`))
+		case "/schemas/ids/9/versions":
+			versionLookups++
+			w.Write([]byte(`[{"subject":"orders-value","version":2}]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error_code":40403,"message":"Schema not found"}`))
+		}
+	}))
+	defer registry.Close()
+
+	p := &PartitionConsumer{
+		Logger:        zap.NewNop(),
+		TopicName:     "orders",
+		SchemaService: schema.NewService(schema.Config{Enabled: true, URLs: []string{registry.URL}}, zap.NewNop()),
+	}
+
+	encode := func(schemaID uint32) []byte {
+		value := make([]byte, 5, 7)
+		binary.BigEndian.PutUint32(value[1:5], schemaID)
+		return append(value, '{', '}')
+	}
+
+	// A window of messages with an evolution in progress
+	versions := make([]int, 0)
+	for _, schemaID := range []uint32{7, 7, 9, 7, 9, 9} {
+		_, _, info := p.getValue(encode(schemaID), MessageFormatAuto)
+		p.annotateSchemaVersion(info, false)
+		assert.Equal(t, "orders-value", info.Subject)
+		versions = append(versions, info.Version)
+	}
+	assert.Equal(t, []int{1, 1, 2, 1, 2, 2}, versions)
+	assert.Equal(t, 2, versionLookups)
+
+	// Unregistered schemas are not annotated
+	_, _, info := p.getValue(encode(1234), MessageFormatAuto)
+	p.annotateSchemaVersion(info, false)
+	assert.Equal(t, 0, info.Version)
+	assert.Empty(t, info.Subject)
+}
+
+func TestGetSchemaVersion_ConcurrentLookups(t *testing.T) {
+	// The versions lookup of schema 7 blocks until it's released, the lookup of schema 9 fails
+	var mutex sync.Mutex
+	lookups := make(map[string]int)
+	isRequested := make(chan struct{})
+	release := make(chan struct{})
+	registry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		lookups[r.URL.Path]++
+		mutex.Unlock()
+		switch r.URL.Path {
+		case "/schemas/ids/7/versions":
+			close(isRequested)
+			<-release
+			w.Write([]byte(`[{"subject":"orders-value","version":1}]`))
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"error_code":50001,"message":"Error in the backend data store"}`))
+		}
+	}))
+	defer registry.Close()
+	svc := schema.NewService(schema.Config{Enabled: true, URLs: []string{registry.URL}}, zap.NewNop())
+
+	var wg sync.WaitGroup
+	versions := make([]*schema.SubjectVersion, 5)
+	for i := range versions {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			version, err := svc.GetSchemaVersion(7, "orders-value")
+			assert.NoError(t, err)
+			versions[i] = version
+		}(i)
+	}
+	<-isRequested
+
+	// Lookups of other schema IDs are not blocked by the pending request, failures are cached
+	for i := 0; i < 3; i++ {
+		_, err := svc.GetSchemaVersion(9, "orders-value")
+		assert.Error(t, err)
+	}
+	close(release)
+	wg.Wait()
+
+	for _, version := range versions {
+		assert.Equal(t, &schema.SubjectVersion{Subject: "orders-value", Version: 1}, version)
+	}
+	assert.Equal(t, map[string]int{"/schemas/ids/7/versions": 1, "/schemas/ids/9/versions": 1}, lookups)
+}
+
+func TestGetValue_WireFormats(t *testing.T) {
+	// The Confluent registry knows schema ID 7, the Apicurio registry knows the global ID 42
+	confluentRegistry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/schemas/ids/7" {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error_code":40403,"message":"Schema not found"}`))
+			return
+		}
+		w.Write([]byte(`{"schema":"{}","schemaType":"JSON"}`))
+	}))
+	defer confluentRegistry.Close()
+	apicurioRegistry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/apis/registry/v2/ids/globalIds/42" {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error_code":404,"message":"No artifact with global ID found"}`))
+			return
+		}
+		w.Header().Set("X-Registry-ArtifactType", "JSON")
+		w.Write([]byte(`{"type":"object"}`))
+	}))
+	defer apicurioRegistry.Close()
+
+	payload := []byte(`{"hello":"world"}`)
+	confluentValue := make([]byte, 5, 5+len(payload))
+	binary.BigEndian.PutUint32(confluentValue[1:5], 7)
+	confluentValue = append(confluentValue, payload...)
+	apicurioValue := make([]byte, 9, 9+len(payload))
+	binary.BigEndian.PutUint64(apicurioValue[1:9], 42)
+	apicurioValue = append(apicurioValue, payload...)
+
+	p := &PartitionConsumer{
+		SchemaService: schema.NewService(schema.Config{Enabled: true, URLs: []string{confluentRegistry.URL}}, zap.NewNop()),
+	}
+	vType, embedding, info := p.getValue(confluentValue, MessageFormatAuto)
+	assert.Equal(t, valueTypeJSON, vType)
+	assert.Equal(t, string(payload), string(embedding.Value))
+	assert.Equal(t, &SchemaInfo{ID: 7, WireFormat: WireFormatConfluent, Type: "JSON", IsRegistered: true}, info)
+
+	p.SchemaService = schema.NewService(schema.Config{Enabled: true, URLs: []string{apicurioRegistry.URL},
+		Type: schema.RegistryTypeApicurio}, zap.NewNop())
+	vType, embedding, info = p.getValue(apicurioValue, MessageFormatAuto)
+	assert.Equal(t, valueTypeJSON, vType)
+	assert.Equal(t, string(payload), string(embedding.Value))
+	assert.Equal(t, &SchemaInfo{ID: 42, WireFormat: WireFormatApicurio, Type: "JSON", IsRegistered: true}, info)
+
+	// Apicurio registry misses are reported like Confluent registry misses
+	binary.BigEndian.PutUint64(apicurioValue[1:9], 43)
+	_, _, info = p.getValue(apicurioValue, MessageFormatAuto)
+	assert.False(t, info.IsRegistered)
+	assert.Equal(t, "schema ID 43 not found in registry", info.Error)
+
+	// Serializers which pass the global ID in the headers don't prefix the payload
+	headers := []*sarama.RecordHeader{
+		{Key: []byte("apicurio.key.globalId"), Value: []byte{0, 0, 0, 0, 0, 0, 0, 42}},
+		{Key: []byte("apicurio.value.globalId"), Value: []byte{0, 0, 0, 0, 0, 0, 0, 43}},
+	}
+	vType, embedding, info = p.getRecordValue(payload, MessageFormatAuto, headers, true)
+	assert.Equal(t, valueTypeJSON, vType)
+	assert.Equal(t, string(payload), string(embedding.Value))
+	assert.Equal(t, &SchemaInfo{ID: 42, WireFormat: WireFormatApicurio, Type: "JSON", IsRegistered: true}, info)
+	_, _, info = p.getRecordValue(payload, MessageFormatAuto, headers, false)
+	assert.Equal(t, uint32(43), info.ID)
+	assert.False(t, info.IsRegistered)
+
+	// Confluent registries can't resolve global IDs, hence neither the header nor the 8 byte global ID are used
+	p.SchemaService = schema.NewService(schema.Config{Enabled: true, URLs: []string{confluentRegistry.URL}}, zap.NewNop())
+	vType, _, info = p.getRecordValue(payload, MessageFormatAuto, headers, true)
+	assert.Nil(t, info)
+	assert.Equal(t, valueTypeJSON, vType)
+	binary.BigEndian.PutUint64(apicurioValue[1:9], 7)
+	_, _, info = p.getValue(apicurioValue, MessageFormatAuto)
+	assert.Equal(t, WireFormatConfluent, info.WireFormat)
+	assert.Equal(t, uint32(0), info.ID)
+	assert.False(t, info.IsRegistered)
+
+	// A value which is not in the wire format of the topic is decoded as if it has no schema
+	p.SchemaService = schema.NewService(schema.Config{Enabled: true, URLs: []string{apicurioRegistry.URL},
+		Type: schema.RegistryTypeApicurio}, zap.NewNop())
+	p.WireFormat = WireFormatApicurio
+	vType, embedding, info = p.getValue(confluentValue, MessageFormatAuto)
+	assert.Nil(t, info)
+	assert.Equal(t, valueTypeText, vType) // The schema ID bytes are valid UTF-8
+	assert.Equal(t, confluentValue, embedding.Value)
+}
@@ -1,9 +1,11 @@
 package kafka
 
 import (
+	"sync"
 	"time"
 
 	"github.com/Shopify/sarama"
+	"github.com/cloudhut/kowl/backend/pkg/schema"
 	"go.uber.org/zap"
 	"golang.org/x/time/rate"
 )
@@ -13,6 +15,13 @@ type Service struct {
 	MetricsNamespace string
 	Client           sarama.Client
 	Logger           *zap.Logger
+
+	// SchemaService is nil if no schema registry has been configured
+	SchemaService *schema.Service
+
+	// producer is created from the Client once the first record is produced
+	producerMutex sync.Mutex
+	producer      sarama.SyncProducer
 }
 
 // Start initializes the Kafka Service and takes care of stuff like KeepAlive
@@ -0,0 +1,85 @@
+package kafka
+
+import (
+	"context"
+	"time"
+
+	"github.com/Shopify/sarama"
+)
+
+// Possible values of the topic config message.timestamp.type
+const (
+	// TimestampTypeCreateTime means timestamps are set by the producers. They are not necessarily monotonic.
+	TimestampTypeCreateTime = "CreateTime"
+	// TimestampTypeLogAppendTime means timestamps are assigned by the broker, therefore they are monotonic.
+	TimestampTypeLogAppendTime = "LogAppendTime"
+)
+
+// OffsetsForTimestamp returns a map of: partitionID -> offset of the first message whose timestamp is greater than
+// or equal to the given timestamp. The offset is -1 if there is no such message in the partition.
+func (s *Service) OffsetsForTimestamp(topic string, partitionIDs []int32, timestamp time.Time) (map[int32]int64, error) {
+	timestampMs := timestamp.UnixNano() / int64(time.Millisecond)
+
+	offsets := make(map[int32]int64, len(partitionIDs))
+	for _, partitionID := range partitionIDs {
+		offset, err := s.Client.GetOffset(topic, partitionID, timestampMs)
+		if err != nil {
+			return nil, err
+		}
+		offsets[partitionID] = offset
+	}
+
+	return offsets, nil
+}
+
+// FetchRecordTimestamp returns the timestamp of the first record at or after the given offset. The second return
+// value is false if there is no record at or after that offset. The fetch is not sent if the context is done already,
+// a fetch which has been sent can't be cancelled.
+func (s *Service) FetchRecordTimestamp(ctx context.Context, topic string, partitionID int32, offset int64) (time.Time, bool, error) {
+	if err := ctx.Err(); err != nil {
+		return time.Time{}, false, err
+	}
+
+	recordsSet, err := s.fetchRecords(topic, partitionID, offset)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+
+	for _, records := range recordsSet {
+		if ts, ok := firstRecordTimestamp(records, offset); ok {
+			return ts, true, nil
+		}
+	}
+
+	return time.Time{}, false, nil
+}
+
+// firstRecordTimestamp returns the timestamp of the first record at or after the given offset
+func firstRecordTimestamp(records *sarama.Records, offset int64) (time.Time, bool) {
+	if batch := records.RecordBatch; batch != nil {
+		if batch.Control {
+			return time.Time{}, false
+		}
+		for _, record := range batch.Records {
+			if batch.FirstOffset+record.OffsetDelta < offset {
+				continue
+			}
+			if batch.LogAppendTime {
+				return batch.MaxTimestamp, true
+			}
+			return batch.FirstTimestamp.Add(record.TimestampDelta), true
+		}
+	}
+
+	if msgSet := records.MsgSet; msgSet != nil {
+		for _, msgBlock := range msgSet.Messages {
+			// Compressed legacy message sets are only inspected on the wrapper message, whose offset and timestamp
+			// are those of the last inner message.
+			if msgBlock.Offset >= offset && msgBlock.Msg != nil {
+				return msgBlock.Msg.Timestamp, true
+			}
+		}
+	}
+
+	return time.Time{}, false
+}
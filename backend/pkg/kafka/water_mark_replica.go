@@ -0,0 +1,108 @@
+package kafka
+
+import (
+	"github.com/Shopify/sarama"
+	"go.uber.org/zap"
+)
+
+// debuggingReplicaID is the replica ID which allows ListOffsets requests to be answered by follower replicas
+// instead of leaders only.
+const debuggingReplicaID int32 = -2
+
+// WaterMarksFromReplica returns a map of: partitionID -> *waterMark, where the watermarks are fetched from the
+// given broker for all partitions it hosts a replica of. This reflects what a consumer which fetches from that
+// (e.g. rack local) replica sees. Partitions which are not hosted by the broker, or for which the broker refuses
+// to answer, are fetched from the partition leader as usual.
+func (s *Service) WaterMarksFromReplica(topic string, partitionIDs []int32, brokerID int32) (map[int32]*WaterMark, error) {
+	var broker *sarama.Broker
+	for _, b := range s.Client.Brokers() {
+		if b.ID() == brokerID {
+			broker = b
+			break
+		}
+	}
+	if broker == nil {
+		s.Logger.Debug("requested replica broker is not available, falling back to partition leaders",
+			zap.Int32("broker_id", brokerID))
+		return s.WaterMarks(topic, partitionIDs)
+	}
+	err := broker.Open(s.Client.Config())
+	if err != nil && err != sarama.ErrAlreadyConnected {
+		s.Logger.Warn("opening the broker connection failed", zap.Error(err))
+	}
+
+	replicaPartitions := make([]int32, 0, len(partitionIDs))
+	leaderPartitions := make([]int32, 0)
+	for _, partitionID := range partitionIDs {
+		replicas, err := s.Client.Replicas(topic, partitionID)
+		if err != nil {
+			return nil, err
+		}
+		if containsBrokerID(replicas, brokerID) {
+			replicaPartitions = append(replicaPartitions, partitionID)
+		} else {
+			leaderPartitions = append(leaderPartitions, partitionID)
+		}
+	}
+
+	waterMarks := make(map[int32]*WaterMark, len(partitionIDs))
+	if len(replicaPartitions) > 0 {
+		oldestReq := &sarama.OffsetRequest{}
+		oldestReq.SetReplicaID(debuggingReplicaID)
+		newestReq := &sarama.OffsetRequest{}
+		newestReq.SetReplicaID(debuggingReplicaID)
+		for _, partitionID := range replicaPartitions {
+			oldestReq.AddBlock(topic, partitionID, sarama.OffsetOldest, 1)
+			newestReq.AddBlock(topic, partitionID, sarama.OffsetNewest, 1)
+		}
+
+		oldest, err := broker.GetAvailableOffsets(oldestReq)
+		if err != nil {
+			return nil, err
+		}
+		newest, err := broker.GetAvailableOffsets(newestReq)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, partitionID := range replicaPartitions {
+			oldestBlock := oldest.GetBlock(topic, partitionID)
+			newestBlock := newest.GetBlock(topic, partitionID)
+			if !isValidOffsetBlock(oldestBlock) || !isValidOffsetBlock(newestBlock) {
+				// Brokers which do not allow follower reads answer with NotLeaderForPartition
+				leaderPartitions = append(leaderPartitions, partitionID)
+				continue
+			}
+			waterMarks[partitionID] = &WaterMark{
+				PartitionID: partitionID,
+				Low:         oldestBlock.Offsets[0],
+				High:        newestBlock.Offsets[0],
+			}
+		}
+	}
+
+	if len(leaderPartitions) > 0 {
+		leaderWaterMarks, err := s.WaterMarks(topic, leaderPartitions)
+		if err != nil {
+			return nil, err
+		}
+		for partitionID, waterMark := range leaderWaterMarks {
+			waterMarks[partitionID] = waterMark
+		}
+	}
+
+	return waterMarks, nil
+}
+
+func isValidOffsetBlock(block *sarama.OffsetResponseBlock) bool {
+	return block != nil && block.Err == sarama.ErrNoError && len(block.Offsets) > 0
+}
+
+func containsBrokerID(brokerIDs []int32, brokerID int32) bool {
+	for _, id := range brokerIDs {
+		if id == brokerID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,52 @@
+package kafka
+
+import (
+	"testing"
+
+	"github.com/Shopify/sarama"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestWaterMarksFromReplica_TargetsFollower(t *testing.T) {
+	leader := sarama.NewMockBroker(t, 1)
+	defer leader.Close()
+	follower := sarama.NewMockBroker(t, 2)
+	defer follower.Close()
+
+	metadata := sarama.NewMockMetadataResponse(t).
+		SetBroker(leader.Addr(), leader.BrokerID()).
+		SetBroker(follower.Addr(), follower.BrokerID()).
+		SetLeader("orders", 0, leader.BrokerID())
+	offsets := sarama.NewMockOffsetResponse(t).
+		SetOffset("orders", 0, sarama.OffsetOldest, 10).
+		SetOffset("orders", 0, sarama.OffsetNewest, 90)
+	for _, b := range []*sarama.MockBroker{leader, follower} {
+		b.SetHandlerByMap(map[string]sarama.MockResponse{
+			"MetadataRequest": metadata,
+			"OffsetRequest":   offsets,
+		})
+	}
+
+	client, err := sarama.NewClient([]string{leader.Addr()}, sarama.NewConfig())
+	require.NoError(t, err)
+	defer client.Close()
+
+	svc := &Service{Client: client, Logger: zap.NewNop()}
+	waterMarks, err := svc.WaterMarksFromReplica("orders", []int32{0}, follower.BrokerID())
+	require.NoError(t, err)
+	assert.Equal(t, &WaterMark{PartitionID: 0, Low: 10, High: 90}, waterMarks[0])
+
+	countOffsetRequests := func(b *sarama.MockBroker) int {
+		count := 0
+		for _, req := range b.History() {
+			if _, ok := req.Request.(*sarama.OffsetRequest); ok {
+				count++
+			}
+		}
+		return count
+	}
+	assert.Equal(t, 2, countOffsetRequests(follower))
+	assert.Equal(t, 0, countOffsetRequests(leader))
+}
@@ -0,0 +1,130 @@
+package owl
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/Shopify/sarama"
+)
+
+// wildcardPrincipal is the ACL principal which matches all users
+const wildcardPrincipal = "User:*"
+
+var aclResourceTypeNames = map[sarama.AclResourceType]string{
+	sarama.AclResourceTopic:           "Topic",
+	sarama.AclResourceGroup:           "Group",
+	sarama.AclResourceCluster:         "Cluster",
+	sarama.AclResourceTransactionalID: "TransactionalID",
+}
+
+var aclOperationNames = map[sarama.AclOperation]string{
+	sarama.AclOperationAll:             "All",
+	sarama.AclOperationRead:            "Read",
+	sarama.AclOperationWrite:           "Write",
+	sarama.AclOperationCreate:          "Create",
+	sarama.AclOperationDelete:          "Delete",
+	sarama.AclOperationAlter:           "Alter",
+	sarama.AclOperationDescribe:        "Describe",
+	sarama.AclOperationClusterAction:   "ClusterAction",
+	sarama.AclOperationDescribeConfigs: "DescribeConfigs",
+	sarama.AclOperationAlterConfigs:    "AlterConfigs",
+	sarama.AclOperationIdempotentWrite: "IdempotentWrite",
+}
+
+// ACLPrincipal is a distinct principal which appears in at least one ACL, along with the operations it has been
+// granted or denied per resource type
+type ACLPrincipal struct {
+	Principal string `json:"principal"`
+	// IsWildcard is true for User:*, whose grants apply to every principal of the cluster
+	IsWildcard bool `json:"isWildcard"`
+	ACLCount   int  `json:"aclCount"`
+
+	// ResourceType -> Operations (sorted)
+	AllowedOperations map[string][]string `json:"allowedOperations"`
+	DeniedOperations  map[string][]string `json:"deniedOperations"`
+}
+
+// ListACLPrincipals returns all distinct principals of the cluster's ACLs. The wildcard principal is listed first,
+// so that blanket grants to all users can be spotted immediately.
+func (s *Service) ListACLPrincipals(_ context.Context) ([]*ACLPrincipal, error) {
+	acls, err := s.kafkaSvc.DescribeAllACLs()
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe acls: %w", err)
+	}
+
+	return aggregateACLPrincipals(acls), nil
+}
+
+func aggregateACLPrincipals(resourceACLs []*sarama.ResourceAcls) []*ACLPrincipal {
+	// Principal -> ResourceType -> Operations, tracked separately for ALLOW and DENY
+	type operationSet map[string]map[string]struct{}
+	principals := make(map[string]*ACLPrincipal)
+	allowed := make(map[string]operationSet)
+	denied := make(map[string]operationSet)
+
+	for _, resourceACL := range resourceACLs {
+		resourceType := aclResourceTypeName(resourceACL.Resource.ResourceType)
+		for _, acl := range resourceACL.Acls {
+			p, exists := principals[acl.Principal]
+			if !exists {
+				p = &ACLPrincipal{Principal: acl.Principal, IsWildcard: acl.Principal == wildcardPrincipal}
+				principals[acl.Principal] = p
+				allowed[acl.Principal] = make(operationSet)
+				denied[acl.Principal] = make(operationSet)
+			}
+			p.ACLCount++
+
+			var operations operationSet
+			switch acl.PermissionType {
+			case sarama.AclPermissionAllow:
+				operations = allowed[acl.Principal]
+			case sarama.AclPermissionDeny:
+				operations = denied[acl.Principal]
+			default:
+				continue
+			}
+			if _, exists := operations[resourceType]; !exists {
+				operations[resourceType] = make(map[string]struct{})
+			}
+			operations[resourceType][aclOperationName(acl.Operation)] = struct{}{}
+		}
+	}
+
+	toSortedLists := func(operations operationSet) map[string][]string {
+		res := make(map[string][]string, len(operations))
+		for resourceType, ops := range operations {
+			res[resourceType] = sortedKeys(ops)
+		}
+		return res
+	}
+
+	res := make([]*ACLPrincipal, 0, len(principals))
+	for name, p := range principals {
+		p.AllowedOperations = toSortedLists(allowed[name])
+		p.DeniedOperations = toSortedLists(denied[name])
+		res = append(res, p)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].IsWildcard != res[j].IsWildcard {
+			return res[i].IsWildcard
+		}
+		return res[i].Principal < res[j].Principal
+	})
+
+	return res
+}
+
+func aclResourceTypeName(resourceType sarama.AclResourceType) string {
+	if name, exists := aclResourceTypeNames[resourceType]; exists {
+		return name
+	}
+	return "Unknown"
+}
+
+func aclOperationName(operation sarama.AclOperation) string {
+	if name, exists := aclOperationNames[operation]; exists {
+		return name
+	}
+	return "Unknown"
+}
@@ -0,0 +1,56 @@
+package owl
+
+import (
+	"testing"
+
+	"github.com/Shopify/sarama"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAggregateACLPrincipals(t *testing.T) {
+	topicAll := sarama.Resource{ResourceType: sarama.AclResourceTopic, ResourceName: "*", ResourcePatternType: sarama.AclPatternLiteral}
+	group := sarama.Resource{ResourceType: sarama.AclResourceGroup, ResourceName: "billing-", ResourcePatternType: sarama.AclPatternPrefixed}
+	acls := []*sarama.ResourceAcls{
+		{
+			Resource: topicAll,
+			Acls: []*sarama.Acl{
+				{Principal: "User:billing", Host: "*", Operation: sarama.AclOperationWrite, PermissionType: sarama.AclPermissionAllow},
+				{Principal: "User:billing", Host: "*", Operation: sarama.AclOperationRead, PermissionType: sarama.AclPermissionAllow},
+				{Principal: "User:*", Host: "*", Operation: sarama.AclOperationRead, PermissionType: sarama.AclPermissionAllow},
+				{Principal: "User:analytics", Host: "*", Operation: sarama.AclOperationWrite, PermissionType: sarama.AclPermissionDeny},
+			},
+		},
+		{
+			Resource: group,
+			Acls: []*sarama.Acl{
+				{Principal: "User:billing", Host: "*", Operation: sarama.AclOperationRead, PermissionType: sarama.AclPermissionAllow},
+				{Principal: "User:billing", Host: "10.0.0.1", Operation: sarama.AclOperationRead, PermissionType: sarama.AclPermissionAllow},
+			},
+		},
+	}
+
+	principals := aggregateACLPrincipals(acls)
+	require.Len(t, principals, 3)
+
+	// The wildcard grant is listed first and flagged
+	assert.Equal(t, &ACLPrincipal{
+		Principal:         "User:*",
+		IsWildcard:        true,
+		ACLCount:          1,
+		AllowedOperations: map[string][]string{"Topic": {"Read"}},
+		DeniedOperations:  map[string][]string{},
+	}, principals[0])
+
+	assert.Equal(t, "User:analytics", principals[1].Principal)
+	assert.False(t, principals[1].IsWildcard)
+	assert.Empty(t, principals[1].AllowedOperations)
+	assert.Equal(t, map[string][]string{"Topic": {"Write"}}, principals[1].DeniedOperations)
+
+	assert.Equal(t, &ACLPrincipal{
+		Principal:         "User:billing",
+		ACLCount:          4,
+		AllowedOperations: map[string][]string{"Topic": {"Read", "Write"}, "Group": {"Read"}},
+		DeniedOperations:  map[string][]string{},
+	}, principals[2])
+}
@@ -0,0 +1,148 @@
+package owl
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/Shopify/sarama"
+	"golang.org/x/sync/errgroup"
+)
+
+// Reasons why a partition would be orphaned by decommissioning its leader
+const (
+	OrphanReasonSingleReplica    = "singleReplica"    // The broker hosts the partition's only replica
+	OrphanReasonNoInSyncFollower = "noInSyncFollower" // None of the followers is in sync and could take over
+)
+
+// BrokerDecommissionImpact describes what would be affected if the given broker was removed from the cluster
+type BrokerDecommissionImpact struct {
+	BrokerID int32 `json:"brokerId"`
+
+	// CoordinatedGroups would have to find a new coordinator, which pauses their consumption until they have rejoined
+	CoordinatedGroups []string `json:"coordinatedGroups"`
+
+	// OrphanedPartitions are led by the broker without any healthy replica on another broker, they would go offline
+	OrphanedPartitions []*OrphanedPartition `json:"orphanedPartitions"`
+}
+
+// OrphanedPartition is a partition which would go offline if its leader was decommissioned
+type OrphanedPartition struct {
+	Topic          string  `json:"topic"`
+	PartitionID    int32   `json:"partitionId"`
+	Replicas       []int32 `json:"replicas"`
+	InSyncReplicas []int32 `json:"inSyncReplicas"`
+	Reason         string  `json:"reason"`
+}
+
+// GetBrokerDecommissionImpact returns the consumer groups the broker coordinates and the partitions it solely leads
+// without an in sync replica on another broker, so that a broker decommissioning can be planned safely.
+func (s *Service) GetBrokerDecommissionImpact(ctx context.Context, brokerID int32) (*BrokerDecommissionImpact, error) {
+	cluster, err := s.kafkaSvc.DescribeCluster()
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe cluster: %w", err)
+	}
+	isKnownBroker := false
+	for _, b := range cluster.Brokers {
+		if b.ID() == brokerID {
+			isKnownBroker = true
+			break
+		}
+	}
+	if !isKnownBroker {
+		return nil, fmt.Errorf("broker '%v' is not part of the cluster", brokerID)
+	}
+
+	eg, ctx := errgroup.WithContext(ctx)
+
+	groupsByCoordinator := make(map[int32][]string)
+	var topics []*sarama.TopicMetadata
+
+	eg.Go(func() error {
+		groups, err := s.kafkaSvc.ListConsumerGroups(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to list consumer groups: %w", err)
+		}
+		describedGroups, err := s.kafkaSvc.DescribeConsumerGroups(ctx, groups)
+		if err != nil {
+			return fmt.Errorf("failed to describe consumer groups: %w", err)
+		}
+		for coordinatorID, res := range describedGroups {
+			for _, group := range res.Groups {
+				groupsByCoordinator[coordinatorID] = append(groupsByCoordinator[coordinatorID], group.GroupId)
+			}
+		}
+		return nil
+	})
+
+	eg.Go(func() error {
+		metadata, err := s.kafkaSvc.DescribeTopics(nil)
+		if err != nil {
+			return fmt.Errorf("failed to describe topics: %w", err)
+		}
+		topics = metadata
+		return nil
+	})
+
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+
+	return newBrokerDecommissionImpact(brokerID, groupsByCoordinator, topics), nil
+}
+
+func newBrokerDecommissionImpact(brokerID int32, groupsByCoordinator map[int32][]string, topics []*sarama.TopicMetadata) *BrokerDecommissionImpact {
+	impact := &BrokerDecommissionImpact{
+		BrokerID:           brokerID,
+		CoordinatedGroups:  make([]string, 0, len(groupsByCoordinator[brokerID])),
+		OrphanedPartitions: make([]*OrphanedPartition, 0),
+	}
+	impact.CoordinatedGroups = append(impact.CoordinatedGroups, groupsByCoordinator[brokerID]...)
+	sort.Strings(impact.CoordinatedGroups)
+
+	for _, topic := range topics {
+		if topic.Err != sarama.ErrNoError {
+			continue
+		}
+		for _, partition := range topic.Partitions {
+			if partition.Leader != brokerID {
+				continue
+			}
+			reason := ""
+			switch {
+			case len(partition.Replicas) <= 1:
+				reason = OrphanReasonSingleReplica
+			case !hasInSyncFollower(partition, brokerID):
+				reason = OrphanReasonNoInSyncFollower
+			default:
+				continue
+			}
+			impact.OrphanedPartitions = append(impact.OrphanedPartitions, &OrphanedPartition{
+				Topic:          topic.Name,
+				PartitionID:    partition.ID,
+				Replicas:       partition.Replicas,
+				InSyncReplicas: partition.Isr,
+				Reason:         reason,
+			})
+		}
+	}
+	sort.Slice(impact.OrphanedPartitions, func(i, j int) bool {
+		a, b := impact.OrphanedPartitions[i], impact.OrphanedPartitions[j]
+		if a.Topic != b.Topic {
+			return a.Topic < b.Topic
+		}
+		return a.PartitionID < b.PartitionID
+	})
+
+	return impact
+}
+
+// hasInSyncFollower returns true if a replica on another broker than the leader is in sync
+func hasInSyncFollower(partition *sarama.PartitionMetadata, leaderID int32) bool {
+	for _, replicaID := range partition.Isr {
+		if replicaID != leaderID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,38 @@
+package owl
+
+import (
+	"testing"
+
+	"github.com/Shopify/sarama"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewBrokerDecommissionImpact(t *testing.T) {
+	groupsByCoordinator := map[int32][]string{
+		1: {"shipping", "billing"},
+		2: {"search"},
+	}
+	topics := []*sarama.TopicMetadata{
+		{Name: "orders", Partitions: []*sarama.PartitionMetadata{
+			{ID: 0, Leader: 1, Replicas: []int32{1}, Isr: []int32{1}},
+			{ID: 1, Leader: 1, Replicas: []int32{1, 2}, Isr: []int32{1, 2}}, // Broker 2 can take over
+			{ID: 2, Leader: 2, Replicas: []int32{2}, Isr: []int32{2}},
+		}},
+		{Name: "audit", Partitions: []*sarama.PartitionMetadata{
+			{ID: 0, Leader: 1, Replicas: []int32{1, 2, 3}, Isr: []int32{1}},
+		}},
+		{Name: "deleted", Err: sarama.ErrUnknownTopicOrPartition},
+	}
+
+	impact := newBrokerDecommissionImpact(1, groupsByCoordinator, topics)
+	assert.Equal(t, int32(1), impact.BrokerID)
+	assert.Equal(t, []string{"billing", "shipping"}, impact.CoordinatedGroups)
+	assert.Equal(t, []*OrphanedPartition{
+		{Topic: "audit", PartitionID: 0, Replicas: []int32{1, 2, 3}, InSyncReplicas: []int32{1}, Reason: OrphanReasonNoInSyncFollower},
+		{Topic: "orders", PartitionID: 0, Replicas: []int32{1}, InSyncReplicas: []int32{1}, Reason: OrphanReasonSingleReplica},
+	}, impact.OrphanedPartitions)
+
+	impact = newBrokerDecommissionImpact(3, groupsByCoordinator, topics)
+	assert.Empty(t, impact.CoordinatedGroups)
+	assert.Empty(t, impact.OrphanedPartitions)
+}
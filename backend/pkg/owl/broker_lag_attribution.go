@@ -0,0 +1,103 @@
+package owl
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/Shopify/sarama"
+)
+
+// unknownLeaderID is used for partitions whose leader is currently unknown (e.g. offline partitions)
+const unknownLeaderID int32 = -1
+
+// BrokerLagAttribution is the summed lag of all partitions a broker is leading, across all consumer groups
+type BrokerLagAttribution struct {
+	BrokerID       int32    `json:"brokerId"` // -1 for partitions without a known leader
+	SummedLag      int64    `json:"summedLag"`
+	PartitionCount int      `json:"partitionCount"` // Number of (group, partition) pairs attributed to the broker
+	GroupIDs       []string `json:"groupIds"`       // Groups lagging on partitions led by this broker
+}
+
+// GetLagByLeaderBroker attributes the lag of every group's partition to the partition's leader broker. A broker
+// which carries a disproportionate share of the lag across many groups points to a broker side bottleneck.
+// Brokers are sorted by their summed lag.
+func (s *Service) GetLagByLeaderBroker(ctx context.Context) ([]*BrokerLagAttribution, error) {
+	groups, err := s.kafkaSvc.ListConsumerGroups(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list consumer groups: %w", err)
+	}
+	lags, err := s.getConsumerGroupLags(ctx, groups)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get consumer group lags: %w", err)
+	}
+
+	topics := make(map[string]struct{})
+	for _, lag := range lags {
+		for _, topicLag := range lag.TopicLags {
+			topics[topicLag.Topic] = struct{}{}
+		}
+	}
+	if len(topics) == 0 {
+		return make([]*BrokerLagAttribution, 0), nil
+	}
+
+	metadata, err := s.kafkaSvc.DescribeTopics(sortedKeys(topics))
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe topics: %w", err)
+	}
+	leaders := make(map[string]map[int32]int32, len(metadata))
+	for _, topic := range metadata {
+		if topic.Err != sarama.ErrNoError {
+			continue
+		}
+		leaders[topic.Name] = make(map[int32]int32, len(topic.Partitions))
+		for _, partition := range topic.Partitions {
+			leaders[topic.Name][partition.ID] = partition.Leader
+		}
+	}
+
+	return attributeLagToLeaders(lags, leaders), nil
+}
+
+func attributeLagToLeaders(lags map[string]*ConsumerGroupLag, leaders map[string]map[int32]int32) []*BrokerLagAttribution {
+	byBroker := make(map[int32]*BrokerLagAttribution)
+	groupsByBroker := make(map[int32]map[string]struct{})
+
+	for groupID, lag := range lags {
+		for _, topicLag := range lag.TopicLags {
+			for _, partitionLag := range topicLag.PartitionLags {
+				leaderID, exists := leaders[topicLag.Topic][partitionLag.PartitionID]
+				if !exists || leaderID < 0 {
+					leaderID = unknownLeaderID
+				}
+
+				attribution, exists := byBroker[leaderID]
+				if !exists {
+					attribution = &BrokerLagAttribution{BrokerID: leaderID}
+					byBroker[leaderID] = attribution
+					groupsByBroker[leaderID] = make(map[string]struct{})
+				}
+				attribution.SummedLag += partitionLag.Lag
+				attribution.PartitionCount++
+				if partitionLag.Lag > 0 {
+					groupsByBroker[leaderID][groupID] = struct{}{}
+				}
+			}
+		}
+	}
+
+	res := make([]*BrokerLagAttribution, 0, len(byBroker))
+	for brokerID, attribution := range byBroker {
+		attribution.GroupIDs = sortedKeys(groupsByBroker[brokerID])
+		res = append(res, attribution)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].SummedLag == res[j].SummedLag {
+			return res[i].BrokerID < res[j].BrokerID
+		}
+		return res[i].SummedLag > res[j].SummedLag
+	})
+
+	return res
+}
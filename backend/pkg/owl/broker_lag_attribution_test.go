@@ -0,0 +1,27 @@
+package owl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAttributeLagToLeaders(t *testing.T) {
+	lags := map[string]*ConsumerGroupLag{
+		"billing": {GroupID: "billing", TopicLags: []*TopicLag{{
+			Topic:         "orders",
+			PartitionLags: []PartitionLag{{PartitionID: 0, Lag: 10}, {PartitionID: 1, Lag: 500}, {PartitionID: 2, Lag: 0}},
+		}}},
+		"audit": {GroupID: "audit", TopicLags: []*TopicLag{{
+			Topic:         "orders",
+			PartitionLags: []PartitionLag{{PartitionID: 1, Lag: 300}, {PartitionID: 2, Lag: 5}},
+		}}},
+	}
+	leaders := map[string]map[int32]int32{"orders": {0: 1, 1: 2, 2: 3}}
+
+	assert.Equal(t, []*BrokerLagAttribution{
+		{BrokerID: 2, SummedLag: 800, PartitionCount: 2, GroupIDs: []string{"audit", "billing"}},
+		{BrokerID: 1, SummedLag: 10, PartitionCount: 1, GroupIDs: []string{"billing"}},
+		{BrokerID: 3, SummedLag: 5, PartitionCount: 2, GroupIDs: []string{"audit"}},
+	}, attributeLagToLeaders(lags, leaders))
+}
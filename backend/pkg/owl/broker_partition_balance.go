@@ -0,0 +1,124 @@
+package owl
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/Shopify/sarama"
+)
+
+// brokerHotspotFactor is the factor by which a broker's replica count must exceed the cluster average to be
+// considered a hotspot
+const brokerHotspotFactor = 1.5
+
+// BrokerPartitionBalance summarizes how the partition replicas are spread across all brokers of the cluster
+type BrokerPartitionBalance struct {
+	Brokers          []*BrokerPartitionCount `json:"brokers"` // Sorted by broker id
+	TotalReplicas    int                     `json:"totalReplicas"`
+	AverageReplicas  float64                 `json:"averageReplicas"`
+	MinReplicas      int                     `json:"minReplicas"`
+	MaxReplicas      int                     `json:"maxReplicas"`
+	ImbalanceRatio   float64                 `json:"imbalanceRatio"`   // MaxReplicas / AverageReplicas, 1 is perfectly balanced
+	HotspotBrokerIDs []int32                 `json:"hotspotBrokerIds"` // Brokers hosting more than 1.5x the average
+}
+
+// BrokerPartitionCount is the number of partitions a broker hosts as leader and as follower across all topics
+type BrokerPartitionCount struct {
+	BrokerID      int32 `json:"brokerId"`
+	LeaderCount   int   `json:"leaderCount"`
+	FollowerCount int   `json:"followerCount"`
+	TotalCount    int   `json:"totalCount"`
+	IsHotspot     bool  `json:"isHotspot"`
+}
+
+// GetBrokerPartitionBalance returns per broker the number of partitions it leads and follows, so that brokers which
+// carry far more partitions than the others can be spotted.
+func (s *Service) GetBrokerPartitionBalance(_ context.Context) (*BrokerPartitionBalance, error) {
+	cluster, err := s.kafkaSvc.DescribeCluster()
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe cluster: %w", err)
+	}
+
+	topics, err := s.kafkaSvc.ListTopics()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list topics: %w", err)
+	}
+
+	brokerIDs := make([]int32, len(cluster.Brokers))
+	for i, broker := range cluster.Brokers {
+		brokerIDs[i] = broker.ID()
+	}
+
+	return newBrokerPartitionBalance(brokerIDs, topics), nil
+}
+
+func newBrokerPartitionBalance(brokerIDs []int32, topics []*sarama.TopicMetadata) *BrokerPartitionBalance {
+	// Brokers without any partitions must be part of the balance as well
+	countByBroker := make(map[int32]*BrokerPartitionCount, len(brokerIDs))
+	getCount := func(brokerID int32) *BrokerPartitionCount {
+		c, exists := countByBroker[brokerID]
+		if !exists {
+			c = &BrokerPartitionCount{BrokerID: brokerID}
+			countByBroker[brokerID] = c
+		}
+		return c
+	}
+	for _, brokerID := range brokerIDs {
+		getCount(brokerID)
+	}
+
+	for _, topic := range topics {
+		if topic.Err != sarama.ErrNoError {
+			continue
+		}
+		for _, partition := range topic.Partitions {
+			for _, replicaID := range partition.Replicas {
+				c := getCount(replicaID)
+				if replicaID == partition.Leader {
+					c.LeaderCount++
+				} else {
+					c.FollowerCount++
+				}
+				c.TotalCount++
+			}
+		}
+	}
+
+	res := &BrokerPartitionBalance{
+		Brokers:          make([]*BrokerPartitionCount, 0, len(countByBroker)),
+		HotspotBrokerIDs: make([]int32, 0),
+	}
+	for _, c := range countByBroker {
+		res.Brokers = append(res.Brokers, c)
+	}
+	sort.Slice(res.Brokers, func(i, j int) bool { return res.Brokers[i].BrokerID < res.Brokers[j].BrokerID })
+	if len(res.Brokers) == 0 {
+		return res
+	}
+
+	res.MinReplicas = res.Brokers[0].TotalCount
+	for _, c := range res.Brokers {
+		res.TotalReplicas += c.TotalCount
+		if c.TotalCount < res.MinReplicas {
+			res.MinReplicas = c.TotalCount
+		}
+		if c.TotalCount > res.MaxReplicas {
+			res.MaxReplicas = c.TotalCount
+		}
+	}
+	res.AverageReplicas = float64(res.TotalReplicas) / float64(len(res.Brokers))
+	if res.AverageReplicas == 0 {
+		return res
+	}
+	res.ImbalanceRatio = float64(res.MaxReplicas) / res.AverageReplicas
+
+	for _, c := range res.Brokers {
+		if float64(c.TotalCount) > res.AverageReplicas*brokerHotspotFactor {
+			c.IsHotspot = true
+			res.HotspotBrokerIDs = append(res.HotspotBrokerIDs, c.BrokerID)
+		}
+	}
+
+	return res
+}
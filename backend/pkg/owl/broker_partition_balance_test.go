@@ -0,0 +1,45 @@
+package owl
+
+import (
+	"testing"
+
+	"github.com/Shopify/sarama"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewBrokerPartitionBalance(t *testing.T) {
+	topics := []*sarama.TopicMetadata{
+		{
+			Name: "orders",
+			Partitions: []*sarama.PartitionMetadata{
+				{ID: 0, Leader: 1, Replicas: []int32{1, 2}},
+				{ID: 1, Leader: 1, Replicas: []int32{1, 3}},
+				{ID: 2, Leader: 1, Replicas: []int32{1, 2}},
+			},
+		},
+		{
+			Name: "payments",
+			Partitions: []*sarama.PartitionMetadata{
+				{ID: 0, Leader: 2, Replicas: []int32{2, 1}},
+				{ID: 1, Leader: 1, Replicas: []int32{1}},
+			},
+		},
+		{Name: "unknown", Err: sarama.ErrUnknownTopicOrPartition},
+	}
+
+	// Broker 4 does not host any partition
+	balance := newBrokerPartitionBalance([]int32{1, 2, 3, 4}, topics)
+	require.Len(t, balance.Brokers, 4)
+	assert.Equal(t, &BrokerPartitionCount{BrokerID: 1, LeaderCount: 4, FollowerCount: 1, TotalCount: 5, IsHotspot: true}, balance.Brokers[0])
+	assert.Equal(t, &BrokerPartitionCount{BrokerID: 2, LeaderCount: 1, FollowerCount: 2, TotalCount: 3}, balance.Brokers[1])
+	assert.Equal(t, &BrokerPartitionCount{BrokerID: 3, LeaderCount: 0, FollowerCount: 1, TotalCount: 1}, balance.Brokers[2])
+	assert.Equal(t, &BrokerPartitionCount{BrokerID: 4}, balance.Brokers[3])
+
+	assert.Equal(t, 9, balance.TotalReplicas)
+	assert.Equal(t, 2.25, balance.AverageReplicas)
+	assert.Equal(t, 0, balance.MinReplicas)
+	assert.Equal(t, 5, balance.MaxReplicas)
+	assert.InDelta(t, 2.22, balance.ImbalanceRatio, 0.01)
+	assert.Equal(t, []int32{1}, balance.HotspotBrokerIDs)
+}
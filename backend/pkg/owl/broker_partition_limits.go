@@ -0,0 +1,119 @@
+package owl
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// BrokerLimitsConfig configures the practical limits of a single broker, beyond which the cluster is at risk of
+// becoming unstable (e.g. slow controller failovers and leader elections)
+type BrokerLimitsConfig struct {
+	// MaxPartitionsPerBroker is the number of partition replicas a single broker should not exceed
+	MaxPartitionsPerBroker int `yaml:"maxPartitionsPerBroker"`
+	// WarningThreshold is the percentage of MaxPartitionsPerBroker at which a broker is approaching the limit
+	WarningThreshold float64 `yaml:"warningThreshold"`
+}
+
+// SetDefaults for the broker limits config
+func (c *BrokerLimitsConfig) SetDefaults() {
+	c.MaxPartitionsPerBroker = 4000
+	c.WarningThreshold = 80
+}
+
+// Validate the broker limits config
+func (c *BrokerLimitsConfig) Validate() error {
+	if c.MaxPartitionsPerBroker <= 0 {
+		return fmt.Errorf("max partitions per broker must be greater than 0")
+	}
+	if c.WarningThreshold <= 0 || c.WarningThreshold > 100 {
+		return fmt.Errorf("warning threshold must be between 0 and 100")
+	}
+
+	return nil
+}
+
+// BrokerPartitionLimits reports the partition replica load per broker relative to the configured partition limit,
+// after adding the replicas of a proposed layout (if any) to the existing replicas
+type BrokerPartitionLimits struct {
+	MaxPartitionsPerBroker int                    `json:"maxPartitionsPerBroker"`
+	WarningThreshold       float64                `json:"warningThreshold"`
+	AddedReplicas          int                    `json:"addedReplicas"`
+	Brokers                []*BrokerPartitionLoad `json:"brokers"` // Sorted by broker id
+	ApproachingBrokerIDs   []int32                `json:"approachingBrokerIds"`
+	ExceedingBrokerIDs     []int32                `json:"exceedingBrokerIds"`
+}
+
+// BrokerPartitionLoad is the number of partition replicas a broker hosts and would host with the proposed layout
+type BrokerPartitionLoad struct {
+	BrokerID           int32   `json:"brokerId"`
+	CurrentReplicas    int     `json:"currentReplicas"`
+	AddedReplicas      int     `json:"addedReplicas"`
+	ProjectedReplicas  int     `json:"projectedReplicas"`
+	Utilization        float64 `json:"utilization"` // Percentage of the projected replicas of the partition limit
+	IsApproachingLimit bool    `json:"isApproachingLimit"`
+	IsExceedingLimit   bool    `json:"isExceedingLimit"`
+}
+
+// GetBrokerPartitionLimits returns the partition load per broker relative to the configured partition limit. The
+// given number of additional replicas (e.g. of a topic which is about to be created) is spread evenly across all
+// brokers, like the controller's replica assignment does. Remaining replicas are assumed to end up on the most
+// loaded brokers, so that the projection errs on the side of caution.
+func (s *Service) GetBrokerPartitionLimits(ctx context.Context, addedReplicas int) (*BrokerPartitionLimits, error) {
+	if addedReplicas < 0 {
+		return nil, fmt.Errorf("added replicas must not be negative")
+	}
+
+	balance, err := s.GetBrokerPartitionBalance(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return newBrokerPartitionLimits(balance.Brokers, addedReplicas, s.brokerLimits), nil
+}
+
+func newBrokerPartitionLimits(brokers []*BrokerPartitionCount, addedReplicas int, cfg BrokerLimitsConfig) *BrokerPartitionLimits {
+	res := &BrokerPartitionLimits{
+		MaxPartitionsPerBroker: cfg.MaxPartitionsPerBroker,
+		WarningThreshold:       cfg.WarningThreshold,
+		AddedReplicas:          addedReplicas,
+		Brokers:                make([]*BrokerPartitionLoad, len(brokers)),
+		ApproachingBrokerIDs:   make([]int32, 0),
+		ExceedingBrokerIDs:     make([]int32, 0),
+	}
+	if len(brokers) == 0 {
+		return res
+	}
+
+	for i, broker := range brokers {
+		res.Brokers[i] = &BrokerPartitionLoad{
+			BrokerID:        broker.BrokerID,
+			CurrentReplicas: broker.TotalCount,
+			AddedReplicas:   addedReplicas / len(brokers),
+		}
+	}
+
+	// The remainder goes to the most loaded brokers
+	byLoad := make([]*BrokerPartitionLoad, len(res.Brokers))
+	copy(byLoad, res.Brokers)
+	sort.SliceStable(byLoad, func(i, j int) bool { return byLoad[i].CurrentReplicas > byLoad[j].CurrentReplicas })
+	for i := 0; i < addedReplicas%len(brokers); i++ {
+		byLoad[i].AddedReplicas++
+	}
+
+	warningCount := float64(cfg.MaxPartitionsPerBroker) * cfg.WarningThreshold / 100
+	for _, broker := range res.Brokers {
+		broker.ProjectedReplicas = broker.CurrentReplicas + broker.AddedReplicas
+		broker.Utilization = 100 * float64(broker.ProjectedReplicas) / float64(cfg.MaxPartitionsPerBroker)
+		switch {
+		case broker.ProjectedReplicas > cfg.MaxPartitionsPerBroker:
+			broker.IsExceedingLimit = true
+			res.ExceedingBrokerIDs = append(res.ExceedingBrokerIDs, broker.BrokerID)
+		case float64(broker.ProjectedReplicas) >= warningCount:
+			broker.IsApproachingLimit = true
+			res.ApproachingBrokerIDs = append(res.ApproachingBrokerIDs, broker.BrokerID)
+		}
+	}
+
+	return res
+}
@@ -0,0 +1,53 @@
+package owl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewBrokerPartitionLimits(t *testing.T) {
+	cfg := BrokerLimitsConfig{MaxPartitionsPerBroker: 4000, WarningThreshold: 80}
+	brokers := []*BrokerPartitionCount{
+		{BrokerID: 1, TotalCount: 2400},
+		{BrokerID: 2, TotalCount: 2300},
+		{BrokerID: 3, TotalCount: 2200},
+	}
+
+	// Creating a topic with 1000 partitions and a replication factor of 3 on a 3 broker cluster
+	desired := &TopicManifest{Topics: []*TopicManifestEntry{{Name: "clickstream", Partitions: 1000, ReplicationFactor: 3}}}
+	result := planTopicManifest(desired, &topicManifestState{})
+	require.Equal(t, 3000, result.addedReplicas())
+
+	limits := newBrokerPartitionLimits(brokers, result.addedReplicas(), cfg)
+	assert.Equal(t, []int32{1, 2, 3}, limits.ApproachingBrokerIDs)
+	assert.Empty(t, limits.ExceedingBrokerIDs)
+	assert.Equal(t, 3400, limits.Brokers[0].ProjectedReplicas)
+	assert.Equal(t, 85.0, limits.Brokers[0].Utilization)
+
+	result.checkPartitionLimits(limits)
+	assert.Len(t, result.Warnings, 3)
+	assert.Empty(t, result.RefusedChanges)
+
+	// Increasing the partitions of an existing topic so that the most loaded broker exceeds the limit. The remainder
+	// of the evenly spread replicas is assigned to the most loaded brokers.
+	brokers = []*BrokerPartitionCount{
+		{BrokerID: 1, TotalCount: 3850},
+		{BrokerID: 2, TotalCount: 3100},
+		{BrokerID: 3, TotalCount: 2000},
+	}
+	current := &topicManifestState{Topics: map[string]*TopicManifestEntry{"clickstream": {Name: "clickstream", Partitions: 100, ReplicationFactor: 2}}}
+	desired = &TopicManifest{Topics: []*TopicManifestEntry{{Name: "clickstream", Partitions: 350, ReplicationFactor: 2}}}
+	result = planTopicManifest(desired, current)
+	require.Equal(t, 500, result.addedReplicas())
+
+	limits = newBrokerPartitionLimits(brokers, result.addedReplicas(), cfg)
+	assert.Equal(t, []int{167, 167, 166}, []int{limits.Brokers[0].AddedReplicas, limits.Brokers[1].AddedReplicas, limits.Brokers[2].AddedReplicas})
+	assert.Equal(t, []int32{1}, limits.ExceedingBrokerIDs)
+	assert.Equal(t, []int32{2}, limits.ApproachingBrokerIDs)
+
+	result.checkPartitionLimits(limits)
+	assert.Len(t, result.RefusedChanges, 1)
+	assert.Len(t, result.Warnings, 1)
+}
@@ -0,0 +1,77 @@
+package owl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// ErrUnknownCluster is returned if a request targets a cluster which has not been configured
+var ErrUnknownCluster = errors.New("unknown cluster")
+
+// Clusters maps cluster names to the owl services which are connected to the respective cluster, each with its own
+// pre-configured TLS and SASL credentials. Requests which don't specify a cluster are served by the default
+// cluster's service.
+type Clusters struct {
+	defaultSvc *Service
+	services   map[string]*Service
+}
+
+// NewClusters creates a cluster map which serves requests without a cluster name from the given default service
+func NewClusters(defaultSvc *Service) *Clusters {
+	return &Clusters{
+		defaultSvc: defaultSvc,
+		services:   make(map[string]*Service),
+	}
+}
+
+// Add registers the service of an additional cluster. Cluster names must be unique and must not be empty, as the
+// empty name refers to the default cluster.
+func (c *Clusters) Add(name string, svc *Service) error {
+	if name == "" {
+		return fmt.Errorf("cluster name must not be empty")
+	}
+	if _, exists := c.services[name]; exists {
+		return fmt.Errorf("cluster '%v' has already been registered", name)
+	}
+	svc.clusterName = name
+	c.services[name] = svc
+
+	return nil
+}
+
+// Get returns the service of the given cluster or the default cluster's service if the name is empty
+func (c *Clusters) Get(name string) (*Service, error) {
+	if name == "" {
+		return c.defaultSvc, nil
+	}
+
+	svc, exists := c.services[name]
+	if !exists {
+		return nil, fmt.Errorf("cluster '%v': %w", name, ErrUnknownCluster)
+	}
+
+	return svc, nil
+}
+
+// Start starts the lag sampler and the water mark warmup of all additional clusters, each is only started if it's
+// enabled. The default cluster's service must be started separately.
+func (c *Clusters) Start(ctx context.Context) {
+	for _, name := range c.Names() {
+		svc := c.services[name]
+		svc.StartLagSampler(ctx)
+		svc.StartWaterMarkWarmup(ctx)
+	}
+}
+
+// Names returns the sorted names of all additional clusters
+func (c *Clusters) Names() []string {
+	names := make([]string, 0, len(c.services))
+	for name := range c.services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
@@ -0,0 +1,37 @@
+package owl
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/cloudhut/kowl/backend/pkg/kafka"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClusters(t *testing.T) {
+	defaultSvc := &Service{kafkaSvc: &kafka.Service{}}
+	stagingSvc := &Service{kafkaSvc: &kafka.Service{}}
+	analyticsSvc := &Service{kafkaSvc: &kafka.Service{}}
+
+	clusters := NewClusters(defaultSvc)
+	require.NoError(t, clusters.Add("staging", stagingSvc))
+	require.NoError(t, clusters.Add("analytics", analyticsSvc))
+	assert.Error(t, clusters.Add("staging", stagingSvc))
+	assert.Error(t, clusters.Add("", stagingSvc))
+	assert.Equal(t, []string{"analytics", "staging"}, clusters.Names())
+	assert.Equal(t, "staging", stagingSvc.clusterName)
+	assert.Empty(t, defaultSvc.clusterName)
+
+	svc, err := clusters.Get("")
+	require.NoError(t, err)
+	assert.Same(t, defaultSvc.kafkaSvc, svc.kafkaSvc)
+
+	svc, err = clusters.Get("staging")
+	require.NoError(t, err)
+	assert.Same(t, stagingSvc.kafkaSvc, svc.kafkaSvc)
+
+	svc, err = clusters.Get("production")
+	assert.Nil(t, svc)
+	assert.True(t, errors.Is(err, ErrUnknownCluster))
+}
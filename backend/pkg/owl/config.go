@@ -0,0 +1,89 @@
+package owl
+
+import "fmt"
+
+// Config for the owl service
+type Config struct {
+	ConsumeQuota ConsumeQuotaConfig `yaml:"consumeQuota"`
+	LagSampler   LagSamplerConfig   `yaml:"lagSampler"`
+	LagRules     LagRulesConfig     `yaml:"lagRules"`
+	HealthScore  HealthScoreConfig  `yaml:"healthScore"`
+	JSONCoercion JSONCoercionConfig `yaml:"jsonCoercion"`
+	BrokerLimits BrokerLimitsConfig `yaml:"brokerLimits"`
+	WireFormat   WireFormatConfig   `yaml:"wireFormat"`
+
+	InnerCompression InnerCompressionConfig `yaml:"innerCompression"`
+
+	WaterMarkCache WaterMarkCacheConfig `yaml:"waterMarkCache"`
+
+	MessageProcessing MessageProcessingConfig `yaml:"messageProcessing"`
+}
+
+// SetDefaults for the owl config
+func (c *Config) SetDefaults() {
+	c.ConsumeQuota.SetDefaults()
+	c.LagSampler.SetDefaults()
+	c.LagRules.SetDefaults()
+	c.HealthScore.SetDefaults()
+	c.JSONCoercion.SetDefaults()
+	c.BrokerLimits.SetDefaults()
+	c.WireFormat.SetDefaults()
+	c.InnerCompression.SetDefaults()
+	c.WaterMarkCache.SetDefaults()
+	c.MessageProcessing.SetDefaults()
+}
+
+// Validate the owl config
+func (c *Config) Validate() error {
+	err := c.ConsumeQuota.Validate()
+	if err != nil {
+		return fmt.Errorf("failed to validate consume quota config: %w", err)
+	}
+
+	err = c.LagSampler.Validate()
+	if err != nil {
+		return fmt.Errorf("failed to validate lag sampler config: %w", err)
+	}
+
+	err = c.LagRules.Validate()
+	if err != nil {
+		return fmt.Errorf("failed to validate lag rules config: %w", err)
+	}
+
+	err = c.HealthScore.Validate()
+	if err != nil {
+		return fmt.Errorf("failed to validate health score config: %w", err)
+	}
+
+	err = c.JSONCoercion.Validate()
+	if err != nil {
+		return fmt.Errorf("failed to validate json coercion config: %w", err)
+	}
+
+	err = c.BrokerLimits.Validate()
+	if err != nil {
+		return fmt.Errorf("failed to validate broker limits config: %w", err)
+	}
+
+	err = c.WireFormat.Validate()
+	if err != nil {
+		return fmt.Errorf("failed to validate wire format config: %w", err)
+	}
+
+	err = c.InnerCompression.Validate()
+	if err != nil {
+		return fmt.Errorf("failed to validate inner compression config: %w", err)
+	}
+
+	err = c.WaterMarkCache.Validate()
+	if err != nil {
+		return fmt.Errorf("failed to validate water mark cache config: %w", err)
+	}
+
+	err = c.MessageProcessing.Validate()
+	if err != nil {
+		return fmt.Errorf("failed to validate message processing config: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,122 @@
+package owl
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// ConsumeQuotaConfig limits the consume throughput per principal, so that a single user can not starve other users
+// in a shared Kowl instance.
+type ConsumeQuotaConfig struct {
+	Enabled           bool    `yaml:"enabled"`
+	BytesPerSecond    int     `yaml:"bytesPerSecond"`    // 0 = unlimited
+	MessagesPerSecond float64 `yaml:"messagesPerSecond"` // 0 = unlimited
+}
+
+// SetDefaults for the consume quota config
+func (c *ConsumeQuotaConfig) SetDefaults() {
+	c.Enabled = false
+	c.BytesPerSecond = 10 * 1024 * 1024 // 10MB/s
+	c.MessagesPerSecond = 0
+}
+
+// Validate the consume quota config
+func (c *ConsumeQuotaConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if c.BytesPerSecond < 0 || c.MessagesPerSecond < 0 {
+		return fmt.Errorf("consume quotas must not be negative")
+	}
+	if c.BytesPerSecond == 0 && c.MessagesPerSecond == 0 {
+		return fmt.Errorf("consume quota is enabled but neither a bytes nor a messages quota is set")
+	}
+
+	return nil
+}
+
+// consumeQuotas keeps track of the consumed throughput for each principal
+type consumeQuotas struct {
+	cfg ConsumeQuotaConfig
+
+	mutex    sync.Mutex
+	limiters map[string]*principalLimiter
+}
+
+type principalLimiter struct {
+	bytes    *rate.Limiter
+	messages *rate.Limiter
+	lastUsed time.Time
+}
+
+// principalLimiterTTL is the idle duration after which a principal's limiter is forgotten
+const principalLimiterTTL = 10 * time.Minute
+
+func newConsumeQuotas(cfg ConsumeQuotaConfig) *consumeQuotas {
+	return &consumeQuotas{
+		cfg:      cfg,
+		limiters: make(map[string]*principalLimiter),
+	}
+}
+
+// reserve accounts a consumed message of the given size for the principal and returns how long the message delivery
+// must be delayed to stay within the principal's quota. Requests without a principal (e.g. internal callers which
+// don't come through the REST API) are not subject to the quota, so that they don't share a single limiter.
+func (q *consumeQuotas) reserve(principal string, size int, now time.Time) time.Duration {
+	if !q.cfg.Enabled || principal == "" {
+		return 0
+	}
+
+	l := q.limiterFor(principal, now)
+	delay := time.Duration(0)
+	if l.messages != nil {
+		if d := l.messages.ReserveN(now, 1).DelayFrom(now); d > delay {
+			delay = d
+		}
+	}
+	if l.bytes != nil && size > 0 {
+		// Messages larger than the burst size would never be allowed otherwise
+		if size > l.bytes.Burst() {
+			size = l.bytes.Burst()
+		}
+		if d := l.bytes.ReserveN(now, size).DelayFrom(now); d > delay {
+			delay = d
+		}
+	}
+
+	return delay
+}
+
+func (q *consumeQuotas) limiterFor(principal string, now time.Time) *principalLimiter {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	// Forget limiters of principals which haven't consumed for a while
+	for p, l := range q.limiters {
+		if now.Sub(l.lastUsed) > principalLimiterTTL {
+			delete(q.limiters, p)
+		}
+	}
+
+	l, ok := q.limiters[principal]
+	if !ok {
+		l = &principalLimiter{}
+		if q.cfg.BytesPerSecond > 0 {
+			l.bytes = rate.NewLimiter(rate.Limit(q.cfg.BytesPerSecond), q.cfg.BytesPerSecond)
+		}
+		if q.cfg.MessagesPerSecond > 0 {
+			burst := int(q.cfg.MessagesPerSecond)
+			if burst < 1 {
+				burst = 1
+			}
+			l.messages = rate.NewLimiter(rate.Limit(q.cfg.MessagesPerSecond), burst)
+		}
+		q.limiters[principal] = l
+	}
+	l.lastUsed = now
+
+	return l
+}
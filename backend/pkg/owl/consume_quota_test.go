@@ -0,0 +1,30 @@
+package owl
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConsumeQuotas_ThrottlePerPrincipal(t *testing.T) {
+	quotas := newConsumeQuotas(ConsumeQuotaConfig{Enabled: true, BytesPerSecond: 1000})
+	now := time.Now()
+
+	// First 1000 bytes are within the burst, everything beyond must be delayed
+	assert.Equal(t, time.Duration(0), quotas.reserve("alice", 1000, now))
+	assert.Equal(t, 500*time.Millisecond, quotas.reserve("alice", 500, now))
+
+	// Bob has his own budget and must not be slowed down by alice
+	assert.Equal(t, time.Duration(0), quotas.reserve("bob", 200, now))
+}
+
+func TestConsumeQuotas_WithoutPrincipal(t *testing.T) {
+	quotas := newConsumeQuotas(ConsumeQuotaConfig{Enabled: true, BytesPerSecond: 1000})
+	now := time.Now()
+
+	// Callers without a principal would otherwise share a single limiter
+	assert.Equal(t, time.Duration(0), quotas.reserve("", 1000, now))
+	assert.Equal(t, time.Duration(0), quotas.reserve("", 1000, now))
+	assert.Empty(t, quotas.limiters)
+}
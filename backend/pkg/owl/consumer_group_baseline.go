@@ -0,0 +1,53 @@
+package owl
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// groupBaselines keeps the "known good" offsets snapshot of each group. Baselines are only kept in memory, hence
+// they are lost when Kowl restarts and are not shared across multiple Kowl instances.
+type groupBaselines struct {
+	mutex     sync.RWMutex
+	snapshots map[string]*ConsumerGroupOffsetsSnapshot
+}
+
+func newGroupBaselines() *groupBaselines {
+	return &groupBaselines{snapshots: make(map[string]*ConsumerGroupOffsetsSnapshot)}
+}
+
+func (b *groupBaselines) set(snapshot *ConsumerGroupOffsetsSnapshot) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.snapshots[snapshot.GroupID] = snapshot
+}
+
+func (b *groupBaselines) get(groupID string) (*ConsumerGroupOffsetsSnapshot, bool) {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+	snapshot, exists := b.snapshots[groupID]
+	return snapshot, exists
+}
+
+// SetGroupBaseline marks the group's currently committed offsets as known good baseline, replacing any previous
+// baseline. Baselines are not durable and only live as long as the service.
+func (s *Service) SetGroupBaseline(ctx context.Context, groupID string) (*ConsumerGroupOffsetsSnapshot, error) {
+	snapshot, err := s.ExportConsumerGroupOffsets(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+	s.groupBaselines.set(snapshot)
+
+	return snapshot, nil
+}
+
+// CompareToBaseline returns the per partition offset drift of the group since its baseline has been set
+func (s *Service) CompareToBaseline(ctx context.Context, groupID string) (*ConsumerGroupOffsetsDrift, error) {
+	baseline, exists := s.groupBaselines.get(groupID)
+	if !exists {
+		return nil, fmt.Errorf("no baseline has been set for consumer group '%v'", groupID)
+	}
+
+	return s.CompareConsumerGroupOffsets(ctx, baseline)
+}
@@ -0,0 +1,34 @@
+package owl
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroupBaselines(t *testing.T) {
+	baselines := newGroupBaselines()
+	baselines.set(&ConsumerGroupOffsetsSnapshot{
+		GroupID:   "billing",
+		CreatedAt: time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC),
+		Offsets:   map[string]partitionOffsets{"orders": {0: 100, 1: 200}},
+	})
+
+	_, exists := baselines.get("audit")
+	assert.False(t, exists)
+	baseline, exists := baselines.get("billing")
+	require.True(t, exists)
+
+	current := &ConsumerGroupOffsetsSnapshot{
+		GroupID: "billing",
+		Offsets: map[string]partitionOffsets{"orders": {0: 150, 1: 200}},
+	}
+	drift := compareOffsetsSnapshots(baseline, current)
+	require.Len(t, drift.Partitions, 2)
+	assert.Equal(t, int64(50), drift.Partitions[0].Delta)
+	assert.Equal(t, OffsetDriftAdvanced, drift.Partitions[0].Status)
+	assert.Equal(t, int64(0), drift.Partitions[1].Delta)
+	assert.Equal(t, OffsetDriftUnchanged, drift.Partitions[1].Status)
+}
@@ -0,0 +1,111 @@
+package owl
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	CommitFrequencyHintNoCommits  = "noCommitsObserved"
+	CommitFrequencyHintInfrequent = "infrequent"
+	CommitFrequencyHintSaturated  = "atLeastOncePerSample"
+	CommitFrequencyHintOK         = "ok"
+
+	// infrequentCommitInterval is the interval above which commits are considered infrequent, which risks a lot
+	// of reprocessing if a consumer crashes
+	infrequentCommitInterval = 10 * time.Minute
+)
+
+// ConsumerGroupCommitFrequency is the estimated commit frequency of a group, derived from the committed offset
+// changes between the recorded lag samples. Multiple commits between two samples can't be told apart, hence the
+// frequency is a lower bound which is saturated if the offsets have changed between all samples.
+type ConsumerGroupCommitFrequency struct {
+	CommitsPerMinute float64 `json:"commitsPerMinute"`
+	ObservedChanges  int     `json:"observedChanges"`
+	SampleCount      int     `json:"sampleCount"`
+	ObservedMs       int64   `json:"observedMs"`
+	IsLowerBound     bool    `json:"isLowerBound"` // True if the group committed between all samples
+	Hint             string  `json:"hint"`
+}
+
+// GetConsumerGroupCommitFrequency estimates how often the group commits its offsets, as a tuning hint. Committing
+// too frequently wastes broker resources, while committing too infrequently risks reprocessing after a crash.
+func (s *Service) GetConsumerGroupCommitFrequency(groupID string) (*ConsumerGroupCommitFrequency, error) {
+	if s.lagHistory == nil {
+		return nil, ErrLagHistoryDisabled
+	}
+
+	f := s.getConsumerGroupCommitFrequency(groupID)
+	if f == nil {
+		return nil, fmt.Errorf("at least two lag samples are required to estimate the commit frequency")
+	}
+	return f, nil
+}
+
+// getConsumerGroupCommitFrequency returns nil if the lag history is disabled or has less than two samples
+func (s *Service) getConsumerGroupCommitFrequency(groupID string) *ConsumerGroupCommitFrequency {
+	if s.lagHistory == nil {
+		return nil
+	}
+
+	return estimateCommitFrequency(s.lagHistory.get(groupID, time.Time{}))
+}
+
+func estimateCommitFrequency(samples []*LagSample) *ConsumerGroupCommitFrequency {
+	if len(samples) < 2 {
+		return nil
+	}
+
+	f := &ConsumerGroupCommitFrequency{SampleCount: len(samples)}
+	hasLag := false
+	for i := 1; i < len(samples); i++ {
+		if hasCommittedOffsetChanged(samples[i-1].Lag, samples[i].Lag) {
+			f.ObservedChanges++
+		}
+	}
+	for _, topicLag := range samples[len(samples)-1].Lag.TopicLags {
+		if topicLag.SummedLag > 0 {
+			hasLag = true
+		}
+	}
+
+	observed := samples[len(samples)-1].Timestamp.Sub(samples[0].Timestamp)
+	f.ObservedMs = observed.Milliseconds()
+	if observed > 0 {
+		f.CommitsPerMinute = float64(f.ObservedChanges) / observed.Minutes()
+	}
+	f.IsLowerBound = f.ObservedChanges == len(samples)-1
+
+	switch {
+	case f.IsLowerBound:
+		f.Hint = CommitFrequencyHintSaturated
+	case f.ObservedChanges == 0 && hasLag:
+		f.Hint = CommitFrequencyHintNoCommits
+	case f.ObservedChanges > 0 && observed/time.Duration(f.ObservedChanges) > infrequentCommitInterval:
+		f.Hint = CommitFrequencyHintInfrequent
+	default:
+		f.Hint = CommitFrequencyHintOK
+	}
+
+	return f
+}
+
+// hasCommittedOffsetChanged returns true if any partition's committed offset differs between both samples
+func hasCommittedOffsetChanged(previous *ConsumerGroupLag, current *ConsumerGroupLag) bool {
+	for _, topicLag := range current.TopicLags {
+		previousTopicLag := previous.GetTopicLag(topicLag.Topic)
+		if previousTopicLag == nil {
+			return true
+		}
+		previousOffsets := make(map[int32]int64, len(previousTopicLag.PartitionLags))
+		for _, p := range previousTopicLag.PartitionLags {
+			previousOffsets[p.PartitionID] = p.CommittedOffset
+		}
+		for _, p := range topicLag.PartitionLags {
+			if offset, exists := previousOffsets[p.PartitionID]; !exists || offset != p.CommittedOffset {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,35 @@
+package owl
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEstimateCommitFrequency(t *testing.T) {
+	start := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+	committedOffsets := []int64{100, 100, 150, 150, 150, 210, 210}
+
+	samples := make([]*LagSample, len(committedOffsets))
+	for i, offset := range committedOffsets {
+		samples[i] = &LagSample{
+			Timestamp: start.Add(time.Duration(i) * time.Minute),
+			Lag: &ConsumerGroupLag{GroupID: "billing", TopicLags: []*TopicLag{{
+				Topic:         "orders",
+				SummedLag:     5,
+				PartitionLags: []PartitionLag{{PartitionID: 0, Lag: 5, CommittedOffset: offset}},
+			}}},
+		}
+	}
+
+	f := estimateCommitFrequency(samples)
+	require.NotNil(t, f)
+	assert.Equal(t, 2, f.ObservedChanges)
+	assert.InDelta(t, 2.0/6.0, f.CommitsPerMinute, 0.0001)
+	assert.False(t, f.IsLowerBound)
+	assert.Equal(t, CommitFrequencyHintOK, f.Hint)
+
+	assert.Nil(t, estimateCommitFrequency(samples[:1]))
+}
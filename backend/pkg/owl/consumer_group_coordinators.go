@@ -0,0 +1,102 @@
+package owl
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// CoordinatorGroups is a broker along with all consumer groups it is coordinating
+type CoordinatorGroups struct {
+	BrokerID   int32    `json:"brokerId"`
+	Address    string   `json:"address"`
+	Rack       string   `json:"rack"`
+	GroupCount int      `json:"groupCount"`
+	GroupIDs   []string `json:"groupIds"`
+}
+
+// ListConsumerGroupsByCoordinator returns all consumer groups bucketed by their coordinating broker, so that brokers
+// which coordinate a disproportionate number of groups can be spotted. Brokers are sorted by their group count.
+func (s *Service) ListConsumerGroupsByCoordinator(ctx context.Context) ([]*CoordinatorGroups, error) {
+	groups, err := s.kafkaSvc.ListConsumerGroups(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list consumer groups: %w", err)
+	}
+
+	eg, _ := errgroup.WithContext(ctx)
+
+	groupsByCoordinator := make(map[int32][]string)
+	var brokersByID map[int32]*Broker
+
+	eg.Go(func() error {
+		describedGroups, err := s.kafkaSvc.DescribeConsumerGroups(ctx, groups)
+		if err != nil {
+			return fmt.Errorf("failed to describe consumer groups: %w", err)
+		}
+		for coordinatorID, res := range describedGroups {
+			for _, group := range res.Groups {
+				groupsByCoordinator[coordinatorID] = append(groupsByCoordinator[coordinatorID], group.GroupId)
+			}
+		}
+		return nil
+	})
+
+	eg.Go(func() error {
+		metadata, err := s.kafkaSvc.DescribeCluster()
+		if err != nil {
+			return fmt.Errorf("failed to describe cluster: %w", err)
+		}
+		brokersByID = make(map[int32]*Broker, len(metadata.Brokers))
+		for _, b := range metadata.Brokers {
+			brokersByID[b.ID()] = &Broker{BrokerID: b.ID(), Address: b.Addr(), Rack: b.Rack()}
+		}
+		return nil
+	})
+
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+
+	return bucketGroupsByCoordinator(groupsByCoordinator, brokersByID), nil
+}
+
+// bucketGroupsByCoordinator merges the coordinated groups with the broker information. Brokers which do not
+// coordinate any group are included with a group count of 0.
+func bucketGroupsByCoordinator(groupsByCoordinator map[int32][]string, brokersByID map[int32]*Broker) []*CoordinatorGroups {
+	res := make([]*CoordinatorGroups, 0, len(brokersByID))
+	for id, broker := range brokersByID {
+		groupIDs := groupsByCoordinator[id]
+		if groupIDs == nil {
+			groupIDs = make([]string, 0)
+		}
+		sort.Strings(groupIDs)
+
+		res = append(res, &CoordinatorGroups{
+			BrokerID:   id,
+			Address:    broker.Address,
+			Rack:       broker.Rack,
+			GroupCount: len(groupIDs),
+			GroupIDs:   groupIDs,
+		})
+	}
+
+	// Coordinators which are not part of the metadata response anymore (e.g. broker has just been shut down)
+	for id, groupIDs := range groupsByCoordinator {
+		if _, exists := brokersByID[id]; exists {
+			continue
+		}
+		sort.Strings(groupIDs)
+		res = append(res, &CoordinatorGroups{BrokerID: id, GroupCount: len(groupIDs), GroupIDs: groupIDs})
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].GroupCount == res[j].GroupCount {
+			return res[i].BrokerID < res[j].BrokerID
+		}
+		return res[i].GroupCount > res[j].GroupCount
+	})
+
+	return res
+}
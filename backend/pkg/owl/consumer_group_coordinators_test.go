@@ -0,0 +1,26 @@
+package owl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBucketGroupsByCoordinator(t *testing.T) {
+	brokers := map[int32]*Broker{
+		0: {BrokerID: 0, Address: "broker-0:9092", Rack: "a"},
+		1: {BrokerID: 1, Address: "broker-1:9092", Rack: "b"},
+		2: {BrokerID: 2, Address: "broker-2:9092", Rack: "c"},
+	}
+	groupsByCoordinator := map[int32][]string{
+		0: {"orders", "billing"},
+		2: {"search", "shipping", "analytics"},
+	}
+
+	expected := []*CoordinatorGroups{
+		{BrokerID: 2, Address: "broker-2:9092", Rack: "c", GroupCount: 3, GroupIDs: []string{"analytics", "search", "shipping"}},
+		{BrokerID: 0, Address: "broker-0:9092", Rack: "a", GroupCount: 2, GroupIDs: []string{"billing", "orders"}},
+		{BrokerID: 1, Address: "broker-1:9092", Rack: "b", GroupCount: 0, GroupIDs: []string{}},
+	}
+	assert.Equal(t, expected, bucketGroupsByCoordinator(groupsByCoordinator, brokers))
+}
@@ -0,0 +1,236 @@
+package owl
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	configRetentionMs    = "retention.ms"
+	configRetentionBytes = "retention.bytes"
+	configCleanupPolicy  = "cleanup.policy"
+
+	// dataLossRateWindow is the time span of lag samples the produce and consume rates are calculated from
+	dataLossRateWindow = time.Hour
+)
+
+// partitionDataLossInput contains everything that is required to project when a partition's committed offset
+// will be deleted by retention
+type partitionDataLossInput struct {
+	CommittedOffset int64
+	LowWaterMark    int64
+	HighWaterMark   int64
+	ProduceRate     float64 // Messages per second
+	ConsumeRate     float64 // Messages per second
+	AvgMessageSize  float64 // Bytes per message of the topic, zero if unknown
+
+	RetentionMs        int64     // -1 if unlimited
+	RetentionBytes     int64     // -1 if unlimited
+	CommittedTimestamp time.Time // Timestamp of the record at the committed offset, zero if unknown
+}
+
+// EstimateConsumerGroupDataLoss returns the group's lag along with the projected time until retention deletes
+// each partition's committed offset. The projection requires the produce and consume rates from the lag history.
+// Partitions which are catching up or whose topic doesn't delete data are not considered at risk.
+func (s *Service) EstimateConsumerGroupDataLoss(ctx context.Context, groupID string) (*ConsumerGroupLag, error) {
+	if s.lagHistory == nil {
+		return nil, ErrLagHistoryDisabled
+	}
+
+	lags, err := s.getConsumerGroupLags(ctx, []string{groupID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get consumer group lags: %w", err)
+	}
+	lag, exists := lags[groupID]
+	if !exists {
+		return nil, fmt.Errorf("no lag has been returned for consumer group '%v'", groupID)
+	}
+
+	samples := s.lagHistory.get(groupID, time.Now().Add(-dataLossRateWindow))
+	if len(samples) < 2 {
+		return nil, fmt.Errorf("at least two lag samples are required to estimate the data loss, but got %v", len(samples))
+	}
+	oldest, newest := samples[0], samples[len(samples)-1]
+
+	topicNames := make([]string, 0, len(lag.TopicLags))
+	for _, topicLag := range lag.TopicLags {
+		topicNames = append(topicNames, topicLag.Topic)
+	}
+	configs, err := s.GetTopicsConfigs(topicNames, []string{configRetentionMs, configRetentionBytes, configCleanupPolicy})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get topic configs: %w", err)
+	}
+
+	// The partition sizes are only required for topics with size based retention
+	var sizeByPartition map[string]map[int32]int64
+	for _, topicLag := range lag.TopicLags {
+		if _, retentionBytes, deletes := retentionFromConfigs(configs[topicLag.Topic]); deletes && retentionBytes > 0 {
+			sizeByPartition, err = s.logDirSizeByPartition()
+			if err != nil {
+				s.logger.Warn("failed to get log dir sizes, size based retention is not projected", zap.Error(err))
+			}
+			break
+		}
+	}
+
+	now := time.Now()
+	for _, topicLag := range lag.TopicLags {
+		retentionMs, retentionBytes, deletes := retentionFromConfigs(configs[topicLag.Topic])
+		if !deletes {
+			continue
+		}
+
+		partitionIDs := make([]int32, 0, len(topicLag.PartitionLags))
+		for _, p := range topicLag.PartitionLags {
+			partitionIDs = append(partitionIDs, p.PartitionID)
+		}
+		waterMarks, err := s.kafkaSvc.WaterMarks(topicLag.Topic, partitionIDs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get water marks for topic '%v': %w", topicLag.Topic, err)
+		}
+		rates := partitionRates(oldest, newest, topicLag.Topic)
+		avgMessageSize := float64(0)
+		if retentionBytes > 0 {
+			avgMessageSize = averageMessageSize(sizeByPartition[topicLag.Topic], waterMarks)
+		}
+
+		for i := range topicLag.PartitionLags {
+			p := &topicLag.PartitionLags[i]
+			rate, hasRate := rates[p.PartitionID]
+			waterMark, hasWaterMark := waterMarks[p.PartitionID]
+			if !hasRate || !hasWaterMark || p.Lag == 0 {
+				continue
+			}
+
+			input := partitionDataLossInput{
+				CommittedOffset: p.CommittedOffset,
+				LowWaterMark:    waterMark.Low,
+				HighWaterMark:   waterMark.High,
+				ProduceRate:     rate.produceRate,
+				ConsumeRate:     rate.consumeRate,
+				AvgMessageSize:  avgMessageSize,
+				RetentionMs:     retentionMs,
+				RetentionBytes:  retentionBytes,
+			}
+			if retentionMs > 0 {
+				ts, exists, err := s.kafkaSvc.FetchRecordTimestamp(ctx, topicLag.Topic, p.PartitionID, p.CommittedOffset)
+				if err != nil {
+					s.logger.Warn("failed to fetch record timestamp of committed offset",
+						zap.String("topic", topicLag.Topic), zap.Int32("partition_id", p.PartitionID), zap.Error(err))
+				} else if exists {
+					input.CommittedTimestamp = ts
+				}
+			}
+			p.TimeUntilDataLoss = projectTimeUntilDataLoss(input, now)
+		}
+	}
+
+	return lag, nil
+}
+
+// projectTimeUntilDataLoss returns the projected duration until the committed offset falls below the low water
+// mark, nil if the partition is not at risk. Time based retention deletes the committed record once it's older
+// than the retention. Size based retention deletes it once the messages after the committed offset exceed the
+// retention bytes, which are converted to a number of messages using the topic's average message size. Kafka
+// deletes whole segments, hence the size based projection is an approximation. The earlier of both projections
+// wins.
+func projectTimeUntilDataLoss(in partitionDataLossInput, now time.Time) *time.Duration {
+	if in.CommittedOffset < in.LowWaterMark {
+		lost := time.Duration(0)
+		return &lost
+	}
+	if in.ConsumeRate >= in.ProduceRate {
+		// The group is catching up
+		return nil
+	}
+
+	var res *time.Duration
+	if in.RetentionMs > 0 && !in.CommittedTimestamp.IsZero() {
+		deletedAt := in.CommittedTimestamp.Add(time.Duration(in.RetentionMs) * time.Millisecond)
+		d := deletedAt.Sub(now)
+		if d < 0 {
+			d = 0
+		}
+		res = &d
+	}
+	if in.RetentionBytes > 0 && in.AvgMessageSize > 0 {
+		retainedMessages := float64(in.RetentionBytes) / in.AvgMessageSize
+		lag := float64(in.HighWaterMark - in.CommittedOffset)
+		seconds := (retainedMessages - lag) / (in.ProduceRate - in.ConsumeRate)
+		if seconds < 0 {
+			seconds = 0
+		}
+		d := time.Duration(seconds * float64(time.Second))
+		if res == nil || d < *res {
+			res = &d
+		}
+	}
+
+	return res
+}
+
+// retentionFromConfigs returns the topic's retention.ms and retention.bytes, along with whether the cleanup policy
+// deletes data at all. Missing configs default to Kafka's defaults.
+func retentionFromConfigs(configs *TopicConfigs) (int64, int64, bool) {
+	retentionMs := int64(7 * 24 * time.Hour / time.Millisecond)
+	retentionBytes := int64(-1)
+	cleanupPolicy := "delete"
+	if configs == nil {
+		return retentionMs, retentionBytes, true
+	}
+
+	if entry := configs.GetConfigEntryByName(configRetentionMs); entry != nil {
+		if v, err := strconv.ParseInt(entry.Value, 10, 64); err == nil {
+			retentionMs = v
+		}
+	}
+	if entry := configs.GetConfigEntryByName(configRetentionBytes); entry != nil {
+		if v, err := strconv.ParseInt(entry.Value, 10, 64); err == nil {
+			retentionBytes = v
+		}
+	}
+	if entry := configs.GetConfigEntryByName(configCleanupPolicy); entry != nil && entry.Value != "" {
+		cleanupPolicy = entry.Value
+	}
+
+	deletes := strings.Contains(cleanupPolicy, "delete") && (retentionMs > 0 || retentionBytes > 0)
+	return retentionMs, retentionBytes, deletes
+}
+
+type partitionRate struct {
+	produceRate float64
+	consumeRate float64
+}
+
+// partitionRates returns the produce and consume rate of each partition which is part of both samples
+func partitionRates(oldest *LagSample, newest *LagSample, topicName string) map[int32]partitionRate {
+	res := make(map[int32]partitionRate)
+	seconds := newest.Timestamp.Sub(oldest.Timestamp).Seconds()
+	oldTopicLag := oldest.Lag.GetTopicLag(topicName)
+	newTopicLag := newest.Lag.GetTopicLag(topicName)
+	if seconds <= 0 || oldTopicLag == nil || newTopicLag == nil {
+		return res
+	}
+
+	oldPartitions := make(map[int32]PartitionLag, len(oldTopicLag.PartitionLags))
+	for _, p := range oldTopicLag.PartitionLags {
+		oldPartitions[p.PartitionID] = p
+	}
+	for _, p := range newTopicLag.PartitionLags {
+		old, exists := oldPartitions[p.PartitionID]
+		if !exists {
+			continue
+		}
+		res[p.PartitionID] = partitionRate{
+			produceRate: float64(p.HighWaterMark-old.HighWaterMark) / seconds,
+			consumeRate: float64(p.CommittedOffset-old.CommittedOffset) / seconds,
+		}
+	}
+
+	return res
+}
@@ -0,0 +1,65 @@
+package owl
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProjectTimeUntilDataLoss(t *testing.T) {
+	now := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	// 1 MiB of retention holds 1024 messages of 1 KiB, the lag of 24 messages grows by 10 msg/s
+	bySize := projectTimeUntilDataLoss(partitionDataLossInput{
+		CommittedOffset: 6000,
+		LowWaterMark:    5000,
+		HighWaterMark:   6024,
+		ProduceRate:     15,
+		ConsumeRate:     5,
+		AvgMessageSize:  1024,
+		RetentionMs:     -1,
+		RetentionBytes:  1024 * 1024,
+	}, now)
+	require.NotNil(t, bySize)
+	assert.Equal(t, 100*time.Second, *bySize)
+
+	// The average message size is required to project size based retention
+	assert.Nil(t, projectTimeUntilDataLoss(partitionDataLossInput{
+		CommittedOffset: 6000, LowWaterMark: 5000, HighWaterMark: 6024, ProduceRate: 15, ConsumeRate: 5,
+		RetentionMs: -1, RetentionBytes: 1024 * 1024,
+	}, now))
+
+	// The committed record is 6 days and 23 hours old with a retention of 7 days
+	byTime := projectTimeUntilDataLoss(partitionDataLossInput{
+		CommittedOffset:    6000,
+		LowWaterMark:       5000,
+		ProduceRate:        15,
+		ConsumeRate:        5,
+		RetentionMs:        int64(7 * 24 * time.Hour / time.Millisecond),
+		RetentionBytes:     -1,
+		CommittedTimestamp: now.Add(-(7*24 - 1) * time.Hour),
+	}, now)
+	require.NotNil(t, byTime)
+	assert.Equal(t, time.Hour, *byTime)
+
+	// Catching up
+	assert.Nil(t, projectTimeUntilDataLoss(partitionDataLossInput{
+		CommittedOffset: 6000, LowWaterMark: 5000, ProduceRate: 5, ConsumeRate: 15, AvgMessageSize: 1, RetentionBytes: 1024,
+	}, now))
+}
+
+func TestRetentionFromConfigs(t *testing.T) {
+	configs := &TopicConfigs{ConfigEntries: []*TopicConfigEntry{
+		{Name: configRetentionMs, Value: "-1"},
+		{Name: configRetentionBytes, Value: "-1"},
+		{Name: configCleanupPolicy, Value: "delete"},
+	}}
+	_, _, deletes := retentionFromConfigs(configs)
+	assert.False(t, deletes)
+
+	compacted := &TopicConfigs{ConfigEntries: []*TopicConfigEntry{{Name: configCleanupPolicy, Value: "compact"}}}
+	_, _, deletes = retentionFromConfigs(compacted)
+	assert.False(t, deletes)
+}
@@ -0,0 +1,178 @@
+package owl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/Shopify/sarama"
+)
+
+// diagnosticsLagHistoryWindow is how much of the recorded lag history is included in a diagnostics bundle
+const diagnosticsLagHistoryWindow = time.Hour
+
+// Anomalies which are detected in a consumer group's diagnostics bundle
+const (
+	GroupAnomalyStalled         = "stalled"         // Lagging partition whose committed offset hasn't moved
+	GroupAnomalyUnowned         = "unowned"         // Lagging partition which is not assigned to any member
+	GroupAnomalyBeyondRetention = "beyondRetention" // Committed offset has already been deleted by retention
+)
+
+// GroupDiagnostics bundles everything that is known about a consumer group into a single document, e.g. to be
+// attached to a support ticket.
+type GroupDiagnostics struct {
+	GroupID      string                    `json:"groupId"`
+	GeneratedAt  time.Time                 `json:"generatedAt"`
+	State        string                    `json:"state"`
+	ProtocolType string                    `json:"protocolType"`
+	Protocol     string                    `json:"protocol"`
+	Coordinator  *Broker                   `json:"coordinator"`
+	Members      []*GroupMemberDescription `json:"members"`
+	Topics       []*GroupDiagnosticsTopic  `json:"topics"`
+
+	// LagHistory contains the lag samples of the last hour, it's empty if the lag sampler is disabled
+	IsLagHistoryAvailable bool            `json:"isLagHistoryAvailable"`
+	LagHistory            []*LagSample    `json:"lagHistory"`
+	Anomalies             []*GroupAnomaly `json:"anomalies"`
+}
+
+// GroupDiagnosticsTopic is the committed offset, water marks, lag and owner of every partition of a topic which
+// the group has committed offsets for
+type GroupDiagnosticsTopic struct {
+	Topic      string                `json:"topic"`
+	SummedLag  int64                 `json:"summedLag"`
+	Partitions []*PartitionLagDetail `json:"partitions"`
+}
+
+// GroupAnomaly is a partition which requires attention
+type GroupAnomaly struct {
+	Type        string `json:"type"`
+	Topic       string `json:"topic"`
+	PartitionID int32  `json:"partitionId"`
+	Description string `json:"description"`
+}
+
+// ExportGroupDiagnostics returns a JSON document with the group's state, members and their assignments, the
+// committed offsets, water marks and lag of all partitions, the recent lag history, detected anomalies and the
+// group's coordinator.
+func (s *Service) ExportGroupDiagnostics(ctx context.Context, groupID string) ([]byte, error) {
+	description, err := s.describeConsumerGroup(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+	members, err := s.convertGroupMembers(description.Members, description.ProtocolType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert group members: %w", err)
+	}
+
+	coordinator, err := s.kafkaSvc.Client.Coordinator(groupID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get group coordinator: %w", err)
+	}
+
+	offsets, err := s.kafkaSvc.ListConsumerGroupOffsets(groupID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list consumer group offsets: %w", err)
+	}
+	partitionDetails := make(map[string][]*PartitionLagDetail)
+	for topicName, topicOffsets := range convertOffsets(offsets) {
+		partitionIDs, err := s.kafkaSvc.Client.Partitions(topicName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get partitions of topic '%v': %w", topicName, err)
+		}
+		waterMarks, err := s.kafkaSvc.WaterMarks(topicName, partitionIDs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get water marks of topic '%v': %w", topicName, err)
+		}
+		partitionDetails[topicName] = newPartitionLagDetails(topicName, waterMarks, topicOffsets, members)
+	}
+
+	now := time.Now()
+	var samples []*LagSample
+	if s.lagHistory != nil {
+		samples = s.lagHistory.get(groupID, now.Add(-diagnosticsLagHistoryWindow))
+	}
+	broker := &Broker{BrokerID: coordinator.ID(), Address: coordinator.Addr(), Rack: coordinator.Rack()}
+	diagnostics := newGroupDiagnostics(description, members, broker, partitionDetails, samples, s.lagHistory != nil, now)
+
+	out, err := json.MarshalIndent(diagnostics, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal group diagnostics: %w", err)
+	}
+
+	return out, nil
+}
+
+func newGroupDiagnostics(description *sarama.GroupDescription, members []*GroupMemberDescription, coordinator *Broker, partitionDetails map[string][]*PartitionLagDetail, samples []*LagSample, isHistoryAvailable bool, now time.Time) *GroupDiagnostics {
+	diagnostics := &GroupDiagnostics{
+		GroupID:               description.GroupId,
+		GeneratedAt:           now,
+		State:                 description.State,
+		ProtocolType:          description.ProtocolType,
+		Protocol:              description.Protocol,
+		Coordinator:           coordinator,
+		Members:               members,
+		IsLagHistoryAvailable: isHistoryAvailable,
+		LagHistory:            samples,
+	}
+	if diagnostics.LagHistory == nil {
+		diagnostics.LagHistory = make([]*LagSample, 0)
+	}
+	diagnostics.Topics, diagnostics.Anomalies = newGroupDiagnosticsTopics(partitionDetails, samples)
+
+	return diagnostics
+}
+
+// newGroupDiagnosticsTopics sorts the partition details by topic and detects the anomalies of all partitions. Stalled
+// partitions can only be detected if at least two lag samples have been recorded.
+func newGroupDiagnosticsTopics(partitionDetails map[string][]*PartitionLagDetail, samples []*LagSample) ([]*GroupDiagnosticsTopic, []*GroupAnomaly) {
+	var oldest *ConsumerGroupLag
+	if len(samples) >= 2 {
+		oldest = samples[0].Lag
+	}
+
+	topics := make([]*GroupDiagnosticsTopic, 0, len(partitionDetails))
+	anomalies := make([]*GroupAnomaly, 0)
+	for topicName, details := range partitionDetails {
+		topic := &GroupDiagnosticsTopic{Topic: topicName, Partitions: details}
+		var oldestTopicLag *TopicLag
+		if oldest != nil {
+			oldestTopicLag = oldest.GetTopicLag(topicName)
+		}
+
+		for _, d := range details {
+			topic.SummedLag += d.Lag
+			if !d.HasCommittedOffset {
+				continue
+			}
+			newAnomaly := func(anomalyType string, description string) {
+				anomalies = append(anomalies, &GroupAnomaly{Type: anomalyType, Topic: topicName, PartitionID: d.PartitionID, Description: description})
+			}
+
+			if d.CommittedOffset < d.LowWaterMark {
+				newAnomaly(GroupAnomalyBeyondRetention, fmt.Sprintf("committed offset %d is below the low water mark %d", d.CommittedOffset, d.LowWaterMark))
+			}
+			if d.Lag <= 0 {
+				continue
+			}
+			if d.MemberID == "" {
+				newAnomaly(GroupAnomalyUnowned, fmt.Sprintf("lag of %d but not assigned to any member", d.Lag))
+			}
+			if isPartitionStalled(oldestTopicLag, PartitionLag{PartitionID: d.PartitionID, CommittedOffset: d.CommittedOffset}) {
+				newAnomaly(GroupAnomalyStalled, fmt.Sprintf("lag of %d and the committed offset %d hasn't moved since %v", d.Lag, d.CommittedOffset, samples[0].Timestamp.Format(time.RFC3339)))
+			}
+		}
+		topics = append(topics, topic)
+	}
+	sort.Slice(topics, func(i, j int) bool { return topics[i].Topic < topics[j].Topic })
+	sort.SliceStable(anomalies, func(i, j int) bool {
+		if anomalies[i].Topic != anomalies[j].Topic {
+			return anomalies[i].Topic < anomalies[j].Topic
+		}
+		return anomalies[i].PartitionID < anomalies[j].PartitionID
+	})
+
+	return topics, anomalies
+}
@@ -0,0 +1,51 @@
+package owl
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewGroupDiagnostics(t *testing.T) {
+	now := time.Date(2020, 7, 1, 9, 0, 0, 0, time.UTC)
+	description := &sarama.GroupDescription{GroupId: "billing", State: "Stable", ProtocolType: "consumer", Protocol: "range"}
+	members := []*GroupMemberDescription{
+		{ID: "consumer-1", ClientID: "billing-1", Assignments: []*GroupMemberAssignment{{TopicName: "orders", PartitionIDs: []int32{0}}}},
+	}
+	partitionDetails := map[string][]*PartitionLagDetail{
+		"orders": {
+			{PartitionID: 0, HasCommittedOffset: true, CommittedOffset: 90, LowWaterMark: 0, HighWaterMark: 100, Lag: 10, MemberID: "consumer-1"},
+			{PartitionID: 1, HasCommittedOffset: true, CommittedOffset: 20, LowWaterMark: 50, HighWaterMark: 100, Lag: 80},
+		},
+	}
+	samples := []*LagSample{
+		{Timestamp: now.Add(-30 * time.Minute), Lag: &ConsumerGroupLag{GroupID: "billing", TopicLags: []*TopicLag{{
+			Topic:         "orders",
+			PartitionLags: []PartitionLag{{PartitionID: 0, CommittedOffset: 90}, {PartitionID: 1, CommittedOffset: 10}},
+		}}}},
+		{Timestamp: now.Add(-time.Minute), Lag: &ConsumerGroupLag{GroupID: "billing"}},
+	}
+
+	diagnostics := newGroupDiagnostics(description, members, &Broker{BrokerID: 2, Address: "broker-2:9092"}, partitionDetails, samples, true, now)
+	require.Len(t, diagnostics.Topics, 1)
+	assert.Equal(t, int64(90), diagnostics.Topics[0].SummedLag)
+
+	types := make([]string, len(diagnostics.Anomalies))
+	for i, anomaly := range diagnostics.Anomalies {
+		types[i] = anomaly.Type
+	}
+	assert.Equal(t, []string{GroupAnomalyStalled, GroupAnomalyBeyondRetention, GroupAnomalyUnowned}, types)
+
+	out, err := json.Marshal(diagnostics)
+	require.NoError(t, err)
+	var bundle map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal(out, &bundle))
+	for _, section := range []string{"groupId", "state", "coordinator", "members", "topics", "lagHistory", "anomalies"} {
+		assert.Contains(t, bundle, section)
+	}
+	assert.JSONEq(t, `{"brokerId":2,"logDirSize":0,"address":"broker-2:9092","rack":""}`, string(bundle["coordinator"]))
+}
@@ -0,0 +1,282 @@
+package owl
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/cloudhut/kowl/backend/pkg/kafka"
+	"go.uber.org/zap"
+)
+
+// Signals which are combined into a consumer group's health score
+const (
+	HealthSignalLag               = "lag"
+	HealthSignalTrend             = "trend"
+	HealthSignalStalledPartitions = "stalledPartitions"
+	HealthSignalRebalances        = "rebalances"
+	HealthSignalRetention         = "retention"
+)
+
+// HealthScoreConfig for combining multiple lag signals into a single health score per consumer group
+type HealthScoreConfig struct {
+	// LagThreshold is the summed lag (and the lag growth within the window) at which the lag (and trend) signal
+	// deducts its full weight
+	LagThreshold int64 `yaml:"lagThreshold"`
+	// RebalanceThreshold is the number of rebalances within the window at which the rebalance signal deducts its
+	// full weight
+	RebalanceThreshold int `yaml:"rebalanceThreshold"`
+	// Window of the lag history which is considered for the trend, stalled partitions and rebalances
+	Window  time.Duration      `yaml:"window"`
+	Weights HealthScoreWeights `yaml:"weights"`
+}
+
+// HealthScoreWeights are the relative weights of the health signals, they don't have to sum up to 100
+type HealthScoreWeights struct {
+	Lag               float64 `yaml:"lag"`
+	Trend             float64 `yaml:"trend"`
+	StalledPartitions float64 `yaml:"stalledPartitions"` // Lagging partitions which are stalled or not assigned
+	Rebalances        float64 `yaml:"rebalances"`
+	Retention         float64 `yaml:"retention"` // Lag relative to the retained messages
+}
+
+// SetDefaults for the health score config
+func (c *HealthScoreConfig) SetDefaults() {
+	c.LagThreshold = 10000
+	c.RebalanceThreshold = 3
+	c.Window = 30 * time.Minute
+	c.Weights = HealthScoreWeights{
+		Lag:               30,
+		Trend:             15,
+		StalledPartitions: 20,
+		Rebalances:        15,
+		Retention:         20,
+	}
+}
+
+// Validate the health score config
+func (c *HealthScoreConfig) Validate() error {
+	if c.LagThreshold <= 0 {
+		return fmt.Errorf("lag threshold must be greater than 0")
+	}
+	if c.RebalanceThreshold <= 0 {
+		return fmt.Errorf("rebalance threshold must be greater than 0")
+	}
+	if c.Window <= 0 {
+		return fmt.Errorf("window must be greater than 0")
+	}
+
+	w := c.Weights
+	if w.Lag < 0 || w.Trend < 0 || w.StalledPartitions < 0 || w.Rebalances < 0 || w.Retention < 0 {
+		return fmt.Errorf("weights must not be negative")
+	}
+	if w.Lag+w.Trend+w.StalledPartitions+w.Rebalances+w.Retention == 0 {
+		return fmt.Errorf("at least one weight must be greater than 0")
+	}
+
+	return nil
+}
+
+// ConsumerGroupHealth is a single 0-100 health score of a consumer group (100 is perfectly healthy) along with the
+// factors which have reduced it
+type ConsumerGroupHealth struct {
+	GroupID string `json:"groupId"`
+	Score   int    `json:"score"`
+	// IsHistoryAvailable is false if the lag sampler is disabled, in which case the trend, stalled partitions and
+	// rebalance signals can't be evaluated and the score is based on the remaining signals only
+	IsHistoryAvailable bool            `json:"isHistoryAvailable"`
+	Factors            []*HealthFactor `json:"factors"` // Biggest contribution first
+}
+
+// HealthFactor is a signal which has reduced the health score
+type HealthFactor struct {
+	Signal      string  `json:"signal"`
+	Severity    float64 `json:"severity"` // 0-1, how bad the signal is regardless of its weight
+	Points      float64 `json:"points"`   // Points which have been deducted from the score
+	Description string  `json:"description"`
+}
+
+// groupHealthSignals are the raw inputs of a group's health score
+type groupHealthSignals struct {
+	SummedLag          int64
+	IsHistoryAvailable bool
+	LagGrowth          int64 // Summed lag now minus the summed lag of the oldest sample within the window
+	LaggingPartitions  int   // Partitions with a lag greater than 0
+	StalledPartitions  int   // Lagging partitions which are not assigned or whose offset hasn't moved
+	Rebalances         int
+	MaxRetainedRatio   float64 // Highest lag relative to a partition's retained messages, >= 1 if data has been lost
+}
+
+// GetConsumerGroupHealthScore combines the group's lag, lag trend, stalled or unassigned partitions, rebalance
+// frequency and lag relative to retention into a single score, weighted as configured.
+func (s *Service) GetConsumerGroupHealthScore(ctx context.Context, groupID string) (*ConsumerGroupHealth, error) {
+	description, err := s.describeConsumerGroup(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+	members, err := s.convertGroupMembers(description.Members, description.ProtocolType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert group members: %w", err)
+	}
+	assigned := assignedPartitions(members)
+
+	lags, err := s.getConsumerGroupLags(ctx, []string{groupID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get consumer group lag: %w", err)
+	}
+	lag, exists := lags[groupID]
+	if !exists {
+		lag = &ConsumerGroupLag{GroupID: groupID, TopicLags: []*TopicLag{}}
+	}
+
+	waterMarks := make(map[string]map[int32]*kafka.WaterMark, len(lag.TopicLags))
+	for _, topicLag := range lag.TopicLags {
+		partitionIDs := make([]int32, len(topicLag.PartitionLags))
+		for i, partitionLag := range topicLag.PartitionLags {
+			partitionIDs[i] = partitionLag.PartitionID
+		}
+		waterMarks[topicLag.Topic], err = s.kafkaSvc.WaterMarks(topicLag.Topic, partitionIDs)
+		if err != nil {
+			// The retention signal is skipped for this topic
+			s.logger.Debug("failed to get water marks for health score", zap.String("topic", topicLag.Topic), zap.Error(err))
+		}
+	}
+
+	var samples []*LagSample
+	if s.lagHistory != nil {
+		samples = s.lagHistory.get(groupID, time.Now().Add(-s.healthScore.Window))
+	}
+	signals := newGroupHealthSignals(lag, assigned, waterMarks, samples, s.lagHistory != nil)
+
+	return scoreGroupHealth(groupID, signals, s.healthScore), nil
+}
+
+func newGroupHealthSignals(lag *ConsumerGroupLag, assigned map[string]map[int32]struct{}, waterMarks map[string]map[int32]*kafka.WaterMark, samples []*LagSample, isHistoryAvailable bool) groupHealthSignals {
+	signals := groupHealthSignals{
+		SummedLag:          summedGroupLag(lag),
+		IsHistoryAvailable: isHistoryAvailable,
+		Rebalances:         len(detectRebalanceImpacts(samples, defaultRebalanceSettleWindow)),
+	}
+
+	var oldest *ConsumerGroupLag
+	if len(samples) > 0 && samples[0].Lag != nil {
+		oldest = samples[0].Lag
+		signals.LagGrowth = signals.SummedLag - summedGroupLag(oldest)
+	}
+
+	for _, topicLag := range lag.TopicLags {
+		var oldestTopicLag *TopicLag
+		if oldest != nil {
+			oldestTopicLag = oldest.GetTopicLag(topicLag.Topic)
+		}
+		for _, partitionLag := range topicLag.PartitionLags {
+			if partitionLag.Lag <= 0 {
+				continue
+			}
+			signals.LaggingPartitions++
+
+			_, isAssigned := assigned[topicLag.Topic][partitionLag.PartitionID]
+			if !isAssigned || isPartitionStalled(oldestTopicLag, partitionLag) {
+				signals.StalledPartitions++
+			}
+
+			if waterMark, exists := waterMarks[topicLag.Topic][partitionLag.PartitionID]; exists {
+				retainedRange := waterMark.High - waterMark.Low
+				if retainedRange > 0 {
+					signals.MaxRetainedRatio = math.Max(signals.MaxRetainedRatio, float64(partitionLag.Lag)/float64(retainedRange))
+				}
+			}
+		}
+	}
+
+	return signals
+}
+
+// isPartitionStalled returns true if the partition's committed offset hasn't moved since the oldest sample
+func isPartitionStalled(oldest *TopicLag, current PartitionLag) bool {
+	if oldest == nil {
+		return false
+	}
+	for _, partitionLag := range oldest.PartitionLags {
+		if partitionLag.PartitionID == current.PartitionID {
+			return partitionLag.CommittedOffset == current.CommittedOffset
+		}
+	}
+	return false
+}
+
+func scoreGroupHealth(groupID string, signals groupHealthSignals, cfg HealthScoreConfig) *ConsumerGroupHealth {
+	type weightedFactor struct {
+		factor *HealthFactor
+		weight float64
+	}
+	factors := []weightedFactor{
+		{weight: cfg.Weights.Lag, factor: &HealthFactor{
+			Signal:      HealthSignalLag,
+			Severity:    boundedRatio(signals.SummedLag, cfg.LagThreshold),
+			Description: fmt.Sprintf("summed lag of %d is %.0f%% of the threshold of %d", signals.SummedLag, 100*float64(signals.SummedLag)/float64(cfg.LagThreshold), cfg.LagThreshold),
+		}},
+		{weight: cfg.Weights.Retention, factor: &HealthFactor{
+			Signal:      HealthSignalRetention,
+			Severity:    math.Min(signals.MaxRetainedRatio, 1),
+			Description: fmt.Sprintf("lag reaches %.0f%% of a partition's retained messages", 100*signals.MaxRetainedRatio),
+		}},
+	}
+	if signals.MaxRetainedRatio >= 1 {
+		factors[1].factor.Description = "unconsumed messages have already been deleted by retention"
+	}
+	if signals.IsHistoryAvailable {
+		stalledSeverity := 0.0
+		if signals.LaggingPartitions > 0 {
+			stalledSeverity = float64(signals.StalledPartitions) / float64(signals.LaggingPartitions)
+		}
+		factors = append(factors,
+			weightedFactor{weight: cfg.Weights.Trend, factor: &HealthFactor{
+				Signal:      HealthSignalTrend,
+				Severity:    boundedRatio(signals.LagGrowth, cfg.LagThreshold),
+				Description: fmt.Sprintf("lag has grown by %d within %v", signals.LagGrowth, cfg.Window),
+			}},
+			weightedFactor{weight: cfg.Weights.StalledPartitions, factor: &HealthFactor{
+				Signal:      HealthSignalStalledPartitions,
+				Severity:    stalledSeverity,
+				Description: fmt.Sprintf("%d of %d lagging partitions are stalled or not assigned", signals.StalledPartitions, signals.LaggingPartitions),
+			}},
+			weightedFactor{weight: cfg.Weights.Rebalances, factor: &HealthFactor{
+				Signal:      HealthSignalRebalances,
+				Severity:    boundedRatio(int64(signals.Rebalances), int64(cfg.RebalanceThreshold)),
+				Description: fmt.Sprintf("%d rebalances within %v", signals.Rebalances, cfg.Window),
+			}},
+		)
+	}
+
+	// Signals which can't be evaluated don't count, so that the score is relative to the available weights
+	var totalWeight float64
+	for _, f := range factors {
+		totalWeight += f.weight
+	}
+
+	res := &ConsumerGroupHealth{GroupID: groupID, IsHistoryAvailable: signals.IsHistoryAvailable, Factors: make([]*HealthFactor, 0)}
+	deducted := 0.0
+	for _, f := range factors {
+		if totalWeight == 0 || f.factor.Severity <= 0 || f.weight <= 0 {
+			continue
+		}
+		f.factor.Points = 100 * f.weight * f.factor.Severity / totalWeight
+		deducted += f.factor.Points
+		res.Factors = append(res.Factors, f.factor)
+	}
+	res.Score = int(math.Round(math.Max(0, 100-deducted)))
+	sort.SliceStable(res.Factors, func(i, j int) bool { return res.Factors[i].Points > res.Factors[j].Points })
+
+	return res
+}
+
+// boundedRatio returns value / threshold bounded to 0-1
+func boundedRatio(value int64, threshold int64) float64 {
+	if value <= 0 || threshold <= 0 {
+		return 0
+	}
+	return math.Min(float64(value)/float64(threshold), 1)
+}
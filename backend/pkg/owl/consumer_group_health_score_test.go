@@ -0,0 +1,73 @@
+package owl
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cloudhut/kowl/backend/pkg/kafka"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScoreGroupHealth(t *testing.T) {
+	cfg := HealthScoreConfig{}
+	cfg.SetDefaults()
+	require.NoError(t, cfg.Validate())
+
+	start := time.Date(2020, 7, 1, 8, 0, 0, 0, time.UTC)
+	newLag := func(committed0, committed1, lag0, lag1 int64, fingerprint string) *ConsumerGroupLag {
+		return &ConsumerGroupLag{
+			GroupID: "billing",
+			TopicLags: []*TopicLag{{
+				Topic:     "orders",
+				SummedLag: lag0 + lag1,
+				PartitionLags: []PartitionLag{
+					{PartitionID: 0, Lag: lag0, CommittedOffset: committed0},
+					{PartitionID: 1, Lag: lag1, CommittedOffset: committed1},
+				},
+			}},
+			MembershipFingerprint: fingerprint,
+		}
+	}
+	// The group rebalanced three times within the window while its lag grew by 2000
+	samples := []*LagSample{
+		{Timestamp: start, Lag: newLag(500, 1500, 10000, 8000, "a")},
+		{Timestamp: start.Add(5 * time.Minute), Lag: newLag(600, 1600, 11000, 8000, "b")},
+		{Timestamp: start.Add(10 * time.Minute), Lag: newLag(800, 1800, 11500, 8000, "c")},
+		{Timestamp: start.Add(15 * time.Minute), Lag: newLag(1000, 2000, 12000, 8000, "d")},
+	}
+	current := newLag(1000, 2000, 12000, 8000, "")
+	assigned := map[string]map[int32]struct{}{"orders": {0: {}, 1: {}}}
+	waterMarks := map[string]map[int32]*kafka.WaterMark{
+		"orders": {0: {Low: 0, High: 100000}, 1: {Low: 0, High: 100000}},
+	}
+
+	signals := newGroupHealthSignals(current, assigned, waterMarks, samples, true)
+	assert.Equal(t, groupHealthSignals{
+		SummedLag:          20000,
+		IsHistoryAvailable: true,
+		LagGrowth:          2000,
+		LaggingPartitions:  2,
+		Rebalances:         3,
+		MaxRetainedRatio:   0.12,
+	}, signals)
+
+	health := scoreGroupHealth("billing", signals, cfg)
+	assert.Equal(t, 50, health.Score)
+	require.Len(t, health.Factors, 4)
+	assert.Equal(t, HealthSignalLag, health.Factors[0].Signal)
+	assert.InDelta(t, 30, health.Factors[0].Points, 0.001)
+	assert.Equal(t, HealthSignalRebalances, health.Factors[1].Signal)
+	assert.InDelta(t, 15, health.Factors[1].Points, 0.001)
+	assert.Equal(t, "3 rebalances within 30m0s", health.Factors[1].Description)
+	assert.Equal(t, HealthSignalTrend, health.Factors[2].Signal)
+	assert.InDelta(t, 3, health.Factors[2].Points, 0.001)
+	assert.Equal(t, HealthSignalRetention, health.Factors[3].Signal)
+	assert.InDelta(t, 2.4, health.Factors[3].Points, 0.001)
+
+	// Without the lag history the score is relative to the lag and retention weights only
+	signals.IsHistoryAvailable = false
+	health = scoreGroupHealth("billing", signals, cfg)
+	assert.Equal(t, 35, health.Score)
+	assert.Len(t, health.Factors, 2)
+}
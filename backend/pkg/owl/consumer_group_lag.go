@@ -3,6 +3,7 @@ package owl
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/Shopify/sarama"
 	"go.uber.org/zap"
@@ -14,6 +15,10 @@ type partitionOffsets map[int32]int64
 type ConsumerGroupLag struct {
 	GroupID   string      `json:"groupId"`
 	TopicLags []*TopicLag `json:"topicLags"`
+
+	// MembershipFingerprint identifies the group's members along with their assignments. It's only set by the lag
+	// sampler, a changed fingerprint between two samples indicates that the group has rebalanced.
+	MembershipFingerprint string `json:"membershipFingerprint,omitempty"`
 }
 
 // GetTopicLag returns the group's topic lag or nil if the group has no group offsets on that topic
@@ -34,12 +39,33 @@ type TopicLag struct {
 	PartitionCount       int            `json:"partitionCount"`
 	PartitionsWithOffset int            `json:"partitionsWithOffset"` // Number of partitions which have an active group offset
 	PartitionLags        []PartitionLag `json:"partitionLags"`
+
+	// DominantPartitionIDs is the smallest set of partitions which together account for the majority of the lag
+	DominantPartitionIDs []int32 `json:"dominantPartitionIds"`
+
+	// EstimatedLagBytes is a rough approximation of the bytes the group still has to consume. It's only set
+	// by EstimateConsumerGroupLagBytes.
+	EstimatedLagBytes int64 `json:"estimatedLagBytes,omitempty"`
+
+	// ProduceRate is the topic's current produce rate in messages per second, so that the lag can be put into
+	// context. It's only set if the lag history has recent samples of the group, 0 if nothing has been produced
+	// recently.
+	ProduceRate *float64 `json:"produceRate,omitempty"`
 }
 
 // PartitionLag describes the kafka lag for a partition for a single consumer group
 type PartitionLag struct {
-	PartitionID int32 `json:"partitionId"`
-	Lag         int64 `json:"lag"`
+	PartitionID     int32 `json:"partitionId"`
+	Lag             int64 `json:"lag"`
+	HighWaterMark   int64 `json:"highWaterMark"`
+	CommittedOffset int64 `json:"committedOffset"`
+
+	// LagShare is the partition's lag relative to the topic's summed lag (0-1)
+	LagShare float64 `json:"lagShare"`
+
+	// TimeUntilDataLoss is the projected time until retention deletes the committed offset, nil if not at risk.
+	// It's only set by EstimateConsumerGroupDataLoss.
+	TimeUntilDataLoss *time.Duration `json:"timeUntilDataLoss,omitempty"`
 }
 
 // convertOffsets returns a map where the key is the topic name
@@ -90,7 +116,7 @@ func (s *Service) getConsumerGroupLags(ctx context.Context, groups []string) (ma
 		topicPartitions[topic] = partitions
 	}
 
-	waterMarks, err := s.kafkaSvc.HighWaterMarks(topicPartitions)
+	waterMarks, err := s.highWaterMarks(topicPartitions)
 	if err != nil {
 		return nil, err
 	}
@@ -131,8 +157,14 @@ func (s *Service) getConsumerGroupLags(ctx context.Context, groups []string) (ma
 					lag = 0
 				}
 				t.SummedLag += lag
-				t.PartitionLags = append(t.PartitionLags, PartitionLag{PartitionID: pID, Lag: lag})
+				t.PartitionLags = append(t.PartitionLags, PartitionLag{
+					PartitionID:     pID,
+					Lag:             lag,
+					HighWaterMark:   watermark,
+					CommittedOffset: groupOffset,
+				})
 			}
+			t.computeLagShares()
 			topicLags = append(topicLags, &t)
 		}
 
@@ -0,0 +1,101 @@
+package owl
+
+import (
+	"context"
+	"time"
+)
+
+// Names of the lag age buckets, ordered from least to most stale
+const (
+	LagAgeBucketCaughtUp = "caughtUp"
+	LagAgeBucketUnder1m  = "<1m"
+	LagAgeBucket1mTo5m   = "1m-5m"
+	LagAgeBucket5mTo30m  = "5m-30m"
+	LagAgeBucketOver30m  = ">30m"
+)
+
+// lagAgeBucketUnbounded is the upper bound of the most stale bucket
+const lagAgeBucketUnbounded = time.Duration(0)
+
+var lagAgeBuckets = []struct {
+	name       string
+	upperBound time.Duration // Exclusive, lagAgeBucketUnbounded for the last bucket
+}{
+	{LagAgeBucketUnder1m, time.Minute},
+	{LagAgeBucket1mTo5m, 5 * time.Minute},
+	{LagAgeBucket5mTo30m, 30 * time.Minute},
+	{LagAgeBucketOver30m, lagAgeBucketUnbounded},
+}
+
+// ConsumerGroupLagAgeDistribution is a histogram of how far in time a group is behind across all its partitions
+type ConsumerGroupLagAgeDistribution struct {
+	GroupID        string `json:"groupId"`
+	PartitionCount int    `json:"partitionCount"`
+	// IsApproximate is true if at least one topic uses producer assigned (CreateTime) timestamps
+	IsApproximate bool            `json:"isApproximate"`
+	Buckets       []*LagAgeBucket `json:"buckets"` // Ordered from caught up to most stale
+}
+
+// LagAgeBucket is the number of partitions whose time lag falls into the bucket's range
+type LagAgeBucket struct {
+	Name           string  `json:"name"`
+	PartitionCount int     `json:"partitionCount"`
+	Share          float64 `json:"share"` // Share of the group's partitions in this bucket (0-1)
+}
+
+// GetConsumerGroupLagAgeDistribution buckets all partitions of a group by their time lag, which gives a quick sense
+// of how uniformly stale a group is. Partitions without lag are counted as caught up.
+func (s *Service) GetConsumerGroupLagAgeDistribution(ctx context.Context, groupID string) (*ConsumerGroupLagAgeDistribution, error) {
+	timeLags, err := s.GetConsumerGroupTimeLag(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	return newLagAgeDistribution(groupID, timeLags), nil
+}
+
+func newLagAgeDistribution(groupID string, timeLags []*TopicTimeLag) *ConsumerGroupLagAgeDistribution {
+	res := &ConsumerGroupLagAgeDistribution{
+		GroupID: groupID,
+		Buckets: make([]*LagAgeBucket, 0, len(lagAgeBuckets)+1),
+	}
+	res.Buckets = append(res.Buckets, &LagAgeBucket{Name: LagAgeBucketCaughtUp})
+	for _, bucket := range lagAgeBuckets {
+		res.Buckets = append(res.Buckets, &LagAgeBucket{Name: bucket.name})
+	}
+
+	for _, topic := range timeLags {
+		if topic.IsApproximate {
+			res.IsApproximate = true
+		}
+		for _, partition := range topic.Partitions {
+			res.PartitionCount++
+			res.Buckets[lagAgeBucketIndex(partition)].PartitionCount++
+		}
+	}
+
+	if res.PartitionCount > 0 {
+		for _, bucket := range res.Buckets {
+			bucket.Share = float64(bucket.PartitionCount) / float64(res.PartitionCount)
+		}
+	}
+
+	return res
+}
+
+// lagAgeBucketIndex returns the index of the bucket the partition belongs to, the caught up bucket has index 0.
+// Lagging partitions whose time lag is unknown or not positive (e.g. producer clocks ahead of ours) are counted in
+// the first non caught up bucket.
+func lagAgeBucketIndex(partition *PartitionTimeLag) int {
+	if partition.Lag <= 0 {
+		return 0
+	}
+
+	timeLag := time.Duration(partition.TimeLagMs) * time.Millisecond
+	for i, bucket := range lagAgeBuckets {
+		if bucket.upperBound == lagAgeBucketUnbounded || timeLag < bucket.upperBound {
+			return i + 1
+		}
+	}
+	return len(lagAgeBuckets)
+}
@@ -0,0 +1,63 @@
+package owl
+
+import (
+	"testing"
+
+	"github.com/cloudhut/kowl/backend/pkg/kafka"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewLagAgeDistribution(t *testing.T) {
+	timeLags := []*TopicTimeLag{
+		{
+			Topic:         "orders",
+			TimestampType: kafka.TimestampTypeLogAppendTime,
+			Partitions: []*PartitionTimeLag{
+				{PartitionID: 0, Lag: 0},
+				{PartitionID: 1, Lag: 3, TimeLagMs: 59 * 1000},
+				{PartitionID: 2, Lag: 10, TimeLagMs: 60 * 1000},
+				{PartitionID: 3, Lag: 25, TimeLagMs: 5 * 60 * 1000},
+			},
+		},
+		{
+			Topic:         "payments",
+			TimestampType: kafka.TimestampTypeCreateTime,
+			IsApproximate: true,
+			Partitions: []*PartitionTimeLag{
+				{PartitionID: 0, Lag: 0},
+				{PartitionID: 1, Lag: 400, TimeLagMs: 2 * 60 * 60 * 1000},
+				{PartitionID: 2, Lag: 5, TimeLagMs: 0}, // Producer clock ahead of ours
+				{PartitionID: 3, Lag: 80, TimeLagMs: 30 * 60 * 1000},
+			},
+		},
+	}
+
+	distribution := newLagAgeDistribution("billing", timeLags)
+	assert.Equal(t, "billing", distribution.GroupID)
+	assert.Equal(t, 8, distribution.PartitionCount)
+	assert.True(t, distribution.IsApproximate)
+
+	require.Len(t, distribution.Buckets, 5)
+	counts := make(map[string]int)
+	for _, bucket := range distribution.Buckets {
+		counts[bucket.Name] = bucket.PartitionCount
+	}
+	assert.Equal(t, map[string]int{
+		LagAgeBucketCaughtUp: 2,
+		LagAgeBucketUnder1m:  2,
+		LagAgeBucket1mTo5m:   1,
+		LagAgeBucket5mTo30m:  1,
+		LagAgeBucketOver30m:  2,
+	}, counts)
+	assert.Equal(t, LagAgeBucketCaughtUp, distribution.Buckets[0].Name)
+	assert.Equal(t, 0.25, distribution.Buckets[0].Share)
+}
+
+func TestNewLagAgeDistribution_NoPartitions(t *testing.T) {
+	distribution := newLagAgeDistribution("billing", []*TopicTimeLag{})
+	assert.Equal(t, 0, distribution.PartitionCount)
+	for _, bucket := range distribution.Buckets {
+		assert.Equal(t, 0.0, bucket.Share)
+	}
+}
@@ -0,0 +1,125 @@
+package owl
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"go.uber.org/zap"
+)
+
+// ConsumerGroupLagByAssignment breaks down a consumer group's lag into the lag of partitions which are assigned to
+// a member (being drained) and the lag of partitions which are subscribed but currently not assigned to any member,
+// e.g. while the group is rebalancing or has less members than required.
+type ConsumerGroupLagByAssignment struct {
+	GroupID       string                  `json:"groupId"`
+	TotalLag      int64                   `json:"totalLag"` // Sum of AssignedLag and UnassignedLag
+	AssignedLag   int64                   `json:"assignedLag"`
+	UnassignedLag int64                   `json:"unassignedLag"`
+	Topics        []*TopicLagByAssignment `json:"topics"`
+}
+
+// TopicLagByAssignment is the lag breakdown of a single subscribed topic
+type TopicLagByAssignment struct {
+	Topic                  string  `json:"topic"`
+	AssignedLag            int64   `json:"assignedLag"`
+	UnassignedLag          int64   `json:"unassignedLag"`
+	AssignedPartitionIDs   []int32 `json:"assignedPartitionIds"`
+	UnassignedPartitionIDs []int32 `json:"unassignedPartitionIds"`
+}
+
+// GetConsumerGroupLagByAssignment returns the group's lag broken down by assigned and unassigned partitions. Only
+// topics which are subscribed by at least one member are considered. Partitions without a committed offset
+// contribute no lag.
+func (s *Service) GetConsumerGroupLagByAssignment(ctx context.Context, groupID string) (*ConsumerGroupLagByAssignment, error) {
+	description, err := s.describeConsumerGroup(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	subscribedTopics := make(map[string]struct{})
+	assigned := make(map[string]map[int32]struct{})
+	for _, member := range description.Members {
+		metadata, err := member.GetMemberMetadata()
+		if err != nil || metadata == nil {
+			s.logger.Warn("failed to decode member metadata", zap.String("client_id", member.ClientId), zap.Error(err))
+			continue
+		}
+		for _, topic := range metadata.Topics {
+			subscribedTopics[topic] = struct{}{}
+		}
+
+		assignment, err := member.GetMemberAssignment()
+		if err != nil || assignment == nil {
+			s.logger.Warn("failed to decode member assignments", zap.String("client_id", member.ClientId), zap.Error(err))
+			continue
+		}
+		for topic, partitionIDs := range assignment.Topics {
+			if _, exists := assigned[topic]; !exists {
+				assigned[topic] = make(map[int32]struct{})
+			}
+			for _, partitionID := range partitionIDs {
+				assigned[topic][partitionID] = struct{}{}
+			}
+		}
+	}
+
+	subscribed := make(map[string][]int32, len(subscribedTopics))
+	for topic := range subscribedTopics {
+		partitionIDs, err := s.kafkaSvc.Client.Partitions(topic)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get partitions of topic '%v': %w", topic, err)
+		}
+		subscribed[topic] = partitionIDs
+	}
+
+	lags, err := s.getConsumerGroupLags(ctx, []string{groupID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get consumer group lag: %w", err)
+	}
+
+	return newConsumerGroupLagByAssignment(groupID, subscribed, assigned, lags[groupID]), nil
+}
+
+func newConsumerGroupLagByAssignment(groupID string, subscribed map[string][]int32, assigned map[string]map[int32]struct{}, lag *ConsumerGroupLag) *ConsumerGroupLagByAssignment {
+	res := &ConsumerGroupLagByAssignment{
+		GroupID: groupID,
+		Topics:  make([]*TopicLagByAssignment, 0, len(subscribed)),
+	}
+
+	for topic, partitionIDs := range subscribed {
+		partitionLags := make(map[int32]int64)
+		if lag != nil {
+			if topicLag := lag.GetTopicLag(topic); topicLag != nil {
+				for _, partitionLag := range topicLag.PartitionLags {
+					partitionLags[partitionLag.PartitionID] = partitionLag.Lag
+				}
+			}
+		}
+
+		t := &TopicLagByAssignment{
+			Topic:                  topic,
+			AssignedPartitionIDs:   make([]int32, 0),
+			UnassignedPartitionIDs: make([]int32, 0),
+		}
+		for _, partitionID := range partitionIDs {
+			if _, isAssigned := assigned[topic][partitionID]; isAssigned {
+				t.AssignedLag += partitionLags[partitionID]
+				t.AssignedPartitionIDs = append(t.AssignedPartitionIDs, partitionID)
+			} else {
+				t.UnassignedLag += partitionLags[partitionID]
+				t.UnassignedPartitionIDs = append(t.UnassignedPartitionIDs, partitionID)
+			}
+		}
+		sort.Slice(t.AssignedPartitionIDs, func(i, j int) bool { return t.AssignedPartitionIDs[i] < t.AssignedPartitionIDs[j] })
+		sort.Slice(t.UnassignedPartitionIDs, func(i, j int) bool { return t.UnassignedPartitionIDs[i] < t.UnassignedPartitionIDs[j] })
+
+		res.AssignedLag += t.AssignedLag
+		res.UnassignedLag += t.UnassignedLag
+		res.Topics = append(res.Topics, t)
+	}
+	res.TotalLag = res.AssignedLag + res.UnassignedLag
+	sort.Slice(res.Topics, func(i, j int) bool { return res.Topics[i].Topic < res.Topics[j].Topic })
+
+	return res
+}
@@ -0,0 +1,49 @@
+package owl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewConsumerGroupLagByAssignment(t *testing.T) {
+	subscribed := map[string][]int32{
+		"orders":   {0, 1, 2, 3},
+		"payments": {0, 1},
+	}
+	// Partitions 2 and 3 of orders have not been reassigned yet after a member left the group
+	assigned := map[string]map[int32]struct{}{
+		"orders":   {0: {}, 1: {}},
+		"payments": {0: {}, 1: {}},
+	}
+	lag := &ConsumerGroupLag{
+		GroupID: "billing",
+		TopicLags: []*TopicLag{
+			{Topic: "orders", PartitionLags: []PartitionLag{
+				{PartitionID: 0, Lag: 10},
+				{PartitionID: 1, Lag: 20},
+				{PartitionID: 2, Lag: 300},
+				{PartitionID: 3, Lag: 400},
+			}},
+			// Partition 1 has no committed offset yet
+			{Topic: "payments", PartitionLags: []PartitionLag{{PartitionID: 0, Lag: 5}}},
+		},
+	}
+
+	res := newConsumerGroupLagByAssignment("billing", subscribed, assigned, lag)
+	assert.Equal(t, int64(35), res.AssignedLag)
+	assert.Equal(t, int64(700), res.UnassignedLag)
+	assert.Equal(t, res.AssignedLag+res.UnassignedLag, res.TotalLag)
+
+	require.Len(t, res.Topics, 2)
+	assert.Equal(t, &TopicLagByAssignment{
+		Topic:                  "orders",
+		AssignedLag:            30,
+		UnassignedLag:          700,
+		AssignedPartitionIDs:   []int32{0, 1},
+		UnassignedPartitionIDs: []int32{2, 3},
+	}, res.Topics[0])
+	assert.Equal(t, int64(5), res.Topics[1].AssignedLag)
+	assert.Empty(t, res.Topics[1].UnassignedPartitionIDs)
+}
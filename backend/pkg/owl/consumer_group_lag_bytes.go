@@ -0,0 +1,63 @@
+package owl
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cloudhut/kowl/backend/pkg/kafka"
+)
+
+// EstimateConsumerGroupLagBytes returns the group's lag along with an estimate of the on-disk bytes the group
+// still has to consume. The estimate is based on the average message size of each topic, which is derived
+// from the partitions' log dir size divided by their message count. It is only an approximation, because
+// message sizes vary and compacted or deleted records are not accounted for.
+func (s *Service) EstimateConsumerGroupLagBytes(ctx context.Context, groupID string) (*ConsumerGroupLag, error) {
+	lags, err := s.getConsumerGroupLags(ctx, []string{groupID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get consumer group lags: %w", err)
+	}
+	lag, exists := lags[groupID]
+	if !exists {
+		return nil, fmt.Errorf("no lag has been returned for consumer group '%v'", groupID)
+	}
+
+	sizeByPartition, err := s.logDirSizeByPartition()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get log dir sizes: %w", err)
+	}
+
+	for _, topicLag := range lag.TopicLags {
+		partitionIDs := make([]int32, 0, len(sizeByPartition[topicLag.Topic]))
+		for pID := range sizeByPartition[topicLag.Topic] {
+			partitionIDs = append(partitionIDs, pID)
+		}
+
+		waterMarks, err := s.kafkaSvc.WaterMarks(topicLag.Topic, partitionIDs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get water marks for topic '%v': %w", topicLag.Topic, err)
+		}
+
+		avgSize := averageMessageSize(sizeByPartition[topicLag.Topic], waterMarks)
+		topicLag.EstimatedLagBytes = int64(avgSize * float64(topicLag.SummedLag))
+	}
+
+	return lag, nil
+}
+
+// averageMessageSize returns the average size in bytes of a single message across all given partitions
+func averageMessageSize(sizeByPartition map[int32]int64, waterMarks map[int32]*kafka.WaterMark) float64 {
+	var totalBytes, totalMessages int64
+	for pID, size := range sizeByPartition {
+		waterMark, exists := waterMarks[pID]
+		if !exists || waterMark.High <= waterMark.Low {
+			continue
+		}
+		totalBytes += size
+		totalMessages += waterMark.High - waterMark.Low
+	}
+	if totalMessages == 0 {
+		return 0
+	}
+
+	return float64(totalBytes) / float64(totalMessages)
+}
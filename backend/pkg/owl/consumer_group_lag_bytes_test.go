@@ -0,0 +1,24 @@
+package owl
+
+import (
+	"testing"
+
+	"github.com/cloudhut/kowl/backend/pkg/kafka"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAverageMessageSize(t *testing.T) {
+	sizes := map[int32]int64{0: 1000 * 2048, 1: 3000 * 2048, 2: 0}
+	waterMarks := map[int32]*kafka.WaterMark{
+		0: {PartitionID: 0, Low: 500, High: 1500},
+		1: {PartitionID: 1, Low: 0, High: 3000},
+		2: {PartitionID: 2, Low: 10, High: 10}, // Empty partition must not distort the average
+	}
+
+	avgSize := averageMessageSize(sizes, waterMarks)
+	assert.Equal(t, float64(2048), avgSize)
+
+	lag := &TopicLag{Topic: "orders", SummedLag: 20 * 1024 * 1024}
+	assert.Equal(t, int64(40*1024*1024*1024), int64(avgSize*float64(lag.SummedLag)))
+	assert.Equal(t, float64(0), averageMessageSize(sizes, nil))
+}
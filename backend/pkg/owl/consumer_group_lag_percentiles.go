@@ -0,0 +1,72 @@
+package owl
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// minLagPercentileSamples is the minimum number of lag samples within the window, below which the p95 and p99 would
+// merely be the maximum of a few samples
+const minLagPercentileSamples = 20
+
+// LagPercentiles characterizes the typical (p50) and worst case (p95, p99) summed lag of a consumer group over the
+// lag history window. A group which is usually fine but has rare spikes has a low p50 and a high p99, whereas a
+// group which is chronically behind has a high p50 already.
+type LagPercentiles struct {
+	GroupID     string    `json:"groupId"`
+	SampleCount int       `json:"sampleCount"`
+	Since       time.Time `json:"since"` // Timestamp of the oldest sample
+	P50         int64     `json:"p50"`
+	P95         int64     `json:"p95"`
+	P99         int64     `json:"p99"`
+	Max         int64     `json:"max"`
+}
+
+// GetConsumerGroupLagPercentiles computes the percentiles of the group's summed lag from the lag samples recorded
+// within the given window. All samples are weighted equally, because the lag sampler records them at a fixed
+// interval.
+func (s *Service) GetConsumerGroupLagPercentiles(groupID string, window time.Duration) (*LagPercentiles, error) {
+	if s.lagHistory == nil {
+		return nil, ErrLagHistoryDisabled
+	}
+
+	samples := s.lagHistory.get(groupID, time.Now().Add(-window))
+	if len(samples) < minLagPercentileSamples {
+		return nil, fmt.Errorf("at least %v lag samples are required within the window, but got %v",
+			minLagPercentileSamples, len(samples))
+	}
+
+	return computeLagPercentiles(groupID, samples), nil
+}
+
+func computeLagPercentiles(groupID string, samples []*LagSample) *LagPercentiles {
+	res := &LagPercentiles{GroupID: groupID, SampleCount: len(samples)}
+	if len(samples) == 0 {
+		return res
+	}
+
+	lags := make([]int64, len(samples))
+	for i, sample := range samples {
+		lags[i] = summedGroupLag(sample.Lag)
+	}
+	sort.Slice(lags, func(i, j int) bool { return lags[i] < lags[j] })
+
+	res.Since = samples[0].Timestamp
+	res.P50 = lagPercentile(lags, 50)
+	res.P95 = lagPercentile(lags, 95)
+	res.P99 = lagPercentile(lags, 99)
+	res.Max = lags[len(lags)-1]
+
+	return res
+}
+
+// lagPercentile returns the p-th percentile of the sorted lags using the nearest-rank method
+func lagPercentile(sorted []int64, p float64) int64 {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
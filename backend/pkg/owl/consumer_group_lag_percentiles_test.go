@@ -0,0 +1,35 @@
+package owl
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComputeLagPercentiles(t *testing.T) {
+	start := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	// 100 samples of a group which is usually fine, with 3 spikes
+	lags := make([]int64, 100)
+	for i := range lags {
+		lags[i] = int64(100 + i%10)
+	}
+	lags[20], lags[50], lags[80] = 50000, 20000, 9000
+
+	res := computeLagPercentiles("billing", newSLOTestSamples(start, time.Minute, lags))
+	assert.Equal(t, 100, res.SampleCount)
+	assert.Equal(t, start, res.Since)
+	assert.Equal(t, int64(105), res.P50)
+	assert.Equal(t, int64(109), res.P95) // The spikes are within the top 3%
+	assert.Equal(t, int64(20000), res.P99)
+	assert.Equal(t, int64(50000), res.Max)
+
+	// A group which is chronically behind has a p50 close to its p99
+	for i := range lags {
+		lags[i] = int64(40000 + i*10)
+	}
+	res = computeLagPercentiles("billing", newSLOTestSamples(start, time.Minute, lags))
+	assert.Equal(t, int64(40490), res.P50)
+	assert.Equal(t, int64(40980), res.P99)
+}
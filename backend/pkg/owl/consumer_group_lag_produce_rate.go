@@ -0,0 +1,45 @@
+package owl
+
+import "time"
+
+// produceRateWindow is the time span of lag samples the produce rates of the topic lags are calculated from
+const produceRateWindow = 5 * time.Minute
+
+// attachRecentProduceRates sets each topic's produce rate on the given group lags, which is derived from the high
+// water mark deltas of the group's lag samples within the produceRateWindow. A lag of 100k messages is caught up
+// within seconds on a topic with 50k messages/s, but may take hours on a topic with 10/s. The lags are left without
+// produce rates if the lag history is disabled or if it has less than two samples of the group.
+func (s *Service) attachRecentProduceRates(lags map[string]*ConsumerGroupLag) {
+	if s.lagHistory == nil {
+		return
+	}
+
+	since := time.Now().Add(-produceRateWindow)
+	for groupID, lag := range lags {
+		samples := s.lagHistory.get(groupID, since)
+		if len(samples) < 2 {
+			continue
+		}
+		attachProduceRates(lag, samples[0], samples[len(samples)-1])
+	}
+}
+
+// attachProduceRates sets the produce rate between the oldest and newest sample on each topic lag. Rates are never
+// negative, even if the high water marks went backwards because a topic has been recreated.
+func attachProduceRates(lag *ConsumerGroupLag, oldest *LagSample, newest *LagSample) {
+	rates := make(map[string]float64)
+	for _, balance := range calculateRateBalance(oldest, newest) {
+		rates[balance.Topic] = balance.ProduceRate
+	}
+
+	for _, topicLag := range lag.TopicLags {
+		rate, exists := rates[topicLag.Topic]
+		if !exists {
+			continue
+		}
+		if rate < 0 {
+			rate = 0
+		}
+		topicLag.ProduceRate = &rate
+	}
+}
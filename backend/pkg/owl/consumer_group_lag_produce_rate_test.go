@@ -0,0 +1,72 @@
+package owl
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAttachProduceRates(t *testing.T) {
+	start := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+	end := start.Add(20 * time.Second)
+	newSample := func(ts time.Time, ordersHighWaterMark int64, auditHighWaterMark int64) *LagSample {
+		return &LagSample{
+			Timestamp: ts,
+			Lag: &ConsumerGroupLag{
+				GroupID: "billing",
+				TopicLags: []*TopicLag{
+					{Topic: "orders", PartitionLags: []PartitionLag{
+						{PartitionID: 0, HighWaterMark: ordersHighWaterMark},
+						{PartitionID: 1, HighWaterMark: ordersHighWaterMark},
+					}},
+					{Topic: "audit", PartitionLags: []PartitionLag{{PartitionID: 0, HighWaterMark: auditHighWaterMark}}},
+				},
+			},
+		}
+	}
+
+	// Both partitions of orders ingest 500k messages within 20s, audit is idle and refunds is not sampled yet
+	lag := &ConsumerGroupLag{
+		GroupID: "billing",
+		TopicLags: []*TopicLag{
+			{Topic: "orders", SummedLag: 100000},
+			{Topic: "audit", SummedLag: 10},
+			{Topic: "refunds", SummedLag: 5},
+		},
+	}
+	attachProduceRates(lag, newSample(start, 1000000, 42), newSample(end, 1500000, 42))
+
+	require.NotNil(t, lag.GetTopicLag("orders").ProduceRate)
+	assert.Equal(t, 50000.0, *lag.GetTopicLag("orders").ProduceRate)
+	require.NotNil(t, lag.GetTopicLag("audit").ProduceRate)
+	assert.Equal(t, 0.0, *lag.GetTopicLag("audit").ProduceRate)
+	assert.Nil(t, lag.GetTopicLag("refunds").ProduceRate)
+}
+
+func TestService_AttachRecentProduceRates(t *testing.T) {
+	now := time.Now()
+	newLag := func(groupID string, highWaterMark int64) *ConsumerGroupLag {
+		return &ConsumerGroupLag{
+			GroupID:   groupID,
+			TopicLags: []*TopicLag{{Topic: "orders", PartitionLags: []PartitionLag{{PartitionID: 0, HighWaterMark: highWaterMark}}}},
+		}
+	}
+
+	// Billing has been sampled twice within the window, shipping only once
+	svc := &Service{lagHistory: newLagHistory(time.Hour)}
+	svc.lagHistory.add(map[string]*ConsumerGroupLag{"billing": newLag("billing", 1000), "shipping": newLag("shipping", 1000)}, now.Add(-20*time.Second))
+	svc.lagHistory.add(map[string]*ConsumerGroupLag{"billing": newLag("billing", 3000)}, now.Add(-10*time.Second))
+
+	lags := map[string]*ConsumerGroupLag{"billing": newLag("billing", 3000), "shipping": newLag("shipping", 3000)}
+	svc.attachRecentProduceRates(lags)
+	require.NotNil(t, lags["billing"].GetTopicLag("orders").ProduceRate)
+	assert.InDelta(t, 200.0, *lags["billing"].GetTopicLag("orders").ProduceRate, 0.001)
+	assert.Nil(t, lags["shipping"].GetTopicLag("orders").ProduceRate)
+
+	// Without a lag history the lags are returned without produce rates
+	lags = map[string]*ConsumerGroupLag{"billing": newLag("billing", 3000)}
+	(&Service{}).attachRecentProduceRates(lags)
+	assert.Nil(t, lags["billing"].GetTopicLag("orders").ProduceRate)
+}
@@ -0,0 +1,37 @@
+package owl
+
+import "sort"
+
+// computeLagShares sets each partition's share of the summed lag along with the dominant partitions, so that the
+// partitions which are worth investigating can be highlighted. All shares are 0 if the topic has no lag.
+func (t *TopicLag) computeLagShares() {
+	t.DominantPartitionIDs = make([]int32, 0)
+	if t.SummedLag <= 0 {
+		for i := range t.PartitionLags {
+			t.PartitionLags[i].LagShare = 0
+		}
+		return
+	}
+
+	for i := range t.PartitionLags {
+		t.PartitionLags[i].LagShare = float64(t.PartitionLags[i].Lag) / float64(t.SummedLag)
+	}
+
+	byLag := make([]PartitionLag, len(t.PartitionLags))
+	copy(byLag, t.PartitionLags)
+	sort.Slice(byLag, func(i, j int) bool {
+		if byLag[i].Lag == byLag[j].Lag {
+			return byLag[i].PartitionID < byLag[j].PartitionID
+		}
+		return byLag[i].Lag > byLag[j].Lag
+	})
+
+	var cumulatedLag int64
+	for _, p := range byLag {
+		t.DominantPartitionIDs = append(t.DominantPartitionIDs, p.PartitionID)
+		cumulatedLag += p.Lag
+		if cumulatedLag*2 > t.SummedLag {
+			break
+		}
+	}
+}
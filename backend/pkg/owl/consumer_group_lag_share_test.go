@@ -0,0 +1,30 @@
+package owl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTopicLag_ComputeLagShares(t *testing.T) {
+	topicLag := &TopicLag{
+		Topic:     "orders",
+		SummedLag: 1000,
+		PartitionLags: []PartitionLag{
+			{PartitionID: 0, Lag: 50},
+			{PartitionID: 1, Lag: 900},
+			{PartitionID: 2, Lag: 50},
+		},
+	}
+
+	topicLag.computeLagShares()
+	assert.InDelta(t, 0.05, topicLag.PartitionLags[0].LagShare, 0.0001)
+	assert.InDelta(t, 0.9, topicLag.PartitionLags[1].LagShare, 0.0001)
+	assert.InDelta(t, 0.05, topicLag.PartitionLags[2].LagShare, 0.0001)
+	assert.Equal(t, []int32{1}, topicLag.DominantPartitionIDs)
+
+	noLag := &TopicLag{Topic: "orders", PartitionLags: []PartitionLag{{PartitionID: 0}, {PartitionID: 1}}}
+	noLag.computeLagShares()
+	assert.Equal(t, 0.0, noLag.PartitionLags[0].LagShare)
+	assert.Empty(t, noLag.DominantPartitionIDs)
+}
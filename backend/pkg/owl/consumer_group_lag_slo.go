@@ -0,0 +1,124 @@
+package owl
+
+import (
+	"fmt"
+	"time"
+)
+
+// LagSLO is a service level objective on the summed lag of a consumer group, e.g. "the summed lag is below 1000
+// for 99% of the time over 30 days"
+type LagSLO struct {
+	MaxSummedLag int64         `json:"maxSummedLag"` // The lag must stay below this value to be within the objective
+	Target       float64       `json:"target"`       // Fraction of the time the lag must be within the objective (0-1)
+	Window       time.Duration `json:"window"`
+}
+
+// Validate the lag SLO
+func (s *LagSLO) Validate() error {
+	if s.MaxSummedLag <= 0 {
+		return fmt.Errorf("max summed lag must be greater than 0")
+	}
+	if s.Target <= 0 || s.Target >= 1 {
+		return fmt.Errorf("target must be between 0 and 1 (exclusive)")
+	}
+	if s.Window <= 0 {
+		return fmt.Errorf("window must be greater than 0")
+	}
+
+	return nil
+}
+
+// LagSLOBurnRate describes how fast a consumer group consumes the error budget of its lag SLO. A burn rate of 1
+// consumes the budget exactly within the SLO window, higher burn rates exhaust it earlier.
+type LagSLOBurnRate struct {
+	GroupID     string `json:"groupId"`
+	SLO         LagSLO `json:"slo"`
+	SampleCount int    `json:"sampleCount"`
+
+	// ObservedDuration is the time covered by the lag history. It's shorter than the SLO window if the lag history
+	// retention is shorter than the window, in that case IsWindowCovered is false and the burn rate is extrapolated.
+	ObservedDuration time.Duration `json:"observedDuration"`
+	IsWindowCovered  bool          `json:"isWindowCovered"`
+
+	BreachDuration time.Duration `json:"breachDuration"` // Time the summed lag was not below MaxSummedLag
+	ErrorBudget    time.Duration `json:"errorBudget"`    // Breach duration the SLO allows within its window
+	BudgetConsumed float64       `json:"budgetConsumed"` // Fraction of the error budget consumed (may exceed 1)
+	BurnRate       float64       `json:"burnRate"`
+
+	// ProjectedExhaustionAt is when the error budget will be exhausted at the current burn rate, nil if no budget
+	// is being consumed
+	ProjectedExhaustionAt *time.Time `json:"projectedExhaustionAt"`
+	IsBudgetExhausted     bool       `json:"isBudgetExhausted"`
+}
+
+// GetConsumerGroupLagSLOBurnRate computes the burn rate of the given lag SLO from the recorded lag history. Each
+// sample's lag is assumed to last until the next sample. Only samples within the lag history's retention are
+// available, so windows longer than the retention are extrapolated from the observed duration.
+func (s *Service) GetConsumerGroupLagSLOBurnRate(groupID string, slo LagSLO) (*LagSLOBurnRate, error) {
+	if s.lagHistory == nil {
+		return nil, ErrLagHistoryDisabled
+	}
+	if err := slo.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid lag slo: %w", err)
+	}
+
+	samples := s.lagHistory.get(groupID, time.Now().Add(-slo.Window))
+	if len(samples) < 2 {
+		return nil, fmt.Errorf("at least two lag samples are required to compute the burn rate")
+	}
+
+	return computeLagSLOBurnRate(groupID, slo, samples), nil
+}
+
+func computeLagSLOBurnRate(groupID string, slo LagSLO, samples []*LagSample) *LagSLOBurnRate {
+	res := &LagSLOBurnRate{
+		GroupID:     groupID,
+		SLO:         slo,
+		SampleCount: len(samples),
+		ErrorBudget: time.Duration((1 - slo.Target) * float64(slo.Window)),
+	}
+
+	for i := 0; i < len(samples)-1; i++ {
+		duration := samples[i+1].Timestamp.Sub(samples[i].Timestamp)
+		res.ObservedDuration += duration
+		if summedGroupLag(samples[i].Lag) >= slo.MaxSummedLag {
+			res.BreachDuration += duration
+		}
+	}
+	res.IsWindowCovered = res.ObservedDuration >= slo.Window
+	if res.ObservedDuration == 0 {
+		return res
+	}
+
+	res.BudgetConsumed = float64(res.BreachDuration) / float64(res.ErrorBudget)
+	breachRate := float64(res.BreachDuration) / float64(res.ObservedDuration)
+	res.BurnRate = breachRate / (1 - slo.Target)
+	if res.BreachDuration == 0 {
+		return res
+	}
+
+	now := samples[len(samples)-1].Timestamp
+	remaining := res.ErrorBudget - res.BreachDuration
+	if remaining <= 0 {
+		res.IsBudgetExhausted = true
+		res.ProjectedExhaustionAt = &now
+		return res
+	}
+	exhaustionAt := now.Add(time.Duration(float64(remaining) / breachRate))
+	res.ProjectedExhaustionAt = &exhaustionAt
+
+	return res
+}
+
+// summedGroupLag returns the lag of all topics of the group
+func summedGroupLag(lag *ConsumerGroupLag) int64 {
+	if lag == nil {
+		return 0
+	}
+
+	summed := int64(0)
+	for _, topicLag := range lag.TopicLags {
+		summed += topicLag.SummedLag
+	}
+	return summed
+}
@@ -0,0 +1,75 @@
+package owl
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newSLOTestSamples(start time.Time, interval time.Duration, summedLags []int64) []*LagSample {
+	samples := make([]*LagSample, len(summedLags))
+	for i, lag := range summedLags {
+		samples[i] = &LagSample{
+			Timestamp: start.Add(time.Duration(i) * interval),
+			Lag: &ConsumerGroupLag{GroupID: "billing", TopicLags: []*TopicLag{
+				{Topic: "orders", SummedLag: lag / 2},
+				{Topic: "payments", SummedLag: lag - lag/2},
+			}},
+		}
+	}
+	return samples
+}
+
+func TestComputeLagSLOBurnRate(t *testing.T) {
+	start := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+	slo := LagSLO{MaxSummedLag: 1000, Target: 0.99, Window: 100 * time.Hour}
+
+	// 11 hourly samples cover 10 hours, the lag breached the objective during 2 of them
+	lags := []int64{10, 20, 1500, 1000, 30, 40, 50, 60, 70, 80, 90}
+	res := computeLagSLOBurnRate("billing", slo, newSLOTestSamples(start, time.Hour, lags))
+
+	assert.Equal(t, 10*time.Hour, res.ObservedDuration)
+	assert.False(t, res.IsWindowCovered)
+	assert.Equal(t, 2*time.Hour, res.BreachDuration)
+	assert.Equal(t, time.Hour, res.ErrorBudget) // 1% of 100h
+	assert.InDelta(t, 2.0, res.BudgetConsumed, 0.0001)
+
+	// Breach rate of 20% against an allowed rate of 1%
+	assert.InDelta(t, 20.0, res.BurnRate, 0.0001)
+	assert.True(t, res.IsBudgetExhausted)
+	require.NotNil(t, res.ProjectedExhaustionAt)
+	assert.Equal(t, start.Add(10*time.Hour), *res.ProjectedExhaustionAt)
+}
+
+func TestComputeLagSLOBurnRate_Projection(t *testing.T) {
+	start := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+	slo := LagSLO{MaxSummedLag: 1000, Target: 0.9, Window: 10 * time.Hour}
+
+	// One breached hour out of 10 consumes the budget exactly within the window
+	lags := []int64{10, 2000, 10, 10, 10, 10, 10, 10, 10, 10, 10}
+	res := computeLagSLOBurnRate("billing", slo, newSLOTestSamples(start, 6*time.Minute, lags))
+
+	assert.Equal(t, time.Hour, res.ObservedDuration)
+	assert.Equal(t, 6*time.Minute, res.BreachDuration)
+	assert.InDelta(t, 1.0, res.BurnRate, 0.0001)
+	assert.InDelta(t, 0.1, res.BudgetConsumed, 0.0001)
+	assert.False(t, res.IsBudgetExhausted)
+
+	// 54 minutes of budget left, consumed at 6 minutes per hour
+	require.NotNil(t, res.ProjectedExhaustionAt)
+	assert.Equal(t, start.Add(10*time.Hour), res.ProjectedExhaustionAt.Round(time.Second))
+
+	// Without breaches no budget is consumed
+	lags = []int64{10, 10, 10}
+	res = computeLagSLOBurnRate("billing", slo, newSLOTestSamples(start, time.Hour, lags))
+	assert.Equal(t, 0.0, res.BurnRate)
+	assert.Nil(t, res.ProjectedExhaustionAt)
+}
+
+func TestLagSLO_Validate(t *testing.T) {
+	assert.NoError(t, (&LagSLO{MaxSummedLag: 1000, Target: 0.99, Window: 30 * 24 * time.Hour}).Validate())
+	assert.Error(t, (&LagSLO{MaxSummedLag: 1000, Target: 1, Window: time.Hour}).Validate())
+	assert.Error(t, (&LagSLO{MaxSummedLag: 0, Target: 0.99, Window: time.Hour}).Validate())
+}
@@ -0,0 +1,66 @@
+package owl
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// lagStreamChunkSize is the number of groups whose lags are computed together before they are emitted. Larger
+// chunks need fewer requests, smaller chunks deliver the first results sooner.
+const lagStreamChunkSize = 20
+
+// StreamConsumerGroupLags computes the lags of the given groups (all groups if empty) chunk by chunk and sends each
+// group's lag to lagCh as soon as its chunk has been computed, so that callers can render the results
+// incrementally instead of waiting for all groups. Groups are processed in the order of their IDs. lagCh is closed
+// once all lags have been sent, the context is done or an error occurred.
+func (s *Service) StreamConsumerGroupLags(ctx context.Context, groupIDs []string, lagCh chan<- *ConsumerGroupLag) error {
+	if len(groupIDs) == 0 {
+		groups, err := s.kafkaSvc.ListConsumerGroups(ctx)
+		if err != nil {
+			close(lagCh)
+			return fmt.Errorf("failed to list consumer groups: %w", err)
+		}
+		groupIDs = groups
+	}
+
+	return streamConsumerGroupLags(ctx, groupIDs, lagStreamChunkSize, s.getConsumerGroupLags, lagCh)
+}
+
+func streamConsumerGroupLags(ctx context.Context, groupIDs []string, chunkSize int, compute func(ctx context.Context, groups []string) (map[string]*ConsumerGroupLag, error), lagCh chan<- *ConsumerGroupLag) error {
+	defer close(lagCh)
+
+	sorted := make([]string, len(groupIDs))
+	copy(sorted, groupIDs)
+	sort.Strings(sorted)
+
+	for start := 0; start < len(sorted); start += chunkSize {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		end := start + chunkSize
+		if end > len(sorted) {
+			end = len(sorted)
+		}
+		chunk := sorted[start:end]
+		lags, err := compute(ctx, chunk)
+		if err != nil {
+			return fmt.Errorf("failed to get consumer group lags: %w", err)
+		}
+
+		for _, groupID := range chunk {
+			lag, exists := lags[groupID]
+			if !exists {
+				continue
+			}
+			select {
+			case lagCh <- lag:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}
+
+	return nil
+}
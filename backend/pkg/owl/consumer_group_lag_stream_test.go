@@ -0,0 +1,67 @@
+package owl
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStreamConsumerGroupLags(t *testing.T) {
+	computedChunks := make([][]string, 0)
+	compute := func(_ context.Context, groups []string) (map[string]*ConsumerGroupLag, error) {
+		computedChunks = append(computedChunks, groups)
+		res := make(map[string]*ConsumerGroupLag, len(groups))
+		for _, group := range groups {
+			res[group] = &ConsumerGroupLag{GroupID: group}
+		}
+		return res, nil
+	}
+
+	// Unbuffered, so that each receive shows which chunks had been computed before the lag has been emitted
+	lagCh := make(chan *ConsumerGroupLag)
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- streamConsumerGroupLags(context.Background(), []string{"e", "c", "a", "d", "b"}, 2, compute, lagCh)
+	}()
+
+	first := <-lagCh
+	assert.Equal(t, "a", first.GroupID)
+	assert.Len(t, computedChunks, 1, "the first lag must be emitted before the remaining chunks are computed")
+
+	received := []string{first.GroupID}
+	for lag := range lagCh {
+		received = append(received, lag.GroupID)
+	}
+	require.NoError(t, <-errCh)
+	assert.Equal(t, []string{"a", "b", "c", "d", "e"}, received)
+	assert.Equal(t, [][]string{{"a", "b"}, {"c", "d"}, {"e"}}, computedChunks)
+}
+
+func TestStreamConsumerGroupLags_Cancellation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	computedChunks := 0
+	compute := func(_ context.Context, groups []string) (map[string]*ConsumerGroupLag, error) {
+		computedChunks++
+		res := make(map[string]*ConsumerGroupLag, len(groups))
+		for _, group := range groups {
+			res[group] = &ConsumerGroupLag{GroupID: group}
+		}
+		return res, nil
+	}
+
+	lagCh := make(chan *ConsumerGroupLag)
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- streamConsumerGroupLags(ctx, []string{"a", "b", "c", "d"}, 1, compute, lagCh)
+	}()
+
+	assert.Equal(t, "a", (<-lagCh).GroupID)
+	cancel()
+
+	assert.Equal(t, context.Canceled, <-errCh)
+	_, isOpen := <-lagCh
+	assert.False(t, isOpen)
+	assert.LessOrEqual(t, computedChunks, 2)
+}
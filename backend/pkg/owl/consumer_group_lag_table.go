@@ -0,0 +1,105 @@
+package owl
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// maxLagTableRows bounds the lag table so that huge clusters can't exhaust the memory
+const maxLagTableRows = 100000
+
+// ConsumerGroupLagTable is the denormalized lag of all groups, suitable for CSV or BI exports
+type ConsumerGroupLagTable struct {
+	Rows        []*ConsumerGroupLagRow `json:"rows"`
+	IsTruncated bool                   `json:"isTruncated"` // True if there are more rows than the requested limit
+}
+
+// ConsumerGroupLagRow is a single partition a group has committed an offset for. Member fields are blank if the
+// partition is not assigned to any member.
+type ConsumerGroupLagRow struct {
+	GroupID         string `json:"groupId"`
+	Topic           string `json:"topic"`
+	PartitionID     int32  `json:"partitionId"`
+	CommittedOffset int64  `json:"committedOffset"`
+	HighWaterMark   int64  `json:"highWaterMark"`
+	Lag             int64  `json:"lag"`
+	MemberID        string `json:"memberId"`
+	ClientID        string `json:"clientId"`
+	Host            string `json:"host"`
+}
+
+// GetConsumerGroupLagTable returns one row per committed partition across all consumer groups, sorted by group,
+// topic and partition. The number of rows is limited to the given limit, which defaults to and is bounded by
+// 100k rows.
+func (s *Service) GetConsumerGroupLagTable(ctx context.Context, limit int) (*ConsumerGroupLagTable, error) {
+	if limit <= 0 || limit > maxLagTableRows {
+		limit = maxLagTableRows
+	}
+
+	groups, err := s.GetConsumerGroupsOverview(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get consumer groups overview: %w", err)
+	}
+
+	return buildConsumerGroupLagTable(groups, limit), nil
+}
+
+func buildConsumerGroupLagTable(groups []*ConsumerGroupOverview, limit int) *ConsumerGroupLagTable {
+	rows := make([]*ConsumerGroupLagRow, 0)
+	for _, group := range groups {
+		if group.Lags == nil {
+			continue
+		}
+
+		ownerByPartition := make(map[string]map[int32]*GroupMemberDescription)
+		for _, member := range group.Members {
+			for _, assignment := range member.Assignments {
+				if _, exists := ownerByPartition[assignment.TopicName]; !exists {
+					ownerByPartition[assignment.TopicName] = make(map[int32]*GroupMemberDescription)
+				}
+				for _, partitionID := range assignment.PartitionIDs {
+					ownerByPartition[assignment.TopicName][partitionID] = member
+				}
+			}
+		}
+
+		for _, topicLag := range group.Lags.TopicLags {
+			for _, partitionLag := range topicLag.PartitionLags {
+				row := &ConsumerGroupLagRow{
+					GroupID:         group.GroupID,
+					Topic:           topicLag.Topic,
+					PartitionID:     partitionLag.PartitionID,
+					CommittedOffset: partitionLag.CommittedOffset,
+					HighWaterMark:   partitionLag.HighWaterMark,
+					Lag:             partitionLag.Lag,
+				}
+				if owner, exists := ownerByPartition[topicLag.Topic][partitionLag.PartitionID]; exists {
+					row.MemberID = owner.ID
+					row.ClientID = owner.ClientID
+					row.Host = strings.TrimPrefix(owner.ClientHost, "/")
+				}
+				rows = append(rows, row)
+			}
+		}
+	}
+
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].GroupID != rows[j].GroupID {
+			return rows[i].GroupID < rows[j].GroupID
+		}
+		if rows[i].Topic != rows[j].Topic {
+			return rows[i].Topic < rows[j].Topic
+		}
+		return rows[i].PartitionID < rows[j].PartitionID
+	})
+
+	table := &ConsumerGroupLagTable{Rows: rows}
+	if len(rows) > limit {
+		table.Rows = rows[:limit]
+		table.IsTruncated = true
+	}
+
+	return table
+}
@@ -0,0 +1,56 @@
+package owl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildConsumerGroupLagTable(t *testing.T) {
+	groups := []*ConsumerGroupOverview{
+		{
+			GroupID: "billing",
+			Members: []*GroupMemberDescription{{
+				ID:          "consumer-1-a",
+				ClientID:    "consumer-1",
+				ClientHost:  "/10.0.0.1",
+				Assignments: []*GroupMemberAssignment{{TopicName: "orders", PartitionIDs: []int32{1}}},
+			}},
+			Lags: &ConsumerGroupLag{GroupID: "billing", TopicLags: []*TopicLag{{
+				Topic: "orders",
+				PartitionLags: []PartitionLag{
+					{PartitionID: 1, Lag: 5, HighWaterMark: 105, CommittedOffset: 100},
+					{PartitionID: 0, Lag: 0, HighWaterMark: 80, CommittedOffset: 80},
+				},
+			}}},
+		},
+		{
+			GroupID: "audit",
+			Lags: &ConsumerGroupLag{GroupID: "audit", TopicLags: []*TopicLag{
+				{Topic: "orders", PartitionLags: []PartitionLag{{PartitionID: 0, Lag: 1}}},
+				{Topic: "payments", PartitionLags: []PartitionLag{{PartitionID: 0, Lag: 2}}},
+			}},
+		},
+	}
+
+	table := buildConsumerGroupLagTable(groups, maxLagTableRows)
+	require.Len(t, table.Rows, 4)
+	assert.False(t, table.IsTruncated)
+	assert.Equal(t, "audit", table.Rows[0].GroupID)
+	assert.Equal(t, &ConsumerGroupLagRow{
+		GroupID:         "billing",
+		Topic:           "orders",
+		PartitionID:     1,
+		CommittedOffset: 100,
+		HighWaterMark:   105,
+		Lag:             5,
+		MemberID:        "consumer-1-a",
+		ClientID:        "consumer-1",
+		Host:            "10.0.0.1",
+	}, table.Rows[3])
+
+	truncated := buildConsumerGroupLagTable(groups, 3)
+	assert.Len(t, truncated.Rows, 3)
+	assert.True(t, truncated.IsTruncated)
+}
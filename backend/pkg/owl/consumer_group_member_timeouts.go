@@ -0,0 +1,104 @@
+package owl
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Shopify/sarama"
+)
+
+// MemberTimeoutsSourceUserData means the timeouts have been read from the user data of the member's subscription
+const MemberTimeoutsSourceUserData = "memberUserData"
+
+// GroupMemberTimeouts are the session and rebalance timeouts of a group member. Kafka does not expose the timeouts
+// via DescribeGroups, hence they are only known if the client has put them into its subscription's user data.
+type GroupMemberTimeouts struct {
+	IsKnown            bool   `json:"isKnown"`
+	Source             string `json:"source,omitempty"`
+	SessionTimeoutMs   *int64 `json:"sessionTimeoutMs,omitempty"`
+	RebalanceTimeoutMs *int64 `json:"rebalanceTimeoutMs,omitempty"`
+	HeartbeatMs        *int64 `json:"heartbeatIntervalMs,omitempty"`
+}
+
+var (
+	sessionTimeoutKeys   = []string{"session.timeout.ms", "sessionTimeoutMs"}
+	rebalanceTimeoutKeys = []string{"rebalance.timeout.ms", "rebalanceTimeoutMs", "max.poll.interval.ms"}
+	heartbeatKeys        = []string{"heartbeat.interval.ms", "heartbeatIntervalMs"}
+)
+
+// getMemberTimeouts returns the member's timeouts as far as they are derivable from the member metadata
+func getMemberTimeouts(member *sarama.GroupMemberDescription) *GroupMemberTimeouts {
+	metadata, err := member.GetMemberMetadata()
+	if err != nil || metadata == nil {
+		return &GroupMemberTimeouts{}
+	}
+
+	return extractMemberTimeouts(metadata.UserData)
+}
+
+// extractMemberTimeouts parses user data which is either a JSON object or a list of key=value pairs (separated
+// by commas, semicolons or new lines). Other user data formats (e.g. Kafka Streams' binary encoded user data)
+// are reported as unknown.
+func extractMemberTimeouts(userData []byte) *GroupMemberTimeouts {
+	timeouts := &GroupMemberTimeouts{}
+	if len(userData) == 0 || !utf8.Valid(userData) {
+		return timeouts
+	}
+
+	values := make(map[string]string)
+	trimmed := bytes.TrimSpace(userData)
+	if len(trimmed) > 0 && trimmed[0] == '{' {
+		var obj map[string]interface{}
+		if err := json.Unmarshal(trimmed, &obj); err != nil {
+			return timeouts
+		}
+		for k, v := range obj {
+			values[k] = jsonScalarString(v)
+		}
+	} else {
+		fields := strings.FieldsFunc(string(trimmed), func(r rune) bool { return r == ',' || r == ';' || r == '\n' })
+		for _, field := range fields {
+			kv := strings.SplitN(field, "=", 2)
+			if len(kv) == 2 {
+				values[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
+			}
+		}
+	}
+
+	timeouts.SessionTimeoutMs = lookupMillis(values, sessionTimeoutKeys)
+	timeouts.RebalanceTimeoutMs = lookupMillis(values, rebalanceTimeoutKeys)
+	timeouts.HeartbeatMs = lookupMillis(values, heartbeatKeys)
+	if timeouts.SessionTimeoutMs != nil || timeouts.RebalanceTimeoutMs != nil || timeouts.HeartbeatMs != nil {
+		timeouts.IsKnown = true
+		timeouts.Source = MemberTimeoutsSourceUserData
+	}
+
+	return timeouts
+}
+
+func lookupMillis(values map[string]string, keys []string) *int64 {
+	for _, key := range keys {
+		v, exists := values[key]
+		if !exists {
+			continue
+		}
+		if ms, err := strconv.ParseInt(v, 10, 64); err == nil && ms >= 0 {
+			return &ms
+		}
+	}
+	return nil
+}
+
+func jsonScalarString(v interface{}) string {
+	switch value := v.(type) {
+	case string:
+		return value
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	default:
+		return ""
+	}
+}
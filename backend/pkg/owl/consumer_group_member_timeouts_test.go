@@ -0,0 +1,27 @@
+package owl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtractMemberTimeouts(t *testing.T) {
+	timeouts := extractMemberTimeouts([]byte("app=billing,session.timeout.ms=6000,max.poll.interval.ms=300000"))
+	assert.True(t, timeouts.IsKnown)
+	assert.Equal(t, MemberTimeoutsSourceUserData, timeouts.Source)
+	require.NotNil(t, timeouts.SessionTimeoutMs)
+	assert.Equal(t, int64(6000), *timeouts.SessionTimeoutMs)
+	require.NotNil(t, timeouts.RebalanceTimeoutMs)
+	assert.Equal(t, int64(300000), *timeouts.RebalanceTimeoutMs)
+	assert.Nil(t, timeouts.HeartbeatMs)
+
+	fromJSON := extractMemberTimeouts([]byte(`{"sessionTimeoutMs": 10000}`))
+	require.NotNil(t, fromJSON.SessionTimeoutMs)
+	assert.Equal(t, int64(10000), *fromJSON.SessionTimeoutMs)
+
+	unknown := extractMemberTimeouts([]byte{0x00, 0x04, 0xff, 0x12})
+	assert.False(t, unknown.IsKnown)
+	assert.Nil(t, unknown.SessionTimeoutMs)
+}
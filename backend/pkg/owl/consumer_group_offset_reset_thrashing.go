@@ -0,0 +1,121 @@
+package owl
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+const (
+	// defaultMinOffsetResets is the number of observed resets on a partition from which on it is considered thrashing
+	defaultMinOffsetResets = 2
+
+	// minSkippedMessagesForReset is the minimum lag a jump to the high water mark must skip to be considered a reset.
+	// Smaller jumps are indistinguishable from a consumer which has simply caught up.
+	minSkippedMessagesForReset = 100
+)
+
+// OffsetResetThrashing reports partitions whose committed offsets repeatedly snap to a water mark, which usually
+// means auto.offset.reset fires because the committed offsets are out of range. Resets to the earliest offset
+// cause reprocessing, resets to the latest offset skip messages.
+type OffsetResetThrashing struct {
+	GroupID     string                       `json:"groupId"`
+	IsThrashing bool                         `json:"isThrashing"`
+	SampleCount int                          `json:"sampleCount"`
+	Partitions  []*PartitionOffsetResetCount `json:"partitions"` // Partitions with at least one observed reset
+}
+
+// PartitionOffsetResetCount is the number of observed offset resets of a single partition
+type PartitionOffsetResetCount struct {
+	Topic            string    `json:"topic"`
+	PartitionID      int32     `json:"partitionId"`
+	ResetsToEarliest int       `json:"resetsToEarliest"` // Committed offset moved backwards
+	ResetsToLatest   int       `json:"resetsToLatest"`   // Committed offset jumped to the high water mark
+	SkippedMessages  int64     `json:"skippedMessages"`  // Lag which has been skipped by the resets to latest
+	LastResetAt      time.Time `json:"lastResetAt"`
+	IsThrashing      bool      `json:"isThrashing"`
+}
+
+// DetectOffsetResetThrashing inspects the committed offsets of the recorded lag samples and flags the group if the
+// committed offset of a partition has snapped to a water mark at least minResets times (defaults to 2). Only
+// samples are compared, hence this is a heuristic: a jump to the high water mark which skips at least 100
+// messages, or a committed offset that moves backwards, is counted as reset.
+func (s *Service) DetectOffsetResetThrashing(groupID string, minResets int) (*OffsetResetThrashing, error) {
+	if s.lagHistory == nil {
+		return nil, ErrLagHistoryDisabled
+	}
+	if minResets <= 0 {
+		minResets = defaultMinOffsetResets
+	}
+
+	samples := s.lagHistory.get(groupID, time.Time{})
+	if len(samples) < 2 {
+		return nil, fmt.Errorf("at least two lag samples are required to detect offset resets")
+	}
+
+	return detectOffsetResetThrashing(groupID, samples, minResets), nil
+}
+
+func detectOffsetResetThrashing(groupID string, samples []*LagSample, minResets int) *OffsetResetThrashing {
+	type partitionKey struct {
+		topic       string
+		partitionID int32
+	}
+	counts := make(map[partitionKey]*PartitionOffsetResetCount)
+	previous := make(map[partitionKey]PartitionLag)
+
+	for _, sample := range samples {
+		for _, topicLag := range sample.Lag.TopicLags {
+			for _, current := range topicLag.PartitionLags {
+				key := partitionKey{topic: topicLag.Topic, partitionID: current.PartitionID}
+				before, exists := previous[key]
+				previous[key] = current
+				if !exists {
+					continue
+				}
+
+				isResetToEarliest := current.CommittedOffset < before.CommittedOffset
+				isResetToLatest := current.CommittedOffset == current.HighWaterMark &&
+					current.CommittedOffset > before.CommittedOffset &&
+					before.Lag >= minSkippedMessagesForReset
+				if !isResetToEarliest && !isResetToLatest {
+					continue
+				}
+
+				c, exists := counts[key]
+				if !exists {
+					c = &PartitionOffsetResetCount{Topic: key.topic, PartitionID: key.partitionID}
+					counts[key] = c
+				}
+				if isResetToEarliest {
+					c.ResetsToEarliest++
+				} else {
+					c.ResetsToLatest++
+					c.SkippedMessages += before.Lag
+				}
+				c.LastResetAt = sample.Timestamp
+			}
+		}
+	}
+
+	res := &OffsetResetThrashing{
+		GroupID:     groupID,
+		SampleCount: len(samples),
+		Partitions:  make([]*PartitionOffsetResetCount, 0, len(counts)),
+	}
+	for _, c := range counts {
+		c.IsThrashing = c.ResetsToEarliest+c.ResetsToLatest >= minResets
+		if c.IsThrashing {
+			res.IsThrashing = true
+		}
+		res.Partitions = append(res.Partitions, c)
+	}
+	sort.Slice(res.Partitions, func(i, j int) bool {
+		if res.Partitions[i].Topic != res.Partitions[j].Topic {
+			return res.Partitions[i].Topic < res.Partitions[j].Topic
+		}
+		return res.Partitions[i].PartitionID < res.Partitions[j].PartitionID
+	})
+
+	return res
+}
@@ -0,0 +1,63 @@
+package owl
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectOffsetResetThrashing(t *testing.T) {
+	start := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	// The group falls far behind and its committed offset repeatedly snaps to the high water mark
+	offsets := []struct{ high, committed int64 }{
+		{1000, 1000},
+		{2000, 1100},
+		{3000, 3000}, // Reset to latest, skips 900 messages
+		{4000, 3050},
+		{5000, 5000}, // Reset to latest, skips 950 messages
+		{6000, 5990},
+		{7000, 7000}, // Caught up regularly, only 10 messages behind before
+	}
+	samples := make([]*LagSample, len(offsets))
+	for i, o := range offsets {
+		samples[i] = newRateBalanceSample(start.Add(time.Duration(i)*time.Minute), "orders", o.high, o.committed)
+	}
+
+	res := detectOffsetResetThrashing("billing", samples, 2)
+	assert.True(t, res.IsThrashing)
+	assert.Equal(t, 7, res.SampleCount)
+	require.Len(t, res.Partitions, 1)
+	assert.Equal(t, &PartitionOffsetResetCount{
+		Topic:           "orders",
+		PartitionID:     0,
+		ResetsToLatest:  2,
+		SkippedMessages: 1850,
+		LastResetAt:     start.Add(4 * time.Minute),
+		IsThrashing:     true,
+	}, res.Partitions[0])
+
+	// A single reset is reported, but doesn't flag the group
+	res = detectOffsetResetThrashing("billing", samples[:4], 2)
+	assert.False(t, res.IsThrashing)
+	require.Len(t, res.Partitions, 1)
+	assert.Equal(t, 1, res.Partitions[0].ResetsToLatest)
+}
+
+func TestDetectOffsetResetThrashing_ResetToEarliest(t *testing.T) {
+	start := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+	samples := []*LagSample{
+		newRateBalanceSample(start, "orders", 1000, 900),
+		newRateBalanceSample(start.Add(time.Minute), "orders", 1100, 200),
+		newRateBalanceSample(start.Add(2*time.Minute), "orders", 1200, 1150),
+		newRateBalanceSample(start.Add(3*time.Minute), "orders", 1300, 300),
+	}
+
+	res := detectOffsetResetThrashing("billing", samples, 2)
+	assert.True(t, res.IsThrashing)
+	require.Len(t, res.Partitions, 1)
+	assert.Equal(t, 2, res.Partitions[0].ResetsToEarliest)
+	assert.Equal(t, 0, res.Partitions[0].ResetsToLatest)
+}
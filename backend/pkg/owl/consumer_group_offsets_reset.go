@@ -0,0 +1,299 @@
+package owl
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/Shopify/sarama"
+)
+
+const (
+	ResetTargetEarliest  = "earliest"
+	ResetTargetLatest    = "latest"
+	ResetTargetTimestamp = "timestamp"
+)
+
+// ResetTarget describes where the committed offsets of a group shall be moved to
+type ResetTarget struct {
+	Type      string   `json:"type"`      // earliest, latest or timestamp
+	Timestamp int64    `json:"timestamp"` // Unix timestamp in ms, only used for the timestamp type
+	Topics    []string `json:"topics"`    // Optional, only reset these topics. All consumed topics if empty
+
+	// ConfirmedGroupIDs are the groups which a prior dry run has planned to reset, i.e. the groups which have not
+	// been skipped. They are required to apply a reset.
+	ConfirmedGroupIDs []string `json:"confirmedGroupIds"`
+}
+
+// Validate the reset target
+func (t *ResetTarget) Validate() error {
+	switch t.Type {
+	case ResetTargetEarliest, ResetTargetLatest:
+		return nil
+	case ResetTargetTimestamp:
+		if t.Timestamp < 0 {
+			return fmt.Errorf("reset timestamp must not be negative")
+		}
+		return nil
+	default:
+		return fmt.Errorf("reset target type '%v' is not supported", t.Type)
+	}
+}
+
+// BulkOffsetsReset is the (planned) offsets reset of all groups which match a pattern
+type BulkOffsetsReset struct {
+	IsDryRun bool                 `json:"isDryRun"`
+	Groups   []*GroupOffsetsReset `json:"groups"`
+}
+
+// GroupOffsetsReset is the (planned) offsets reset of a single group. Active groups are skipped, because Kafka
+// does not allow to commit offsets for groups with members.
+type GroupOffsetsReset struct {
+	GroupID    string                  `json:"groupId"`
+	State      string                  `json:"state"`
+	IsSkipped  bool                    `json:"isSkipped"`
+	SkipReason string                  `json:"skipReason,omitempty"`
+	Error      string                  `json:"error,omitempty"` // Set if the offsets could not be committed
+	Partitions []*PartitionOffsetReset `json:"partitions"`
+}
+
+// PartitionOffsetReset is the planned offset change of a single partition
+type PartitionOffsetReset struct {
+	Topic         string `json:"topic"`
+	PartitionID   int32  `json:"partitionId"`
+	CurrentOffset int64  `json:"currentOffset"`
+	TargetOffset  int64  `json:"targetOffset"`
+}
+
+// ResetOffsetsForGroups resets the committed offsets of all groups whose id matches the regex pattern to the given
+// target. Groups which are not empty are skipped and reported. The returned plan is only applied if dryRun is false,
+// which requires the target to confirm the groups a prior dry run has planned to reset. Nothing is applied if a
+// group would be reset which has not been confirmed, e.g. because it has started to match the pattern since.
+// Confirmed groups which are not empty anymore are skipped.
+func (s *Service) ResetOffsetsForGroups(ctx context.Context, groupPattern string, target ResetTarget, dryRun bool) (*BulkOffsetsReset, error) {
+	if groupPattern == "" {
+		return nil, fmt.Errorf("a group pattern is required")
+	}
+	pattern, err := regexp.Compile(groupPattern)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile group pattern: %w", err)
+	}
+	if err := target.Validate(); err != nil {
+		return nil, err
+	}
+	if !dryRun && len(target.ConfirmedGroupIDs) == 0 {
+		return nil, fmt.Errorf("resetting offsets requires the group ids of a prior dry run as confirmation")
+	}
+
+	groups, err := s.kafkaSvc.ListConsumerGroups(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list consumer groups: %w", err)
+	}
+	matchingGroups := make([]string, 0)
+	for _, group := range groups {
+		if pattern.MatchString(group) {
+			matchingGroups = append(matchingGroups, group)
+		}
+	}
+	if len(matchingGroups) == 0 {
+		return &BulkOffsetsReset{IsDryRun: dryRun, Groups: make([]*GroupOffsetsReset, 0)}, nil
+	}
+
+	describedGroups, err := s.kafkaSvc.DescribeConsumerGroups(ctx, matchingGroups)
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe consumer groups: %w", err)
+	}
+	states := make(map[string]string, len(matchingGroups))
+	for _, res := range describedGroups {
+		for _, group := range res.Groups {
+			states[group.GroupId] = group.State
+		}
+	}
+
+	offsets, err := s.kafkaSvc.ListConsumerGroupOffsetsBulk(ctx, matchingGroups)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list consumer group offsets: %w", err)
+	}
+	offsetsByGroup := make(map[string]map[string]partitionOffsets, len(offsets))
+	topics := make(map[string]struct{})
+	for group, offset := range offsets {
+		offsetsByGroup[group] = filterTopicOffsets(convertOffsets(offset), target.Topics)
+		for topic := range offsetsByGroup[group] {
+			topics[topic] = struct{}{}
+		}
+	}
+
+	targetOffsets, err := s.resolveResetTargetOffsets(sortedKeys(topics), target)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := planOffsetsResets(matchingGroups, states, offsetsByGroup, targetOffsets)
+	plan.IsDryRun = dryRun
+	if !dryRun {
+		if err := verifyOffsetsResetConfirmation(plan, target.ConfirmedGroupIDs); err != nil {
+			return nil, err
+		}
+		applyOffsetsResets(ctx, plan, s.commitGroupOffsets)
+	}
+
+	return plan, nil
+}
+
+// resolveResetTargetOffsets returns the target offset of every partition of the given topics
+func (s *Service) resolveResetTargetOffsets(topics []string, target ResetTarget) (map[string]partitionOffsets, error) {
+	res := make(map[string]partitionOffsets, len(topics))
+	for _, topic := range topics {
+		partitionIDs, err := s.kafkaSvc.Client.Partitions(topic)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get partitions of topic '%v': %w", topic, err)
+		}
+		waterMarks, err := s.kafkaSvc.WaterMarks(topic, partitionIDs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get water marks of topic '%v': %w", topic, err)
+		}
+
+		var timestampOffsets map[int32]int64
+		if target.Type == ResetTargetTimestamp {
+			ts := time.Unix(0, target.Timestamp*int64(time.Millisecond))
+			timestampOffsets, err = s.kafkaSvc.OffsetsForTimestamp(topic, partitionIDs, ts)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get offsets for timestamp of topic '%v': %w", topic, err)
+			}
+		}
+
+		res[topic] = make(partitionOffsets, len(waterMarks))
+		for partitionID, waterMark := range waterMarks {
+			switch target.Type {
+			case ResetTargetEarliest:
+				res[topic][partitionID] = waterMark.Low
+			case ResetTargetLatest:
+				res[topic][partitionID] = waterMark.High
+			case ResetTargetTimestamp:
+				// -1 means there's no message newer than the timestamp, hence the group continues at the end
+				offset, exists := timestampOffsets[partitionID]
+				if !exists || offset < 0 {
+					offset = waterMark.High
+				}
+				res[topic][partitionID] = offset
+			}
+		}
+	}
+
+	return res, nil
+}
+
+func planOffsetsResets(groups []string, states map[string]string, offsetsByGroup map[string]map[string]partitionOffsets, targetOffsets map[string]partitionOffsets) *BulkOffsetsReset {
+	plan := &BulkOffsetsReset{Groups: make([]*GroupOffsetsReset, 0, len(groups))}
+	for _, group := range groups {
+		g := &GroupOffsetsReset{
+			GroupID:    group,
+			State:      states[group],
+			Partitions: make([]*PartitionOffsetReset, 0),
+		}
+		if g.State != GroupStateEmpty {
+			g.IsSkipped = true
+			g.SkipReason = fmt.Sprintf("group is in state '%v', only empty groups can be reset", g.State)
+		}
+
+		for topic, offsets := range offsetsByGroup[group] {
+			for partitionID, currentOffset := range offsets {
+				targetOffset, exists := targetOffsets[topic][partitionID]
+				if !exists {
+					continue
+				}
+				g.Partitions = append(g.Partitions, &PartitionOffsetReset{
+					Topic:         topic,
+					PartitionID:   partitionID,
+					CurrentOffset: currentOffset,
+					TargetOffset:  targetOffset,
+				})
+			}
+		}
+		sort.Slice(g.Partitions, func(i, j int) bool {
+			if g.Partitions[i].Topic == g.Partitions[j].Topic {
+				return g.Partitions[i].PartitionID < g.Partitions[j].PartitionID
+			}
+			return g.Partitions[i].Topic < g.Partitions[j].Topic
+		})
+		plan.Groups = append(plan.Groups, g)
+	}
+	sort.Slice(plan.Groups, func(i, j int) bool { return plan.Groups[i].GroupID < plan.Groups[j].GroupID })
+
+	return plan
+}
+
+// verifyOffsetsResetConfirmation returns an error if the plan resets groups which have not been confirmed, so that
+// groups which have started to match the pattern or have become empty since the dry run are not reset without
+// confirmation. Confirmed groups which are skipped or don't match anymore are not reset, hence they are accepted.
+func verifyOffsetsResetConfirmation(plan *BulkOffsetsReset, confirmedGroupIDs []string) error {
+	confirmed := make(map[string]struct{}, len(confirmedGroupIDs))
+	for _, group := range confirmedGroupIDs {
+		confirmed[group] = struct{}{}
+	}
+	unconfirmed := make([]string, 0)
+	for _, g := range plan.Groups {
+		if _, isConfirmed := confirmed[g.GroupID]; !isConfirmed && !g.IsSkipped {
+			unconfirmed = append(unconfirmed, g.GroupID)
+		}
+	}
+
+	if len(unconfirmed) > 0 {
+		return fmt.Errorf("refusing to reset offsets, because the groups %v have not been confirmed", unconfirmed)
+	}
+	return nil
+}
+
+// applyOffsetsResets commits the planned offsets of all groups which have not been skipped. Failed commits are
+// reported on the respective group, so that the remaining groups are still reset.
+func applyOffsetsResets(ctx context.Context, plan *BulkOffsetsReset, commit func(ctx context.Context, group string, offsets map[string]map[int32]int64) error) {
+	for _, g := range plan.Groups {
+		if g.IsSkipped || len(g.Partitions) == 0 {
+			continue
+		}
+
+		offsets := make(map[string]map[int32]int64)
+		for _, p := range g.Partitions {
+			if _, exists := offsets[p.Topic]; !exists {
+				offsets[p.Topic] = make(map[int32]int64)
+			}
+			offsets[p.Topic][p.PartitionID] = p.TargetOffset
+		}
+		if err := commit(ctx, g.GroupID, offsets); err != nil {
+			g.Error = err.Error()
+		}
+	}
+}
+
+// commitGroupOffsets commits the offsets and returns the first partition error, if any
+func (s *Service) commitGroupOffsets(ctx context.Context, group string, offsets map[string]map[int32]int64) error {
+	res, err := s.kafkaSvc.CommitConsumerGroupOffsets(ctx, group, offsets)
+	if err != nil {
+		return err
+	}
+	for topic, partitions := range res.Errors {
+		for partitionID, kErr := range partitions {
+			if kErr != sarama.ErrNoError {
+				return fmt.Errorf("failed to commit offset for topic '%v' partition %v: %w", topic, partitionID, kErr)
+			}
+		}
+	}
+
+	return nil
+}
+
+// filterTopicOffsets returns the offsets of the given topics only, or all offsets if no topics are given
+func filterTopicOffsets(offsets map[string]partitionOffsets, topics []string) map[string]partitionOffsets {
+	if len(topics) == 0 {
+		return offsets
+	}
+	res := make(map[string]partitionOffsets, len(topics))
+	for _, topic := range topics {
+		if o, exists := offsets[topic]; exists {
+			res[topic] = o
+		}
+	}
+	return res
+}
@@ -0,0 +1,125 @@
+package owl
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Shopify/sarama"
+	"github.com/cloudhut/kowl/backend/pkg/kafka"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestPlanOffsetsResets(t *testing.T) {
+	groups := []string{"reprocessing-b", "reprocessing-a"}
+	states := map[string]string{"reprocessing-a": GroupStateEmpty, "reprocessing-b": GroupStateEmpty}
+	offsetsByGroup := map[string]map[string]partitionOffsets{
+		"reprocessing-a": {"orders": {0: 500, 1: 700}},
+		"reprocessing-b": {"orders": {0: 900}},
+	}
+	targetOffsets := map[string]partitionOffsets{"orders": {0: 100, 1: 120}}
+
+	plan := planOffsetsResets(groups, states, offsetsByGroup, targetOffsets)
+	require.Len(t, plan.Groups, 2)
+	assert.Equal(t, "reprocessing-a", plan.Groups[0].GroupID)
+	assert.False(t, plan.Groups[0].IsSkipped)
+	assert.Equal(t, []*PartitionOffsetReset{
+		{Topic: "orders", PartitionID: 0, CurrentOffset: 500, TargetOffset: 100},
+		{Topic: "orders", PartitionID: 1, CurrentOffset: 700, TargetOffset: 120},
+	}, plan.Groups[0].Partitions)
+	assert.Equal(t, []*PartitionOffsetReset{
+		{Topic: "orders", PartitionID: 0, CurrentOffset: 900, TargetOffset: 100},
+	}, plan.Groups[1].Partitions)
+}
+
+func TestVerifyOffsetsResetConfirmation(t *testing.T) {
+	states := map[string]string{"etl-1": GroupStateEmpty, "etl-2": GroupStateEmpty, "etl-3": GroupStateStable}
+	plan := planOffsetsResets([]string{"etl-1", "etl-2", "etl-3"}, states, nil, nil)
+	assert.NoError(t, verifyOffsetsResetConfirmation(plan, []string{"etl-2", "etl-1"}))
+
+	// Confirmed groups which have become active or have been deleted since the dry run are not reset
+	assert.NoError(t, verifyOffsetsResetConfirmation(plan, []string{"etl-1", "etl-2", "etl-3", "etl-4"}))
+
+	// A group which has become empty or has started to match the pattern since the dry run
+	err := verifyOffsetsResetConfirmation(plan, []string{"etl-1"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "etl-2")
+}
+
+func TestService_ResetOffsetsForGroups(t *testing.T) {
+	broker := sarama.NewMockBroker(t, 1)
+	defer broker.Close()
+
+	findCoordinator := sarama.NewMockFindCoordinatorResponse(t)
+	describeGroups := sarama.NewMockDescribeGroupsResponse(t)
+	offsetFetch := sarama.NewMockOffsetFetchResponse(t)
+	listGroups := sarama.NewMockListGroupsResponse(t).AddGroup("billing", "consumer")
+	states := map[string]string{"etl-1": GroupStateEmpty, "etl-2": GroupStateStable, "etl-3": GroupStateEmpty}
+	for group, state := range states {
+		findCoordinator.SetCoordinator(sarama.CoordinatorGroup, group, broker)
+		describeGroups.AddGroupDescription(group, &sarama.GroupDescription{GroupId: group, State: state, ProtocolType: "consumer"})
+		offsetFetch.SetOffset(group, "orders", 0, 80, "", sarama.ErrNoError)
+		listGroups.AddGroup(group, "consumer")
+	}
+	broker.SetHandlerByMap(map[string]sarama.MockResponse{
+		"MetadataRequest": sarama.NewMockMetadataResponse(t).
+			SetBroker(broker.Addr(), broker.BrokerID()).
+			SetLeader("orders", 0, broker.BrokerID()),
+		"FindCoordinatorRequest": findCoordinator,
+		"ListGroupsRequest":      listGroups,
+		"DescribeGroupsRequest":  describeGroups,
+		"OffsetFetchRequest":     offsetFetch,
+		"OffsetRequest": sarama.NewMockOffsetResponse(t).
+			SetOffset("orders", 0, sarama.OffsetOldest, 10).
+			SetOffset("orders", 0, sarama.OffsetNewest, 100),
+		"OffsetCommitRequest": sarama.NewMockOffsetCommitResponse(t).
+			SetError("etl-3", "orders", 0, sarama.ErrOffsetMetadataTooLarge),
+	})
+
+	cfg := sarama.NewConfig()
+	cfg.Version = sarama.V1_0_0_0
+	client, err := sarama.NewClient([]string{broker.Addr()}, cfg)
+	require.NoError(t, err)
+	defer client.Close()
+	svc := NewService(Config{}, &kafka.Service{Client: client, Logger: zap.NewNop()}, zap.NewNop())
+
+	committedGroups := func() []string {
+		groups := make([]string, 0)
+		for _, rr := range broker.History() {
+			if req, ok := rr.Request.(*sarama.OffsetCommitRequest); ok {
+				groups = append(groups, req.ConsumerGroup)
+			}
+		}
+		return groups
+	}
+	target := ResetTarget{Type: ResetTargetEarliest}
+
+	// The dry run plans all matching groups, but reports the active group as skipped
+	plan, err := svc.ResetOffsetsForGroups(context.Background(), "^etl-", target, true)
+	require.NoError(t, err)
+	require.Len(t, plan.Groups, 3)
+	assert.True(t, plan.IsDryRun)
+	assert.True(t, plan.Groups[1].IsSkipped)
+	assert.Equal(t, []*PartitionOffsetReset{{Topic: "orders", PartitionID: 0, CurrentOffset: 80, TargetOffset: 10}}, plan.Groups[0].Partitions)
+	assert.Empty(t, committedGroups())
+
+	// Applying requires the confirmation of the dry run
+	_, err = svc.ResetOffsetsForGroups(context.Background(), "^etl-", target, false)
+	assert.Error(t, err)
+	target.ConfirmedGroupIDs = []string{"etl-1"}
+	_, err = svc.ResetOffsetsForGroups(context.Background(), "^etl-", target, false)
+	assert.Error(t, err)
+	assert.Empty(t, committedGroups())
+
+	// The apply skips the active group and resets the others, failed commits are reported on the group
+	target.ConfirmedGroupIDs = []string{"etl-1", "etl-3"}
+	plan, err = svc.ResetOffsetsForGroups(context.Background(), "^etl-", target, false)
+	require.NoError(t, err)
+	assert.False(t, plan.IsDryRun)
+	assert.ElementsMatch(t, []string{"etl-1", "etl-3"}, committedGroups())
+	assert.Empty(t, plan.Groups[0].Error)
+	assert.True(t, plan.Groups[1].IsSkipped)
+	assert.Contains(t, plan.Groups[1].SkipReason, GroupStateStable)
+	assert.NotEmpty(t, plan.Groups[2].Error)
+}
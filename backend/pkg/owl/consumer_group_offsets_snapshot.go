@@ -0,0 +1,130 @@
+package owl
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+)
+
+const (
+	// OffsetDriftAdvanced means the committed offset has moved forward since the snapshot (expected behaviour)
+	OffsetDriftAdvanced = "advanced"
+	// OffsetDriftUnchanged means the committed offset is still the same as in the snapshot
+	OffsetDriftUnchanged = "unchanged"
+	// OffsetDriftMovedBackward means the committed offset is lower than in the snapshot, which indicates a reset
+	OffsetDriftMovedBackward = "movedBackward"
+	// OffsetDriftDisappeared means there's no committed offset anymore for a partition that is part of the snapshot
+	OffsetDriftDisappeared = "disappeared"
+	// OffsetDriftNew means there's a committed offset for a partition which is not part of the snapshot
+	OffsetDriftNew = "new"
+)
+
+// ConsumerGroupOffsetsSnapshot is an export of a group's committed offsets at a given time
+type ConsumerGroupOffsetsSnapshot struct {
+	GroupID   string                      `json:"groupId"`
+	CreatedAt time.Time                   `json:"createdAt"`
+	Offsets   map[string]partitionOffsets `json:"offsets"` // TopicName -> PartitionID -> Offset
+}
+
+// ConsumerGroupOffsetsDrift describes how a group's committed offsets have changed since a snapshot
+type ConsumerGroupOffsetsDrift struct {
+	GroupID           string         `json:"groupId"`
+	SnapshotCreatedAt time.Time      `json:"snapshotCreatedAt"`
+	HasMovedBackward  bool           `json:"hasMovedBackward"` // True if at least one partition offset has been reset
+	Partitions        []*OffsetDrift `json:"partitions"`
+}
+
+// OffsetDrift describes the change of a single partition's committed offset
+type OffsetDrift struct {
+	Topic          string `json:"topic"`
+	PartitionID    int32  `json:"partitionId"`
+	SnapshotOffset int64  `json:"snapshotOffset"` // -1 if the partition is not part of the snapshot
+	CurrentOffset  int64  `json:"currentOffset"`  // -1 if there's no committed offset anymore
+	Delta          int64  `json:"delta"`
+	Status         string `json:"status"`
+}
+
+// ExportConsumerGroupOffsets returns a snapshot of the group's currently committed offsets
+func (s *Service) ExportConsumerGroupOffsets(ctx context.Context, groupID string) (*ConsumerGroupOffsetsSnapshot, error) {
+	offsetsByGroup, err := s.kafkaSvc.ListConsumerGroupOffsetsBulk(ctx, []string{groupID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list consumer group offsets: %w", err)
+	}
+	offsets, exists := offsetsByGroup[groupID]
+	if !exists {
+		return nil, fmt.Errorf("no offsets have been returned for consumer group '%v'", groupID)
+	}
+
+	return &ConsumerGroupOffsetsSnapshot{
+		GroupID:   groupID,
+		CreatedAt: time.Now(),
+		Offsets:   convertOffsets(offsets),
+	}, nil
+}
+
+// CompareConsumerGroupOffsets compares the group's currently committed offsets against a previously exported
+// snapshot. Partitions whose offset moved backwards indicate an unexpected offset reset.
+func (s *Service) CompareConsumerGroupOffsets(ctx context.Context, snapshot *ConsumerGroupOffsetsSnapshot) (*ConsumerGroupOffsetsDrift, error) {
+	current, err := s.ExportConsumerGroupOffsets(ctx, snapshot.GroupID)
+	if err != nil {
+		return nil, err
+	}
+
+	return compareOffsetsSnapshots(snapshot, current), nil
+}
+
+func compareOffsetsSnapshots(reference *ConsumerGroupOffsetsSnapshot, current *ConsumerGroupOffsetsSnapshot) *ConsumerGroupOffsetsDrift {
+	drift := &ConsumerGroupOffsetsDrift{
+		GroupID:           reference.GroupID,
+		SnapshotCreatedAt: reference.CreatedAt,
+		Partitions:        make([]*OffsetDrift, 0),
+	}
+
+	for topic, partitions := range reference.Offsets {
+		for partitionID, referenceOffset := range partitions {
+			d := &OffsetDrift{Topic: topic, PartitionID: partitionID, SnapshotOffset: referenceOffset, CurrentOffset: -1}
+			currentOffset, exists := current.Offsets[topic][partitionID]
+			switch {
+			case !exists:
+				d.Status = OffsetDriftDisappeared
+			case currentOffset > referenceOffset:
+				d.Status = OffsetDriftAdvanced
+			case currentOffset < referenceOffset:
+				d.Status = OffsetDriftMovedBackward
+				drift.HasMovedBackward = true
+			default:
+				d.Status = OffsetDriftUnchanged
+			}
+			if exists {
+				d.CurrentOffset = currentOffset
+				d.Delta = currentOffset - referenceOffset
+			}
+			drift.Partitions = append(drift.Partitions, d)
+		}
+	}
+
+	for topic, partitions := range current.Offsets {
+		for partitionID, currentOffset := range partitions {
+			if _, exists := reference.Offsets[topic][partitionID]; exists {
+				continue
+			}
+			drift.Partitions = append(drift.Partitions, &OffsetDrift{
+				Topic:          topic,
+				PartitionID:    partitionID,
+				SnapshotOffset: -1,
+				CurrentOffset:  currentOffset,
+				Status:         OffsetDriftNew,
+			})
+		}
+	}
+
+	sort.Slice(drift.Partitions, func(i, j int) bool {
+		if drift.Partitions[i].Topic == drift.Partitions[j].Topic {
+			return drift.Partitions[i].PartitionID < drift.Partitions[j].PartitionID
+		}
+		return drift.Partitions[i].Topic < drift.Partitions[j].Topic
+	})
+
+	return drift
+}
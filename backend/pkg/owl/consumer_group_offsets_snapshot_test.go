@@ -0,0 +1,36 @@
+package owl
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompareOffsetsSnapshots(t *testing.T) {
+	reference := &ConsumerGroupOffsetsSnapshot{
+		GroupID:   "billing",
+		CreatedAt: time.Now().Add(-time.Hour),
+		Offsets:   map[string]partitionOffsets{"invoices": {0: 100, 1: 500}},
+	}
+	current := &ConsumerGroupOffsetsSnapshot{
+		GroupID:   "billing",
+		CreatedAt: time.Now(),
+		Offsets:   map[string]partitionOffsets{"invoices": {0: 150, 1: 20}},
+	}
+
+	drift := compareOffsetsSnapshots(reference, current)
+	require.Len(t, drift.Partitions, 2)
+	assert.True(t, drift.HasMovedBackward)
+
+	advanced := drift.Partitions[0]
+	assert.Equal(t, int32(0), advanced.PartitionID)
+	assert.Equal(t, OffsetDriftAdvanced, advanced.Status)
+	assert.Equal(t, int64(50), advanced.Delta)
+
+	reset := drift.Partitions[1]
+	assert.Equal(t, int32(1), reset.PartitionID)
+	assert.Equal(t, OffsetDriftMovedBackward, reset.Status)
+	assert.Equal(t, int64(-480), reset.Delta)
+}
@@ -3,6 +3,7 @@ package owl
 import (
 	"context"
 	"sort"
+	"time"
 
 	"github.com/Shopify/sarama"
 	"go.uber.org/zap"
@@ -18,6 +19,12 @@ type ConsumerGroupOverview struct {
 	CoordinatorID  int32                     `json:"coordinatorId"`
 	Lags           *ConsumerGroupLag         `json:"lag"`
 	AllowedActions []string                  `json:"allowedActions"`
+
+	// CommitFrequency is estimated from the lag history, nil if the lag sampler is disabled
+	CommitFrequency *ConsumerGroupCommitFrequency `json:"commitFrequency,omitempty"`
+
+	// EmptySince is the time since when the group has been observed in the Empty state, nil if it's not empty
+	EmptySince *time.Time `json:"emptySince,omitempty"`
 }
 
 // GroupMemberDescription is a member (e. g. connected host) of a Consumer Group
@@ -26,6 +33,9 @@ type GroupMemberDescription struct {
 	ClientID    string                   `json:"clientId"`
 	ClientHost  string                   `json:"clientHost"`
 	Assignments []*GroupMemberAssignment `json:"assignments"`
+
+	// Timeouts are the member's session and rebalance timeouts, which are usually unknown
+	Timeouts *GroupMemberTimeouts `json:"timeouts"`
 }
 
 // GroupMemberAssignment represents a partition assignment for a group member
@@ -50,6 +60,7 @@ func (s *Service) GetConsumerGroupsOverview(ctx context.Context) ([]*ConsumerGro
 	if err != nil {
 		return nil, err
 	}
+	s.attachRecentProduceRates(groupLags)
 
 	res := make([]*ConsumerGroupOverview, 0)
 	for id, group := range describedGroups {
@@ -65,6 +76,7 @@ func (s *Service) GetConsumerGroupsOverview(ctx context.Context) ([]*ConsumerGro
 }
 
 func (s *Service) convertSaramaGroupDescriptions(descriptions []*sarama.GroupDescription, lags map[string]*ConsumerGroupLag, coordinator int32) ([]*ConsumerGroupOverview, error) {
+	now := time.Now()
 	response := make([]*ConsumerGroupOverview, len(descriptions))
 	for i, d := range descriptions {
 		if d.Err != sarama.ErrNoError {
@@ -83,6 +95,11 @@ func (s *Service) convertSaramaGroupDescriptions(descriptions []*sarama.GroupDes
 			Members:       members,
 			CoordinatorID: coordinator,
 			Lags:          lags[d.GroupId],
+
+			CommitFrequency: s.getConsumerGroupCommitFrequency(d.GroupId),
+		}
+		if since := s.groupStates.observe(d.GroupId, d.State, now); !since.IsZero() {
+			response[i].EmptySince = &since
 		}
 	}
 
@@ -103,7 +120,10 @@ func (s *Service) convertGroupMembers(members map[string]*sarama.GroupMemberDesc
 		// see: https://cwiki.apache.org/confluence/display/KAFKA/A+Guide+To+The+Kafka+Protocol
 
 		resultAssignments := make([]*GroupMemberAssignment, 0)
+		timeouts := &GroupMemberTimeouts{}
 		if protocolType == "consumer" {
+			timeouts = getMemberTimeouts(m)
+
 			assignments, err := m.GetMemberAssignment()
 			if err != nil {
 				s.logger.Warn("failed to decode member assignments", zap.String("client_id", m.ClientId), zap.Error(err))
@@ -130,6 +150,7 @@ func (s *Service) convertGroupMembers(members map[string]*sarama.GroupMemberDesc
 			ClientID:    m.ClientId,
 			ClientHost:  m.ClientHost,
 			Assignments: resultAssignments,
+			Timeouts:    timeouts,
 		}
 		counter++
 	}
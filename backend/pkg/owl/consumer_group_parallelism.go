@@ -0,0 +1,111 @@
+package owl
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// ConsumerGroupParallelism compares a group's members with the partitions of the topics they consume. A group
+// with fewer members than partitions can't consume all partitions in parallel, a group with more members than
+// partitions has idle members.
+type ConsumerGroupParallelism struct {
+	GroupID            string `json:"groupId"`
+	MemberCount        int    `json:"memberCount"`
+	ActiveMemberCount  int    `json:"activeMemberCount"` // Members with at least one assigned partition
+	PartitionCount     int    `json:"partitionCount"`    // Partitions of all topics which are assigned to the group
+	AssignedPartitions int    `json:"assignedPartitions"`
+
+	// MemberUtilization is the percentage of members which are assigned at least one partition
+	MemberUtilization float64 `json:"memberUtilization"`
+	// PartitionParallelism is the percentage of partitions which can be consumed in parallel by the active members
+	PartitionParallelism float64 `json:"partitionParallelism"`
+
+	IsUnderParallelized bool                 `json:"isUnderParallelized"` // Fewer members than partitions
+	IdleMembers         []*MemberParallelism `json:"idleMembers"`
+	// OverloadedMembers are assigned more partitions than an even distribution across all members would give them
+	OverloadedMembers []*MemberParallelism `json:"overloadedMembers"`
+}
+
+// MemberParallelism is a group member along with the number of partitions assigned to it
+type MemberParallelism struct {
+	MemberID           string `json:"memberId"`
+	ClientID           string `json:"clientId"`
+	ClientHost         string `json:"clientHost"`
+	AssignedPartitions int    `json:"assignedPartitions"`
+}
+
+// GetConsumerGroupParallelism returns how well the group's members are utilized by the partitions of the topics
+// they consume, reporting idle members and members which are assigned more than their share of partitions.
+func (s *Service) GetConsumerGroupParallelism(ctx context.Context, groupID string) (*ConsumerGroupParallelism, error) {
+	description, err := s.describeConsumerGroup(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+	members, err := s.convertGroupMembers(description.Members, description.ProtocolType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert group members: %w", err)
+	}
+
+	partitionCounts := make(map[string]int)
+	for topicName := range assignedPartitions(members) {
+		partitionIDs, err := s.kafkaSvc.ListPartitions(topicName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get partitions of topic '%v': %w", topicName, err)
+		}
+		partitionCounts[topicName] = len(partitionIDs)
+	}
+
+	return newConsumerGroupParallelism(groupID, members, partitionCounts), nil
+}
+
+func newConsumerGroupParallelism(groupID string, members []*GroupMemberDescription, partitionCounts map[string]int) *ConsumerGroupParallelism {
+	res := &ConsumerGroupParallelism{
+		GroupID:           groupID,
+		MemberCount:       len(members),
+		IdleMembers:       make([]*MemberParallelism, 0),
+		OverloadedMembers: make([]*MemberParallelism, 0),
+	}
+	for _, count := range partitionCounts {
+		res.PartitionCount += count
+	}
+
+	memberParallelism := make([]*MemberParallelism, 0, len(members))
+	for _, member := range members {
+		m := &MemberParallelism{MemberID: member.ID, ClientID: member.ClientID, ClientHost: member.ClientHost}
+		for _, assignment := range member.Assignments {
+			m.AssignedPartitions += len(assignment.PartitionIDs)
+		}
+		res.AssignedPartitions += m.AssignedPartitions
+		if m.AssignedPartitions > 0 {
+			res.ActiveMemberCount++
+		}
+		memberParallelism = append(memberParallelism, m)
+	}
+	sort.Slice(memberParallelism, func(i, j int) bool { return memberParallelism[i].MemberID < memberParallelism[j].MemberID })
+
+	// An even distribution of the assigned partitions across all members gives each member at most its fair share
+	fairShare := 0
+	if len(members) > 0 {
+		fairShare = int(math.Ceil(float64(res.AssignedPartitions) / float64(len(members))))
+	}
+	for _, m := range memberParallelism {
+		switch {
+		case m.AssignedPartitions == 0:
+			res.IdleMembers = append(res.IdleMembers, m)
+		case m.AssignedPartitions > fairShare:
+			res.OverloadedMembers = append(res.OverloadedMembers, m)
+		}
+	}
+
+	if res.MemberCount > 0 {
+		res.MemberUtilization = 100 * float64(res.ActiveMemberCount) / float64(res.MemberCount)
+	}
+	if res.PartitionCount > 0 {
+		res.PartitionParallelism = 100 * math.Min(float64(res.ActiveMemberCount)/float64(res.PartitionCount), 1)
+	}
+	res.IsUnderParallelized = res.MemberCount < res.PartitionCount
+
+	return res
+}
@@ -0,0 +1,40 @@
+package owl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewConsumerGroupParallelism(t *testing.T) {
+	// 12 partitions consumed by 3 members, one of them has been assigned half of the partitions
+	underParallelized := []*GroupMemberDescription{
+		{ID: "a", Assignments: []*GroupMemberAssignment{{TopicName: "orders", PartitionIDs: []int32{0, 1, 2, 3, 4, 5}}}},
+		{ID: "b", Assignments: []*GroupMemberAssignment{{TopicName: "orders", PartitionIDs: []int32{6, 7, 8}}}},
+		{ID: "c", Assignments: []*GroupMemberAssignment{{TopicName: "orders", PartitionIDs: []int32{9, 10, 11}}}},
+	}
+	res := newConsumerGroupParallelism("billing", underParallelized, map[string]int{"orders": 12})
+	assert.True(t, res.IsUnderParallelized)
+	assert.Equal(t, 100.0, res.MemberUtilization)
+	assert.Equal(t, 25.0, res.PartitionParallelism)
+	assert.Empty(t, res.IdleMembers)
+	assert.Equal(t, []*MemberParallelism{{MemberID: "a", AssignedPartitions: 6}}, res.OverloadedMembers)
+
+	// 6 partitions consumed by 8 members, so that two of them are idle
+	members := make([]*GroupMemberDescription, 0)
+	for i, id := range []string{"a", "b", "c", "d", "e", "f", "g", "h"} {
+		member := &GroupMemberDescription{ID: id, Assignments: []*GroupMemberAssignment{}}
+		if i < 6 {
+			member.Assignments = append(member.Assignments, &GroupMemberAssignment{TopicName: "orders", PartitionIDs: []int32{int32(i)}})
+		}
+		members = append(members, member)
+	}
+	res = newConsumerGroupParallelism("billing", members, map[string]int{"orders": 6})
+	assert.False(t, res.IsUnderParallelized)
+	assert.Equal(t, 8, res.MemberCount)
+	assert.Equal(t, 6, res.ActiveMemberCount)
+	assert.Equal(t, 75.0, res.MemberUtilization)
+	assert.Equal(t, 100.0, res.PartitionParallelism)
+	assert.Equal(t, []*MemberParallelism{{MemberID: "g"}, {MemberID: "h"}}, res.IdleMembers)
+	assert.Empty(t, res.OverloadedMembers)
+}
@@ -0,0 +1,132 @@
+package owl
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Shopify/sarama"
+	"github.com/cloudhut/kowl/backend/pkg/kafka"
+)
+
+// PartitionLagDetail is everything that is known about a single partition from a consumer group's perspective.
+// Member fields are blank if the partition is not assigned to any member.
+type PartitionLagDetail struct {
+	PartitionID        int32   `json:"partitionId"`
+	HasCommittedOffset bool    `json:"hasCommittedOffset"`
+	CommittedOffset    int64   `json:"committedOffset"` // -1 if the group has no committed offset
+	LowWaterMark       int64   `json:"lowWaterMark"`
+	HighWaterMark      int64   `json:"highWaterMark"`
+	Lag                int64   `json:"lag"`
+	LagFraction        float64 `json:"lagFraction"` // Lag relative to the number of messages in the partition (0-1)
+
+	MemberID string `json:"memberId"`
+	ClientID string `json:"clientId"`
+	Host     string `json:"host"`
+}
+
+// GetConsumerGroupPartitionDetails returns the detailed lag of all partitions of the given topic along with the
+// member each partition is assigned to.
+func (s *Service) GetConsumerGroupPartitionDetails(ctx context.Context, groupID string, topicName string) ([]*PartitionLagDetail, error) {
+	description, err := s.describeConsumerGroup(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+	members, err := s.convertGroupMembers(description.Members, description.ProtocolType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert group members: %w", err)
+	}
+
+	offsets, err := s.kafkaSvc.ListConsumerGroupOffsets(groupID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list consumer group offsets: %w", err)
+	}
+
+	partitionIDs, err := s.kafkaSvc.Client.Partitions(topicName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get partitions: %w", err)
+	}
+	waterMarks, err := s.kafkaSvc.WaterMarks(topicName, partitionIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get watermarks: %w", err)
+	}
+
+	return newPartitionLagDetails(topicName, waterMarks, convertOffsets(offsets)[topicName], members), nil
+}
+
+func newPartitionLagDetails(topicName string, waterMarks map[int32]*kafka.WaterMark, offsets partitionOffsets, members []*GroupMemberDescription) []*PartitionLagDetail {
+	ownerByPartition := make(map[int32]*GroupMemberDescription)
+	for _, member := range members {
+		for _, assignment := range member.Assignments {
+			if assignment.TopicName != topicName {
+				continue
+			}
+			for _, partitionID := range assignment.PartitionIDs {
+				ownerByPartition[partitionID] = member
+			}
+		}
+	}
+
+	res := make([]*PartitionLagDetail, 0, len(waterMarks))
+	for partitionID, waterMark := range waterMarks {
+		d := &PartitionLagDetail{
+			PartitionID:     partitionID,
+			CommittedOffset: -1,
+			LowWaterMark:    waterMark.Low,
+			HighWaterMark:   waterMark.High,
+		}
+
+		// Offsets of -1 are returned by Kafka for partitions without a committed offset
+		if committedOffset, exists := offsets[partitionID]; exists && committedOffset >= 0 {
+			d.HasCommittedOffset = true
+			d.CommittedOffset = committedOffset
+			d.Lag = waterMark.High - committedOffset
+			if d.Lag < 0 {
+				d.Lag = 0
+			}
+			if messageCount := waterMark.High - waterMark.Low; messageCount > 0 {
+				d.LagFraction = float64(d.Lag) / float64(messageCount)
+				if d.LagFraction > 1 {
+					// The committed offset may be below the low water mark if the messages have been deleted already
+					d.LagFraction = 1
+				}
+			}
+		}
+
+		if owner, exists := ownerByPartition[partitionID]; exists {
+			d.MemberID = owner.ID
+			d.ClientID = owner.ClientID
+			d.Host = strings.TrimPrefix(owner.ClientHost, "/")
+		}
+		res = append(res, d)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].PartitionID < res[j].PartitionID })
+
+	return res
+}
+
+// describeConsumerGroup returns the group description as reported by the group's coordinator
+func (s *Service) describeConsumerGroup(ctx context.Context, groupID string) (*sarama.GroupDescription, error) {
+	describedGroups, err := s.kafkaSvc.DescribeConsumerGroups(ctx, []string{groupID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe consumer group: %w", err)
+	}
+
+	var description *sarama.GroupDescription
+	for _, res := range describedGroups {
+		for _, group := range res.Groups {
+			if group.GroupId == groupID {
+				description = group
+			}
+		}
+	}
+	if description == nil {
+		return nil, fmt.Errorf("consumer group '%v' has not been described by its coordinator", groupID)
+	}
+	if description.Err != sarama.ErrNoError {
+		return nil, fmt.Errorf("failed to describe consumer group: %w", description.Err)
+	}
+
+	return description, nil
+}
@@ -0,0 +1,45 @@
+package owl
+
+import (
+	"testing"
+
+	"github.com/cloudhut/kowl/backend/pkg/kafka"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewPartitionLagDetails(t *testing.T) {
+	waterMarks := map[int32]*kafka.WaterMark{
+		0: {PartitionID: 0, Low: 100, High: 300},
+		1: {PartitionID: 1, Low: 0, High: 50},
+	}
+	offsets := partitionOffsets{0: 250}
+	members := []*GroupMemberDescription{{
+		ID:          "consumer-1-4f2a",
+		ClientID:    "consumer-1",
+		ClientHost:  "/10.0.0.7",
+		Assignments: []*GroupMemberAssignment{{TopicName: "orders", PartitionIDs: []int32{0}}},
+	}}
+
+	details := newPartitionLagDetails("orders", waterMarks, offsets, members)
+	require.Len(t, details, 2)
+
+	assert.Equal(t, &PartitionLagDetail{
+		PartitionID:        0,
+		HasCommittedOffset: true,
+		CommittedOffset:    250,
+		LowWaterMark:       100,
+		HighWaterMark:      300,
+		Lag:                50,
+		LagFraction:        0.25,
+		MemberID:           "consumer-1-4f2a",
+		ClientID:           "consumer-1",
+		Host:               "10.0.0.7",
+	}, details[0])
+
+	// Neither committed nor assigned
+	assert.False(t, details[1].HasCommittedOffset)
+	assert.Equal(t, int64(-1), details[1].CommittedOffset)
+	assert.Empty(t, details[1].MemberID)
+	assert.Empty(t, details[1].Host)
+}
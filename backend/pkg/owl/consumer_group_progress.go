@@ -0,0 +1,102 @@
+package owl
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/cloudhut/kowl/backend/pkg/kafka"
+)
+
+// TopicConsumptionProgress describes how much of a topic's retained data a consumer group has consumed
+type TopicConsumptionProgress struct {
+	Topic string `json:"topic"`
+
+	// ConsumedPercent is the share of the retained messages of all partitions the group has consumed (0-100),
+	// weighted by the partitions' retained ranges. It's 0 if no messages are retained.
+	ConsumedPercent  float64                         `json:"consumedPercent"`
+	ConsumedMessages int64                           `json:"consumedMessages"`
+	RetainedMessages int64                           `json:"retainedMessages"`
+	Partitions       []*PartitionConsumptionProgress `json:"partitions"`
+}
+
+// PartitionConsumptionProgress describes how much of a partition's retained data a consumer group has consumed
+type PartitionConsumptionProgress struct {
+	PartitionID      int32   `json:"partitionId"`
+	ConsumedPercent  float64 `json:"consumedPercent"`
+	ConsumedMessages int64   `json:"consumedMessages"` // Committed offset - low water mark
+	RetainedMessages int64   `json:"retainedMessages"` // High water mark - low water mark
+}
+
+// GetConsumerGroupProgress returns the progress of the group through the retained data of each consumed topic, e.g.
+// "73% of the retained messages have been consumed". Only partitions with a committed offset are considered.
+func (s *Service) GetConsumerGroupProgress(ctx context.Context, groupID string) ([]*TopicConsumptionProgress, error) {
+	lags, err := s.getConsumerGroupLags(ctx, []string{groupID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get consumer group lags: %w", err)
+	}
+	lag, exists := lags[groupID]
+	if !exists {
+		return nil, fmt.Errorf("no lag has been returned for consumer group '%v'", groupID)
+	}
+
+	res := make([]*TopicConsumptionProgress, 0, len(lag.TopicLags))
+	for _, topicLag := range lag.TopicLags {
+		partitionIDs := make([]int32, len(topicLag.PartitionLags))
+		for i, partitionLag := range topicLag.PartitionLags {
+			partitionIDs[i] = partitionLag.PartitionID
+		}
+		waterMarks, err := s.kafkaSvc.WaterMarks(topicLag.Topic, partitionIDs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get water marks for topic '%v': %w", topicLag.Topic, err)
+		}
+		res = append(res, newTopicConsumptionProgress(topicLag, waterMarks))
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Topic < res[j].Topic })
+
+	return res, nil
+}
+
+func newTopicConsumptionProgress(topicLag *TopicLag, waterMarks map[int32]*kafka.WaterMark) *TopicConsumptionProgress {
+	res := &TopicConsumptionProgress{
+		Topic:      topicLag.Topic,
+		Partitions: make([]*PartitionConsumptionProgress, 0, len(topicLag.PartitionLags)),
+	}
+
+	for _, partitionLag := range topicLag.PartitionLags {
+		waterMark, exists := waterMarks[partitionLag.PartitionID]
+		if !exists {
+			continue
+		}
+
+		p := &PartitionConsumptionProgress{PartitionID: partitionLag.PartitionID}
+		if waterMark.High > waterMark.Low {
+			p.RetainedMessages = waterMark.High - waterMark.Low
+		}
+		// A committed offset below the low water mark points to already deleted messages, one beyond the high water
+		// mark can be observed if the water marks have been fetched before the offset has been committed.
+		p.ConsumedMessages = partitionLag.CommittedOffset - waterMark.Low
+		if p.ConsumedMessages < 0 {
+			p.ConsumedMessages = 0
+		}
+		if p.ConsumedMessages > p.RetainedMessages {
+			p.ConsumedMessages = p.RetainedMessages
+		}
+		p.ConsumedPercent = consumedPercent(p.ConsumedMessages, p.RetainedMessages)
+
+		res.ConsumedMessages += p.ConsumedMessages
+		res.RetainedMessages += p.RetainedMessages
+		res.Partitions = append(res.Partitions, p)
+	}
+	res.ConsumedPercent = consumedPercent(res.ConsumedMessages, res.RetainedMessages)
+	sort.Slice(res.Partitions, func(i, j int) bool { return res.Partitions[i].PartitionID < res.Partitions[j].PartitionID })
+
+	return res
+}
+
+func consumedPercent(consumed int64, retained int64) float64 {
+	if retained == 0 {
+		return 0
+	}
+	return float64(consumed) / float64(retained) * 100
+}
@@ -0,0 +1,44 @@
+package owl
+
+import (
+	"testing"
+
+	"github.com/cloudhut/kowl/backend/pkg/kafka"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewTopicConsumptionProgress(t *testing.T) {
+	topicLag := &TopicLag{
+		Topic: "orders",
+		PartitionLags: []PartitionLag{
+			{PartitionID: 1, CommittedOffset: 1000},
+			{PartitionID: 0, CommittedOffset: 175},
+			{PartitionID: 2, CommittedOffset: 10}, // Below the low water mark
+		},
+	}
+	waterMarks := map[int32]*kafka.WaterMark{
+		0: {PartitionID: 0, Low: 100, High: 200},
+		1: {PartitionID: 1, Low: 0, High: 1000},
+		2: {PartitionID: 2, Low: 50, High: 150},
+	}
+
+	res := newTopicConsumptionProgress(topicLag, waterMarks)
+	assert.Equal(t, int64(1075), res.ConsumedMessages)
+	assert.Equal(t, int64(1200), res.RetainedMessages)
+	assert.InDelta(t, 89.583, res.ConsumedPercent, 0.001)
+	require.Len(t, res.Partitions, 3)
+	assert.Equal(t, &PartitionConsumptionProgress{PartitionID: 0, ConsumedPercent: 75, ConsumedMessages: 75, RetainedMessages: 100}, res.Partitions[0])
+	assert.Equal(t, float64(100), res.Partitions[1].ConsumedPercent)
+	assert.Equal(t, float64(0), res.Partitions[2].ConsumedPercent)
+}
+
+func TestNewTopicConsumptionProgress_Empty(t *testing.T) {
+	topicLag := &TopicLag{Topic: "orders", PartitionLags: []PartitionLag{{PartitionID: 0, CommittedOffset: 500}}}
+	waterMarks := map[int32]*kafka.WaterMark{0: {PartitionID: 0, Low: 500, High: 500}}
+
+	res := newTopicConsumptionProgress(topicLag, waterMarks)
+	assert.Equal(t, float64(0), res.ConsumedPercent)
+	assert.Equal(t, int64(0), res.RetainedMessages)
+	assert.Equal(t, float64(0), res.Partitions[0].ConsumedPercent)
+}
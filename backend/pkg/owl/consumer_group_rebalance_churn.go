@@ -0,0 +1,107 @@
+package owl
+
+import (
+	"fmt"
+	"time"
+)
+
+// Dominant causes of a consumer group's lag growth
+const (
+	LagGrowthCauseNone       = "none"
+	LagGrowthCauseRebalances = "rebalances" // Consider the cooperative assignor or static membership
+	LagGrowthCauseThroughput = "throughput" // Consider adding consumers or speeding up the processing
+)
+
+// RebalanceChurnAttribution splits a group's lag growth within the lag history into the growth during rebalances
+// (including their settle window) and the growth in between, which is caused by an insufficient throughput.
+type RebalanceChurnAttribution struct {
+	GroupID    string    `json:"groupId"`
+	Since      time.Time `json:"since"`
+	Rebalances int       `json:"rebalances"`
+
+	// RebalanceDuration is the time spent in rebalances and their settle windows
+	RebalanceDuration time.Duration `json:"rebalanceDuration"`
+
+	// The lag growth sums up all increases of the summed lag between two consecutive samples, decreases are ignored
+	TotalLagGrowth             int64   `json:"totalLagGrowth"`
+	RebalanceAttributedGrowth  int64   `json:"rebalanceAttributedGrowth"`
+	ThroughputAttributedGrowth int64   `json:"throughputAttributedGrowth"`
+	RebalanceShare             float64 `json:"rebalanceShare"` // Percentage of the lag growth attributed to rebalances
+	DominantCause              string  `json:"dominantCause"`
+}
+
+// GetConsumerGroupRebalanceChurn attributes the group's lag growth since the given time to rebalances or to an
+// insufficient throughput. Lag growth between the last sample before a rebalance and the end of its settle window
+// (5 minutes by default) is attributed to the rebalance.
+func (s *Service) GetConsumerGroupRebalanceChurn(groupID string, since time.Time, settleWindow time.Duration) (*RebalanceChurnAttribution, error) {
+	if s.lagHistory == nil {
+		return nil, ErrLagHistoryDisabled
+	}
+	if settleWindow < 0 {
+		return nil, fmt.Errorf("settle window must not be negative")
+	}
+	if settleWindow == 0 {
+		settleWindow = defaultRebalanceSettleWindow
+	}
+
+	return attributeRebalanceChurn(groupID, since, s.lagHistory.get(groupID, since), settleWindow), nil
+}
+
+func attributeRebalanceChurn(groupID string, since time.Time, samples []*LagSample, settleWindow time.Duration) *RebalanceChurnAttribution {
+	res := &RebalanceChurnAttribution{GroupID: groupID, Since: since, DominantCause: LagGrowthCauseNone}
+
+	// Rebalance periods range from the last sample before the rebalance until the end of its settle window, but
+	// not beyond the next rebalance
+	impacts := detectRebalanceImpacts(samples, settleWindow)
+	type period struct{ start, end time.Time }
+	periods := make([]period, len(impacts))
+	for i := range impacts {
+		// Impacts are sorted most recent first
+		impact := impacts[len(impacts)-1-i]
+		periods[i] = period{start: impact.LastSampleBefore, end: impact.FirstSampleAfter.Add(settleWindow)}
+		if i > 0 && periods[i-1].end.After(periods[i].start) {
+			periods[i-1].end = periods[i].start
+		}
+	}
+	res.Rebalances = len(periods)
+	for _, p := range periods {
+		res.RebalanceDuration += p.end.Sub(p.start)
+	}
+	isDuringRebalance := func(t time.Time) bool {
+		for _, p := range periods {
+			if t.After(p.start) && !t.After(p.end) {
+				return true
+			}
+		}
+		return false
+	}
+
+	var previous *LagSample
+	for _, sample := range samples {
+		if sample.Lag == nil {
+			continue
+		}
+		if previous != nil {
+			growth := summedGroupLag(sample.Lag) - summedGroupLag(previous.Lag)
+			if growth > 0 {
+				if isDuringRebalance(sample.Timestamp) {
+					res.RebalanceAttributedGrowth += growth
+				} else {
+					res.ThroughputAttributedGrowth += growth
+				}
+			}
+		}
+		previous = sample
+	}
+
+	res.TotalLagGrowth = res.RebalanceAttributedGrowth + res.ThroughputAttributedGrowth
+	if res.TotalLagGrowth > 0 {
+		res.RebalanceShare = 100 * float64(res.RebalanceAttributedGrowth) / float64(res.TotalLagGrowth)
+		res.DominantCause = LagGrowthCauseThroughput
+		if res.RebalanceAttributedGrowth > res.ThroughputAttributedGrowth {
+			res.DominantCause = LagGrowthCauseRebalances
+		}
+	}
+
+	return res
+}
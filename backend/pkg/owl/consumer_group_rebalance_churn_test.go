@@ -0,0 +1,54 @@
+package owl
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAttributeRebalanceChurn(t *testing.T) {
+	start := time.Date(2020, 7, 1, 8, 0, 0, 0, time.UTC)
+	newSample := func(minute int, fingerprint string, lag int64) *LagSample {
+		return &LagSample{
+			Timestamp: start.Add(time.Duration(minute) * time.Minute),
+			Lag: &ConsumerGroupLag{
+				GroupID:               "billing",
+				TopicLags:             []*TopicLag{{Topic: "orders", SummedLag: lag}},
+				MembershipFingerprint: fingerprint,
+			},
+		}
+	}
+
+	// The group falls behind by 10 messages per minute, the rebalance between minute 3 and 4 adds a spike
+	samples := []*LagSample{
+		newSample(0, "a", 100),
+		newSample(1, "a", 110),
+		newSample(2, "a", 120),
+		newSample(3, "a", 130),
+		newSample(4, "b", 630),
+		newSample(5, "b", 930),
+		newSample(6, "b", 900), // Catching up after the rebalance
+		newSample(7, "b", 910), // End of the settle window
+		newSample(8, "b", 920),
+		newSample(9, "b", 930),
+	}
+
+	res := attributeRebalanceChurn("billing", start, samples, 3*time.Minute)
+	assert.Equal(t, 1, res.Rebalances)
+	assert.Equal(t, 4*time.Minute, res.RebalanceDuration)
+	assert.Equal(t, int64(810), res.RebalanceAttributedGrowth)
+	assert.Equal(t, int64(50), res.ThroughputAttributedGrowth)
+	assert.Equal(t, int64(860), res.TotalLagGrowth)
+	assert.InDelta(t, 94.19, res.RebalanceShare, 0.01)
+	assert.Equal(t, LagGrowthCauseRebalances, res.DominantCause)
+
+	// Without rebalances all lag growth is caused by the throughput
+	steady := []*LagSample{newSample(0, "a", 100), newSample(1, "a", 150), newSample(2, "a", 200)}
+	res = attributeRebalanceChurn("billing", start, steady, 3*time.Minute)
+	assert.Equal(t, 0, res.Rebalances)
+	assert.Equal(t, int64(100), res.ThroughputAttributedGrowth)
+	assert.Equal(t, LagGrowthCauseThroughput, res.DominantCause)
+
+	assert.Equal(t, LagGrowthCauseNone, attributeRebalanceChurn("billing", start, nil, 3*time.Minute).DominantCause)
+}
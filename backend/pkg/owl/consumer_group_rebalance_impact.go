@@ -0,0 +1,146 @@
+package owl
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"go.uber.org/zap"
+)
+
+// defaultRebalanceSettleWindow is the time after a rebalance in which the peak lag is attributed to the rebalance
+const defaultRebalanceSettleWindow = 5 * time.Minute
+
+// RebalanceImpact quantifies how the group's summed lag changed around a detected rebalance. Rebalances are
+// detected by a changed membership (members or their assignments) between two consecutive samples, because Kafka
+// does not expose the group's generation. Rebalances which result in the very same membership are not detected.
+type RebalanceImpact struct {
+	// LastSampleBefore and FirstSampleAfter are the samples between which the rebalance has happened
+	LastSampleBefore time.Time `json:"lastSampleBefore"`
+	FirstSampleAfter time.Time `json:"firstSampleAfter"`
+
+	LagBefore int64 `json:"lagBefore"`
+	LagAfter  int64 `json:"lagAfter"`
+	LagDelta  int64 `json:"lagDelta"` // LagAfter - LagBefore
+
+	// PeakLagAfter is the highest summed lag within the settle window after the rebalance (or until the next
+	// rebalance), PeakLagDelta is its difference to LagBefore
+	PeakLagAfter int64 `json:"peakLagAfter"`
+	PeakLagDelta int64 `json:"peakLagDelta"`
+}
+
+// GetConsumerGroupRebalanceImpact returns the rebalances of the group which have been detected in the lag history
+// since the given time, most recent first, along with the summed lag before and after each rebalance. The settle
+// window defaults to 5 minutes.
+func (s *Service) GetConsumerGroupRebalanceImpact(groupID string, since time.Time, settleWindow time.Duration) ([]*RebalanceImpact, error) {
+	if s.lagHistory == nil {
+		return nil, ErrLagHistoryDisabled
+	}
+	if settleWindow < 0 {
+		return nil, fmt.Errorf("settle window must not be negative")
+	}
+	if settleWindow == 0 {
+		settleWindow = defaultRebalanceSettleWindow
+	}
+
+	return detectRebalanceImpacts(s.lagHistory.get(groupID, since), settleWindow), nil
+}
+
+func detectRebalanceImpacts(samples []*LagSample, settleWindow time.Duration) []*RebalanceImpact {
+	// Samples without a fingerprint can't be compared, e.g. because the group could not be described
+	fingerprinted := make([]*LagSample, 0, len(samples))
+	for _, sample := range samples {
+		if sample.Lag != nil && sample.Lag.MembershipFingerprint != "" {
+			fingerprinted = append(fingerprinted, sample)
+		}
+	}
+
+	res := make([]*RebalanceImpact, 0)
+	for i := 1; i < len(fingerprinted); i++ {
+		before, after := fingerprinted[i-1], fingerprinted[i]
+		if before.Lag.MembershipFingerprint == after.Lag.MembershipFingerprint {
+			continue
+		}
+
+		impact := &RebalanceImpact{
+			LastSampleBefore: before.Timestamp,
+			FirstSampleAfter: after.Timestamp,
+			LagBefore:        summedGroupLag(before.Lag),
+			LagAfter:         summedGroupLag(after.Lag),
+		}
+		impact.LagDelta = impact.LagAfter - impact.LagBefore
+		impact.PeakLagAfter = impact.LagAfter
+		settledAt := after.Timestamp.Add(settleWindow)
+		for _, sample := range fingerprinted[i+1:] {
+			if sample.Timestamp.After(settledAt) || sample.Lag.MembershipFingerprint != after.Lag.MembershipFingerprint {
+				break
+			}
+			if lag := summedGroupLag(sample.Lag); lag > impact.PeakLagAfter {
+				impact.PeakLagAfter = lag
+			}
+		}
+		impact.PeakLagDelta = impact.PeakLagAfter - impact.LagBefore
+		res = append(res, impact)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].FirstSampleAfter.After(res[j].FirstSampleAfter) })
+
+	return res
+}
+
+// annotateMembershipFingerprints describes the given groups and sets the membership fingerprint of their lags.
+// Failures are only logged, because the fingerprints are not essential for the lag samples.
+func (s *Service) annotateMembershipFingerprints(ctx context.Context, groups []string, lags map[string]*ConsumerGroupLag) {
+	describedGroups, err := s.kafkaSvc.DescribeConsumerGroups(ctx, groups)
+	if err != nil {
+		s.logger.Debug("failed to describe consumer groups for membership fingerprints", zap.Error(err))
+		return
+	}
+
+	for _, response := range describedGroups {
+		for _, description := range response.Groups {
+			lag, exists := lags[description.GroupId]
+			if !exists || description.Err != sarama.ErrNoError {
+				continue
+			}
+			members, err := s.convertGroupMembers(description.Members, description.ProtocolType)
+			if err != nil {
+				continue
+			}
+			lag.MembershipFingerprint = membershipFingerprint(members)
+		}
+	}
+}
+
+// membershipFingerprint hashes the group's member IDs along with their assignments, regardless of their order
+func membershipFingerprint(members []*GroupMemberDescription) string {
+	entries := make([]string, 0, len(members))
+	for _, member := range members {
+		var b strings.Builder
+		b.WriteString(member.ID)
+		for _, assignment := range member.Assignments {
+			b.WriteString(";" + assignment.TopicName + "=")
+			partitionIDs := make([]int, len(assignment.PartitionIDs))
+			for i, partitionID := range assignment.PartitionIDs {
+				partitionIDs[i] = int(partitionID)
+			}
+			sort.Ints(partitionIDs)
+			for i, partitionID := range partitionIDs {
+				if i > 0 {
+					b.WriteString(",")
+				}
+				b.WriteString(strconv.Itoa(partitionID))
+			}
+		}
+		entries = append(entries, b.String())
+	}
+	sort.Strings(entries)
+
+	hash := fnv.New64a()
+	hash.Write([]byte(strings.Join(entries, "|")))
+	return strconv.FormatUint(hash.Sum64(), 16)
+}
@@ -0,0 +1,62 @@
+package owl
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectRebalanceImpacts(t *testing.T) {
+	start := time.Date(2020, 7, 1, 8, 0, 0, 0, time.UTC)
+	newSample := func(minute int, fingerprint string, lag int64) *LagSample {
+		return &LagSample{
+			Timestamp: start.Add(time.Duration(minute) * time.Minute),
+			Lag: &ConsumerGroupLag{
+				GroupID:               "billing",
+				TopicLags:             []*TopicLag{{Topic: "orders", SummedLag: lag}},
+				MembershipFingerprint: fingerprint,
+			},
+		}
+	}
+	samples := []*LagSample{
+		newSample(0, "a", 100),
+		newSample(1, "a", 120),
+		newSample(2, "b", 900), // Rebalanced between minute 1 and 2
+		newSample(3, "b", 1500),
+		newSample(4, "", 5000),  // Group could not be described
+		newSample(9, "b", 2000), // Outside of the settle window
+		newSample(10, "b", 200),
+	}
+
+	impacts := detectRebalanceImpacts(samples, 5*time.Minute)
+	require.Len(t, impacts, 1)
+	assert.Equal(t, &RebalanceImpact{
+		LastSampleBefore: start.Add(time.Minute),
+		FirstSampleAfter: start.Add(2 * time.Minute),
+		LagBefore:        120,
+		LagAfter:         900,
+		LagDelta:         780,
+		PeakLagAfter:     1500,
+		PeakLagDelta:     1380,
+	}, impacts[0])
+}
+
+func TestMembershipFingerprint(t *testing.T) {
+	members := []*GroupMemberDescription{
+		{ID: "m1", Assignments: []*GroupMemberAssignment{{TopicName: "orders", PartitionIDs: []int32{1, 0}}}},
+		{ID: "m2", Assignments: []*GroupMemberAssignment{{TopicName: "orders", PartitionIDs: []int32{2}}}},
+	}
+	reordered := []*GroupMemberDescription{
+		{ID: "m2", Assignments: []*GroupMemberAssignment{{TopicName: "orders", PartitionIDs: []int32{2}}}},
+		{ID: "m1", Assignments: []*GroupMemberAssignment{{TopicName: "orders", PartitionIDs: []int32{0, 1}}}},
+	}
+	reassigned := []*GroupMemberDescription{
+		{ID: "m1", Assignments: []*GroupMemberAssignment{{TopicName: "orders", PartitionIDs: []int32{0}}}},
+		{ID: "m2", Assignments: []*GroupMemberAssignment{{TopicName: "orders", PartitionIDs: []int32{1, 2}}}},
+	}
+
+	assert.Equal(t, membershipFingerprint(members), membershipFingerprint(reordered))
+	assert.NotEqual(t, membershipFingerprint(members), membershipFingerprint(reassigned))
+}
@@ -0,0 +1,50 @@
+package owl
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Shopify/sarama"
+)
+
+// ConsumerGroupResumeOffsets are the committed offsets of a group, which is where the group will resume consuming
+// once it is (re)started. If the group is empty these offsets are final until a member joins again.
+type ConsumerGroupResumeOffsets struct {
+	GroupID    string                      `json:"groupId"`
+	State      string                      `json:"state"`
+	IsEmpty    bool                        `json:"isEmpty"`
+	EmptySince *time.Time                  `json:"emptySince,omitempty"` // Only known if the empty state has been observed before
+	Offsets    map[string]partitionOffsets `json:"offsets"`              // TopicName -> PartitionID -> Offset
+}
+
+// GetConsumerGroupResumeOffsets returns the group's committed offsets along with whether the group is currently
+// empty, so that upcoming restarts or migrations can be planned.
+func (s *Service) GetConsumerGroupResumeOffsets(ctx context.Context, groupID string) (*ConsumerGroupResumeOffsets, error) {
+	description, err := s.describeConsumerGroup(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	offsets, err := s.kafkaSvc.ListConsumerGroupOffsets(groupID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list consumer group offsets: %w", err)
+	}
+
+	emptySince := s.groupStates.observe(groupID, description.State, time.Now())
+	return newConsumerGroupResumeOffsets(description, convertOffsets(offsets), emptySince), nil
+}
+
+func newConsumerGroupResumeOffsets(description *sarama.GroupDescription, offsets map[string]partitionOffsets, emptySince time.Time) *ConsumerGroupResumeOffsets {
+	res := &ConsumerGroupResumeOffsets{
+		GroupID: description.GroupId,
+		State:   description.State,
+		IsEmpty: description.State == GroupStateEmpty,
+		Offsets: offsets,
+	}
+	if res.IsEmpty && !emptySince.IsZero() {
+		res.EmptySince = &emptySince
+	}
+
+	return res
+}
@@ -0,0 +1,23 @@
+package owl
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewConsumerGroupResumeOffsets(t *testing.T) {
+	offsets := map[string]partitionOffsets{"orders": {0: 120, 1: 98}}
+	emptySince := time.Now().Add(-time.Hour)
+
+	empty := newConsumerGroupResumeOffsets(&sarama.GroupDescription{GroupId: "billing", State: GroupStateEmpty}, offsets, emptySince)
+	assert.True(t, empty.IsEmpty)
+	assert.Equal(t, &emptySince, empty.EmptySince)
+	assert.Equal(t, offsets, empty.Offsets)
+
+	stable := newConsumerGroupResumeOffsets(&sarama.GroupDescription{GroupId: "billing", State: GroupStateStable}, offsets, time.Time{})
+	assert.False(t, stable.IsEmpty)
+	assert.Nil(t, stable.EmptySince)
+}
@@ -0,0 +1,149 @@
+package owl
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/cloudhut/kowl/backend/pkg/kafka"
+)
+
+// defaultRetentionEdgeThreshold is the fraction of the retained range a partition's lag must reach to be flagged
+const defaultRetentionEdgeThreshold = 0.9
+
+// RetentionEdgeGroup is a consumer group whose lag on at least one partition is close to (or beyond) the total
+// range of retained messages, which means retention is about to delete or already deletes unconsumed messages.
+type RetentionEdgeGroup struct {
+	GroupID string `json:"groupId"`
+	// IsDataLossActive is true if the committed offset of at least one partition has already been deleted
+	IsDataLossActive bool  `json:"isDataLossActive"`
+	SummedLag        int64 `json:"summedLag"`
+	// RetainedRatio is the summed lag divided by the summed retained range of all partitions the group consumes
+	RetainedRatio float64                   `json:"retainedRatio"`
+	Partitions    []*RetentionEdgePartition `json:"partitions"` // Flagged partitions, most critical first
+}
+
+// RetentionEdgePartition is a partition whose lag is within the threshold of the retained range
+type RetentionEdgePartition struct {
+	Topic            string  `json:"topic"`
+	PartitionID      int32   `json:"partitionId"`
+	Lag              int64   `json:"lag"`
+	RetainedRange    int64   `json:"retainedRange"` // High water mark - low water mark
+	RetainedRatio    float64 `json:"retainedRatio"` // Lag / retained range, >= 1 if messages have been lost
+	IsDataLossActive bool    `json:"isDataLossActive"`
+}
+
+// ListGroupsAtRetentionEdge returns all consumer groups whose lag on a partition has reached the given fraction
+// (e.g. 0.9) of the partition's retained range. Such groups are at the edge of data loss, because their next
+// messages are the oldest ones that retention will delete. A threshold of 0 uses the default of 0.9.
+func (s *Service) ListGroupsAtRetentionEdge(ctx context.Context, threshold float64) ([]*RetentionEdgeGroup, error) {
+	if threshold < 0 || threshold > 1 {
+		return nil, fmt.Errorf("threshold must be between 0 and 1")
+	}
+	if threshold == 0 {
+		threshold = defaultRetentionEdgeThreshold
+	}
+
+	groups, err := s.kafkaSvc.ListConsumerGroups(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list consumer groups: %w", err)
+	}
+	lags, err := s.getConsumerGroupLags(ctx, groups)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get consumer group lags: %w", err)
+	}
+
+	partitionsByTopic := make(map[string]map[int32]struct{})
+	for _, lag := range lags {
+		for _, topicLag := range lag.TopicLags {
+			if _, exists := partitionsByTopic[topicLag.Topic]; !exists {
+				partitionsByTopic[topicLag.Topic] = make(map[int32]struct{})
+			}
+			for _, partitionLag := range topicLag.PartitionLags {
+				partitionsByTopic[topicLag.Topic][partitionLag.PartitionID] = struct{}{}
+			}
+		}
+	}
+
+	waterMarks := make(map[string]map[int32]*kafka.WaterMark, len(partitionsByTopic))
+	for topic, partitions := range partitionsByTopic {
+		partitionIDs := make([]int32, 0, len(partitions))
+		for pID := range partitions {
+			partitionIDs = append(partitionIDs, pID)
+		}
+		waterMarks[topic], err = s.kafkaSvc.WaterMarks(topic, partitionIDs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get water marks for topic '%v': %w", topic, err)
+		}
+	}
+
+	return findGroupsAtRetentionEdge(lags, waterMarks, threshold), nil
+}
+
+func findGroupsAtRetentionEdge(lags map[string]*ConsumerGroupLag, waterMarks map[string]map[int32]*kafka.WaterMark, threshold float64) []*RetentionEdgeGroup {
+	res := make([]*RetentionEdgeGroup, 0)
+	for groupID, lag := range lags {
+		group := &RetentionEdgeGroup{GroupID: groupID, Partitions: make([]*RetentionEdgePartition, 0)}
+		var summedRange int64
+		for _, topicLag := range lag.TopicLags {
+			for _, partitionLag := range topicLag.PartitionLags {
+				waterMark, exists := waterMarks[topicLag.Topic][partitionLag.PartitionID]
+				if !exists {
+					continue
+				}
+				retainedRange := waterMark.High - waterMark.Low
+				if retainedRange <= 0 || partitionLag.Lag <= 0 {
+					// Nothing retained (or nothing to consume) can't be lost
+					continue
+				}
+				group.SummedLag += partitionLag.Lag
+				summedRange += retainedRange
+
+				ratio := float64(partitionLag.Lag) / float64(retainedRange)
+				if ratio < threshold {
+					continue
+				}
+				p := &RetentionEdgePartition{
+					Topic:            topicLag.Topic,
+					PartitionID:      partitionLag.PartitionID,
+					Lag:              partitionLag.Lag,
+					RetainedRange:    retainedRange,
+					RetainedRatio:    ratio,
+					IsDataLossActive: partitionLag.CommittedOffset < waterMark.Low,
+				}
+				if p.IsDataLossActive {
+					group.IsDataLossActive = true
+				}
+				group.Partitions = append(group.Partitions, p)
+			}
+		}
+		if len(group.Partitions) == 0 {
+			continue
+		}
+		group.RetainedRatio = float64(group.SummedLag) / float64(summedRange)
+
+		sort.Slice(group.Partitions, func(i, j int) bool {
+			a, b := group.Partitions[i], group.Partitions[j]
+			if a.RetainedRatio != b.RetainedRatio {
+				return a.RetainedRatio > b.RetainedRatio
+			}
+			if a.Topic != b.Topic {
+				return a.Topic < b.Topic
+			}
+			return a.PartitionID < b.PartitionID
+		})
+		res = append(res, group)
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].IsDataLossActive != res[j].IsDataLossActive {
+			return res[i].IsDataLossActive
+		}
+		if res[i].Partitions[0].RetainedRatio != res[j].Partitions[0].RetainedRatio {
+			return res[i].Partitions[0].RetainedRatio > res[j].Partitions[0].RetainedRatio
+		}
+		return res[i].GroupID < res[j].GroupID
+	})
+
+	return res
+}
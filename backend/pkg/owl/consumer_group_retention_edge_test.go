@@ -0,0 +1,61 @@
+package owl
+
+import (
+	"testing"
+
+	"github.com/cloudhut/kowl/backend/pkg/kafka"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindGroupsAtRetentionEdge(t *testing.T) {
+	waterMarks := map[string]map[int32]*kafka.WaterMark{
+		"orders": {
+			0: {PartitionID: 0, Low: 1000, High: 2000},
+			1: {PartitionID: 1, Low: 1000, High: 2000},
+		},
+	}
+	lags := map[string]*ConsumerGroupLag{
+		// 950 of 1000 retained messages haven't been consumed yet
+		"billing": {GroupID: "billing", TopicLags: []*TopicLag{{
+			Topic: "orders",
+			PartitionLags: []PartitionLag{
+				{PartitionID: 0, Lag: 950, HighWaterMark: 2000, CommittedOffset: 1050},
+				{PartitionID: 1, Lag: 10, HighWaterMark: 2000, CommittedOffset: 1990},
+			},
+		}}},
+		"shipping": {GroupID: "shipping", TopicLags: []*TopicLag{{
+			Topic: "orders",
+			PartitionLags: []PartitionLag{
+				{PartitionID: 0, Lag: 500, HighWaterMark: 2000, CommittedOffset: 1500},
+				{PartitionID: 1, Lag: 0, HighWaterMark: 2000, CommittedOffset: 2000},
+			},
+		}}},
+		// Committed offset has already been deleted by retention
+		"archive": {GroupID: "archive", TopicLags: []*TopicLag{{
+			Topic: "orders",
+			PartitionLags: []PartitionLag{
+				{PartitionID: 1, Lag: 1200, HighWaterMark: 2000, CommittedOffset: 800},
+			},
+		}}},
+	}
+
+	groups := findGroupsAtRetentionEdge(lags, waterMarks, 0.9)
+	require.Len(t, groups, 2)
+
+	assert.Equal(t, "archive", groups[0].GroupID)
+	assert.True(t, groups[0].IsDataLossActive)
+	assert.Equal(t, 1.2, groups[0].Partitions[0].RetainedRatio)
+
+	billing := groups[1]
+	assert.Equal(t, "billing", billing.GroupID)
+	assert.False(t, billing.IsDataLossActive)
+	assert.Equal(t, int64(960), billing.SummedLag)
+	assert.Equal(t, 0.48, billing.RetainedRatio)
+	assert.Equal(t, []*RetentionEdgePartition{
+		{Topic: "orders", PartitionID: 0, Lag: 950, RetainedRange: 1000, RetainedRatio: 0.95},
+	}, billing.Partitions)
+
+	// A less strict threshold flags the shipping group as well
+	assert.Len(t, findGroupsAtRetentionEdge(lags, waterMarks, 0.5), 3)
+}
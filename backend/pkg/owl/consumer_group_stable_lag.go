@@ -0,0 +1,109 @@
+package owl
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// ConsumerGroupStableLag is the group's lag without the partitions which are transiently unassigned because the
+// group is rebalancing. Their lag grows until the rebalance has completed, but isn't actionable.
+type ConsumerGroupStableLag struct {
+	GroupID       string `json:"groupId"`
+	State         string `json:"state"`
+	IsRebalancing bool   `json:"isRebalancing"`
+
+	// StableLag excludes the lag of all ExcludedPartitions, FullLag is the summed lag of all partitions
+	StableLag          int64                  `json:"stableLag"`
+	FullLag            int64                  `json:"fullLag"`
+	ExcludedPartitions []*RebalancingLagEntry `json:"excludedPartitions"`
+
+	Lag *ConsumerGroupLag `json:"lag"` // Full lag of all partitions
+}
+
+// RebalancingLagEntry is a partition which is excluded from the stable lag, because it's not assigned while the
+// group is rebalancing
+type RebalancingLagEntry struct {
+	Topic       string `json:"topic"`
+	PartitionID int32  `json:"partitionId"`
+	Lag         int64  `json:"lag"`
+}
+
+// GetConsumerGroupStableLag returns the group's lag along with its stable lag, which excludes all partitions that
+// are unassigned because of an in-progress rebalance. Unassigned partitions of groups which are not rebalancing are
+// not excluded, as they won't be picked up by a member.
+func (s *Service) GetConsumerGroupStableLag(ctx context.Context, groupID string) (*ConsumerGroupStableLag, error) {
+	description, err := s.describeConsumerGroup(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+	members, err := s.convertGroupMembers(description.Members, description.ProtocolType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert group members: %w", err)
+	}
+
+	lags, err := s.getConsumerGroupLags(ctx, []string{groupID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get consumer group lag: %w", err)
+	}
+	lag, exists := lags[groupID]
+	if !exists {
+		lag = &ConsumerGroupLag{GroupID: groupID, TopicLags: []*TopicLag{}}
+	}
+
+	return newConsumerGroupStableLag(description.State, assignedPartitions(members), lag), nil
+}
+
+func newConsumerGroupStableLag(state string, assigned map[string]map[int32]struct{}, lag *ConsumerGroupLag) *ConsumerGroupStableLag {
+	res := &ConsumerGroupStableLag{
+		GroupID:            lag.GroupID,
+		State:              state,
+		IsRebalancing:      state == GroupStatePreparingRebalance || state == GroupStateCompletingRebalance,
+		FullLag:            summedGroupLag(lag),
+		ExcludedPartitions: make([]*RebalancingLagEntry, 0),
+		Lag:                lag,
+	}
+	res.StableLag = res.FullLag
+	if !res.IsRebalancing {
+		return res
+	}
+
+	for _, topicLag := range lag.TopicLags {
+		for _, partitionLag := range topicLag.PartitionLags {
+			if _, isAssigned := assigned[topicLag.Topic][partitionLag.PartitionID]; isAssigned {
+				continue
+			}
+			res.StableLag -= partitionLag.Lag
+			res.ExcludedPartitions = append(res.ExcludedPartitions, &RebalancingLagEntry{
+				Topic:       topicLag.Topic,
+				PartitionID: partitionLag.PartitionID,
+				Lag:         partitionLag.Lag,
+			})
+		}
+	}
+	sort.Slice(res.ExcludedPartitions, func(i, j int) bool {
+		a, b := res.ExcludedPartitions[i], res.ExcludedPartitions[j]
+		if a.Topic != b.Topic {
+			return a.Topic < b.Topic
+		}
+		return a.PartitionID < b.PartitionID
+	})
+
+	return res
+}
+
+// assignedPartitions returns the partitions which are assigned to any of the members, keyed by topic
+func assignedPartitions(members []*GroupMemberDescription) map[string]map[int32]struct{} {
+	assigned := make(map[string]map[int32]struct{})
+	for _, member := range members {
+		for _, assignment := range member.Assignments {
+			if _, exists := assigned[assignment.TopicName]; !exists {
+				assigned[assignment.TopicName] = make(map[int32]struct{})
+			}
+			for _, partitionID := range assignment.PartitionIDs {
+				assigned[assignment.TopicName][partitionID] = struct{}{}
+			}
+		}
+	}
+	return assigned
+}
@@ -0,0 +1,38 @@
+package owl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewConsumerGroupStableLag(t *testing.T) {
+	lag := &ConsumerGroupLag{
+		GroupID: "billing",
+		TopicLags: []*TopicLag{{
+			Topic:     "orders",
+			SummedLag: 1600,
+			PartitionLags: []PartitionLag{
+				{PartitionID: 0, Lag: 100},
+				{PartitionID: 1, Lag: 500}, // Unassigned while the group is rebalancing
+				{PartitionID: 2, Lag: 1000},
+			},
+		}},
+	}
+	members := []*GroupMemberDescription{
+		{ID: "consumer-1", Assignments: []*GroupMemberAssignment{{TopicName: "orders", PartitionIDs: []int32{0, 2}}}},
+	}
+
+	stable := newConsumerGroupStableLag(GroupStateCompletingRebalance, assignedPartitions(members), lag)
+	assert.True(t, stable.IsRebalancing)
+	assert.Equal(t, int64(1100), stable.StableLag)
+	assert.Equal(t, int64(1600), stable.FullLag)
+	assert.Equal(t, []*RebalancingLagEntry{{Topic: "orders", PartitionID: 1, Lag: 500}}, stable.ExcludedPartitions)
+	assert.Equal(t, lag, stable.Lag)
+
+	// Unassigned partitions of a stable group are not going to be picked up, hence they are not excluded
+	steady := newConsumerGroupStableLag(GroupStateStable, assignedPartitions(members), lag)
+	assert.False(t, steady.IsRebalancing)
+	assert.Equal(t, int64(1600), steady.StableLag)
+	assert.Empty(t, steady.ExcludedPartitions)
+}
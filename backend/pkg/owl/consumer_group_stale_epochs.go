@@ -0,0 +1,167 @@
+package owl
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/Shopify/sarama"
+	"github.com/cloudhut/kowl/backend/pkg/kafka"
+	"go.uber.org/zap"
+)
+
+// StaleEpochReport lists all consumer groups with offsets which have been committed with an older leader epoch than
+// the partition's current one. Such offsets have been consumed from a previous leader, whose log may have been
+// truncated after the leader changed.
+type StaleEpochReport struct {
+	// IsEpochSupported is false if the cluster doesn't return committed leader epochs (Kafka < 2.1)
+	IsEpochSupported bool `json:"isEpochSupported"`
+	// PartitionsWithoutEpoch is the number of committed offsets without a leader epoch, e.g. because the group's
+	// client doesn't commit leader epochs. They can't be checked.
+	PartitionsWithoutEpoch int `json:"partitionsWithoutEpoch"`
+	// UncheckedPartitions is the number of partitions whose current leader epoch could not be determined
+	UncheckedPartitions int                `json:"uncheckedPartitions"`
+	Groups              []*StaleEpochGroup `json:"groups"`
+}
+
+// StaleEpochGroup is a consumer group with at least one offset committed with a stale leader epoch
+type StaleEpochGroup struct {
+	GroupID    string                 `json:"groupId"`
+	Partitions []*StaleEpochPartition `json:"partitions"`
+}
+
+// StaleEpochPartition is a committed offset which is bound to an older leader epoch
+type StaleEpochPartition struct {
+	Topic                string `json:"topic"`
+	PartitionID          int32  `json:"partitionId"`
+	CommittedOffset      int64  `json:"committedOffset"`
+	CommittedLeaderEpoch int32  `json:"committedLeaderEpoch"`
+	// CurrentLeaderEpoch is the epoch of the leader which has written the partition's latest record batch
+	CurrentLeaderEpoch int32 `json:"currentLeaderEpoch"`
+	// LastConsumedLeaderEpoch is the epoch of the record batch which now contains the last consumed offset
+	// (committed offset - 1), -1 if it's unknown
+	LastConsumedLeaderEpoch int32 `json:"lastConsumedLeaderEpoch"`
+	// IsDiverged is true if the last consumed record has been rewritten by a later leader, which means the group
+	// has consumed records that have been truncated from the log
+	IsDiverged bool `json:"isDiverged"`
+}
+
+// ListStaleEpochCommits checks the committed leader epochs of all consumer groups against the partitions' current
+// leader epochs and reports all offsets which have been committed with an older epoch.
+func (s *Service) ListStaleEpochCommits(ctx context.Context) (*StaleEpochReport, error) {
+	groups, err := s.kafkaSvc.ListConsumerGroups(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list consumer groups: %w", err)
+	}
+	sort.Strings(groups)
+
+	report := &StaleEpochReport{IsEpochSupported: true, Groups: make([]*StaleEpochGroup, 0)}
+	currentEpochs := make(map[string]map[int32]int32)
+	currentEpoch := func(topic string, partitionID int32) (int32, error) {
+		if epoch, exists := currentEpochs[topic][partitionID]; exists {
+			return epoch, nil
+		}
+		highWaterMark, err := s.kafkaSvc.Client.GetOffset(topic, partitionID, sarama.OffsetNewest)
+		if err != nil {
+			return kafka.NoLeaderEpoch, err
+		}
+		epoch := kafka.NoLeaderEpoch
+		if highWaterMark > 0 {
+			epoch, err = s.kafkaSvc.LeaderEpochAt(topic, partitionID, highWaterMark-1)
+			if err != nil {
+				return kafka.NoLeaderEpoch, err
+			}
+		}
+		if _, exists := currentEpochs[topic]; !exists {
+			currentEpochs[topic] = make(map[int32]int32)
+		}
+		currentEpochs[topic][partitionID] = epoch
+		return epoch, nil
+	}
+
+	for _, groupID := range groups {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		offsets, isSupported, err := s.kafkaSvc.ListConsumerGroupOffsetsWithLeaderEpochs(groupID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list offsets of consumer group '%v': %w", groupID, err)
+		}
+		if !isSupported {
+			return &StaleEpochReport{IsEpochSupported: false, Groups: []*StaleEpochGroup{}}, nil
+		}
+
+		group := &StaleEpochGroup{GroupID: groupID, Partitions: make([]*StaleEpochPartition, 0)}
+		for topic, blocks := range offsets.Blocks {
+			for partitionID, block := range blocks {
+				if block.Err != sarama.ErrNoError || block.Offset < 0 {
+					continue
+				}
+				if block.LeaderEpoch < 0 {
+					report.PartitionsWithoutEpoch++
+					continue
+				}
+
+				current, err := currentEpoch(topic, partitionID)
+				if err != nil || current == kafka.NoLeaderEpoch {
+					s.logger.Debug("failed to get current leader epoch", zap.String("topic", topic), zap.Int32("partition_id", partitionID), zap.Error(err))
+					report.UncheckedPartitions++
+					continue
+				}
+				if block.LeaderEpoch >= current {
+					continue
+				}
+
+				lastConsumed := kafka.NoLeaderEpoch
+				if block.Offset > 0 {
+					lastConsumed, err = s.kafkaSvc.LeaderEpochAt(topic, partitionID, block.Offset-1)
+					if err != nil {
+						// The last consumed record may have been deleted by retention in the meantime
+						s.logger.Debug("failed to get leader epoch of last consumed offset", zap.String("topic", topic), zap.Int32("partition_id", partitionID), zap.Error(err))
+						lastConsumed = kafka.NoLeaderEpoch
+					}
+				}
+				group.Partitions = append(group.Partitions, newStaleEpochPartition(topic, partitionID, block, current, lastConsumed))
+			}
+		}
+		if len(group.Partitions) == 0 {
+			continue
+		}
+		sortStaleEpochPartitions(group.Partitions)
+		report.Groups = append(report.Groups, group)
+	}
+
+	return report, nil
+}
+
+// newStaleEpochPartition returns the stale committed offset or nil if it has been committed with the current (or a
+// newer) leader epoch or without any epoch
+func newStaleEpochPartition(topic string, partitionID int32, block *sarama.OffsetFetchResponseBlock, currentEpoch int32, lastConsumedEpoch int32) *StaleEpochPartition {
+	if block.LeaderEpoch < 0 || block.LeaderEpoch >= currentEpoch {
+		return nil
+	}
+
+	return &StaleEpochPartition{
+		Topic:                   topic,
+		PartitionID:             partitionID,
+		CommittedOffset:         block.Offset,
+		CommittedLeaderEpoch:    block.LeaderEpoch,
+		CurrentLeaderEpoch:      currentEpoch,
+		LastConsumedLeaderEpoch: lastConsumedEpoch,
+		IsDiverged:              lastConsumedEpoch > block.LeaderEpoch,
+	}
+}
+
+// sortStaleEpochPartitions sorts diverged partitions first, then by topic and partition ID
+func sortStaleEpochPartitions(partitions []*StaleEpochPartition) {
+	sort.Slice(partitions, func(i, j int) bool {
+		a, b := partitions[i], partitions[j]
+		if a.IsDiverged != b.IsDiverged {
+			return a.IsDiverged
+		}
+		if a.Topic != b.Topic {
+			return a.Topic < b.Topic
+		}
+		return a.PartitionID < b.PartitionID
+	})
+}
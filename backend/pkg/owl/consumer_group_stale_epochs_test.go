@@ -0,0 +1,34 @@
+package owl
+
+import (
+	"testing"
+
+	"github.com/Shopify/sarama"
+	"github.com/cloudhut/kowl/backend/pkg/kafka"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewStaleEpochPartition(t *testing.T) {
+	// Committed with epoch 3 while the partition's leader is in epoch 5 by now
+	stale := newStaleEpochPartition("orders", 2, &sarama.OffsetFetchResponseBlock{Offset: 120, LeaderEpoch: 3}, 5, 3)
+	assert.Equal(t, &StaleEpochPartition{
+		Topic:                   "orders",
+		PartitionID:             2,
+		CommittedOffset:         120,
+		CommittedLeaderEpoch:    3,
+		CurrentLeaderEpoch:      5,
+		LastConsumedLeaderEpoch: 3,
+		IsDiverged:              false,
+	}, stale)
+
+	// The last consumed record has been truncated and rewritten by the leader of epoch 4
+	diverged := newStaleEpochPartition("orders", 0, &sarama.OffsetFetchResponseBlock{Offset: 80, LeaderEpoch: 3}, 5, 4)
+	assert.True(t, diverged.IsDiverged)
+
+	partitions := []*StaleEpochPartition{stale, diverged}
+	sortStaleEpochPartitions(partitions)
+	assert.Equal(t, []*StaleEpochPartition{diverged, stale}, partitions)
+
+	assert.Nil(t, newStaleEpochPartition("orders", 1, &sarama.OffsetFetchResponseBlock{Offset: 50, LeaderEpoch: 5}, 5, 5))
+	assert.Nil(t, newStaleEpochPartition("orders", 1, &sarama.OffsetFetchResponseBlock{Offset: 50, LeaderEpoch: kafka.NoLeaderEpoch}, 5, 5))
+}
@@ -0,0 +1,90 @@
+package owl
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// ConsumerGroupTargetLag is the number of messages a group still has to consume to reach a set of target offsets,
+// e.g. a known good checkpoint or the offset from which on a bug has been fixed
+type ConsumerGroupTargetLag struct {
+	GroupID   string            `json:"groupId"`
+	SummedLag int64             `json:"summedLag"`
+	Topics    []*TopicTargetLag `json:"topics"`
+}
+
+// TopicTargetLag is the group's lag to the target offsets of a single topic
+type TopicTargetLag struct {
+	Topic      string                `json:"topic"`
+	SummedLag  int64                 `json:"summedLag"`
+	Partitions []*PartitionTargetLag `json:"partitions"`
+}
+
+// PartitionTargetLag is the group's lag to the target offset of a single partition
+type PartitionTargetLag struct {
+	PartitionID        int32 `json:"partitionId"`
+	TargetOffset       int64 `json:"targetOffset"`
+	CommittedOffset    int64 `json:"committedOffset"` // -1 if the group has no committed offset
+	HasCommittedOffset bool  `json:"hasCommittedOffset"`
+	Lag                int64 `json:"lag"` // Target offset - committed offset, 0 if the target has been reached
+	HasReachedTarget   bool  `json:"hasReachedTarget"`
+}
+
+// GetConsumerGroupLagToTarget computes the group's lag against the given target offsets (topic -> partition ->
+// offset) instead of the high water marks. Partitions without a committed offset lag by the full target offset.
+func (s *Service) GetConsumerGroupLagToTarget(_ context.Context, groupID string, targets map[string]map[int32]int64) (*ConsumerGroupTargetLag, error) {
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("at least one target offset must be given")
+	}
+	for topic, partitionTargets := range targets {
+		for partitionID, offset := range partitionTargets {
+			if offset < 0 {
+				return nil, fmt.Errorf("target offset of topic '%v' partition '%v' must not be negative", topic, partitionID)
+			}
+		}
+	}
+
+	offsets, err := s.kafkaSvc.ListConsumerGroupOffsets(groupID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list consumer group offsets: %w", err)
+	}
+
+	return newConsumerGroupTargetLag(groupID, convertOffsets(offsets), targets), nil
+}
+
+func newConsumerGroupTargetLag(groupID string, committed map[string]partitionOffsets, targets map[string]map[int32]int64) *ConsumerGroupTargetLag {
+	res := &ConsumerGroupTargetLag{GroupID: groupID, Topics: make([]*TopicTargetLag, 0, len(targets))}
+	for topic, partitionTargets := range targets {
+		topicLag := &TopicTargetLag{Topic: topic, Partitions: make([]*PartitionTargetLag, 0, len(partitionTargets))}
+		for partitionID, targetOffset := range partitionTargets {
+			p := &PartitionTargetLag{PartitionID: partitionID, TargetOffset: targetOffset, CommittedOffset: -1}
+			committedOffset, exists := committed[topic][partitionID]
+			if exists && committedOffset >= 0 {
+				p.CommittedOffset = committedOffset
+				p.HasCommittedOffset = true
+			}
+
+			// The committed offset is the next offset to consume, hence the target is reached once it's consumed
+			lag := targetOffset - p.CommittedOffset
+			if !p.HasCommittedOffset {
+				lag = targetOffset
+			}
+			if lag < 0 {
+				lag = 0
+			}
+			p.Lag = lag
+			p.HasReachedTarget = p.HasCommittedOffset && p.CommittedOffset >= targetOffset
+
+			topicLag.SummedLag += p.Lag
+			topicLag.Partitions = append(topicLag.Partitions, p)
+		}
+		sort.Slice(topicLag.Partitions, func(i, j int) bool { return topicLag.Partitions[i].PartitionID < topicLag.Partitions[j].PartitionID })
+
+		res.SummedLag += topicLag.SummedLag
+		res.Topics = append(res.Topics, topicLag)
+	}
+	sort.Slice(res.Topics, func(i, j int) bool { return res.Topics[i].Topic < res.Topics[j].Topic })
+
+	return res
+}
@@ -0,0 +1,23 @@
+package owl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewConsumerGroupTargetLag(t *testing.T) {
+	// The high water marks are far beyond the target offsets, which must not be taken into account
+	committed := map[string]partitionOffsets{"orders": {0: 400, 1: 900, 2: -1}}
+	targets := map[string]map[int32]int64{"orders": {0: 500, 1: 800, 2: 50}}
+
+	res := newConsumerGroupTargetLag("billing", committed, targets)
+	assert.Equal(t, int64(150), res.SummedLag)
+	require.Len(t, res.Topics, 1)
+	assert.Equal(t, []*PartitionTargetLag{
+		{PartitionID: 0, TargetOffset: 500, CommittedOffset: 400, HasCommittedOffset: true, Lag: 100},
+		{PartitionID: 1, TargetOffset: 800, CommittedOffset: 900, HasCommittedOffset: true, Lag: 0, HasReachedTarget: true},
+		{PartitionID: 2, TargetOffset: 50, CommittedOffset: -1, Lag: 50},
+	}, res.Topics[0].Partitions)
+}
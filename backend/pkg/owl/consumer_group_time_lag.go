@@ -0,0 +1,125 @@
+package owl
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cloudhut/kowl/backend/pkg/kafka"
+	"golang.org/x/sync/errgroup"
+)
+
+// TopicTimeLag describes how far a consumer group is behind in time on a single topic
+type TopicTimeLag struct {
+	Topic string `json:"topic"`
+
+	// TimestampType is the topic's message.timestamp.type. With CreateTime the timestamps are set by producers and
+	// may be skewed or out of order, therefore the time lag is only approximate.
+	TimestampType string              `json:"timestampType"`
+	IsApproximate bool                `json:"isApproximate"`
+	MaxTimeLagMs  int64               `json:"maxTimeLagMs"`
+	Partitions    []*PartitionTimeLag `json:"partitions"`
+}
+
+// PartitionTimeLag is the age of the oldest message in a partition which has not been consumed yet
+type PartitionTimeLag struct {
+	PartitionID int32 `json:"partitionId"`
+	Lag         int64 `json:"lag"`
+	TimeLagMs   int64 `json:"timeLagMs"` // 0 if the group has consumed all messages
+}
+
+// GetConsumerGroupTimeLag returns the group's lag in time for all consumed topics. The time lag of a partition is
+// the age of the next message the group has to consume.
+func (s *Service) GetConsumerGroupTimeLag(ctx context.Context, groupID string) ([]*TopicTimeLag, error) {
+	offsets, err := s.kafkaSvc.ListConsumerGroupOffsets(groupID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list consumer group offsets: %w", err)
+	}
+	groupOffsets := convertOffsets(offsets)
+
+	lags, err := s.getConsumerGroupLags(ctx, []string{groupID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get consumer group lags: %w", err)
+	}
+	lag, exists := lags[groupID]
+	if !exists {
+		return nil, fmt.Errorf("no lag has been returned for consumer group '%v'", groupID)
+	}
+
+	topicNames := make([]string, len(lag.TopicLags))
+	for i, topicLag := range lag.TopicLags {
+		topicNames[i] = topicLag.Topic
+	}
+	configs, err := s.GetTopicsConfigs(topicNames, []string{configTimestampType})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get topic configs: %w", err)
+	}
+
+	now := time.Now()
+	mutex := sync.Mutex{}
+	timestampsByTopic := make(map[string]map[int32]time.Time)
+	eg, ctx := errgroup.WithContext(ctx)
+	for _, topicLag := range lag.TopicLags {
+		timestampsByTopic[topicLag.Topic] = make(map[int32]time.Time)
+		for _, partitionLag := range topicLag.PartitionLags {
+			if partitionLag.Lag == 0 {
+				continue
+			}
+			topic := topicLag.Topic
+			partitionID := partitionLag.PartitionID
+			offset := groupOffsets[topic][partitionID]
+			eg.Go(func() error {
+				ts, ok, err := s.kafkaSvc.FetchRecordTimestamp(ctx, topic, partitionID, offset)
+				if err != nil {
+					return fmt.Errorf("failed to fetch record timestamp of topic '%v' partition '%v': %w", topic, partitionID, err)
+				}
+				if ok {
+					mutex.Lock()
+					timestampsByTopic[topic][partitionID] = ts
+					mutex.Unlock()
+				}
+				return nil
+			})
+		}
+	}
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+
+	res := make([]*TopicTimeLag, len(lag.TopicLags))
+	for i, topicLag := range lag.TopicLags {
+		res[i] = newTopicTimeLag(topicLag, timestampTypeFromConfigs(configs[topicLag.Topic]), timestampsByTopic[topicLag.Topic], now)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Topic < res[j].Topic })
+
+	return res, nil
+}
+
+// newTopicTimeLag computes the time lag for a topic given the timestamps of the next messages to consume
+func newTopicTimeLag(topicLag *TopicLag, timestampType string, nextTimestamps map[int32]time.Time, now time.Time) *TopicTimeLag {
+	res := &TopicTimeLag{
+		Topic:         topicLag.Topic,
+		TimestampType: timestampType,
+		IsApproximate: timestampType != kafka.TimestampTypeLogAppendTime,
+		Partitions:    make([]*PartitionTimeLag, 0, len(topicLag.PartitionLags)),
+	}
+
+	for _, partitionLag := range topicLag.PartitionLags {
+		p := &PartitionTimeLag{PartitionID: partitionLag.PartitionID, Lag: partitionLag.Lag}
+		if ts, ok := nextTimestamps[partitionLag.PartitionID]; ok && partitionLag.Lag > 0 {
+			// Producer clocks may be ahead of ours (CreateTime), which must not result in a negative time lag
+			if age := now.Sub(ts).Milliseconds(); age > 0 {
+				p.TimeLagMs = age
+			}
+		}
+		if p.TimeLagMs > res.MaxTimeLagMs {
+			res.MaxTimeLagMs = p.TimeLagMs
+		}
+		res.Partitions = append(res.Partitions, p)
+	}
+	sort.Slice(res.Partitions, func(i, j int) bool { return res.Partitions[i].PartitionID < res.Partitions[j].PartitionID })
+
+	return res
+}
@@ -0,0 +1,73 @@
+package owl
+
+import (
+	"context"
+)
+
+// TopicTimeLagSpread is the difference in time lag between a group's most-behind and least-behind partition of a
+// topic. A large spread means that a single partition is dragging far behind the others, for example because of a
+// slow or stuck consumer instance.
+type TopicTimeLagSpread struct {
+	Topic         string `json:"topic"`
+	IsApproximate bool   `json:"isApproximate"`
+
+	SpreadMs           int64 `json:"spreadMs"` // 0 if all partitions are caught up
+	SlowestPartitionID int32 `json:"slowestPartitionId"`
+	SlowestTimeLagMs   int64 `json:"slowestTimeLagMs"`
+	FastestPartitionID int32 `json:"fastestPartitionId"`
+	FastestTimeLagMs   int64 `json:"fastestTimeLagMs"`
+	CaughtUpPartitions int   `json:"caughtUpPartitions"`
+	PartitionCount     int   `json:"partitionCount"`
+}
+
+// GetConsumerGroupTimeLagSpread returns the time lag spread of all topics the group consumes. It's based on the
+// group's time lag, caught up partitions have a time lag of 0.
+func (s *Service) GetConsumerGroupTimeLagSpread(ctx context.Context, groupID string) ([]*TopicTimeLagSpread, error) {
+	timeLags, err := s.GetConsumerGroupTimeLag(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]*TopicTimeLagSpread, len(timeLags))
+	for i, timeLag := range timeLags {
+		res[i] = newTopicTimeLagSpread(timeLag)
+	}
+
+	return res, nil
+}
+
+// newTopicTimeLagSpread computes the spread between the partitions with the highest and lowest time lag. Ties are
+// resolved in favour of the lower partition ID, so that the result is deterministic.
+func newTopicTimeLagSpread(timeLag *TopicTimeLag) *TopicTimeLagSpread {
+	res := &TopicTimeLagSpread{
+		Topic:          timeLag.Topic,
+		IsApproximate:  timeLag.IsApproximate,
+		PartitionCount: len(timeLag.Partitions),
+	}
+
+	var slowest, fastest *PartitionTimeLag
+	for _, p := range timeLag.Partitions {
+		if p.TimeLagMs == 0 {
+			res.CaughtUpPartitions++
+		}
+		if slowest == nil || p.TimeLagMs > slowest.TimeLagMs ||
+			(p.TimeLagMs == slowest.TimeLagMs && p.PartitionID < slowest.PartitionID) {
+			slowest = p
+		}
+		if fastest == nil || p.TimeLagMs < fastest.TimeLagMs ||
+			(p.TimeLagMs == fastest.TimeLagMs && p.PartitionID < fastest.PartitionID) {
+			fastest = p
+		}
+	}
+	if slowest == nil {
+		return res
+	}
+
+	res.SlowestPartitionID = slowest.PartitionID
+	res.SlowestTimeLagMs = slowest.TimeLagMs
+	res.FastestPartitionID = fastest.PartitionID
+	res.FastestTimeLagMs = fastest.TimeLagMs
+	res.SpreadMs = slowest.TimeLagMs - fastest.TimeLagMs
+
+	return res
+}
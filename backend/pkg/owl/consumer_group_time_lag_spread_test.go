@@ -0,0 +1,43 @@
+package owl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewTopicTimeLagSpread(t *testing.T) {
+	stale := newTopicTimeLagSpread(&TopicTimeLag{
+		Topic:         "orders",
+		IsApproximate: true,
+		Partitions: []*PartitionTimeLag{
+			{PartitionID: 0, Lag: 10, TimeLagMs: 2000},
+			{PartitionID: 1, Lag: 90000, TimeLagMs: 3600000},
+			{PartitionID: 2, Lag: 5, TimeLagMs: 1500},
+			{PartitionID: 3, Lag: 8, TimeLagMs: 1500},
+		},
+	})
+	assert.Equal(t, &TopicTimeLagSpread{
+		Topic:              "orders",
+		IsApproximate:      true,
+		SpreadMs:           3598500,
+		SlowestPartitionID: 1,
+		SlowestTimeLagMs:   3600000,
+		FastestPartitionID: 2,
+		FastestTimeLagMs:   1500,
+		PartitionCount:     4,
+	}, stale)
+
+	caughtUp := newTopicTimeLagSpread(&TopicTimeLag{
+		Topic:      "payments",
+		Partitions: []*PartitionTimeLag{{PartitionID: 0}, {PartitionID: 1}},
+	})
+	assert.Equal(t, int64(0), caughtUp.SpreadMs)
+	assert.Equal(t, 2, caughtUp.CaughtUpPartitions)
+	assert.Equal(t, int32(0), caughtUp.SlowestPartitionID)
+	assert.Equal(t, int32(0), caughtUp.FastestPartitionID)
+
+	empty := newTopicTimeLagSpread(&TopicTimeLag{Topic: "empty"})
+	assert.Equal(t, int64(0), empty.SpreadMs)
+	assert.Equal(t, 0, empty.PartitionCount)
+}
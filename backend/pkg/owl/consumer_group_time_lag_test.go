@@ -0,0 +1,35 @@
+package owl
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cloudhut/kowl/backend/pkg/kafka"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewTopicTimeLag(t *testing.T) {
+	now := time.Now()
+	topicLag := &TopicLag{
+		Topic:         "orders",
+		PartitionLags: []PartitionLag{{PartitionID: 1, Lag: 0}, {PartitionID: 0, Lag: 500}, {PartitionID: 2, Lag: 3}},
+	}
+	nextTimestamps := map[int32]time.Time{
+		0: now.Add(-90 * time.Second),
+		2: now.Add(5 * time.Second), // Producer clock is ahead
+	}
+
+	createTime := newTopicTimeLag(topicLag, kafka.TimestampTypeCreateTime, nextTimestamps, now)
+	assert.True(t, createTime.IsApproximate)
+	assert.Equal(t, kafka.TimestampTypeCreateTime, createTime.TimestampType)
+	assert.Equal(t, int64(90000), createTime.MaxTimeLagMs)
+	assert.Equal(t, []*PartitionTimeLag{
+		{PartitionID: 0, Lag: 500, TimeLagMs: 90000},
+		{PartitionID: 1, Lag: 0, TimeLagMs: 0},
+		{PartitionID: 2, Lag: 3, TimeLagMs: 0},
+	}, createTime.Partitions)
+
+	logAppendTime := newTopicTimeLag(topicLag, kafka.TimestampTypeLogAppendTime, nextTimestamps, now)
+	assert.False(t, logAppendTime.IsApproximate)
+	assert.Equal(t, kafka.TimestampTypeLogAppendTime, logAppendTime.TimestampType)
+}
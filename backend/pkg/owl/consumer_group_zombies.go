@@ -0,0 +1,101 @@
+package owl
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// groupStateTracker remembers since when consumer groups have been observed in the Empty state. The Kafka protocol
+// does not expose when a group became empty, hence we can only track the state transitions we have observed during
+// the lifetime of this service.
+type groupStateTracker struct {
+	mutex      sync.Mutex
+	emptySince map[string]time.Time
+}
+
+func newGroupStateTracker() *groupStateTracker {
+	return &groupStateTracker{emptySince: make(map[string]time.Time)}
+}
+
+// observe records the group's current state and returns since when the group has been empty. The returned
+// time is zero if the group is not empty.
+func (g *groupStateTracker) observe(groupID string, state string, now time.Time) time.Time {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	if state != GroupStateEmpty {
+		delete(g.emptySince, groupID)
+		return time.Time{}
+	}
+
+	since, ok := g.emptySince[groupID]
+	if !ok {
+		since = now
+		g.emptySince[groupID] = since
+	}
+	return since
+}
+
+// ZombieConsumerGroup is a consumer group which holds committed offsets, but has not had any members for a while
+type ZombieConsumerGroup struct {
+	GroupID      string    `json:"groupId"`
+	EmptySince   time.Time `json:"emptySince"`
+	EmptyForSecs int64     `json:"emptyForSecs"`
+
+	TopicCount           int   `json:"topicCount"`
+	PartitionsWithOffset int   `json:"partitionsWithOffset"`
+	SummedLag            int64 `json:"summedLag"`
+}
+
+// ListZombieConsumerGroups returns all consumer groups which have committed offsets, but have been observed in the
+// Empty state for at least minEmptyDuration. Because Kafka does not expose when a group became empty, the duration
+// is measured from the first time this service has observed the group as empty.
+func (s *Service) ListZombieConsumerGroups(ctx context.Context, minEmptyDuration time.Duration) ([]*ZombieConsumerGroup, error) {
+	groups, err := s.GetConsumerGroupsOverview(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get consumer groups overview: %w", err)
+	}
+
+	now := time.Now()
+	zombies := make([]*ZombieConsumerGroup, 0)
+	for _, group := range groups {
+		if !isZombieGroup(group, now, minEmptyDuration) {
+			continue
+		}
+
+		zombie := &ZombieConsumerGroup{
+			GroupID:      group.GroupID,
+			EmptySince:   *group.EmptySince,
+			EmptyForSecs: int64(now.Sub(*group.EmptySince).Seconds()),
+			TopicCount:   len(group.Lags.TopicLags),
+		}
+		for _, topicLag := range group.Lags.TopicLags {
+			zombie.PartitionsWithOffset += topicLag.PartitionsWithOffset
+			zombie.SummedLag += topicLag.SummedLag
+		}
+		zombies = append(zombies, zombie)
+	}
+	sort.Slice(zombies, func(i, j int) bool { return zombies[i].EmptySince.Before(zombies[j].EmptySince) })
+
+	return zombies, nil
+}
+
+// isZombieGroup returns true if the group has been empty for at least minEmptyDuration, but still holds offsets
+func isZombieGroup(group *ConsumerGroupOverview, now time.Time, minEmptyDuration time.Duration) bool {
+	if group.EmptySince == nil || now.Sub(*group.EmptySince) < minEmptyDuration {
+		return false
+	}
+	if group.Lags == nil {
+		return false
+	}
+
+	for _, topicLag := range group.Lags.TopicLags {
+		if topicLag.PartitionsWithOffset > 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,27 @@
+package owl
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsZombieGroup(t *testing.T) {
+	tracker := newGroupStateTracker()
+	start := time.Now()
+	now := start.Add(48 * time.Hour)
+
+	lags := &ConsumerGroupLag{TopicLags: []*TopicLag{{Topic: "orders", PartitionsWithOffset: 3, SummedLag: 500}}}
+
+	emptySince := tracker.observe("empty-group", "Empty", start)
+	tracker.observe("empty-group", "Empty", now) // Observing again must not reset the time
+	empty := &ConsumerGroupOverview{GroupID: "empty-group", State: "Empty", Lags: lags, EmptySince: &emptySince}
+	assert.Equal(t, start, tracker.observe("empty-group", "Empty", now))
+	assert.True(t, isZombieGroup(empty, now, 24*time.Hour))
+	assert.False(t, isZombieGroup(empty, now, 72*time.Hour))
+
+	assert.True(t, tracker.observe("active-group", "Stable", start).IsZero())
+	active := &ConsumerGroupOverview{GroupID: "active-group", State: "Stable", Lags: lags}
+	assert.False(t, isZombieGroup(active, now, 24*time.Hour))
+}
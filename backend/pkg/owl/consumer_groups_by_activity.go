@@ -0,0 +1,70 @@
+package owl
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// ConsumerGroupActivity is the time a group's committed offsets have last been observed to change
+type ConsumerGroupActivity struct {
+	GroupID string `json:"groupId"`
+
+	// LastActivityAt is the time of the first lag sample which has observed the latest committed offset change.
+	// It's nil if no change has been observed within the lag history.
+	LastActivityAt *time.Time `json:"lastActivityAt"`
+	SampleCount    int        `json:"sampleCount"`
+}
+
+// ListConsumerGroupsByActivity returns all consumer groups sorted by their most recent committed offset change, so
+// that active groups come first. Groups without observed activity are sorted last. Offset changes are derived
+// from the lag history, hence the activity is only as precise as the lag sampling interval.
+func (s *Service) ListConsumerGroupsByActivity(ctx context.Context) ([]*ConsumerGroupActivity, error) {
+	if s.lagHistory == nil {
+		return nil, ErrLagHistoryDisabled
+	}
+
+	groupIDs, err := s.kafkaSvc.ListConsumerGroups(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list consumer groups: %w", err)
+	}
+
+	res := make([]*ConsumerGroupActivity, len(groupIDs))
+	for i, groupID := range groupIDs {
+		res[i] = newConsumerGroupActivity(groupID, s.lagHistory.get(groupID, time.Time{}))
+	}
+	sortConsumerGroupsByActivity(res)
+
+	return res, nil
+}
+
+func newConsumerGroupActivity(groupID string, samples []*LagSample) *ConsumerGroupActivity {
+	activity := &ConsumerGroupActivity{GroupID: groupID, SampleCount: len(samples)}
+	for i := len(samples) - 1; i > 0; i-- {
+		if hasCommittedOffsetChanged(samples[i-1].Lag, samples[i].Lag) {
+			lastActivityAt := samples[i].Timestamp
+			activity.LastActivityAt = &lastActivityAt
+			break
+		}
+	}
+
+	return activity
+}
+
+// sortConsumerGroupsByActivity sorts the most recently active groups first, groups without activity last and
+// groups with the same activity by their ID
+func sortConsumerGroupsByActivity(groups []*ConsumerGroupActivity) {
+	sort.Slice(groups, func(i, j int) bool {
+		a, b := groups[i].LastActivityAt, groups[j].LastActivityAt
+		switch {
+		case a != nil && b != nil && !a.Equal(*b):
+			return a.After(*b)
+		case a != nil && b == nil:
+			return true
+		case a == nil && b != nil:
+			return false
+		}
+		return groups[i].GroupID < groups[j].GroupID
+	})
+}
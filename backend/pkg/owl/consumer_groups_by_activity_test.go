@@ -0,0 +1,40 @@
+package owl
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConsumerGroupsByActivity(t *testing.T) {
+	start := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+	newSamples := func(committedOffsets ...int64) []*LagSample {
+		samples := make([]*LagSample, len(committedOffsets))
+		for i, offset := range committedOffsets {
+			samples[i] = newRateBalanceSample(start.Add(time.Duration(i)*time.Minute), "orders", 1000, offset)
+		}
+		return samples
+	}
+
+	groups := []*ConsumerGroupActivity{
+		newConsumerGroupActivity("idle", newSamples(100, 100, 100, 100)),
+		newConsumerGroupActivity("billing", newSamples(100, 200, 200, 200)),  // Last commit observed at 12:01
+		newConsumerGroupActivity("shipping", newSamples(100, 200, 300, 400)), // Last commit observed at 12:03
+		newConsumerGroupActivity("new-group", nil),
+		newConsumerGroupActivity("analytics", newSamples(100, 150, 150, 150)), // Same activity as billing
+	}
+	sortConsumerGroupsByActivity(groups)
+
+	groupIDs := make([]string, len(groups))
+	for i, group := range groups {
+		groupIDs[i] = group.GroupID
+	}
+	assert.Equal(t, []string{"shipping", "analytics", "billing", "idle", "new-group"}, groupIDs)
+
+	require.NotNil(t, groups[0].LastActivityAt)
+	assert.Equal(t, start.Add(3*time.Minute), *groups[0].LastActivityAt)
+	assert.Nil(t, groups[3].LastActivityAt)
+	assert.Equal(t, 4, groups[3].SampleCount)
+}
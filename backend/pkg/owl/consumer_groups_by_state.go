@@ -0,0 +1,87 @@
+package owl
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/Shopify/sarama"
+)
+
+// All states a consumer group can be in as reported by DescribeGroups
+const (
+	GroupStateStable              = "Stable"
+	GroupStateEmpty               = "Empty"
+	GroupStatePreparingRebalance  = "PreparingRebalance"
+	GroupStateCompletingRebalance = "CompletingRebalance"
+	GroupStateDead                = "Dead"
+)
+
+var validGroupStates = map[string]struct{}{
+	GroupStateStable:              {},
+	GroupStateEmpty:               {},
+	GroupStatePreparingRebalance:  {},
+	GroupStateCompletingRebalance: {},
+	GroupStateDead:                {},
+}
+
+// GetConsumerGroupsOverviewByState returns a ConsumerGroupOverview for all consumer groups which are in one of
+// the given states. Lags are only fetched for the matching groups.
+func (s *Service) GetConsumerGroupsOverviewByState(ctx context.Context, states []string) ([]*ConsumerGroupOverview, error) {
+	for _, state := range states {
+		if _, isValid := validGroupStates[state]; !isValid {
+			return nil, fmt.Errorf("unknown consumer group state '%v'", state)
+		}
+	}
+
+	groups, err := s.kafkaSvc.ListConsumerGroups(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list consumer groups: %w", err)
+	}
+
+	describedGroups, err := s.kafkaSvc.DescribeConsumerGroups(ctx, groups)
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe consumer groups: %w", err)
+	}
+
+	filteredByCoordinator := make(map[int32][]*sarama.GroupDescription)
+	matchingGroups := make([]string, 0)
+	for coordinatorID, res := range describedGroups {
+		filtered := filterGroupsByState(res.Groups, states)
+		for _, group := range filtered {
+			matchingGroups = append(matchingGroups, group.GroupId)
+		}
+		filteredByCoordinator[coordinatorID] = filtered
+	}
+
+	groupLags, err := s.getConsumerGroupLags(ctx, matchingGroups)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]*ConsumerGroupOverview, 0, len(matchingGroups))
+	for coordinatorID, descriptions := range filteredByCoordinator {
+		converted, err := s.convertSaramaGroupDescriptions(descriptions, groupLags, coordinatorID)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, converted...)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].GroupID < res[j].GroupID })
+
+	return res, nil
+}
+
+// filterGroupsByState returns all group descriptions which are in one of the given states
+func filterGroupsByState(descriptions []*sarama.GroupDescription, states []string) []*sarama.GroupDescription {
+	filtered := make([]*sarama.GroupDescription, 0)
+	for _, d := range descriptions {
+		for _, state := range states {
+			if d.State == state {
+				filtered = append(filtered, d)
+				break
+			}
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,26 @@
+package owl
+
+import (
+	"testing"
+
+	"github.com/Shopify/sarama"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilterGroupsByState(t *testing.T) {
+	descriptions := []*sarama.GroupDescription{
+		{GroupId: "billing", State: GroupStateStable},
+		{GroupId: "shipping", State: GroupStatePreparingRebalance},
+		{GroupId: "search", State: GroupStateEmpty},
+		{GroupId: "analytics", State: GroupStateCompletingRebalance},
+		{GroupId: "legacy", State: GroupStateDead},
+	}
+
+	rebalancing := filterGroupsByState(descriptions, []string{GroupStatePreparingRebalance, GroupStateCompletingRebalance})
+	groupIDs := make([]string, len(rebalancing))
+	for i, d := range rebalancing {
+		groupIDs[i] = d.GroupId
+	}
+	assert.Equal(t, []string{"shipping", "analytics"}, groupIDs)
+	assert.Empty(t, filterGroupsByState(descriptions, nil))
+}
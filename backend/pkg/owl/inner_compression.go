@@ -0,0 +1,52 @@
+package owl
+
+import (
+	"fmt"
+
+	"github.com/cloudhut/kowl/backend/pkg/kafka"
+)
+
+// InnerCompressionConfig configures per topic codecs which the producers have compressed the value payload with,
+// in addition to Kafka's batch compression. Values are decompressed before they are deserialized.
+type InnerCompressionConfig struct {
+	Topics []TopicInnerCompression `yaml:"topics"`
+}
+
+// TopicInnerCompression is the codec (gzip, snappy or zstd) which is used for all values of a topic
+type TopicInnerCompression struct {
+	Topic string `yaml:"topic"`
+	Codec string `yaml:"codec"`
+}
+
+// SetDefaults for the inner compression config
+func (c *InnerCompressionConfig) SetDefaults() {
+	c.Topics = make([]TopicInnerCompression, 0)
+}
+
+// Validate the inner compression config
+func (c *InnerCompressionConfig) Validate() error {
+	seen := make(map[string]struct{}, len(c.Topics))
+	for i, topic := range c.Topics {
+		if topic.Topic == "" {
+			return fmt.Errorf("inner compression at index %v has no topic", i)
+		}
+		if _, exists := seen[topic.Topic]; exists {
+			return fmt.Errorf("inner compression for topic '%v' is configured more than once", topic.Topic)
+		}
+		seen[topic.Topic] = struct{}{}
+		if topic.Codec == kafka.InnerCompressionNone || !kafka.IsValidInnerCompression(topic.Codec) {
+			return fmt.Errorf("inner compression codec '%v' for topic '%v' is not supported", topic.Codec, topic.Topic)
+		}
+	}
+
+	return nil
+}
+
+// codecsByTopic returns the configured inner compression codecs keyed by topic name
+func (c *InnerCompressionConfig) codecsByTopic() map[string]string {
+	res := make(map[string]string, len(c.Topics))
+	for _, topic := range c.Topics {
+		res[topic.Topic] = topic.Codec
+	}
+	return res
+}
@@ -0,0 +1,24 @@
+package owl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInnerCompressionConfig_Validate(t *testing.T) {
+	cfg := InnerCompressionConfig{}
+	cfg.SetDefaults()
+	assert.NoError(t, cfg.Validate())
+
+	cfg.Topics = []TopicInnerCompression{{Topic: "orders", Codec: "zstd"}, {Topic: "payments", Codec: "gzip"}}
+	assert.NoError(t, cfg.Validate())
+	assert.Equal(t, map[string]string{"orders": "zstd", "payments": "gzip"}, cfg.codecsByTopic())
+
+	cfg.Topics = []TopicInnerCompression{{Topic: "orders", Codec: "lz4"}}
+	assert.Error(t, cfg.Validate())
+	cfg.Topics = []TopicInnerCompression{{Topic: "orders"}}
+	assert.Error(t, cfg.Validate())
+	cfg.Topics = []TopicInnerCompression{{Topic: "orders", Codec: "zstd"}, {Topic: "orders", Codec: "gzip"}}
+	assert.Error(t, cfg.Validate())
+}
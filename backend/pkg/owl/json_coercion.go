@@ -0,0 +1,57 @@
+package owl
+
+import (
+	"fmt"
+
+	"github.com/cloudhut/kowl/backend/pkg/kafka"
+)
+
+// JSONCoercionConfig configures per topic coercion hints for the fields of schemaless JSON values, e.g. numbers which
+// are encoded as strings or timestamps which are encoded as epoch millis
+type JSONCoercionConfig struct {
+	Topics []TopicCoercionHints `yaml:"topics"`
+}
+
+// TopicCoercionHints are the coercion hints which are applied to all message values of a topic
+type TopicCoercionHints struct {
+	Topic string               `yaml:"topic"`
+	Hints []kafka.CoercionHint `yaml:"hints"`
+}
+
+// SetDefaults for the json coercion config
+func (c *JSONCoercionConfig) SetDefaults() {
+	c.Topics = make([]TopicCoercionHints, 0)
+}
+
+// Validate the json coercion config
+func (c *JSONCoercionConfig) Validate() error {
+	seen := make(map[string]struct{}, len(c.Topics))
+	for i, topic := range c.Topics {
+		if topic.Topic == "" {
+			return fmt.Errorf("coercion hints at index %v have no topic", i)
+		}
+		if _, exists := seen[topic.Topic]; exists {
+			return fmt.Errorf("coercion hints for topic '%v' are configured more than once", topic.Topic)
+		}
+		seen[topic.Topic] = struct{}{}
+		if err := kafka.ValidateCoercionHints(topic.Hints); err != nil {
+			return fmt.Errorf("invalid coercion hints for topic '%v': %w", topic.Topic, err)
+		}
+	}
+
+	return nil
+}
+
+// rulesByTopic returns the parsed coercion hints keyed by topic name, so that the paths are not parsed for every
+// message. It expects a validated config.
+func (c *JSONCoercionConfig) rulesByTopic() map[string][]*kafka.CoercionRule {
+	res := make(map[string][]*kafka.CoercionRule, len(c.Topics))
+	for _, topic := range c.Topics {
+		rules, err := kafka.NewCoercionRules(topic.Hints)
+		if err != nil {
+			continue
+		}
+		res[topic.Topic] = rules
+	}
+	return res
+}
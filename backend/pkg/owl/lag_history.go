@@ -0,0 +1,69 @@
+package owl
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrLagHistoryDisabled is returned by all methods which rely on recorded lag samples if the lag sampler is disabled
+var ErrLagHistoryDisabled = errors.New("lag history is not available because the lag sampler is disabled")
+
+// LagSample is the lag of a consumer group at a given point in time
+type LagSample struct {
+	Timestamp time.Time         `json:"timestamp"`
+	Lag       *ConsumerGroupLag `json:"lag"`
+}
+
+// lagHistory keeps the recorded lag samples of all consumer groups in memory for the configured retention
+type lagHistory struct {
+	mutex     sync.RWMutex
+	retention time.Duration
+	samples   map[string][]*LagSample // GroupID -> Samples (sorted by time, oldest first)
+}
+
+func newLagHistory(retention time.Duration) *lagHistory {
+	return &lagHistory{
+		retention: retention,
+		samples:   make(map[string][]*LagSample),
+	}
+}
+
+// add records the given lags and removes all samples which are older than the retention. Groups which
+// are not part of the given lags anymore are removed once all their samples have expired.
+func (h *lagHistory) add(lags map[string]*ConsumerGroupLag, now time.Time) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	for groupID, lag := range lags {
+		h.samples[groupID] = append(h.samples[groupID], &LagSample{Timestamp: now, Lag: lag})
+	}
+
+	cutoff := now.Add(-h.retention)
+	for groupID, samples := range h.samples {
+		i := 0
+		for i < len(samples) && samples[i].Timestamp.Before(cutoff) {
+			i++
+		}
+		if i == len(samples) {
+			delete(h.samples, groupID)
+			continue
+		}
+		h.samples[groupID] = samples[i:]
+	}
+}
+
+// get returns a copy of all samples for the given group that have been recorded since the given time
+func (h *lagHistory) get(groupID string, since time.Time) []*LagSample {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+
+	res := make([]*LagSample, 0)
+	for _, sample := range h.samples[groupID] {
+		if sample.Timestamp.Before(since) {
+			continue
+		}
+		res = append(res, sample)
+	}
+	return res
+}
@@ -0,0 +1,69 @@
+package owl
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+const influxLagMeasurement = "consumer_lag"
+
+// influxTagEscaper escapes tag keys and values as required by the InfluxDB line protocol
+var influxTagEscaper = strings.NewReplacer(",", `\,`, "=", `\=`, " ", `\ `)
+
+// ExportConsumerGroupLagsInflux renders the current partition lags of the given consumer groups in the InfluxDB
+// line protocol, so that they can be ingested by InfluxDB or Telegraf. All groups are exported if no group ids
+// are given.
+func (s *Service) ExportConsumerGroupLagsInflux(ctx context.Context, groupIDs []string) (string, error) {
+	if len(groupIDs) == 0 {
+		groups, err := s.kafkaSvc.ListConsumerGroups(ctx)
+		if err != nil {
+			return "", fmt.Errorf("failed to list consumer groups: %w", err)
+		}
+		groupIDs = groups
+	}
+
+	lags, err := s.getConsumerGroupLags(ctx, groupIDs)
+	if err != nil {
+		return "", fmt.Errorf("failed to get consumer group lags: %w", err)
+	}
+
+	return renderLagsInfluxLineProtocol(lags, time.Now()), nil
+}
+
+// renderLagsInfluxLineProtocol renders one line per partition. Lines are sorted by group, topic and partition
+// so that the output is deterministic.
+func renderLagsInfluxLineProtocol(lags map[string]*ConsumerGroupLag, ts time.Time) string {
+	groupIDs := make([]string, 0, len(lags))
+	for groupID := range lags {
+		groupIDs = append(groupIDs, groupID)
+	}
+	sort.Strings(groupIDs)
+
+	var sb strings.Builder
+	for _, groupID := range groupIDs {
+		topicLags := make([]*TopicLag, len(lags[groupID].TopicLags))
+		copy(topicLags, lags[groupID].TopicLags)
+		sort.Slice(topicLags, func(i, j int) bool { return topicLags[i].Topic < topicLags[j].Topic })
+
+		for _, topicLag := range topicLags {
+			partitionLags := make([]PartitionLag, len(topicLag.PartitionLags))
+			copy(partitionLags, topicLag.PartitionLags)
+			sort.Slice(partitionLags, func(i, j int) bool { return partitionLags[i].PartitionID < partitionLags[j].PartitionID })
+
+			for _, partitionLag := range partitionLags {
+				fmt.Fprintf(&sb, "%v,group=%v,topic=%v,partition=%v value=%vi %v\n",
+					influxLagMeasurement,
+					influxTagEscaper.Replace(groupID),
+					influxTagEscaper.Replace(topicLag.Topic),
+					partitionLag.PartitionID,
+					partitionLag.Lag,
+					ts.UnixNano())
+			}
+		}
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,26 @@
+package owl
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderLagsInfluxLineProtocol(t *testing.T) {
+	lags := map[string]*ConsumerGroupLag{
+		"billing service,eu": {
+			GroupID: "billing service,eu",
+			TopicLags: []*TopicLag{{
+				Topic:         "orders=v2",
+				PartitionLags: []PartitionLag{{PartitionID: 1, Lag: 7}, {PartitionID: 0, Lag: 42}},
+			}},
+		},
+	}
+	ts := time.Unix(1591000000, 0)
+
+	expected := `consumer_lag,group=billing\ service\,eu,topic=orders\=v2,partition=0 value=42i 1591000000000000000
+consumer_lag,group=billing\ service\,eu,topic=orders\=v2,partition=1 value=7i 1591000000000000000
+`
+	assert.Equal(t, expected, renderLagsInfluxLineProtocol(lags, ts))
+}
@@ -0,0 +1,167 @@
+package owl
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	MaintenanceWindowActionSuppress  = "suppress"
+	MaintenanceWindowActionDowngrade = "downgrade"
+)
+
+// MaintenanceWindow is a time range in which lag alerts are expected (e.g. batch jobs) and therefore suppressed or
+// downgraded to a warning. A window is either a one-off range (From/To) or a recurring window which starts at the
+// StartTime on the given Weekdays and lasts for Duration. GroupIDs and Topics scope the window, empty means all.
+type MaintenanceWindow struct {
+	Name   string `yaml:"name"`
+	Action string `yaml:"action"` // suppress (default) or downgrade
+
+	From time.Time `yaml:"from"`
+	To   time.Time `yaml:"to"`
+
+	Weekdays  []string      `yaml:"weekdays"`  // E.g. "saturday", all days if empty
+	StartTime string        `yaml:"startTime"` // HH:MM
+	Duration  time.Duration `yaml:"duration"`
+	Timezone  string        `yaml:"timezone"` // IANA name, defaults to UTC
+
+	GroupIDs []string `yaml:"groupIds"`
+	Topics   []string `yaml:"topics"`
+}
+
+// Validate the maintenance window
+func (w *MaintenanceWindow) Validate() error {
+	if w.Name == "" {
+		return fmt.Errorf("maintenance window has no name")
+	}
+	if w.Action != "" && w.Action != MaintenanceWindowActionSuppress && w.Action != MaintenanceWindowActionDowngrade {
+		return fmt.Errorf("action '%v' is not supported", w.Action)
+	}
+
+	isOneOff := !w.From.IsZero() || !w.To.IsZero()
+	isRecurring := w.StartTime != "" || w.Duration != 0
+	switch {
+	case isOneOff && isRecurring:
+		return fmt.Errorf("window '%v' must either be a one-off or a recurring window", w.Name)
+	case isOneOff:
+		if !w.To.After(w.From) {
+			return fmt.Errorf("end of window '%v' must be after its start", w.Name)
+		}
+	case isRecurring:
+		if _, err := time.Parse("15:04", w.StartTime); err != nil {
+			return fmt.Errorf("failed to parse start time of window '%v': %w", w.Name, err)
+		}
+		if w.Duration <= 0 || w.Duration > 7*24*time.Hour {
+			return fmt.Errorf("duration of window '%v' must be between 0 and 7 days", w.Name)
+		}
+		for _, day := range w.Weekdays {
+			if _, ok := parseWeekday(day); !ok {
+				return fmt.Errorf("weekday '%v' of window '%v' is invalid", day, w.Name)
+			}
+		}
+		if _, err := time.LoadLocation(w.Timezone); err != nil {
+			return fmt.Errorf("failed to load timezone of window '%v': %w", w.Name, err)
+		}
+	default:
+		return fmt.Errorf("window '%v' has neither a time range nor a recurring schedule", w.Name)
+	}
+
+	return nil
+}
+
+// isActive returns true if the given time is within the window. The window must have been validated before.
+func (w *MaintenanceWindow) isActive(now time.Time) bool {
+	if !w.From.IsZero() {
+		return !now.Before(w.From) && now.Before(w.To)
+	}
+
+	location, err := time.LoadLocation(w.Timezone)
+	if err != nil {
+		return false
+	}
+	start, err := time.Parse("15:04", w.StartTime)
+	if err != nil {
+		return false
+	}
+
+	// A recurring window may have started on one of the preceding days, e.g. a window from 22:00 lasting 4h
+	local := now.In(location)
+	for daysBack := 0; daysBack <= int(w.Duration/(24*time.Hour))+1; daysBack++ {
+		day := local.AddDate(0, 0, -daysBack)
+		windowStart := time.Date(day.Year(), day.Month(), day.Day(), start.Hour(), start.Minute(), 0, 0, location)
+		if !w.isScheduledOn(windowStart.Weekday()) {
+			continue
+		}
+		if !local.Before(windowStart) && local.Before(windowStart.Add(w.Duration)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (w *MaintenanceWindow) isScheduledOn(weekday time.Weekday) bool {
+	if len(w.Weekdays) == 0 {
+		return true
+	}
+	for _, day := range w.Weekdays {
+		if d, ok := parseWeekday(day); ok && d == weekday {
+			return true
+		}
+	}
+	return false
+}
+
+// appliesTo returns true if the window is scoped to the given group and topic
+func (w *MaintenanceWindow) appliesTo(groupID string, topic string) bool {
+	return (len(w.GroupIDs) == 0 || containsString(w.GroupIDs, groupID)) &&
+		(len(w.Topics) == 0 || containsString(w.Topics, topic))
+}
+
+// applyMaintenanceWindows suppresses or downgrades a firing evaluation if an active window applies to it. Suppressing
+// windows take precedence over downgrading windows.
+func applyMaintenanceWindows(evaluation *LagRuleEvaluation, windows []MaintenanceWindow, now time.Time) {
+	var downgradedBy *MaintenanceWindow
+	for i := range windows {
+		w := &windows[i]
+		if !w.appliesTo(evaluation.GroupID, evaluation.Topic) || !w.isActive(now) {
+			continue
+		}
+		if w.Action == MaintenanceWindowActionDowngrade {
+			if downgradedBy == nil {
+				downgradedBy = w
+			}
+			continue
+		}
+
+		evaluation.IsFiring = false
+		evaluation.Severity = ""
+		evaluation.IsSuppressed = true
+		evaluation.SuppressionReason = fmt.Sprintf("suppressed by maintenance window '%v'", w.Name)
+		return
+	}
+
+	if downgradedBy != nil {
+		evaluation.Severity = LagAlertSeverityWarning
+		evaluation.SuppressionReason = fmt.Sprintf("downgraded by maintenance window '%v'", downgradedBy.Name)
+	}
+}
+
+func parseWeekday(day string) (time.Weekday, bool) {
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		if strings.EqualFold(d.String(), day) {
+			return d, true
+		}
+	}
+	return 0, false
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
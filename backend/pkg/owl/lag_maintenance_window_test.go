@@ -0,0 +1,58 @@
+package owl
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvaluateLagRules_MaintenanceWindow(t *testing.T) {
+	cfg := LagRulesConfig{
+		Rules: []LagRule{{Name: "orders-lag", Topic: "orders", MaxLag: 100}},
+		MaintenanceWindows: []MaintenanceWindow{
+			{Name: "nightly-batch", Weekdays: []string{"Monday"}, StartTime: "22:00", Duration: 4 * time.Hour, GroupIDs: []string{"billing"}},
+		},
+	}
+	require.NoError(t, cfg.Validate())
+
+	lags := map[string]*ConsumerGroupLag{
+		"billing": {GroupID: "billing", TopicLags: []*TopicLag{{Topic: "orders", SummedLag: 5000}}},
+	}
+
+	// Tuesday 01:00 UTC is within the window which has started on Monday 22:00
+	inside := evaluateLagRules(cfg, lags, time.Date(2020, 6, 2, 1, 0, 0, 0, time.UTC))
+	require.Len(t, inside, 1)
+	assert.False(t, inside[0].IsFiring)
+	assert.True(t, inside[0].IsSuppressed)
+	assert.Equal(t, "suppressed by maintenance window 'nightly-batch'", inside[0].SuppressionReason)
+
+	outside := evaluateLagRules(cfg, lags, time.Date(2020, 6, 2, 3, 0, 0, 0, time.UTC))
+	require.Len(t, outside, 1)
+	assert.True(t, outside[0].IsFiring)
+	assert.False(t, outside[0].IsSuppressed)
+	assert.Equal(t, LagAlertSeverityCritical, outside[0].Severity)
+}
+
+func TestEvaluateLagRules_DowngradeWindow(t *testing.T) {
+	cfg := LagRulesConfig{
+		Rules: []LagRule{{Name: "any-lag", MaxLag: 0}},
+		MaintenanceWindows: []MaintenanceWindow{{
+			Name:   "migration",
+			Action: MaintenanceWindowActionDowngrade,
+			From:   time.Date(2020, 7, 1, 8, 0, 0, 0, time.UTC),
+			To:     time.Date(2020, 7, 1, 12, 0, 0, 0, time.UTC),
+		}},
+	}
+	require.NoError(t, cfg.Validate())
+	lags := map[string]*ConsumerGroupLag{
+		"billing": {GroupID: "billing", TopicLags: []*TopicLag{{Topic: "orders", SummedLag: 1}}},
+	}
+
+	res := evaluateLagRules(cfg, lags, time.Date(2020, 7, 1, 9, 0, 0, 0, time.UTC))
+	require.Len(t, res, 1)
+	assert.True(t, res[0].IsFiring)
+	assert.Equal(t, LagAlertSeverityWarning, res[0].Severity)
+	assert.Equal(t, "downgraded by maintenance window 'migration'", res[0].SuppressionReason)
+}
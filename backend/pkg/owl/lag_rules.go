@@ -0,0 +1,125 @@
+package owl
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+)
+
+const (
+	LagAlertSeverityCritical = "critical"
+	LagAlertSeverityWarning  = "warning"
+)
+
+// LagRulesConfig defines lag thresholds which are evaluated against the current consumer group lags
+type LagRulesConfig struct {
+	Rules              []LagRule           `yaml:"rules"`
+	MaintenanceWindows []MaintenanceWindow `yaml:"maintenanceWindows"`
+}
+
+// LagRule fires if a group's summed lag on a topic exceeds MaxLag. An empty GroupID or Topic matches all groups
+// or topics respectively.
+type LagRule struct {
+	Name    string `yaml:"name"`
+	GroupID string `yaml:"groupId"`
+	Topic   string `yaml:"topic"`
+	MaxLag  int64  `yaml:"maxLag"`
+}
+
+// SetDefaults for the lag rules config
+func (c *LagRulesConfig) SetDefaults() {
+	c.Rules = make([]LagRule, 0)
+	c.MaintenanceWindows = make([]MaintenanceWindow, 0)
+}
+
+// Validate the lag rules config
+func (c *LagRulesConfig) Validate() error {
+	for i, rule := range c.Rules {
+		if rule.Name == "" {
+			return fmt.Errorf("lag rule at index %v has no name", i)
+		}
+		if rule.MaxLag < 0 {
+			return fmt.Errorf("max lag of lag rule '%v' must not be negative", rule.Name)
+		}
+	}
+	for i := range c.MaintenanceWindows {
+		if err := c.MaintenanceWindows[i].Validate(); err != nil {
+			return fmt.Errorf("failed to validate maintenance window at index %v: %w", i, err)
+		}
+	}
+
+	return nil
+}
+
+// LagRuleEvaluation is the result of a single rule for a single group and topic
+type LagRuleEvaluation struct {
+	Rule     string `json:"rule"`
+	GroupID  string `json:"groupId"`
+	Topic    string `json:"topic"`
+	Lag      int64  `json:"lag"`
+	MaxLag   int64  `json:"maxLag"`
+	IsFiring bool   `json:"isFiring"`
+	Severity string `json:"severity,omitempty"` // Only set if the rule is firing
+
+	// IsSuppressed is true if the rule would fire, but an active maintenance window suppressed the alert
+	IsSuppressed      bool   `json:"isSuppressed"`
+	SuppressionReason string `json:"suppressionReason,omitempty"`
+}
+
+// EvaluateLagRules evaluates all configured lag rules against the current lag of all consumer groups. Alerts
+// within an active maintenance window are suppressed or downgraded.
+func (s *Service) EvaluateLagRules(ctx context.Context) ([]*LagRuleEvaluation, error) {
+	if len(s.lagRules.Rules) == 0 {
+		return make([]*LagRuleEvaluation, 0), nil
+	}
+
+	lags, err := s.sampleConsumerGroupLags(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return evaluateLagRules(s.lagRules, lags, time.Now()), nil
+}
+
+func evaluateLagRules(cfg LagRulesConfig, lags map[string]*ConsumerGroupLag, now time.Time) []*LagRuleEvaluation {
+	res := make([]*LagRuleEvaluation, 0)
+	for _, rule := range cfg.Rules {
+		for groupID, groupLag := range lags {
+			if rule.GroupID != "" && rule.GroupID != groupID {
+				continue
+			}
+			for _, topicLag := range groupLag.TopicLags {
+				if rule.Topic != "" && rule.Topic != topicLag.Topic {
+					continue
+				}
+
+				evaluation := &LagRuleEvaluation{
+					Rule:    rule.Name,
+					GroupID: groupID,
+					Topic:   topicLag.Topic,
+					Lag:     topicLag.SummedLag,
+					MaxLag:  rule.MaxLag,
+				}
+				if topicLag.SummedLag > rule.MaxLag {
+					evaluation.IsFiring = true
+					evaluation.Severity = LagAlertSeverityCritical
+					applyMaintenanceWindows(evaluation, cfg.MaintenanceWindows, now)
+				}
+				res = append(res, evaluation)
+			}
+		}
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Rule != res[j].Rule {
+			return res[i].Rule < res[j].Rule
+		}
+		if res[i].GroupID != res[j].GroupID {
+			return res[i].GroupID < res[j].GroupID
+		}
+		return res[i].Topic < res[j].Topic
+	})
+
+	return res
+}
@@ -0,0 +1,228 @@
+package owl
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.uber.org/zap"
+)
+
+// LagSamplerConfig for the background sampler which periodically records the lag of all consumer groups
+type LagSamplerConfig struct {
+	Enabled   bool          `yaml:"enabled"`
+	Interval  time.Duration `yaml:"interval"`
+	Retention time.Duration `yaml:"retention"` // How long samples are kept in memory
+
+	// After FailureThreshold consecutive failed samplings the sampler pauses and retries with the BackoffInterval
+	// until a sampling succeeds again.
+	FailureThreshold int           `yaml:"failureThreshold"`
+	BackoffInterval  time.Duration `yaml:"backoffInterval"`
+}
+
+// SetDefaults for the lag sampler config
+func (c *LagSamplerConfig) SetDefaults() {
+	c.Enabled = false
+	c.Interval = time.Minute
+	c.Retention = 24 * time.Hour
+	c.FailureThreshold = 3
+	c.BackoffInterval = 5 * time.Minute
+}
+
+// Validate the lag sampler config
+func (c *LagSamplerConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if c.Interval <= 0 {
+		return fmt.Errorf("lag sampling interval must be greater than 0")
+	}
+	if c.Retention < c.Interval {
+		return fmt.Errorf("lag sample retention must not be shorter than the sampling interval")
+	}
+	if c.FailureThreshold < 1 {
+		return fmt.Errorf("lag sampler failure threshold must be at least 1")
+	}
+	if c.BackoffInterval < c.Interval {
+		return fmt.Errorf("lag sampler backoff interval must not be shorter than the sampling interval")
+	}
+
+	return nil
+}
+
+// LagSamplerStatus describes the current state of the background lag sampler
+type LagSamplerStatus struct {
+	Enabled             bool      `json:"enabled"`
+	IsPaused            bool      `json:"isPaused"` // True if the sampler backed off due to consecutive failures
+	ConsecutiveFailures int       `json:"consecutiveFailures"`
+	LastSuccessAt       time.Time `json:"lastSuccessAt"`
+}
+
+// samplerBreaker is a circuit breaker which opens after a number of consecutive failures and closes again after
+// the first success.
+type samplerBreaker struct {
+	mutex               sync.RWMutex
+	threshold           int
+	consecutiveFailures int
+	isOpen              bool
+	lastSuccessAt       time.Time
+}
+
+func newSamplerBreaker(threshold int) *samplerBreaker {
+	return &samplerBreaker{threshold: threshold}
+}
+
+// recordFailure returns true if the failure has opened the breaker
+func (b *samplerBreaker) recordFailure() bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.consecutiveFailures++
+	if !b.isOpen && b.consecutiveFailures >= b.threshold {
+		b.isOpen = true
+		return true
+	}
+	return false
+}
+
+// recordSuccess returns true if the success has closed the breaker
+func (b *samplerBreaker) recordSuccess(now time.Time) bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	wasOpen := b.isOpen
+	b.consecutiveFailures = 0
+	b.isOpen = false
+	b.lastSuccessAt = now
+	return wasOpen
+}
+
+func (b *samplerBreaker) open() bool {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+	return b.isOpen
+}
+
+// lagSampler periodically fetches the lag of all consumer groups and stores it in the lag history
+type lagSampler struct {
+	cfg     LagSamplerConfig
+	logger  *zap.Logger
+	breaker *samplerBreaker
+	history *lagHistory
+	sample  func(ctx context.Context) (map[string]*ConsumerGroupLag, error)
+
+	// leaderHistory and sampleLeaders are optional. Partition leaders are sampled along with the lags so that
+	// leader changes can be detected.
+	leaderHistory *leaderHistory
+	sampleLeaders func(ctx context.Context) (map[string]map[int32]int32, error)
+
+	breakerOpenGauge prometheus.Gauge
+}
+
+// StartLagSampler starts the background lag sampler if it is enabled. It returns immediately.
+func (s *Service) StartLagSampler(ctx context.Context) {
+	if s.lagSampler == nil {
+		return
+	}
+
+	// The default cluster's empty label is dropped by Prometheus, so that the series of additional clusters are added
+	s.lagSampler.breakerOpenGauge = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace:   s.kafkaSvc.MetricsNamespace,
+		Subsystem:   "lag_sampler",
+		Name:        "paused",
+		Help:        "Whether the lag sampler has been paused due to consecutive sampling failures (1) or not (0)",
+		ConstLabels: prometheus.Labels{"cluster": s.clusterName},
+	})
+	go s.lagSampler.run(ctx)
+}
+
+// GetLagSamplerStatus returns the current state of the background lag sampler
+func (s *Service) GetLagSamplerStatus() *LagSamplerStatus {
+	if s.lagSampler == nil {
+		return &LagSamplerStatus{Enabled: false}
+	}
+
+	b := s.lagSampler.breaker
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+	return &LagSamplerStatus{
+		Enabled:             true,
+		IsPaused:            b.isOpen,
+		ConsecutiveFailures: b.consecutiveFailures,
+		LastSuccessAt:       b.lastSuccessAt,
+	}
+}
+
+func (s *Service) sampleConsumerGroupLags(ctx context.Context) (map[string]*ConsumerGroupLag, error) {
+	groups, err := s.kafkaSvc.ListConsumerGroups(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list consumer groups: %w", err)
+	}
+
+	lags, err := s.getConsumerGroupLags(ctx, groups)
+	if err != nil {
+		return nil, err
+	}
+	s.annotateMembershipFingerprints(ctx, groups, lags)
+
+	return lags, nil
+}
+
+func (l *lagSampler) run(ctx context.Context) {
+	for {
+		l.sampleOnce(ctx)
+
+		interval := l.cfg.Interval
+		if l.breaker.open() {
+			interval = l.cfg.BackoffInterval
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
+		}
+	}
+}
+
+func (l *lagSampler) sampleOnce(ctx context.Context) {
+	lags, err := l.sample(ctx)
+	now := time.Now()
+	if err != nil {
+		if l.breaker.recordFailure() {
+			l.logger.Error("lag sampling failed repeatedly, backing off until the cluster is reachable again",
+				zap.Int("failure_threshold", l.cfg.FailureThreshold),
+				zap.Duration("backoff_interval", l.cfg.BackoffInterval),
+				zap.Error(err))
+			l.setBreakerGauge(1)
+		} else {
+			l.logger.Debug("lag sampling failed", zap.Error(err))
+		}
+		return
+	}
+
+	if l.breaker.recordSuccess(now) {
+		l.logger.Info("lag sampling succeeded again, resuming normal sampling interval")
+		l.setBreakerGauge(0)
+	}
+	l.history.add(lags, now)
+
+	if l.sampleLeaders == nil || l.leaderHistory == nil {
+		return
+	}
+	leaders, err := l.sampleLeaders(ctx)
+	if err != nil {
+		l.logger.Debug("partition leader sampling failed", zap.Error(err))
+		return
+	}
+	l.leaderHistory.add(leaders, now)
+}
+
+func (l *lagSampler) setBreakerGauge(value float64) {
+	if l.breakerOpenGauge != nil {
+		l.breakerOpenGauge.Set(value)
+	}
+}
@@ -0,0 +1,43 @@
+package owl
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestLagSamplerBreaker(t *testing.T) {
+	cfg := LagSamplerConfig{}
+	cfg.SetDefaults()
+
+	var sampleErr error
+	sampler := &lagSampler{
+		cfg:     cfg,
+		logger:  zap.NewNop(),
+		breaker: newSamplerBreaker(cfg.FailureThreshold),
+		history: newLagHistory(cfg.Retention),
+		sample: func(ctx context.Context) (map[string]*ConsumerGroupLag, error) {
+			if sampleErr != nil {
+				return nil, sampleErr
+			}
+			return map[string]*ConsumerGroupLag{"billing": {GroupID: "billing"}}, nil
+		},
+	}
+
+	sampleErr = errors.New("cluster unreachable")
+	for i := 0; i < cfg.FailureThreshold-1; i++ {
+		sampler.sampleOnce(context.Background())
+		assert.False(t, sampler.breaker.open())
+	}
+	sampler.sampleOnce(context.Background())
+	assert.True(t, sampler.breaker.open())
+
+	sampleErr = nil
+	sampler.sampleOnce(context.Background())
+	assert.False(t, sampler.breaker.open())
+	assert.Len(t, sampler.history.get("billing", time.Time{}), 1)
+}
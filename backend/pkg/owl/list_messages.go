@@ -15,6 +15,10 @@ const (
 	partitionsAll int32 = -1
 )
 
+// defaultMaxParallelPartitions is the number of partitions which are consumed concurrently by a single request,
+// unless the request specifies a different limit
+const defaultMaxParallelPartitions = 32
+
 const (
 	// Recent = High water mark - number of results
 	StartOffsetRecent int64 = -1
@@ -22,6 +26,10 @@ const (
 	StartOffsetOldest int64 = -2
 	// Newest = High water mark / Live tail
 	StartOffsetNewest int64 = -3
+	// Timestamp = First offset whose message timestamp is greater than or equal to the requested StartTimestamp
+	StartOffsetTimestamp int64 = -4
+	// Bookmark = Resume where the owner's bookmark for the topic has stopped, see SetMessageBookmark
+	StartOffsetBookmark int64 = -5
 )
 
 // ListMessageRequest carries all filter, sort and cancellation options for fetching messages from Kafka
@@ -31,6 +39,75 @@ type ListMessageRequest struct {
 	StartOffset           int64 // -1 for recent (high - n), -2 for oldest offset, -3 for newest offset
 	MessageCount          uint16
 	FilterInterpreterCode string
+	Flatten               bool
+	FlattenPaths          []string // Optional, only return these paths of the flattened value
+	ProjectionPaths       []string // Optional, only return these JSON paths of the value
+	ProjectKeys           bool     // Apply the projection paths to JSON keys as well
+	ExtractJSONPath       string   // Optional, only return the value at this JSON path
+	ConnectTopicType      string   // Optional, detected based on the topic name if empty
+	ProducerID            *int64   // Optional, only return records written by this producer
+	DedupByKey            bool     // Only return the latest message per key within the fetched window
+	DedupOrder            string   // Order of the deduplicated messages by the first or last appearance of the key
+	WithSchemaVersions    bool     // Resolve the schema version of every message in Confluent's wire format
+	ParseDeadLetters      bool     // Surface the error headers of dead-letter records
+	StableOrder           bool     // Return the messages ordered by timestamp, partition and offset
+	WithBinaryPreview     bool     // Add the length and a hex preview of the leading bytes to binary values
+	WithPartitionKeyHash  bool     // Add the partition each key hashes to with the default partitioner
+	WithBatchCompression  bool     // Add the compression codec of each record's batch
+	RecordVisibility      string   // Optional, additionally return control and/or aborted transactional records
+
+	// SamplingRate only returns every n-th message of each partition within the window of MessageCount messages.
+	// Alternatively SampleSize derives the rate from the planned window, so that about SampleSize messages are
+	// returned. The SamplingSeed shifts which offsets are sampled.
+	SamplingRate int64
+	SampleSize   int
+	SamplingSeed int64
+
+	// GlobalRecordCap stops the whole request once this many records have been consumed across all partitions, which
+	// take their records in turns. Unlike MessageCount, which is applied per partition if the results can't be
+	// predicted (e.g. with a filter or in live tail mode), no single partition can dominate the result.
+	GlobalRecordCap int
+
+	// CorrelationKey is extracted from every message if set, so that messages can be correlated across topics
+	CorrelationKey *kafka.CorrelationKey
+
+	// FetchTuning overrides the consumer's fetch settings (max wait, min bytes, max bytes) for this request
+	FetchTuning kafka.FetchTuning
+
+	// MaxParallelPartitions limits the number of partitions which are consumed concurrently, the remaining partitions
+	// are queued. Defaults to defaultMaxParallelPartitions if not set. It's ignored in live tail mode, because live
+	// tail consumers only stop once enough messages have arrived across all partitions.
+	MaxParallelPartitions int
+
+	// SessionID identifies a browse session, the detected key and value formats are remembered per session and
+	// topic unless KeyFormat or ValueFormat are explicitly set.
+	SessionID   string
+	KeyFormat   string
+	ValueFormat string
+
+	// Principal identifies the requester, it's used to enforce per principal consume quotas
+	Principal string
+
+	// StartTimestamp is the unix timestamp in ms to start consuming from, only used with StartOffsetTimestamp
+	StartTimestamp int64
+
+	// MaxAge only returns messages whose timestamp is within the given duration before the request, 0 disables it
+	MaxAge time.Duration
+
+	// BookmarkOwner identifies the user whose bookmark is resumed from (StartOffsetBookmark) or updated
+	BookmarkOwner string
+
+	// UpdateBookmark advances the owner's bookmark for the topic past every consumed message, so that the next
+	// request with StartOffsetBookmark resumes after this page
+	UpdateBookmark bool
+
+	// timestampType and timestampOffsets are resolved for StartOffsetTimestamp requests before the consume requests
+	// are calculated.
+	timestampType    string
+	timestampOffsets map[int32]int64
+
+	// bookmarkOffsets are the owner's bookmarked offsets, resolved for StartOffsetBookmark requests
+	bookmarkOffsets map[int32]int64
 }
 
 // ListMessageResponse returns the requested kafka messages along with some metadata about the operation
@@ -48,11 +125,22 @@ func (s *Service) ListMessages(ctx context.Context, listReq ListMessageRequest,
 	start := time.Now()
 	logger := s.logger.With(zap.String("topic", listReq.TopicName))
 
+	if listReq.StableOrder {
+		progress = newStableOrderProgress(progress)
+	}
+	if listReq.DedupByKey {
+		progress = newDedupProgress(progress, listReq.DedupOrder)
+	}
+
 	progress.OnPhase("Create Topic Consumer")
 	// We must create a new Consumer for every request,
 	// because each consumer can only consume every topic+partition once at the same time
 	// which means that concurrent requests will not work with one shared Consumer
-	consumer, err := sarama.NewConsumerFromClient(s.kafkaSvc.Client)
+	// Control and aborted records are only told apart from data records by a read_committed consumer
+	if listReq.RecordVisibility != "" {
+		listReq.FetchTuning.ReadCommitted = true
+	}
+	consumer, err := s.kafkaSvc.NewConsumer(listReq.FetchTuning)
 	if err != nil {
 		return fmt.Errorf("couldn't create consumer: %w", err)
 	}
@@ -87,6 +175,74 @@ func (s *Service) ListMessages(ctx context.Context, listReq ListMessageRequest,
 	if err != nil {
 		return fmt.Errorf("failed to get watermarks: %w", err)
 	}
+	if listReq.RecordVisibility != "" {
+		// A read_committed consumer doesn't get any records beyond the last stable offset, the consumers would wait
+		// for the end offsets until the request times out if a transaction is open.
+		marks, err = s.boundByLastStableOffsets(listReq.TopicName, marks)
+		if err != nil {
+			return err
+		}
+	}
+
+	var minTimestamp time.Time
+	if listReq.StartOffset == StartOffsetTimestamp {
+		progress.OnPhase("Get offsets for timestamp")
+		listReq.timestampType, err = s.getTopicTimestampType(listReq.TopicName)
+		if err != nil {
+			return fmt.Errorf("failed to get timestamp type: %w", err)
+		}
+		startTime := time.Unix(0, listReq.StartTimestamp*int64(time.Millisecond))
+		listReq.timestampOffsets, err = s.kafkaSvc.OffsetsForTimestamp(listReq.TopicName, partitionIDs, startTime)
+		if err != nil {
+			return fmt.Errorf("failed to get offsets for timestamp: %w", err)
+		}
+
+		// Producer assigned timestamps may be out of order, so that messages after the start offset can still
+		// be older than the requested timestamp. Broker assigned timestamps are monotonic.
+		if listReq.timestampType != kafka.TimestampTypeLogAppendTime {
+			minTimestamp = startTime
+		}
+	}
+
+	if listReq.StartOffset == StartOffsetBookmark {
+		bookmark := s.bookmarks.get(listReq.BookmarkOwner, listReq.TopicName, time.Now())
+		if bookmark == nil {
+			return fmt.Errorf("no bookmark has been set for topic '%v'", listReq.TopicName)
+		}
+		listReq.bookmarkOffsets = bookmark.Offsets
+	}
+
+	if listReq.MaxAge > 0 {
+		progress.OnPhase("Get offsets for max age")
+		boundary := time.Now().Add(-listReq.MaxAge)
+		if listReq.timestampType == "" {
+			listReq.timestampType, err = s.getTopicTimestampType(listReq.TopicName)
+			if err != nil {
+				return fmt.Errorf("failed to get timestamp type: %w", err)
+			}
+		}
+		boundaryOffsets, err := s.kafkaSvc.OffsetsForTimestamp(listReq.TopicName, partitionIDs, boundary)
+		if err != nil {
+			return fmt.Errorf("failed to get offsets for max age: %w", err)
+		}
+		applyMaxAgeBoundary(marks, boundaryOffsets)
+
+		// Messages before the boundary offsets are skipped by the raised low water marks, but with producer assigned
+		// timestamps older messages may follow the boundary. Some start offsets (e.g. in live tail mode) don't take
+		// the low water marks into account either, so that the timestamps are always filtered.
+		if boundary.After(minTimestamp) {
+			minTimestamp = boundary
+		}
+	}
+
+	connectTopicType := listReq.ConnectTopicType
+	if connectTopicType == kafka.ConnectTopicTypeNone {
+		connectTopicType = kafka.GuessConnectTopicType(listReq.TopicName)
+	}
+
+	keyFormat, valueFormat := s.sessionFormats.resolve(listReq.SessionID, listReq.TopicName, listReq.KeyFormat, listReq.ValueFormat, time.Now())
+	// Remembered formats are detected again for messages which don't match them
+	isFormatDetected := listReq.KeyFormat == kafka.MessageFormatAuto && listReq.ValueFormat == kafka.MessageFormatAuto
 
 	progress.OnPhase("Setup consumer agents")
 
@@ -97,10 +253,28 @@ func (s *Service) ListMessages(ctx context.Context, listReq ListMessageRequest,
 
 	// Get partition consume request by calculating start and end offsets for each partition
 	consumeRequests := calculateConsumeRequests(&listReq, marks)
+	samplingRate := listReq.SamplingRate
+	if listReq.SampleSize > 0 {
+		samplingRate = samplingRateForSize(listReq.SampleSize, listReq.MessageCount, consumeRequests)
+	}
+	if samplingRate > 1 {
+		progress = newSamplingProgress(progress, samplingRate, listReq.SamplingSeed)
+	}
+	var recordCap kafka.IRecordCap
+	if listReq.GlobalRecordCap > 0 {
+		// Partitions which are queued by the bounded parallelism are registered as well and are waited for until
+		// they become idle
+		globalCap := newGlobalRecordCap(int64(listReq.GlobalRecordCap))
+		for _, req := range consumeRequests {
+			globalCap.Register(req.PartitionID)
+		}
+		recordCap = globalCap
+	}
 	childCtx, cancel := context.WithCancel(ctx)
 	defer cancel()
+	consumerRuns := make([]func(), 0, len(consumeRequests))
 	for _, req := range consumeRequests {
-		pConsumer := kafka.PartitionConsumer{
+		pConsumer := &kafka.PartitionConsumer{
 			Logger: logger.With(zap.Int32("partition_id", req.PartitionID)),
 
 			DoneCh:    doneCh,
@@ -111,31 +285,102 @@ func (s *Service) ListMessages(ctx context.Context, listReq ListMessageRequest,
 			TopicName:             listReq.TopicName,
 			Req:                   req,
 			FilterInterpreterCode: listReq.FilterInterpreterCode,
+			SchemaService:         s.kafkaSvc.SchemaService,
+			WireFormat:            s.wireFormats[listReq.TopicName],
+			Decoders:              s.decoders,
+			WithSchemaVersions:    listReq.WithSchemaVersions,
+			KeyFormat:             keyFormat,
+			ValueFormat:           valueFormat,
+			InnerCompression:      s.innerCodecs[listReq.TopicName],
+			CoercionRules:         s.coercionRules[listReq.TopicName],
+			Processors:            s.processors.forTopic(listReq.TopicName),
+			ProducerIDFilter:      listReq.ProducerID,
+			FetchRecordBatches:    s.recordBatchFetcher(listReq.TopicName, req.PartitionID),
+			FetchSkippedRecords:   s.skippedRecordFetcher(listReq.TopicName, req.PartitionID),
+			LocateCorruptedBatch:  s.corruptedBatchLocator(listReq.TopicName, req.PartitionID),
+			LowWaterMark:          s.lowWaterMarkResolver(listReq.TopicName, req.PartitionID),
+			Flatten:               listReq.Flatten,
+			FlattenPaths:          listReq.FlattenPaths,
+			ProjectionPaths:       listReq.ProjectionPaths,
+			ProjectKeys:           listReq.ProjectKeys,
+			ExtractJSONPath:       listReq.ExtractJSONPath,
+			ConnectTopicType:      connectTopicType,
+			ParseDeadLetters:      listReq.ParseDeadLetters,
+			WithBinaryPreview:     listReq.WithBinaryPreview,
+			CorrelationKey:        listReq.CorrelationKey,
+			WithPartitionKeyHash:  listReq.WithPartitionKeyHash,
+			WithBatchCompression:  listReq.WithBatchCompression,
+			RecordVisibility:      listReq.RecordVisibility,
+			PartitionCount:        int32(len(partitions)),
+			MinTimestamp:          minTimestamp,
+			RecordCap:             recordCap,
 		}
 		startedWorkers++
-		go pConsumer.Run(childCtx)
+		consumerRuns = append(consumerRuns, func() { pConsumer.Run(childCtx) })
 	}
 
+	maxParallel := listReq.MaxParallelPartitions
+	if maxParallel <= 0 {
+		maxParallel = defaultMaxParallelPartitions
+	}
+	if listReq.StartOffset == StartOffsetNewest {
+		maxParallel = 0
+	}
+	// Consumers that are still queued when the request is done count as completed workers
+	runBounded(childCtx, consumerRuns, maxParallel, func() { doneCh <- struct{}{} })
+
 	completedWorkers := 0
 	allWorkersDone := false
 	requestCancelled := false
 
 	progress.OnPhase("Consuming messages")
 
+	// The delivery stops with the child context, which the partition consumers use as well. Once all workers are
+	// done, it finishes delivering the last received (possibly throttled) message before the request is completed.
+	workersDoneCh := make(chan struct{})
+	deliveryDoneCh := make(chan struct{})
 	go func(ch <-chan *kafka.TopicMessage, req ListMessageRequest) {
+		defer close(deliveryDoneCh)
 		messagesToFetch := req.MessageCount
+		if req.GlobalRecordCap > 0 && req.GlobalRecordCap < int(messagesToFetch) {
+			messagesToFetch = uint16(req.GlobalRecordCap)
+		}
+		lastThrottleReport := time.Time{}
 		for {
 			select {
 			case msg := <-ch:
 				messagesToFetch--
+
+				// Delay the message delivery if the requester has exceeded it's consume quota
+				if delay := s.consumeQuotas.reserve(req.Principal, msg.Size, time.Now()); delay > 0 {
+					if time.Since(lastThrottleReport) > time.Second {
+						progress.OnThrottled(delay.Milliseconds(), "consume quota exceeded")
+						lastThrottleReport = time.Now()
+					}
+					select {
+					case <-time.After(delay):
+					case <-childCtx.Done():
+						return
+					}
+				}
+
+				if isFormatDetected {
+					s.sessionFormats.remember(req.SessionID, req.TopicName, msg, time.Now())
+				}
 				progress.OnMessage(msg)
+				if req.UpdateBookmark {
+					s.bookmarks.advance(req.BookmarkOwner, req.TopicName, msg.PartitionID, msg.Offset, time.Now())
+				}
 
 				// When we are done quit routine and cancel context so that all partition consumers will stop as well
 				if messagesToFetch == 0 {
 					cancel()
 					return
 				}
-			case <-ctx.Done():
+			case <-workersDoneCh:
+				// Workers only complete once their messages have been received, so that no message is pending
+				return
+			case <-childCtx.Done():
 				return
 			}
 		}
@@ -180,6 +425,12 @@ Loop:
 		<-time.After(50 * time.Millisecond)
 	}
 
+	if requestCancelled {
+		cancel()
+	} else {
+		close(workersDoneCh)
+	}
+	<-deliveryDoneCh
 	progress.OnComplete(time.Since(start).Milliseconds(), requestCancelled)
 
 	if requestCancelled {
@@ -195,7 +446,15 @@ Loop:
 func calculateConsumeRequests(listReq *ListMessageRequest, marks map[int32]*kafka.WaterMark) map[int32]*kafka.PartitionConsumeRequest {
 	requests := make(map[int32]*kafka.PartitionConsumeRequest, len(marks))
 
-	predictableResults := listReq.StartOffset != StartOffsetNewest && listReq.FilterInterpreterCode == ""
+	predictableResults := listReq.StartOffset != StartOffsetNewest && listReq.FilterInterpreterCode == "" && listReq.ProducerID == nil
+	if listReq.StartOffset == StartOffsetTimestamp && listReq.timestampType != kafka.TimestampTypeLogAppendTime {
+		// Messages older than the requested timestamp will be filtered, hence we can't predict the results
+		predictableResults = false
+	}
+	if listReq.MaxAge > 0 && listReq.timestampType != kafka.TimestampTypeLogAppendTime {
+		// Same as above, messages older than the max age will be filtered
+		predictableResults = false
+	}
 	// Init result map
 	notInitialized := int64(-1)
 	for _, mark := range marks {
@@ -220,6 +479,29 @@ func calculateConsumeRequests(listReq *ListMessageRequest, marks map[int32]*kafk
 			// In Live tail mode we consume onwards until max results are reached. Start Offset is always high watermark
 			// and end offset is always MaxInt64.
 			p.StartOffset = sarama.OffsetNewest
+		} else if listReq.StartOffset == StartOffsetTimestamp {
+			p.StartOffset = mark.High
+			if offset, ok := listReq.timestampOffsets[mark.PartitionID]; ok && offset >= 0 {
+				// -1 means there's no message with a timestamp that is greater than the requested one
+				p.StartOffset = offset
+			}
+			if p.StartOffset < mark.Low {
+				p.StartOffset = mark.Low
+			}
+			if p.StartOffset > p.EndOffset {
+				// Nothing to consume on this partition
+				continue
+			}
+		} else if listReq.StartOffset == StartOffsetBookmark {
+			// Partitions which are not bookmarked (e.g. added since the bookmark has been set) are entirely new
+			p.StartOffset = mark.Low
+			if offset, ok := listReq.bookmarkOffsets[mark.PartitionID]; ok && offset > mark.Low {
+				p.StartOffset = offset
+			}
+			if p.StartOffset > p.EndOffset {
+				// Nothing new on this partition
+				continue
+			}
 		} else {
 			p.StartOffset = listReq.StartOffset
 
@@ -313,3 +595,70 @@ func calculateConsumeRequests(listReq *ListMessageRequest, marks map[int32]*kafk
 
 	return filteredRequests
 }
+
+// runBounded runs every job in its own goroutine, but at most maxParallel jobs at the same time, while the others
+// wait for a free slot. Jobs which are still waiting once the context is done are not run, skip is called for them
+// instead. A maxParallel of zero or less runs all jobs at once. runBounded does not wait for the jobs to complete.
+func runBounded(ctx context.Context, jobs []func(), maxParallel int, skip func()) {
+	if maxParallel <= 0 || maxParallel > len(jobs) {
+		maxParallel = len(jobs)
+	}
+	slots := make(chan struct{}, maxParallel)
+
+	for _, job := range jobs {
+		go func(job func()) {
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				skip()
+				return
+			}
+			defer func() { <-slots }()
+			job()
+		}(job)
+	}
+}
+
+// recordBatchFetcher returns a function which fetches the record batch metadata of a partition starting at an offset
+func (s *Service) recordBatchFetcher(topicName string, partitionID int32) func(offset int64) ([]*kafka.RecordBatchInfo, error) {
+	return func(offset int64) ([]*kafka.RecordBatchInfo, error) {
+		return s.kafkaSvc.FetchRecordBatchInfos(topicName, partitionID, offset)
+	}
+}
+
+// skippedRecordFetcher returns a function which fetches the records, including those skipped by read_committed
+// consumers (control records and records of aborted transactions)
+func (s *Service) skippedRecordFetcher(topicName string, partitionID int32) func(offset int64) ([]*kafka.TransactionalRecord, error) {
+	return func(offset int64) ([]*kafka.TransactionalRecord, error) {
+		return s.kafkaSvc.FetchTransactionalRecords(topicName, partitionID, offset)
+	}
+}
+
+// corruptedBatchLocator returns a function which locates the first record batch of a partition which fails its CRC
+// check
+func (s *Service) corruptedBatchLocator(topicName string, partitionID int32) func(offset int64, endOffset int64) (int64, string, bool, error) {
+	return func(offset int64, endOffset int64) (int64, string, bool, error) {
+		return s.kafkaSvc.LocateCorruptedBatch(topicName, partitionID, offset, endOffset)
+	}
+}
+
+// lowWaterMarkResolver returns a function which fetches the current low water mark of a partition
+func (s *Service) lowWaterMarkResolver(topicName string, partitionID int32) func() (int64, error) {
+	return func() (int64, error) {
+		return s.kafkaSvc.Client.GetOffset(topicName, partitionID, sarama.OffsetOldest)
+	}
+}
+
+// boundByLastStableOffsets returns a copy of the water marks whose high water marks are lowered to the partitions'
+// last stable offsets
+func (s *Service) boundByLastStableOffsets(topicName string, marks map[int32]*kafka.WaterMark) (map[int32]*kafka.WaterMark, error) {
+	bounded := make(map[int32]*kafka.WaterMark, len(marks))
+	for pID, mark := range marks {
+		lastStableOffset, err := s.kafkaSvc.LastStableOffset(topicName, pID, mark.High)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get last stable offset of partition %v: %w", pID, err)
+		}
+		bounded[pID] = &kafka.WaterMark{PartitionID: mark.PartitionID, Low: mark.Low, High: lastStableOffset}
+	}
+	return bounded, nil
+}
@@ -0,0 +1,111 @@
+package owl
+
+import (
+	"sync"
+
+	"github.com/cloudhut/kowl/backend/pkg/kafka"
+)
+
+const (
+	// DedupOrderFirstAppearance sorts the deduplicated messages by the first appearance of their key in the window
+	DedupOrderFirstAppearance = "first"
+	// DedupOrderLastAppearance sorts the deduplicated messages by the last appearance of their key in the window
+	DedupOrderLastAppearance = "last"
+)
+
+// IsValidDedupOrder returns true if the given order is supported, an empty order defaults to the last appearance
+func IsValidDedupOrder(order string) bool {
+	return order == "" || order == DedupOrderFirstAppearance || order == DedupOrderLastAppearance
+}
+
+// dedupProgress buffers all messages of a browse window and only passes the latest message per key on to the
+// wrapped progress, once the window is complete. Messages without a key are never collapsed.
+type dedupProgress struct {
+	kafka.IListMessagesProgress
+	order string
+
+	mutex       sync.Mutex
+	isCompleted bool
+	messages    []*kafka.TopicMessage
+}
+
+func newDedupProgress(progress kafka.IListMessagesProgress, order string) *dedupProgress {
+	return &dedupProgress{
+		IListMessagesProgress: progress,
+		order:                 order,
+		messages:              make([]*kafka.TopicMessage, 0),
+	}
+}
+
+func (d *dedupProgress) OnMessage(message *kafka.TopicMessage) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if d.isCompleted {
+		d.IListMessagesProgress.OnMessage(message)
+		return
+	}
+	d.messages = append(d.messages, message)
+}
+
+func (d *dedupProgress) OnComplete(elapsedMs int64, isCancelled bool) {
+	d.mutex.Lock()
+	for _, message := range dedupMessagesByKey(d.messages, d.order) {
+		d.IListMessagesProgress.OnMessage(message)
+	}
+	d.messages = nil
+	d.isCompleted = true
+	d.mutex.Unlock()
+
+	d.IListMessagesProgress.OnComplete(elapsedMs, isCancelled)
+}
+
+// dedupMessagesByKey keeps the latest message of each key. Within a partition the highest offset is the latest,
+// across partitions the newer timestamp wins. The returned messages are ordered by the first or last appearance of
+// their key in the window.
+func dedupMessagesByKey(messages []*kafka.TopicMessage, order string) []*kafka.TopicMessage {
+	type keyEntry struct {
+		latest   *kafka.TopicMessage
+		position int
+	}
+	entries := make(map[string]*keyEntry)
+	positions := make([]*kafka.TopicMessage, len(messages)) // Position in window -> message to emit at that position
+
+	for i, msg := range messages {
+		if len(msg.Key.Value) == 0 {
+			positions[i] = msg
+			continue
+		}
+
+		key := msg.KeyType + "/" + string(msg.Key.Value)
+		entry, exists := entries[key]
+		if !exists {
+			entries[key] = &keyEntry{latest: msg, position: i}
+			positions[i] = msg
+			continue
+		}
+
+		if isNewerMessage(msg, entry.latest) {
+			entry.latest = msg
+		}
+		if order != DedupOrderFirstAppearance {
+			positions[entry.position] = nil
+			entry.position = i
+		}
+		positions[entry.position] = entry.latest
+	}
+
+	res := make([]*kafka.TopicMessage, 0, len(entries))
+	for _, msg := range positions {
+		if msg != nil {
+			res = append(res, msg)
+		}
+	}
+	return res
+}
+
+func isNewerMessage(a *kafka.TopicMessage, b *kafka.TopicMessage) bool {
+	if a.PartitionID == b.PartitionID {
+		return a.Offset > b.Offset
+	}
+	return a.Timestamp > b.Timestamp
+}
@@ -0,0 +1,40 @@
+package owl
+
+import (
+	"testing"
+
+	"github.com/cloudhut/kowl/backend/pkg/kafka"
+	"github.com/stretchr/testify/assert"
+)
+
+func newDedupTestMessage(key string, offset int64) *kafka.TopicMessage {
+	return &kafka.TopicMessage{
+		PartitionID: 0,
+		Offset:      offset,
+		Key:         kafka.DirectEmbedding{Value: []byte(key)},
+		KeyType:     "text",
+	}
+}
+
+func TestDedupMessagesByKey(t *testing.T) {
+	messages := []*kafka.TopicMessage{
+		newDedupTestMessage("a", 0),
+		newDedupTestMessage("b", 1),
+		newDedupTestMessage("a", 2),
+		newDedupTestMessage("", 3), // Messages without key are never collapsed
+		newDedupTestMessage("c", 4),
+		newDedupTestMessage("b", 5),
+	}
+
+	offsets := func(msgs []*kafka.TopicMessage) []int64 {
+		res := make([]int64, len(msgs))
+		for i, m := range msgs {
+			res[i] = m.Offset
+		}
+		return res
+	}
+
+	// One entry per key, each entry being the latest message of its key
+	assert.Equal(t, []int64{2, 3, 4, 5}, offsets(dedupMessagesByKey(messages, DedupOrderLastAppearance)))
+	assert.Equal(t, []int64{2, 5, 3, 4}, offsets(dedupMessagesByKey(messages, DedupOrderFirstAppearance)))
+}
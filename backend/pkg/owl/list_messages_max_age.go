@@ -0,0 +1,23 @@
+package owl
+
+import (
+	"github.com/cloudhut/kowl/backend/pkg/kafka"
+)
+
+// applyMaxAgeBoundary raises the low water marks to the first offset whose timestamp is within the max age, so that
+// the consume requests skip all older messages without consuming them. An offset of -1 means that a partition has
+// no message within the max age, it's treated as drained.
+func applyMaxAgeBoundary(marks map[int32]*kafka.WaterMark, boundaryOffsets map[int32]int64) {
+	for partitionID, mark := range marks {
+		offset, exists := boundaryOffsets[partitionID]
+		if !exists {
+			continue
+		}
+		if offset < 0 || offset > mark.High {
+			offset = mark.High
+		}
+		if offset > mark.Low {
+			mark.Low = offset
+		}
+	}
+}
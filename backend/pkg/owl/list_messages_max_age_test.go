@@ -0,0 +1,29 @@
+package owl
+
+import (
+	"testing"
+
+	"github.com/cloudhut/kowl/backend/pkg/kafka"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyMaxAgeBoundary(t *testing.T) {
+	marks := map[int32]*kafka.WaterMark{
+		0: {PartitionID: 0, Low: 0, High: 100},
+		1: {PartitionID: 1, Low: 0, High: 100},
+		2: {PartitionID: 2, Low: 50, High: 100},
+	}
+	// Partition 1 has no message within the max age, partition 2's boundary has already been deleted by retention
+	applyMaxAgeBoundary(marks, map[int32]int64{0: 80, 1: -1, 2: 20})
+	assert.Equal(t, int64(80), marks[0].Low)
+	assert.Equal(t, int64(100), marks[1].Low)
+	assert.Equal(t, int64(50), marks[2].Low)
+
+	// Only messages within the age window are requested, although more recent messages have been requested
+	req := &ListMessageRequest{TopicName: "test", PartitionID: partitionsAll, StartOffset: StartOffsetRecent, MessageCount: 60,
+		MaxAge: 1, timestampType: kafka.TimestampTypeLogAppendTime}
+	assert.Equal(t, map[int32]*kafka.PartitionConsumeRequest{
+		0: {PartitionID: 0, IsDrained: true, StartOffset: 80, EndOffset: 99, MaxMessageCount: 20, LowWaterMark: 80, HighWaterMark: 100},
+		2: {PartitionID: 2, IsDrained: false, StartOffset: 60, EndOffset: 99, MaxMessageCount: 40, LowWaterMark: 50, HighWaterMark: 100},
+	}, calculateConsumeRequests(req, marks))
+}
@@ -0,0 +1,67 @@
+package owl
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/cloudhut/kowl/backend/pkg/kafka"
+)
+
+// stableOrderProgress buffers all messages of a browse window and passes them on to the wrapped progress in the
+// canonical message order once the window is complete. Partition consumers deliver their messages concurrently,
+// so that without it the order of the merged output depends on which consumer happens to be faster.
+type stableOrderProgress struct {
+	kafka.IListMessagesProgress
+
+	mutex       sync.Mutex
+	isCompleted bool
+	messages    []*kafka.TopicMessage
+}
+
+func newStableOrderProgress(progress kafka.IListMessagesProgress) *stableOrderProgress {
+	return &stableOrderProgress{
+		IListMessagesProgress: progress,
+		messages:              make([]*kafka.TopicMessage, 0),
+	}
+}
+
+func (o *stableOrderProgress) OnMessage(message *kafka.TopicMessage) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	if o.isCompleted {
+		o.IListMessagesProgress.OnMessage(message)
+		return
+	}
+	o.messages = append(o.messages, message)
+}
+
+func (o *stableOrderProgress) OnComplete(elapsedMs int64, isCancelled bool) {
+	o.mutex.Lock()
+	sortMessagesCanonically(o.messages)
+	for _, message := range o.messages {
+		o.IListMessagesProgress.OnMessage(message)
+	}
+	o.messages = nil
+	o.isCompleted = true
+	o.mutex.Unlock()
+
+	o.IListMessagesProgress.OnComplete(elapsedMs, isCancelled)
+}
+
+// sortMessagesCanonically orders messages by timestamp, ties are broken by partition ID and then by offset. The
+// (partition, offset) pair is unique within a topic, hence the order is total and the same set of messages is
+// always returned in the same order, regardless of the order in which the partition consumers delivered them. The
+// last message's timestamp, partition and offset can therefore be used as a cursor to resume browsing.
+func sortMessagesCanonically(messages []*kafka.TopicMessage) {
+	sort.Slice(messages, func(i, j int) bool { return isBeforeInCanonicalOrder(messages[i], messages[j]) })
+}
+
+func isBeforeInCanonicalOrder(a *kafka.TopicMessage, b *kafka.TopicMessage) bool {
+	if a.Timestamp != b.Timestamp {
+		return a.Timestamp < b.Timestamp
+	}
+	if a.PartitionID != b.PartitionID {
+		return a.PartitionID < b.PartitionID
+	}
+	return a.Offset < b.Offset
+}
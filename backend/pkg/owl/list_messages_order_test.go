@@ -0,0 +1,37 @@
+package owl
+
+import (
+	"testing"
+
+	"github.com/cloudhut/kowl/backend/pkg/kafka"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSortMessagesCanonically(t *testing.T) {
+	newMessage := func(partitionID int32, offset int64, timestamp int64) *kafka.TopicMessage {
+		return &kafka.TopicMessage{PartitionID: partitionID, Offset: offset, Timestamp: timestamp}
+	}
+	positions := func(msgs []*kafka.TopicMessage) [][2]int64 {
+		res := make([][2]int64, len(msgs))
+		for i, m := range msgs {
+			res[i] = [2]int64{int64(m.PartitionID), m.Offset}
+		}
+		return res
+	}
+
+	// The same messages as delivered by concurrent partition consumers in two different requests
+	first := []*kafka.TopicMessage{
+		newMessage(2, 10, 1000),
+		newMessage(0, 5, 1000),
+		newMessage(1, 7, 900),
+		newMessage(0, 4, 1000),
+	}
+	second := []*kafka.TopicMessage{first[3], first[0], first[2], first[1]}
+
+	sortMessagesCanonically(first)
+	sortMessagesCanonically(second)
+
+	expected := [][2]int64{{1, 7}, {0, 4}, {0, 5}, {2, 10}}
+	assert.Equal(t, expected, positions(first))
+	assert.Equal(t, expected, positions(second))
+}
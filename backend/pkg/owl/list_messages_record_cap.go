@@ -0,0 +1,99 @@
+package owl
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// recordCapIdleTimeout is how long a partition may go without taking a record before the other partitions stop
+// waiting for it, e.g. because it has no new messages in live tail mode or its fetches are slow
+const recordCapIdleTimeout = 250 * time.Millisecond
+
+// globalRecordCap caps the records across all partitions of a request. Partitions take records in turns, so that
+// the cap is spread evenly across all consuming partitions instead of being filled by the fastest partition.
+type globalRecordCap struct {
+	idleTimeout time.Duration
+
+	mutex      sync.Mutex
+	remaining  int64
+	takenCount map[int32]int64     // Records taken by each partition which is still consuming
+	lastActive map[int32]time.Time // Last time each partition has been registered or taken a record
+	changed    chan struct{}       // Closed and replaced whenever a partition takes a record or is done
+}
+
+func newGlobalRecordCap(maxRecords int64) *globalRecordCap {
+	return &globalRecordCap{
+		idleTimeout: recordCapIdleTimeout,
+		remaining:   maxRecords,
+		takenCount:  make(map[int32]int64),
+		lastActive:  make(map[int32]time.Time),
+		changed:     make(chan struct{}),
+	}
+}
+
+// Register adds a partition which will take records. All partitions must be registered before the first one is
+// consumed, so that the partitions which start consuming first can't fill the cap before the others are known.
+func (c *globalRecordCap) Register(partitionID int32) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.takenCount[partitionID] = 0
+	c.lastActive[partitionID] = time.Now()
+}
+
+func (c *globalRecordCap) Acquire(ctx context.Context, partitionID int32) bool {
+	for {
+		c.mutex.Lock()
+		if c.remaining <= 0 {
+			c.mutex.Unlock()
+			return false
+		}
+		now := time.Now()
+		if !isAheadOfActivePartitions(partitionID, c.takenCount, c.lastActive, now, c.idleTimeout) {
+			c.remaining--
+			c.takenCount[partitionID]++
+			c.lastActive[partitionID] = now
+			c.notifyLocked()
+			c.mutex.Unlock()
+			return true
+		}
+		changed := c.changed
+		c.mutex.Unlock()
+
+		// Re-evaluate once another partition has caught up or might have become idle
+		select {
+		case <-changed:
+		case <-time.After(c.idleTimeout):
+		case <-ctx.Done():
+			return false
+		}
+	}
+}
+
+func (c *globalRecordCap) Done(partitionID int32) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	delete(c.takenCount, partitionID)
+	delete(c.lastActive, partitionID)
+	c.notifyLocked()
+}
+
+func (c *globalRecordCap) notifyLocked() {
+	close(c.changed)
+	c.changed = make(chan struct{})
+}
+
+// isAheadOfActivePartitions returns true if the partition has taken more records than any other partition which is
+// still active. Partitions which haven't taken a record within the idle timeout are not waited for.
+func isAheadOfActivePartitions(partitionID int32, takenCount map[int32]int64, lastActive map[int32]time.Time, now time.Time, idleTimeout time.Duration) bool {
+	own := takenCount[partitionID]
+	for otherID, count := range takenCount {
+		if otherID == partitionID || now.Sub(lastActive[otherID]) >= idleTimeout {
+			continue
+		}
+		if count < own {
+			return true
+		}
+	}
+	return false
+}
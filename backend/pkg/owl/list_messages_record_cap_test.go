@@ -0,0 +1,68 @@
+package owl
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// runCappedPartitions registers the partitions with the record cap and consumes them like the partition consumers
+// of a request, each partition has the given number of records
+func runCappedPartitions(recordCap *globalRecordCap, recordCounts map[int32]int, maxParallel int) map[int32]int {
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+	taken := make(map[int32]int)
+	jobs := make([]func(), 0, len(recordCounts))
+	for partitionID := int32(0); int(partitionID) < len(recordCounts); partitionID++ {
+		recordCap.Register(partitionID)
+		wg.Add(1)
+		partitionID := partitionID
+		jobs = append(jobs, func() {
+			defer wg.Done()
+			defer recordCap.Done(partitionID)
+			for i := 0; i < recordCounts[partitionID]; i++ {
+				if !recordCap.Acquire(context.Background(), partitionID) {
+					return
+				}
+				mutex.Lock()
+				taken[partitionID]++
+				mutex.Unlock()
+			}
+		})
+	}
+	runBounded(context.Background(), jobs, maxParallel, func() {})
+	wg.Wait()
+	return taken
+}
+
+func TestGlobalRecordCap_SpreadsAcrossPartitions(t *testing.T) {
+	// Partitions which start consuming first must not fill the cap before the others have started
+	recordCap := newGlobalRecordCap(6)
+	recordCap.idleTimeout = 5 * time.Second
+	taken := runCappedPartitions(recordCap, map[int32]int{0: 10, 1: 10, 2: 10}, 0)
+	assert.Equal(t, map[int32]int{0: 2, 1: 2, 2: 2}, taken)
+	assert.False(t, recordCap.Acquire(context.Background(), 0))
+
+	// Queued partitions are waited for until they become idle, so that the consuming partitions can finish and free
+	// their slots for the queued partitions
+	recordCap = newGlobalRecordCap(6)
+	recordCap.idleTimeout = 50 * time.Millisecond
+	taken = runCappedPartitions(recordCap, map[int32]int{0: 2, 1: 2, 2: 2}, 2)
+	assert.Equal(t, map[int32]int{0: 2, 1: 2, 2: 2}, taken)
+	assert.False(t, recordCap.Acquire(context.Background(), 0))
+}
+
+func TestIsAheadOfActivePartitions(t *testing.T) {
+	now := time.Now()
+	takenCount := map[int32]int64{0: 3, 1: 2, 2: 0}
+	lastActive := map[int32]time.Time{0: now, 1: now, 2: now.Add(-time.Second)}
+
+	// Partition 2 is idle, hence only partition 1 is waited for
+	assert.True(t, isAheadOfActivePartitions(0, takenCount, lastActive, now, 250*time.Millisecond))
+	assert.False(t, isAheadOfActivePartitions(1, takenCount, lastActive, now, 250*time.Millisecond))
+	assert.True(t, isAheadOfActivePartitions(1, takenCount, lastActive, now, 2*time.Second))
+	assert.False(t, isAheadOfActivePartitions(2, takenCount, lastActive, now, 250*time.Millisecond))
+}
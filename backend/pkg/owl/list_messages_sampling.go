@@ -0,0 +1,98 @@
+package owl
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"sync"
+
+	"github.com/cloudhut/kowl/backend/pkg/kafka"
+)
+
+// samplingProgress only passes every rate-th message of each partition on to the wrapped progress, so that a large
+// browse window can be explored without returning all of its messages. The sampled offsets only depend on the rate
+// and the seed, hence the same window always returns the same sample regardless of the consumption order.
+type samplingProgress struct {
+	kafka.IListMessagesProgress
+	rate int64
+	seed int64
+
+	mutex         sync.Mutex
+	consumedCount int64
+	sampledCount  int64
+}
+
+func newSamplingProgress(progress kafka.IListMessagesProgress, rate int64, seed int64) *samplingProgress {
+	return &samplingProgress{
+		IListMessagesProgress: progress,
+		rate:                  rate,
+		seed:                  seed,
+	}
+}
+
+func (p *samplingProgress) OnMessage(message *kafka.TopicMessage) {
+	p.mutex.Lock()
+	p.consumedCount++
+	isSampled := isSampledOffset(message.PartitionID, message.Offset, p.rate, p.seed)
+	if isSampled {
+		p.sampledCount++
+	}
+	p.mutex.Unlock()
+
+	if isSampled {
+		p.IListMessagesProgress.OnMessage(message)
+	}
+}
+
+func (p *samplingProgress) OnComplete(elapsedMs int64, isCancelled bool) {
+	p.mutex.Lock()
+	consumed, sampled := p.consumedCount, p.sampledCount
+	p.mutex.Unlock()
+
+	p.IListMessagesProgress.OnSampled(p.rate, consumed, sampled)
+	p.IListMessagesProgress.OnComplete(elapsedMs, isCancelled)
+}
+
+// isSampledOffset returns true for every rate-th offset of a partition. The seed shifts which offsets are sampled,
+// each partition is shifted differently so that the sampled offsets don't line up across partitions.
+func isSampledOffset(partitionID int32, offset int64, rate int64, seed int64) bool {
+	if rate <= 1 {
+		return true
+	}
+
+	h := fnv.New64a()
+	var buf [12]byte
+	binary.BigEndian.PutUint64(buf[:8], uint64(seed))
+	binary.BigEndian.PutUint32(buf[8:], uint32(partitionID))
+	_, _ = h.Write(buf[:])
+	phase := int64(h.Sum64() % uint64(rate))
+
+	return (offset+phase)%rate == 0
+}
+
+// samplingRateForSize returns the rate at which about sampleSize messages are sampled from the planned consume
+// requests. The planned window is bounded by the requested message count.
+func samplingRateForSize(sampleSize int, messageCount uint16, requests map[int32]*kafka.PartitionConsumeRequest) int64 {
+	if sampleSize <= 0 {
+		return 1
+	}
+
+	var planned int64
+	for _, req := range requests {
+		count := req.EndOffset - req.StartOffset + 1
+		if req.MaxMessageCount > 0 && req.MaxMessageCount < count {
+			count = req.MaxMessageCount
+		}
+		if count > 0 {
+			planned += count
+		}
+	}
+	if planned > int64(messageCount) {
+		planned = int64(messageCount)
+	}
+
+	rate := (planned + int64(sampleSize) - 1) / int64(sampleSize)
+	if rate < 1 {
+		rate = 1
+	}
+	return rate
+}
@@ -0,0 +1,48 @@
+package owl
+
+import (
+	"testing"
+
+	"github.com/cloudhut/kowl/backend/pkg/kafka"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSamplingProgress(t *testing.T) {
+	sample := func(seed int64) []*kafka.TopicMessage {
+		collector := &messageCollector{}
+		progress := newSamplingProgress(collector, 10, seed)
+		for offset := int64(0); offset < 100; offset++ {
+			progress.OnMessage(&kafka.TopicMessage{PartitionID: 0, Offset: offset})
+		}
+		for offset := int64(100); offset < 150; offset++ {
+			progress.OnMessage(&kafka.TopicMessage{PartitionID: 1, Offset: offset})
+		}
+		progress.OnComplete(0, false)
+
+		assert.Equal(t, int64(150), progress.consumedCount)
+		assert.Equal(t, int64(len(collector.messages)), progress.sampledCount)
+		return collector.messages
+	}
+
+	// 1 in 10 messages of each partition's contiguous range is returned
+	sampled := sample(42)
+	assert.Len(t, sampled, 15)
+	perPartition := make(map[int32]int)
+	for _, msg := range sampled {
+		perPartition[msg.PartitionID]++
+	}
+	assert.Equal(t, map[int32]int{0: 10, 1: 5}, perPartition)
+
+	// The same seed always returns the same sample
+	assert.Equal(t, sampled, sample(42))
+}
+
+func TestSamplingRateForSize(t *testing.T) {
+	requests := map[int32]*kafka.PartitionConsumeRequest{
+		0: {StartOffset: 0, EndOffset: 999, MaxMessageCount: 600},
+		1: {StartOffset: 500, EndOffset: 899, MaxMessageCount: 400},
+	}
+	assert.Equal(t, int64(10), samplingRateForSize(100, 5000, requests))
+	assert.Equal(t, int64(4), samplingRateForSize(100, 400, requests)) // Bounded by the message count
+	assert.Equal(t, int64(1), samplingRateForSize(2000, 5000, requests))
+}
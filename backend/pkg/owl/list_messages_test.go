@@ -1,10 +1,15 @@
 package owl
 
 import (
+	"context"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
 	"github.com/Shopify/sarama"
 	"github.com/cloudhut/kowl/backend/pkg/kafka"
 	"github.com/stretchr/testify/assert"
-	"testing"
 )
 
 func TestCalculateConsumeRequests_AllPartitions_FewNewestMessages(t *testing.T) {
@@ -174,3 +179,105 @@ func TestCalculateConsumeRequests_AllPartitions_WithFilter(t *testing.T) {
 		assert.Equal(t, table.expected, actual, "expected other result for all partitions with filter enable. Case: ", i)
 	}
 }
+
+func TestCalculateConsumeRequests_Timestamp(t *testing.T) {
+	marks := map[int32]*kafka.WaterMark{
+		0: {PartitionID: 0, Low: 0, High: 100},
+		1: {PartitionID: 1, Low: 0, High: 100},
+	}
+	offsets := map[int32]int64{0: 60, 1: -1} // No message newer than the timestamp on partition 1
+
+	// Broker assigned timestamps are monotonic, so that results can be balanced without filtering
+	logAppendReq := &ListMessageRequest{TopicName: "test", PartitionID: partitionsAll, StartOffset: StartOffsetTimestamp, MessageCount: 10,
+		timestampType: kafka.TimestampTypeLogAppendTime, timestampOffsets: offsets}
+	assert.Equal(t, map[int32]*kafka.PartitionConsumeRequest{
+		0: {PartitionID: 0, IsDrained: false, StartOffset: 60, EndOffset: 99, MaxMessageCount: 10, LowWaterMark: 0, HighWaterMark: 100},
+	}, calculateConsumeRequests(logAppendReq, marks))
+
+	// Producer assigned timestamps must be filtered, so that each partition consumer may consume up to the message count
+	createTimeReq := &ListMessageRequest{TopicName: "test", PartitionID: partitionsAll, StartOffset: StartOffsetTimestamp, MessageCount: 10,
+		timestampType: kafka.TimestampTypeCreateTime, timestampOffsets: map[int32]int64{0: 60, 1: 95}}
+	assert.Equal(t, map[int32]*kafka.PartitionConsumeRequest{
+		0: {PartitionID: 0, IsDrained: false, StartOffset: 60, EndOffset: 99, MaxMessageCount: 10, LowWaterMark: 0, HighWaterMark: 100},
+		1: {PartitionID: 1, IsDrained: false, StartOffset: 95, EndOffset: 99, MaxMessageCount: 10, LowWaterMark: 0, HighWaterMark: 100},
+	}, calculateConsumeRequests(createTimeReq, marks))
+}
+
+func TestRunBounded(t *testing.T) {
+	var mutex sync.Mutex
+	running, maxRunning := 0, 0
+	results := make(chan int64, 100)
+	done := make(chan struct{}, 20)
+
+	// 20 partitions with 5 messages each
+	jobs := make([]func(), 20)
+	for i := range jobs {
+		partitionID := int64(i)
+		jobs[i] = func() {
+			defer func() { done <- struct{}{} }()
+			mutex.Lock()
+			running++
+			if running > maxRunning {
+				maxRunning = running
+			}
+			mutex.Unlock()
+
+			for offset := int64(0); offset < 5; offset++ {
+				time.Sleep(time.Millisecond)
+				results <- partitionID*100 + offset
+			}
+
+			mutex.Lock()
+			running--
+			mutex.Unlock()
+		}
+	}
+
+	runBounded(context.Background(), jobs, 3, func() { t.Error("no job must be skipped") })
+	for range jobs {
+		<-done
+	}
+	close(results)
+
+	merged := make([]int64, 0, 100)
+	for r := range results {
+		merged = append(merged, r)
+	}
+	sort.Slice(merged, func(i, j int) bool { return merged[i] < merged[j] })
+
+	expected := make([]int64, 0, 100)
+	for partitionID := int64(0); partitionID < 20; partitionID++ {
+		for offset := int64(0); offset < 5; offset++ {
+			expected = append(expected, partitionID*100+offset)
+		}
+	}
+	assert.Equal(t, expected, merged)
+	assert.Equal(t, 3, maxRunning)
+}
+
+func TestRunBounded_SkipsQueuedJobsOnceDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	release := make(chan struct{})
+	done := make(chan string, 5)
+
+	jobs := make([]func(), 5)
+	for i := range jobs {
+		jobs[i] = func() {
+			<-release
+			done <- "run"
+		}
+	}
+	runBounded(ctx, jobs, 2, func() { done <- "skipped" })
+
+	// Let the two running jobs block until the request is cancelled, all others are still queued
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+
+	counts := make(map[string]int)
+	for range jobs {
+		counts[<-done]++
+	}
+	assert.Equal(t, map[string]int{"run": 2, "skipped": 3}, counts)
+}
@@ -63,3 +63,35 @@ func (s *Service) logDirSizeByTopic() (map[string]int64, error) {
 
 	return sizeByTopic, nil
 }
+
+// logDirSizeByPartition returns a nested map where the topic name and partition ID are the keys and the size of
+// the partition's biggest replica is the value.
+func (s *Service) logDirSizeByPartition() (map[string]map[int32]int64, error) {
+	responses := s.kafkaSvc.DescribeLogDirs()
+
+	sizeByPartition := make(map[string]map[int32]int64)
+	for _, response := range responses {
+		if response.Err != nil {
+			continue
+		}
+
+		for _, dir := range response.LogDirs {
+			if dir.ErrorCode != sarama.ErrNoError {
+				return nil, fmt.Errorf("log dir request has failed with error code '%v' - %s", dir.ErrorCode, dir.ErrorCode.Error())
+			}
+
+			for _, topic := range dir.Topics {
+				if _, exists := sizeByPartition[topic.Topic]; !exists {
+					sizeByPartition[topic.Topic] = make(map[int32]int64)
+				}
+				for _, partition := range topic.Partitions {
+					if partition.Size > sizeByPartition[topic.Topic][partition.PartitionID] {
+						sizeByPartition[topic.Topic][partition.PartitionID] = partition.Size
+					}
+				}
+			}
+		}
+	}
+
+	return sizeByPartition, nil
+}
@@ -0,0 +1,100 @@
+package owl
+
+import (
+	"sync"
+	"time"
+)
+
+// messageBookmarkTTL is the duration after which a bookmark which hasn't been updated is forgotten
+const messageBookmarkTTL = 7 * 24 * time.Hour
+
+// MessageBookmark is the next offset to view per partition of a topic, i.e. the offset after the last viewed message
+type MessageBookmark struct {
+	Offsets   map[int32]int64 `json:"offsets"`
+	UpdatedAt time.Time       `json:"updatedAt"`
+}
+
+// messageBookmarks remembers per user and topic where the user has stopped browsing, so that a later request can
+// resume from there. Bookmarks are only kept in memory, they are lost when Kowl restarts and are not shared across
+// multiple Kowl instances.
+type messageBookmarks struct {
+	mutex     sync.Mutex
+	bookmarks map[ownerTopic]*MessageBookmark
+}
+
+type ownerTopic struct {
+	owner     string
+	topicName string
+}
+
+func newMessageBookmarks() *messageBookmarks {
+	return &messageBookmarks{bookmarks: make(map[ownerTopic]*MessageBookmark)}
+}
+
+// set replaces the owner's bookmark for the topic
+func (b *messageBookmarks) set(owner string, topicName string, offsets map[int32]int64, now time.Time) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.prune(now)
+
+	copied := make(map[int32]int64, len(offsets))
+	for partitionID, offset := range offsets {
+		copied[partitionID] = offset
+	}
+	b.bookmarks[ownerTopic{owner: owner, topicName: topicName}] = &MessageBookmark{Offsets: copied, UpdatedAt: now}
+}
+
+// advance moves the bookmark of a partition past the viewed offset. Bookmarks never move backwards, so that
+// viewing older messages again doesn't lose the position.
+func (b *messageBookmarks) advance(owner string, topicName string, partitionID int32, viewedOffset int64, now time.Time) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	pt := ownerTopic{owner: owner, topicName: topicName}
+	bookmark, exists := b.bookmarks[pt]
+	if !exists {
+		bookmark = &MessageBookmark{Offsets: make(map[int32]int64)}
+		b.bookmarks[pt] = bookmark
+	}
+	if current, exists := bookmark.Offsets[partitionID]; !exists || viewedOffset+1 > current {
+		bookmark.Offsets[partitionID] = viewedOffset + 1
+	}
+	bookmark.UpdatedAt = now
+}
+
+// get returns a copy of the owner's bookmark for the topic or nil if there is none
+func (b *messageBookmarks) get(owner string, topicName string, now time.Time) *MessageBookmark {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.prune(now)
+
+	bookmark, exists := b.bookmarks[ownerTopic{owner: owner, topicName: topicName}]
+	if !exists {
+		return nil
+	}
+
+	copied := &MessageBookmark{Offsets: make(map[int32]int64, len(bookmark.Offsets)), UpdatedAt: bookmark.UpdatedAt}
+	for partitionID, offset := range bookmark.Offsets {
+		copied.Offsets[partitionID] = offset
+	}
+	return copied
+}
+
+func (b *messageBookmarks) prune(now time.Time) {
+	for pt, bookmark := range b.bookmarks {
+		if now.Sub(bookmark.UpdatedAt) > messageBookmarkTTL {
+			delete(b.bookmarks, pt)
+		}
+	}
+}
+
+// SetMessageBookmark stores the next offsets to view per partition for the owner and topic, replacing the
+// previous bookmark. A consume request with StartOffsetBookmark resumes from these offsets.
+func (s *Service) SetMessageBookmark(owner string, topicName string, offsets map[int32]int64) {
+	s.bookmarks.set(owner, topicName, offsets, time.Now())
+}
+
+// GetMessageBookmark returns the owner's bookmark for the topic or nil if there is none
+func (s *Service) GetMessageBookmark(owner string, topicName string) *MessageBookmark {
+	return s.bookmarks.get(owner, topicName, time.Now())
+}
@@ -0,0 +1,42 @@
+package owl
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cloudhut/kowl/backend/pkg/kafka"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessageBookmarks_Resume(t *testing.T) {
+	b := newMessageBookmarks()
+	now := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+	marks := map[int32]*kafka.WaterMark{
+		0: {PartitionID: 0, Low: 0, High: 100},
+		1: {PartitionID: 1, Low: 0, High: 100},
+		2: {PartitionID: 2, Low: 20, High: 30}, // Not bookmarked yet
+	}
+
+	// The bookmark is set explicitly and advanced by each viewed message of a page
+	b.set("10.0.0.1", "orders", map[int32]int64{0: 50, 1: 99}, now)
+	b.advance("10.0.0.1", "orders", 0, 54, now)
+	b.advance("10.0.0.1", "orders", 1, 10, now) // Viewing older messages again doesn't move the bookmark back
+	b.advance("10.0.0.1", "orders", 1, 99, now)
+	assert.Nil(t, b.get("10.0.0.2", "orders", now))
+
+	// A later request resumes after the last viewed messages
+	bookmark := b.get("10.0.0.1", "orders", now.Add(time.Hour))
+	require.NotNil(t, bookmark)
+	assert.Equal(t, map[int32]int64{0: 55, 1: 100}, bookmark.Offsets)
+
+	req := &ListMessageRequest{TopicName: "orders", PartitionID: partitionsAll, StartOffset: StartOffsetBookmark, MessageCount: 10,
+		bookmarkOffsets: bookmark.Offsets}
+	requests := calculateConsumeRequests(req, marks)
+	require.Len(t, requests, 2) // Partition 1 has no new messages
+	assert.Equal(t, int64(55), requests[0].StartOffset)
+	assert.Equal(t, int64(20), requests[2].StartOffset)
+
+	// Bookmarks which haven't been updated for a long time are forgotten
+	assert.Nil(t, b.get("10.0.0.1", "orders", now.Add(messageBookmarkTTL+time.Minute)))
+}
@@ -0,0 +1,157 @@
+package owl
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cloudhut/kowl/backend/pkg/kafka"
+)
+
+const (
+	// defaultCorrelationMessageCount is the number of recent messages consumed per topic if no count is requested
+	defaultCorrelationMessageCount uint16 = 100
+	// maxCorrelationMessageCount bounds the number of messages per topic that are kept in memory to be correlated
+	maxCorrelationMessageCount uint16 = 500
+	// defaultCorrelationMaxDelay is the max time between a request and its response if no delay is requested
+	defaultCorrelationMaxDelay = time.Minute
+)
+
+// CorrelateMessagesRequest consumes the most recent messages of a request and a response topic and pairs the
+// messages which share the same correlation key
+type CorrelateMessagesRequest struct {
+	RequestTopic   string               `json:"requestTopic"`
+	ResponseTopic  string               `json:"responseTopic"`
+	CorrelationKey kafka.CorrelationKey `json:"correlationKey"`
+
+	// MessageCount is the number of recent messages that are consumed from each topic, defaults to 100 and must
+	// not exceed 500
+	MessageCount uint16 `json:"messageCount"`
+
+	// MaxDelay is the max time between the timestamps of a request and its response, defaults to one minute
+	MaxDelay time.Duration `json:"maxDelay"`
+}
+
+// Validate the correlate messages request
+func (c *CorrelateMessagesRequest) Validate() error {
+	if c.RequestTopic == "" || c.ResponseTopic == "" {
+		return fmt.Errorf("request and response topic must be set")
+	}
+	if c.MessageCount > maxCorrelationMessageCount {
+		return fmt.Errorf("message count must not exceed %d", maxCorrelationMessageCount)
+	}
+	if c.MaxDelay < 0 {
+		return fmt.Errorf("max delay must not be negative")
+	}
+	if err := c.CorrelationKey.Validate(); err != nil {
+		return fmt.Errorf("invalid correlation key: %w", err)
+	}
+
+	return nil
+}
+
+// CorrelatedMessages are the paired request and response messages along with all messages without a counterpart
+type CorrelatedMessages struct {
+	Pairs              []*CorrelatedMessagePair `json:"pairs"`
+	UnmatchedRequests  []*kafka.TopicMessage    `json:"unmatchedRequests"`
+	UnmatchedResponses []*kafka.TopicMessage    `json:"unmatchedResponses"`
+}
+
+// CorrelatedMessagePair is a request along with its response
+type CorrelatedMessagePair struct {
+	CorrelationKey string              `json:"correlationKey"`
+	Request        *kafka.TopicMessage `json:"request"`
+	Response       *kafka.TopicMessage `json:"response"`
+	DelaySeconds   int64               `json:"delaySeconds"` // Response timestamp - request timestamp
+}
+
+// CorrelateMessages joins the most recent messages of the request and the response topic by their correlation key,
+// which is read from a header or a JSON field. The correlation window is bounded by the number of messages consumed
+// per topic and by the max delay between a request and its response.
+func (s *Service) CorrelateMessages(ctx context.Context, req CorrelateMessagesRequest) (*CorrelatedMessages, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid correlate messages request: %w", err)
+	}
+	if req.MessageCount == 0 {
+		req.MessageCount = defaultCorrelationMessageCount
+	}
+	if req.MaxDelay == 0 {
+		req.MaxDelay = defaultCorrelationMaxDelay
+	}
+
+	consume := func(topicName string) ([]*kafka.TopicMessage, error) {
+		return s.collectMessages(ctx, ListMessageRequest{
+			TopicName:      topicName,
+			PartitionID:    partitionsAll,
+			StartOffset:    StartOffsetRecent,
+			MessageCount:   req.MessageCount,
+			CorrelationKey: &req.CorrelationKey,
+		})
+	}
+	requests, err := consume(req.RequestTopic)
+	if err != nil {
+		return nil, fmt.Errorf("failed to consume request topic: %w", err)
+	}
+	responses, err := consume(req.ResponseTopic)
+	if err != nil {
+		return nil, fmt.Errorf("failed to consume response topic: %w", err)
+	}
+
+	return correlateMessages(requests, responses, req.MaxDelay), nil
+}
+
+// correlateMessages pairs each request with the earliest response that has the same correlation key and has not been
+// paired yet, as long as their timestamps are not further apart than maxDelay. Timestamps are compared in both
+// directions, because producer clocks may be skewed. Messages without a correlation key are never paired.
+func correlateMessages(requests []*kafka.TopicMessage, responses []*kafka.TopicMessage, maxDelay time.Duration) *CorrelatedMessages {
+	sortMessagesCanonically(requests)
+	sortMessagesCanonically(responses)
+
+	responsesByKey := make(map[string][]*kafka.TopicMessage)
+	for _, response := range responses {
+		if response.CorrelationKey != "" {
+			responsesByKey[response.CorrelationKey] = append(responsesByKey[response.CorrelationKey], response)
+		}
+	}
+
+	res := &CorrelatedMessages{
+		Pairs:              make([]*CorrelatedMessagePair, 0),
+		UnmatchedRequests:  make([]*kafka.TopicMessage, 0),
+		UnmatchedResponses: make([]*kafka.TopicMessage, 0),
+	}
+	paired := make(map[*kafka.TopicMessage]struct{})
+	maxDelaySeconds := int64(maxDelay.Seconds())
+	for _, request := range requests {
+		var match *kafka.TopicMessage
+		for _, response := range responsesByKey[request.CorrelationKey] {
+			if _, isPaired := paired[response]; isPaired || request.CorrelationKey == "" {
+				continue
+			}
+			delay := response.Timestamp - request.Timestamp
+			if delay > maxDelaySeconds || -delay > maxDelaySeconds {
+				continue
+			}
+			match = response
+			break
+		}
+		if match == nil {
+			res.UnmatchedRequests = append(res.UnmatchedRequests, request)
+			continue
+		}
+
+		paired[match] = struct{}{}
+		res.Pairs = append(res.Pairs, &CorrelatedMessagePair{
+			CorrelationKey: request.CorrelationKey,
+			Request:        request,
+			Response:       match,
+			DelaySeconds:   match.Timestamp - request.Timestamp,
+		})
+	}
+	for _, response := range responses {
+		if _, isPaired := paired[response]; !isPaired {
+			res.UnmatchedResponses = append(res.UnmatchedResponses, response)
+		}
+	}
+
+	return res
+}
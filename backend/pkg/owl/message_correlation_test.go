@@ -0,0 +1,41 @@
+package owl
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cloudhut/kowl/backend/pkg/kafka"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCorrelateMessages(t *testing.T) {
+	newMessage := func(offset int64, timestamp int64, correlationKey string) *kafka.TopicMessage {
+		return &kafka.TopicMessage{Offset: offset, Timestamp: timestamp, CorrelationKey: correlationKey}
+	}
+	requests := []*kafka.TopicMessage{
+		newMessage(0, 1000, "a"),
+		newMessage(1, 1001, "b"), // Never answered
+		newMessage(2, 1002, ""),
+		newMessage(3, 1003, "c"),
+	}
+	responses := []*kafka.TopicMessage{
+		newMessage(10, 1004, "a"),
+		newMessage(11, 1500, "c"), // Too late to be the response to c
+		newMessage(12, 1010, "c"),
+	}
+
+	res := correlateMessages(requests, responses, time.Minute)
+	require.Len(t, res.Pairs, 2)
+	assert.Equal(t, "a", res.Pairs[0].CorrelationKey)
+	assert.Equal(t, int64(0), res.Pairs[0].Request.Offset)
+	assert.Equal(t, int64(10), res.Pairs[0].Response.Offset)
+	assert.Equal(t, int64(4), res.Pairs[0].DelaySeconds)
+	assert.Equal(t, int64(12), res.Pairs[1].Response.Offset)
+
+	require.Len(t, res.UnmatchedRequests, 2)
+	assert.Equal(t, int64(1), res.UnmatchedRequests[0].Offset)
+	assert.Equal(t, int64(2), res.UnmatchedRequests[1].Offset)
+	require.Len(t, res.UnmatchedResponses, 1)
+	assert.Equal(t, int64(11), res.UnmatchedResponses[0].Offset)
+}
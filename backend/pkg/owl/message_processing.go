@@ -0,0 +1,76 @@
+package owl
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/cloudhut/kowl/backend/pkg/kafka"
+)
+
+// MessageProcessingConfig configures the processing pipelines which tailor the message values users see per topic,
+// e.g. by redacting sensitive fields. Processors are applied server side, so that processed fields never leave the
+// backend. Values which can't be processed are removed. Message keys are passed through unchanged, hence sensitive
+// data must not be part of the keys.
+type MessageProcessingConfig struct {
+	Pipelines []TopicProcessingPipeline `yaml:"pipelines"`
+}
+
+// TopicProcessingPipeline is an ordered list of processors which is applied to the message values of all topics
+// matching the regex pattern. If multiple pipelines match a topic, they are applied in the configured order.
+type TopicProcessingPipeline struct {
+	TopicPattern string                   `yaml:"topicPattern"`
+	Processors   []kafka.MessageProcessor `yaml:"processors"`
+}
+
+// SetDefaults for the message processing config
+func (c *MessageProcessingConfig) SetDefaults() {
+	c.Pipelines = make([]TopicProcessingPipeline, 0)
+}
+
+// Validate the message processing config
+func (c *MessageProcessingConfig) Validate() error {
+	for i, pipeline := range c.Pipelines {
+		if pipeline.TopicPattern == "" {
+			return fmt.Errorf("processing pipeline at index %v has no topic pattern", i)
+		}
+		if _, err := regexp.Compile(pipeline.TopicPattern); err != nil {
+			return fmt.Errorf("failed to compile topic pattern '%v': %w", pipeline.TopicPattern, err)
+		}
+		if err := kafka.ValidateMessageProcessors(pipeline.Processors); err != nil {
+			return fmt.Errorf("invalid processing pipeline for topic pattern '%v': %w", pipeline.TopicPattern, err)
+		}
+	}
+
+	return nil
+}
+
+// messageProcessors resolves the processors of a topic from the configured processing pipelines
+type messageProcessors []compiledProcessingPipeline
+
+type compiledProcessingPipeline struct {
+	pattern    *regexp.Regexp
+	processors []kafka.MessageProcessor
+}
+
+// newMessageProcessors expects a validated config
+func newMessageProcessors(cfg MessageProcessingConfig) messageProcessors {
+	pipelines := make(messageProcessors, len(cfg.Pipelines))
+	for i, pipeline := range cfg.Pipelines {
+		pipelines[i] = compiledProcessingPipeline{
+			pattern:    regexp.MustCompile(pipeline.TopicPattern),
+			processors: pipeline.Processors,
+		}
+	}
+	return pipelines
+}
+
+// forTopic returns the processors of all pipelines whose pattern matches the topic, in the configured order
+func (m messageProcessors) forTopic(topicName string) []kafka.MessageProcessor {
+	var res []kafka.MessageProcessor
+	for _, pipeline := range m {
+		if pipeline.pattern.MatchString(topicName) {
+			res = append(res, pipeline.processors...)
+		}
+	}
+	return res
+}
@@ -0,0 +1,77 @@
+package owl
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/cloudhut/kowl/backend/pkg/kafka"
+)
+
+// messageCollector implements kafka.IListMessagesProgress and collects all consumed messages, so that messages
+// can be sampled for diagnostics.
+type messageCollector struct {
+	mutex    sync.Mutex
+	messages []*kafka.TopicMessage
+	errMsg   string
+}
+
+func (m *messageCollector) OnPhase(name string) {}
+
+func (m *messageCollector) OnMessage(message *kafka.TopicMessage) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.messages = append(m.messages, message)
+}
+
+func (m *messageCollector) OnMessageConsumed(size int64) {}
+
+func (m *messageCollector) OnComplete(elapsedMs int64, isCancelled bool) {}
+
+func (m *messageCollector) OnThrottled(delayMs int64, reason string) {}
+
+func (m *messageCollector) OnStartOffsetAdjusted(partitionID int32, plannedOffset, adjustedOffset int64) {
+}
+
+func (m *messageCollector) OnBatchCorrupted(partitionID int32, offset int64, reason string) {
+	m.OnError(fmt.Sprintf("record batch at offset %v of partition %v is corrupted: %v", offset, partitionID, reason))
+}
+
+func (m *messageCollector) OnSampled(rate int64, consumedCount int64, sampledCount int64) {}
+
+func (m *messageCollector) OnError(msg string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.errMsg = msg
+}
+
+// sampleRecentMessages returns up to count of the most recent messages across all partitions of a topic
+func (s *Service) sampleRecentMessages(ctx context.Context, topicName string, count uint16) ([]*kafka.TopicMessage, error) {
+	req := ListMessageRequest{
+		TopicName:    topicName,
+		PartitionID:  partitionsAll,
+		StartOffset:  StartOffsetRecent,
+		MessageCount: count,
+	}
+	messages, err := s.collectMessages(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sample messages: %w", err)
+	}
+	return messages, nil
+}
+
+// collectMessages consumes the requested messages and returns them once the request is complete
+func (s *Service) collectMessages(ctx context.Context, req ListMessageRequest) ([]*kafka.TopicMessage, error) {
+	collector := &messageCollector{messages: make([]*kafka.TopicMessage, 0, req.MessageCount)}
+	err := s.ListMessages(ctx, req, collector)
+	if err != nil {
+		return nil, err
+	}
+
+	collector.mutex.Lock()
+	defer collector.mutex.Unlock()
+	if collector.errMsg != "" {
+		return nil, fmt.Errorf("%v", collector.errMsg)
+	}
+	return collector.messages, nil
+}
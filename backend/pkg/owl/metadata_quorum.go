@@ -0,0 +1,86 @@
+package owl
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/cloudhut/kowl/backend/pkg/kafka"
+)
+
+// MetadataQuorumInfo describes the state of the KRaft metadata quorum
+type MetadataQuorumInfo struct {
+	IsKRaft       bool                    `json:"isKRaft"`
+	LeaderID      int32                   `json:"leaderId"`
+	LeaderEpoch   int32                   `json:"leaderEpoch"`
+	HighWatermark int64                   `json:"highWatermark"`
+	Voters        []*MetadataQuorumMember `json:"voters"`
+	Observers     []*MetadataQuorumMember `json:"observers"`
+}
+
+// MetadataQuorumMember is a voter or observer of the metadata quorum
+type MetadataQuorumMember struct {
+	NodeID       int32 `json:"nodeId"`
+	LogEndOffset int64 `json:"logEndOffset"`
+	Lag          int64 `json:"lag"` // Number of records the member is behind the quorum's high watermark
+}
+
+// GetMetadataQuorumInfo returns the state of the cluster's metadata quorum. For ZooKeeper-mode clusters a result
+// with IsKRaft set to false is returned.
+func (s *Service) GetMetadataQuorumInfo(ctx context.Context) (*MetadataQuorumInfo, error) {
+	versions, err := s.kafkaSvc.GetAPIVersions()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get api versions: %w", err)
+	}
+
+	isKRaft := false
+	maxVersion := int16(0)
+	for _, apiVersion := range versions.ApiVersions {
+		if apiVersion.ApiKey == kafka.APIKeyDescribeQuorum {
+			isKRaft = true
+			maxVersion = apiVersion.MaxVersion
+			break
+		}
+	}
+	if !isKRaft {
+		return &MetadataQuorumInfo{IsKRaft: false}, nil
+	}
+
+	quorum, err := s.kafkaSvc.DescribeQuorum(ctx, maxVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	return newMetadataQuorumInfo(quorum.LeaderID, quorum.LeaderEpoch, quorum.HighWatermark,
+		newMetadataQuorumMembers(quorum.Voters), newMetadataQuorumMembers(quorum.Observers)), nil
+}
+
+func newMetadataQuorumMembers(states []*kafka.QuorumReplicaState) []*MetadataQuorumMember {
+	members := make([]*MetadataQuorumMember, len(states))
+	for i, state := range states {
+		members[i] = &MetadataQuorumMember{NodeID: state.ReplicaID, LogEndOffset: state.LogEndOffset}
+	}
+	return members
+}
+
+// newMetadataQuorumInfo computes each member's replication lag relative to the quorum's high watermark
+func newMetadataQuorumInfo(leaderID int32, leaderEpoch int32, highWatermark int64, voters []*MetadataQuorumMember, observers []*MetadataQuorumMember) *MetadataQuorumInfo {
+	for _, members := range [][]*MetadataQuorumMember{voters, observers} {
+		for _, member := range members {
+			member.Lag = highWatermark - member.LogEndOffset
+			if member.Lag < 0 {
+				member.Lag = 0
+			}
+		}
+		sort.Slice(members, func(i, j int) bool { return members[i].NodeID < members[j].NodeID })
+	}
+
+	return &MetadataQuorumInfo{
+		IsKRaft:       true,
+		LeaderID:      leaderID,
+		LeaderEpoch:   leaderEpoch,
+		HighWatermark: highWatermark,
+		Voters:        voters,
+		Observers:     observers,
+	}
+}
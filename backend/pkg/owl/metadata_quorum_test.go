@@ -0,0 +1,26 @@
+package owl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewMetadataQuorumInfo(t *testing.T) {
+	voters := []*MetadataQuorumMember{
+		{NodeID: 3, LogEndOffset: 880},
+		{NodeID: 1, LogEndOffset: 1000},
+		{NodeID: 2, LogEndOffset: 1000},
+	}
+	observers := []*MetadataQuorumMember{{NodeID: 4, LogEndOffset: 995}}
+
+	info := newMetadataQuorumInfo(1, 7, 1000, voters, observers)
+	assert.True(t, info.IsKRaft)
+	require.Len(t, info.Voters, 3)
+	assert.Equal(t, int32(1), info.Voters[0].NodeID)
+	assert.Equal(t, int64(0), info.Voters[0].Lag)
+	assert.Equal(t, int32(3), info.Voters[2].NodeID)
+	assert.Equal(t, int64(120), info.Voters[2].Lag)
+	assert.Equal(t, int64(5), info.Observers[0].Lag)
+}
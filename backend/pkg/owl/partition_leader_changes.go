@@ -0,0 +1,157 @@
+package owl
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// FlappingPartition describes how often the leader of a partition has changed within the inspected window.
+// The metadata version supported by our Kafka client does not expose the leader epoch, therefore leader changes
+// are derived from the leader broker id of consecutive samples. Re-elections of the same broker in between two
+// samples are not visible, so LeaderChanges is a lower bound of the leader epoch advances.
+type FlappingPartition struct {
+	TopicName     string  `json:"topicName"`
+	PartitionID   int32   `json:"partitionId"`
+	LeaderChanges int     `json:"leaderChanges"`
+	Leaders       []int32 `json:"leaders"` // Sequence of observed leader broker ids, oldest first
+	IsFlapping    bool    `json:"isFlapping"`
+}
+
+// leaderSnapshot contains the leader broker id of all partitions at a given point in time
+type leaderSnapshot struct {
+	Timestamp time.Time
+	Leaders   map[string]map[int32]int32 // TopicName -> PartitionID -> Leader broker id
+}
+
+// leaderHistory keeps the recorded partition leaders in memory for the configured retention
+type leaderHistory struct {
+	mutex     sync.RWMutex
+	retention time.Duration
+	snapshots []*leaderSnapshot // Sorted by time, oldest first
+}
+
+func newLeaderHistory(retention time.Duration) *leaderHistory {
+	return &leaderHistory{retention: retention}
+}
+
+// add records the given leaders and removes all snapshots which are older than the retention
+func (h *leaderHistory) add(leaders map[string]map[int32]int32, now time.Time) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.snapshots = append(h.snapshots, &leaderSnapshot{Timestamp: now, Leaders: leaders})
+
+	cutoff := now.Add(-h.retention)
+	i := 0
+	for i < len(h.snapshots) && h.snapshots[i].Timestamp.Before(cutoff) {
+		i++
+	}
+	h.snapshots = h.snapshots[i:]
+}
+
+// get returns all snapshots that have been recorded since the given time
+func (h *leaderHistory) get(since time.Time) []*leaderSnapshot {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+
+	snapshots := make([]*leaderSnapshot, 0, len(h.snapshots))
+	for _, snapshot := range h.snapshots {
+		if snapshot.Timestamp.Before(since) {
+			continue
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	return snapshots
+}
+
+// ListFlappingPartitions returns all partitions whose leader has changed at least once within the given window.
+// Partitions with at least threshold leader changes are flagged as flapping.
+func (s *Service) ListFlappingPartitions(window time.Duration, threshold int) ([]*FlappingPartition, error) {
+	if s.leaderHistory == nil {
+		return nil, ErrLagHistoryDisabled
+	}
+	if threshold <= 0 {
+		return nil, fmt.Errorf("threshold must be greater than zero")
+	}
+
+	snapshots := s.leaderHistory.get(time.Now().Add(-window))
+	return findFlappingPartitions(snapshots, threshold), nil
+}
+
+// samplePartitionLeaders returns the current leader broker id of all partitions
+func (s *Service) samplePartitionLeaders(_ context.Context) (map[string]map[int32]int32, error) {
+	topics, err := s.kafkaSvc.DescribeTopics(nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe topics: %w", err)
+	}
+
+	leaders := make(map[string]map[int32]int32, len(topics))
+	for _, topic := range topics {
+		if topic.Err != 0 {
+			continue
+		}
+		partitionLeaders := make(map[int32]int32, len(topic.Partitions))
+		for _, partition := range topic.Partitions {
+			partitionLeaders[partition.ID] = partition.Leader
+		}
+		leaders[topic.Name] = partitionLeaders
+	}
+
+	return leaders, nil
+}
+
+// findFlappingPartitions counts the leader changes of each partition across the given snapshots, which must be
+// sorted by time. Partitions missing in a snapshot (e.g. due to a failed topic lookup) keep their last known leader.
+func findFlappingPartitions(snapshots []*leaderSnapshot, threshold int) []*FlappingPartition {
+	partitionsByTopic := make(map[string]map[int32]*FlappingPartition)
+	for _, snapshot := range snapshots {
+		for topicName, leaders := range snapshot.Leaders {
+			partitions, exists := partitionsByTopic[topicName]
+			if !exists {
+				partitions = make(map[int32]*FlappingPartition)
+				partitionsByTopic[topicName] = partitions
+			}
+			for partitionID, leader := range leaders {
+				p, exists := partitions[partitionID]
+				if !exists {
+					partitions[partitionID] = &FlappingPartition{
+						TopicName:   topicName,
+						PartitionID: partitionID,
+						Leaders:     []int32{leader},
+					}
+					continue
+				}
+				if p.Leaders[len(p.Leaders)-1] != leader {
+					p.Leaders = append(p.Leaders, leader)
+					p.LeaderChanges++
+				}
+			}
+		}
+	}
+
+	res := make([]*FlappingPartition, 0)
+	for _, partitions := range partitionsByTopic {
+		for _, p := range partitions {
+			if p.LeaderChanges == 0 {
+				continue
+			}
+			p.IsFlapping = p.LeaderChanges >= threshold
+			res = append(res, p)
+		}
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].LeaderChanges != res[j].LeaderChanges {
+			return res[i].LeaderChanges > res[j].LeaderChanges
+		}
+		if res[i].TopicName != res[j].TopicName {
+			return res[i].TopicName < res[j].TopicName
+		}
+		return res[i].PartitionID < res[j].PartitionID
+	})
+
+	return res
+}
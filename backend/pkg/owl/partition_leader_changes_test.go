@@ -0,0 +1,52 @@
+package owl
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindFlappingPartitions(t *testing.T) {
+	start := time.Date(2020, 5, 1, 12, 0, 0, 0, time.UTC)
+	leadersPerSample := []map[int32]int32{
+		{0: 1, 1: 2, 2: 3},
+		{0: 2, 1: 2, 2: 3},
+		{0: 1, 1: 2, 2: 1},
+		{0: 3, 1: 2},
+		{0: 1, 1: 2, 2: 1},
+	}
+
+	snapshots := make([]*leaderSnapshot, len(leadersPerSample))
+	for i, leaders := range leadersPerSample {
+		snapshots[i] = &leaderSnapshot{
+			Timestamp: start.Add(time.Duration(i) * time.Minute),
+			Leaders:   map[string]map[int32]int32{"orders": leaders},
+		}
+	}
+
+	res := findFlappingPartitions(snapshots, 3)
+	require.Len(t, res, 2)
+
+	assert.Equal(t, int32(0), res[0].PartitionID)
+	assert.Equal(t, 4, res[0].LeaderChanges)
+	assert.Equal(t, []int32{1, 2, 1, 3, 1}, res[0].Leaders)
+	assert.True(t, res[0].IsFlapping)
+
+	// Partition 2 is missing in one snapshot and keeps its last known leader
+	assert.Equal(t, int32(2), res[1].PartitionID)
+	assert.Equal(t, 1, res[1].LeaderChanges)
+	assert.False(t, res[1].IsFlapping)
+}
+
+func TestLeaderHistoryRetention(t *testing.T) {
+	start := time.Date(2020, 5, 1, 12, 0, 0, 0, time.UTC)
+	h := newLeaderHistory(2 * time.Minute)
+	for i := 0; i < 5; i++ {
+		h.add(map[string]map[int32]int32{"orders": {0: int32(i)}}, start.Add(time.Duration(i)*time.Minute))
+	}
+
+	assert.Len(t, h.get(time.Time{}), 3)
+	assert.Len(t, h.get(start.Add(4*time.Minute)), 1)
+}
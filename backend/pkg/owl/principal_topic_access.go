@@ -0,0 +1,133 @@
+package owl
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Shopify/sarama"
+)
+
+const (
+	// TopicOperationRead is the permission to consume from a topic
+	TopicOperationRead = "Read"
+	// TopicOperationWrite is the permission to produce to a topic
+	TopicOperationWrite = "Write"
+	// TopicOperationDescribe is the permission to fetch a topic's metadata
+	TopicOperationDescribe = "Describe"
+)
+
+var evaluatedTopicOperations = map[string]sarama.AclOperation{
+	TopicOperationRead:     sarama.AclOperationRead,
+	TopicOperationWrite:    sarama.AclOperationWrite,
+	TopicOperationDescribe: sarama.AclOperationDescribe,
+}
+
+// AccessibleTopic is a topic along with the operations a principal is allowed to perform on it
+type AccessibleTopic struct {
+	TopicName  string   `json:"topicName"`
+	Operations []string `json:"operations"`
+}
+
+// GetAccessibleTopicsForPrincipal returns all topics which the given principal (e.g. "User:billing") can read,
+// write or describe. The ACLs are evaluated with the semantics of Kafka's default authorizer: wildcard and
+// prefixed ACLs apply, a matching DENY always overrides ALLOW and Read/Write imply Describe. Since the principal's
+// host is unknown, only ACLs which apply to all hosts are taken into account.
+func (s *Service) GetAccessibleTopicsForPrincipal(ctx context.Context, principal string) ([]*AccessibleTopic, error) {
+	topics, err := s.kafkaSvc.ListTopics()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list topics: %w", err)
+	}
+
+	acls, err := s.kafkaSvc.DescribeTopicACLs()
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe topic acls: %w", err)
+	}
+
+	topicNames := make([]string, len(topics))
+	for i, topic := range topics {
+		topicNames[i] = topic.Name
+	}
+
+	return evaluateTopicAccess(principal, topicNames, acls), nil
+}
+
+func evaluateTopicAccess(principal string, topicNames []string, resourceACLs []*sarama.ResourceAcls) []*AccessibleTopic {
+	res := make([]*AccessibleTopic, 0)
+	for _, topicName := range topicNames {
+		operations := make([]string, 0)
+		for name, operation := range evaluatedTopicOperations {
+			if isTopicOperationAuthorized(principal, topicName, operation, resourceACLs) {
+				operations = append(operations, name)
+			}
+		}
+		if len(operations) == 0 {
+			continue
+		}
+		sort.Strings(operations)
+		res = append(res, &AccessibleTopic{TopicName: topicName, Operations: operations})
+	}
+
+	return res
+}
+
+func isTopicOperationAuthorized(principal string, topicName string, operation sarama.AclOperation, resourceACLs []*sarama.ResourceAcls) bool {
+	isAllowed := false
+	for _, resourceACL := range resourceACLs {
+		if !aclResourceMatches(resourceACL.Resource, topicName) {
+			continue
+		}
+
+		for _, acl := range resourceACL.Acls {
+			if acl.Host != "*" || !aclPrincipalMatches(acl.Principal, principal) {
+				continue
+			}
+
+			switch acl.PermissionType {
+			case sarama.AclPermissionDeny:
+				if acl.Operation == operation || acl.Operation == sarama.AclOperationAll {
+					return false
+				}
+			case sarama.AclPermissionAllow:
+				if aclOperationImplies(acl.Operation, operation) {
+					isAllowed = true
+				}
+			}
+		}
+	}
+
+	return isAllowed
+}
+
+func aclResourceMatches(resource sarama.Resource, topicName string) bool {
+	if resource.ResourceType != sarama.AclResourceTopic {
+		return false
+	}
+
+	switch resource.ResourcePatternType {
+	case sarama.AclPatternLiteral:
+		return resource.ResourceName == "*" || resource.ResourceName == topicName
+	case sarama.AclPatternPrefixed:
+		return strings.HasPrefix(topicName, resource.ResourceName)
+	}
+	return false
+}
+
+func aclPrincipalMatches(aclPrincipal string, principal string) bool {
+	return aclPrincipal == principal || aclPrincipal == "User:*"
+}
+
+// aclOperationImplies returns true if an ALLOW for the granted operation also grants the requested operation
+func aclOperationImplies(granted sarama.AclOperation, requested sarama.AclOperation) bool {
+	if granted == requested || granted == sarama.AclOperationAll {
+		return true
+	}
+	if requested == sarama.AclOperationDescribe {
+		switch granted {
+		case sarama.AclOperationRead, sarama.AclOperationWrite, sarama.AclOperationDelete, sarama.AclOperationAlter:
+			return true
+		}
+	}
+	return false
+}
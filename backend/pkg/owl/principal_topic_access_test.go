@@ -0,0 +1,28 @@
+package owl
+
+import (
+	"testing"
+
+	"github.com/Shopify/sarama"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEvaluateTopicAccess(t *testing.T) {
+	acls := []*sarama.ResourceAcls{
+		{
+			Resource: sarama.Resource{ResourceType: sarama.AclResourceTopic, ResourceName: "billing.", ResourcePatternType: sarama.AclPatternPrefixed},
+			Acls:     []*sarama.Acl{{Principal: "User:billing", Host: "*", Operation: sarama.AclOperationRead, PermissionType: sarama.AclPermissionAllow}},
+		},
+		{
+			Resource: sarama.Resource{ResourceType: sarama.AclResourceTopic, ResourceName: "billing.secrets", ResourcePatternType: sarama.AclPatternLiteral},
+			Acls:     []*sarama.Acl{{Principal: "User:billing", Host: "*", Operation: sarama.AclOperationRead, PermissionType: sarama.AclPermissionDeny}},
+		},
+	}
+	topics := []string{"billing.invoices", "billing.secrets", "shipping.orders"}
+
+	accessible := evaluateTopicAccess("User:billing", topics, acls)
+	assert.Equal(t, []*AccessibleTopic{
+		{TopicName: "billing.invoices", Operations: []string{TopicOperationDescribe, TopicOperationRead}},
+		{TopicName: "billing.secrets", Operations: []string{TopicOperationDescribe}},
+	}, accessible)
+}
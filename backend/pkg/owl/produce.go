@@ -0,0 +1,95 @@
+package owl
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// ProduceRequest describes a single record which shall be produced
+type ProduceRequest struct {
+	TopicName   string
+	PartitionID int32 // -1 to choose the partition based on the key
+	Key         []byte
+	Value       []byte
+
+	// Tombstone produces a record with a null value, which deletes the key in a compacted topic. It requires a key
+	// and the value must be empty.
+	Tombstone bool
+}
+
+// ProduceResponse is the position the record has been written to
+type ProduceResponse struct {
+	PartitionID int32    `json:"partitionId"`
+	Offset      int64    `json:"offset"`
+	Warnings    []string `json:"warnings"` // E.g. a tombstone has been produced to a non compacted topic
+}
+
+// Validate the produce request
+func (r *ProduceRequest) Validate() error {
+	if r.TopicName == "" {
+		return fmt.Errorf("topic name is required")
+	}
+	if r.PartitionID < -1 {
+		return fmt.Errorf("partition id must be -1 (any partition) or a valid partition id")
+	}
+	if r.Tombstone {
+		if len(r.Key) == 0 {
+			return fmt.Errorf("a tombstone requires a key")
+		}
+		if len(r.Value) > 0 {
+			return fmt.Errorf("a tombstone must not have a value")
+		}
+	}
+
+	return nil
+}
+
+// Produce writes a single record to the topic. Tombstones are produced with a null value, regular records with an
+// empty value are produced with an empty (non null) value. Producing a tombstone to a topic which is not compacted
+// succeeds, but returns a warning as the key won't be deleted.
+func (s *Service) Produce(_ context.Context, req ProduceRequest) (*ProduceResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid produce request: %w", err)
+	}
+
+	warnings := make([]string, 0)
+	value := req.Value
+	if req.Tombstone {
+		value = nil
+		if warning := s.checkTombstoneTopic(req.TopicName); warning != "" {
+			s.logger.Warn("producing tombstone to a topic which may not be compacted",
+				zap.String("topic", req.TopicName), zap.String("reason", warning))
+			warnings = append(warnings, warning)
+		}
+	} else if value == nil {
+		value = []byte{}
+	}
+
+	partitionID, offset, err := s.kafkaSvc.ProduceRecord(req.TopicName, req.PartitionID, req.Key, value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to produce record: %w", err)
+	}
+
+	return &ProduceResponse{PartitionID: partitionID, Offset: offset, Warnings: warnings}, nil
+}
+
+// checkTombstoneTopic returns a warning if the topic is not compacted or its cleanup policy couldn't be described
+func (s *Service) checkTombstoneTopic(topicName string) string {
+	configs, err := s.GetTopicConfigs(topicName, []string{configCleanupPolicy})
+	if err != nil {
+		return fmt.Sprintf("failed to verify that the topic is compacted: %v", err)
+	}
+	if configs == nil {
+		return "failed to verify that the topic is compacted: the topic's configs have not been described"
+	}
+
+	entry := configs.GetConfigEntryByName(configCleanupPolicy)
+	if entry == nil || !strings.Contains(entry.Value, "compact") {
+		return "topic is not compacted, the tombstone won't delete the key"
+	}
+
+	return ""
+}
@@ -0,0 +1,59 @@
+package owl
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Shopify/sarama"
+	"github.com/cloudhut/kowl/backend/pkg/kafka"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestService_ProduceTombstone(t *testing.T) {
+	broker := sarama.NewMockBroker(t, 1)
+	defer broker.Close()
+	broker.SetHandlerByMap(map[string]sarama.MockResponse{
+		"MetadataRequest": sarama.NewMockMetadataResponse(t).
+			SetBroker(broker.Addr(), broker.BrokerID()).
+			SetLeader("customers", 0, broker.BrokerID()),
+		"ProduceRequest":         sarama.NewMockProduceResponse(t).SetVersion(3),
+		"DescribeConfigsRequest": sarama.NewMockDescribeConfigsResponse(t),
+	})
+
+	cfg := sarama.NewConfig()
+	cfg.Version = sarama.V1_0_0_0
+	client, err := sarama.NewClient([]string{broker.Addr()}, cfg)
+	require.NoError(t, err)
+	defer client.Close()
+
+	svc := NewService(Config{}, &kafka.Service{Client: client, Logger: zap.NewNop()}, zap.NewNop())
+	res, err := svc.Produce(context.Background(), ProduceRequest{
+		TopicName:   "customers",
+		PartitionID: 0,
+		Key:         []byte("customer-42"),
+		Tombstone:   true,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int32(0), res.PartitionID)
+
+	// The mocked topic is not compacted
+	assert.Len(t, res.Warnings, 1)
+
+	// The null value of the tombstone on the wire is covered by the kafka package's produce tests
+	produced := false
+	for _, rr := range broker.History() {
+		if _, ok := rr.Request.(*sarama.ProduceRequest); ok {
+			produced = true
+		}
+	}
+	assert.True(t, produced, "tombstone must be produced")
+}
+
+func TestProduceRequest_Validate(t *testing.T) {
+	assert.NoError(t, (&ProduceRequest{TopicName: "customers", PartitionID: -1, Key: []byte("a"), Tombstone: true}).Validate())
+	assert.Error(t, (&ProduceRequest{TopicName: "customers", PartitionID: -1, Tombstone: true}).Validate())
+	assert.Error(t, (&ProduceRequest{TopicName: "customers", PartitionID: -1, Key: []byte("a"), Value: []byte("b"), Tombstone: true}).Validate())
+	assert.NoError(t, (&ProduceRequest{TopicName: "customers", PartitionID: -1, Value: []byte{}}).Validate())
+}
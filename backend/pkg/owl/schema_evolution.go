@@ -0,0 +1,81 @@
+package owl
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cloudhut/kowl/backend/pkg/schema"
+)
+
+// TopicSchemaEvolution describes the evolution rules and history of a topic's key and value subjects, which are
+// expected to follow the TopicNameStrategy (<topic>-key and <topic>-value).
+type TopicSchemaEvolution struct {
+	TopicName string            `json:"topicName"`
+	Key       *SubjectEvolution `json:"key"`
+	Value     *SubjectEvolution `json:"value"`
+}
+
+// SubjectEvolution is a subject's compatibility level along with its schema versions in registration order
+type SubjectEvolution struct {
+	Subject            string `json:"subject"`
+	CompatibilityLevel string `json:"compatibilityLevel"`
+	// IsGlobalCompatibility is true if the subject has no compatibility level of its own and the global one applies
+	IsGlobalCompatibility bool             `json:"isGlobalCompatibility"`
+	HasSchemas            bool             `json:"hasSchemas"`
+	Versions              []*SchemaVersion `json:"versions"` // Oldest first
+}
+
+// SchemaVersion is a single schema version which has been registered under a subject
+type SchemaVersion struct {
+	Version           int    `json:"version"`
+	SchemaID          uint32 `json:"schemaId"`
+	SchemaType        string `json:"schemaType"`
+	RegistrationOrder int    `json:"registrationOrder"` // 1 for the first registered version of the subject
+}
+
+// GetTopicSchemaEvolution returns the compatibility level and schema history of a topic's key and value subjects,
+// so that the evolution rules are known before changes to the schemas are proposed.
+func (s *Service) GetTopicSchemaEvolution(_ context.Context, topicName string) (*TopicSchemaEvolution, error) {
+	if s.kafkaSvc.SchemaService == nil {
+		return nil, fmt.Errorf("schema registry is not configured")
+	}
+
+	keyHistory, err := s.kafkaSvc.SchemaService.GetSubjectHistory(topicName + "-key")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get key subject history: %w", err)
+	}
+	valueHistory, err := s.kafkaSvc.SchemaService.GetSubjectHistory(topicName + "-value")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get value subject history: %w", err)
+	}
+
+	return &TopicSchemaEvolution{
+		TopicName: topicName,
+		Key:       newSubjectEvolution(keyHistory),
+		Value:     newSubjectEvolution(valueHistory),
+	}, nil
+}
+
+func newSubjectEvolution(history *schema.SubjectHistory) *SubjectEvolution {
+	res := &SubjectEvolution{
+		Subject:               history.Subject,
+		CompatibilityLevel:    history.CompatibilityLevel,
+		IsGlobalCompatibility: history.IsGlobalCompatibility,
+		HasSchemas:            len(history.Versions) > 0,
+		Versions:              make([]*SchemaVersion, len(history.Versions)),
+	}
+	for i, version := range history.Versions {
+		schemaType := version.SchemaType
+		if schemaType == "" {
+			schemaType = "AVRO"
+		}
+		res.Versions[i] = &SchemaVersion{
+			Version:           version.Version,
+			SchemaID:          version.ID,
+			SchemaType:        schemaType,
+			RegistrationOrder: i + 1,
+		}
+	}
+
+	return res
+}
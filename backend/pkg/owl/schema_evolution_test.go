@@ -0,0 +1,73 @@
+package owl
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cloudhut/kowl/backend/pkg/kafka"
+	"github.com/cloudhut/kowl/backend/pkg/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestGetTopicSchemaEvolution(t *testing.T) {
+	responses := map[string]string{
+		"/config":                           `{"compatibilityLevel":"BACKWARD"}`,
+		"/config/orders-value":              `{"compatibilityLevel":"FULL_TRANSITIVE"}`,
+		"/subjects/orders-value/versions":   `[3,1,2]`,
+		"/subjects/orders-value/versions/1": `{"subject":"orders-value","version":1,"id":10,"schema":"{}"}`,
+		"/subjects/orders-value/versions/2": `{"subject":"orders-value","version":2,"id":14,"schema":"{}"}`,
+		"/subjects/orders-value/versions/3": `{"subject":"orders-value","version":3,"id":21,"schema":"{}","schemaType":"JSON"}`,
+	}
+	registry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/config/orders-key":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error_code":40408,"message":"Subject does not have subject-level compatibility configured"}`))
+			return
+		case "/subjects/orders-key/versions":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error_code":40401,"message":"Subject not found."}`))
+			return
+		}
+		res, exists := responses[r.URL.Path]
+		if !exists {
+			t.Errorf("unexpected request to %v", r.URL.Path)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte(res))
+	}))
+	defer registry.Close()
+
+	svc := &Service{
+		kafkaSvc: &kafka.Service{SchemaService: schema.NewService(schema.Config{Enabled: true, URLs: []string{registry.URL}}, zap.NewNop())},
+		logger:   zap.NewNop(),
+	}
+
+	evolution, err := svc.GetTopicSchemaEvolution(context.Background(), "orders")
+	require.NoError(t, err)
+
+	// The key subject has no schemas and no compatibility level of its own
+	assert.Equal(t, &SubjectEvolution{
+		Subject:               "orders-key",
+		CompatibilityLevel:    "BACKWARD",
+		IsGlobalCompatibility: true,
+		HasSchemas:            false,
+		Versions:              []*SchemaVersion{},
+	}, evolution.Key)
+
+	assert.Equal(t, &SubjectEvolution{
+		Subject:            "orders-value",
+		CompatibilityLevel: "FULL_TRANSITIVE",
+		HasSchemas:         true,
+		Versions: []*SchemaVersion{
+			{Version: 1, SchemaID: 10, SchemaType: "AVRO", RegistrationOrder: 1},
+			{Version: 2, SchemaID: 14, SchemaType: "AVRO", RegistrationOrder: 2},
+			{Version: 3, SchemaID: 21, SchemaType: "JSON", RegistrationOrder: 3},
+		},
+	}, evolution.Value)
+}
@@ -0,0 +1,125 @@
+package owl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/cloudhut/kowl/backend/pkg/schema"
+	"go.uber.org/zap"
+)
+
+// SchemaReferencedBy lists all subject versions whose schemas reference a version of the given subject, so that the
+// impact of evolving a shared schema is known before it's changed.
+type SchemaReferencedBy struct {
+	Subject string `json:"subject"`
+	// IsReferencesSupported is false if the schema registry doesn't support schema references (e.g. prior to
+	// Confluent Platform 5.5), in which case Dependents is always empty.
+	IsReferencesSupported bool               `json:"isReferencesSupported"`
+	Dependents            []*SchemaDependent `json:"dependents"` // Sorted by subject and version
+}
+
+// SchemaDependent is a subject version whose schema references the shared subject
+type SchemaDependent struct {
+	Subject            string `json:"subject"`
+	Version            int    `json:"version"`
+	SchemaID           uint32 `json:"schemaId"`
+	ReferencedVersions []int  `json:"referencedVersions"` // Versions of the shared subject which are referenced
+	Error              string `json:"error,omitempty"`    // Set if the referencing schema ID couldn't be resolved
+}
+
+// GetSchemaReferencedBy returns all subject versions whose schemas reference any version of the given subject
+func (s *Service) GetSchemaReferencedBy(_ context.Context, subject string) (*SchemaReferencedBy, error) {
+	if s.kafkaSvc.SchemaService == nil {
+		return nil, fmt.Errorf("schema registry is not configured")
+	}
+
+	referencedBy, err := s.kafkaSvc.SchemaService.GetSubjectReferencedBy(subject)
+	if err != nil {
+		if errors.Is(err, schema.ErrReferencesNotSupported) {
+			return &SchemaReferencedBy{Subject: subject, IsReferencesSupported: false, Dependents: []*SchemaDependent{}}, nil
+		}
+		return nil, fmt.Errorf("failed to get schema references: %w", err)
+	}
+
+	versionsByID := make(map[uint32][]*schema.SubjectVersion)
+	errorsByID := make(map[uint32]string)
+	for _, ids := range referencedBy {
+		for _, id := range ids {
+			if _, exists := versionsByID[id]; exists {
+				continue
+			}
+			versions, err := s.kafkaSvc.SchemaService.GetSubjectVersionsBySchemaID(id)
+			if err != nil {
+				s.logger.Debug("failed to get subject versions for schema id", zap.Uint32("schema_id", id), zap.Error(err))
+				errorsByID[id] = err.Error()
+				versions = make([]*schema.SubjectVersion, 0)
+			}
+			versionsByID[id] = versions
+		}
+	}
+
+	return &SchemaReferencedBy{
+		Subject:               subject,
+		IsReferencesSupported: true,
+		Dependents:            newSchemaDependents(subject, referencedBy, versionsByID, errorsByID),
+	}, nil
+}
+
+// newSchemaDependents resolves the referencing schema IDs into the subject versions they are registered with. The
+// shared subject itself is skipped, and IDs which couldn't be resolved are reported with their error.
+func newSchemaDependents(subject string, referencedBy map[int][]uint32, versionsByID map[uint32][]*schema.SubjectVersion, errorsByID map[uint32]string) []*SchemaDependent {
+	type dependentKey struct {
+		Subject  string
+		Version  int
+		SchemaID uint32
+	}
+	dependents := make(map[dependentKey]*SchemaDependent)
+	addDependent := func(key dependentKey, referencedVersion int) {
+		dependent, exists := dependents[key]
+		if !exists {
+			dependent = &SchemaDependent{
+				Subject:            key.Subject,
+				Version:            key.Version,
+				SchemaID:           key.SchemaID,
+				ReferencedVersions: make([]int, 0),
+				Error:              errorsByID[key.SchemaID],
+			}
+			dependents[key] = dependent
+		}
+		dependent.ReferencedVersions = append(dependent.ReferencedVersions, referencedVersion)
+	}
+
+	for referencedVersion, ids := range referencedBy {
+		for _, id := range ids {
+			if _, failed := errorsByID[id]; failed {
+				addDependent(dependentKey{SchemaID: id}, referencedVersion)
+				continue
+			}
+			for _, version := range versionsByID[id] {
+				if version.Subject == subject {
+					continue
+				}
+				addDependent(dependentKey{Subject: version.Subject, Version: version.Version, SchemaID: id}, referencedVersion)
+			}
+		}
+	}
+
+	res := make([]*SchemaDependent, 0, len(dependents))
+	for _, dependent := range dependents {
+		sort.Ints(dependent.ReferencedVersions)
+		res = append(res, dependent)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Subject == res[j].Subject {
+			if res[i].Version == res[j].Version {
+				return res[i].SchemaID < res[j].SchemaID
+			}
+			return res[i].Version < res[j].Version
+		}
+		return res[i].Subject < res[j].Subject
+	})
+
+	return res
+}
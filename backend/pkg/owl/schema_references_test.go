@@ -0,0 +1,32 @@
+package owl
+
+import (
+	"testing"
+
+	"github.com/cloudhut/kowl/backend/pkg/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewSchemaDependents(t *testing.T) {
+	// Versions 1 and 2 of the shared "common-address" subject are referenced by two dependent subjects
+	referencedBy := map[int][]uint32{
+		1: {11},
+		2: {12, 21},
+	}
+	versionsByID := map[uint32][]*schema.SubjectVersion{
+		11: {{Subject: "orders-value", Version: 1}},
+		12: {{Subject: "orders-value", Version: 2}},
+		21: {{Subject: "customers-value", Version: 3}, {Subject: "common-address", Version: 9}},
+	}
+
+	dependents := newSchemaDependents("common-address", referencedBy, versionsByID, nil)
+	assert.Equal(t, []*SchemaDependent{
+		{Subject: "customers-value", Version: 3, SchemaID: 21, ReferencedVersions: []int{2}},
+		{Subject: "orders-value", Version: 1, SchemaID: 11, ReferencedVersions: []int{1}},
+		{Subject: "orders-value", Version: 2, SchemaID: 12, ReferencedVersions: []int{2}},
+	}, dependents)
+
+	// Schema IDs which couldn't be resolved are still reported
+	dependents = newSchemaDependents("common-address", map[int][]uint32{1: {30}}, map[uint32][]*schema.SubjectVersion{}, map[uint32]string{30: "timeout"})
+	assert.Equal(t, []*SchemaDependent{{SchemaID: 30, ReferencedVersions: []int{1}, Error: "timeout"}}, dependents)
+}
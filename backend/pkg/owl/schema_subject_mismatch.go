@@ -0,0 +1,120 @@
+package owl
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/cloudhut/kowl/backend/pkg/kafka"
+	"go.uber.org/zap"
+)
+
+// SchemaSubjectReport lists all schema IDs of a topic's message sample whose subjects do not match the subject
+// names that are expected with the TopicNameStrategy (<topic>-key and <topic>-value).
+type SchemaSubjectReport struct {
+	TopicName  string                   `json:"topicName"`
+	SampleSize int                      `json:"sampleSize"`
+	Mismatches []*SchemaSubjectMismatch `json:"mismatches"`
+}
+
+// SchemaSubjectMismatch is a schema ID which is not registered under the expected subject
+type SchemaSubjectMismatch struct {
+	SchemaID        uint32   `json:"schemaId"`
+	IsKey           bool     `json:"isKey"`
+	ExpectedSubject string   `json:"expectedSubject"`
+	ActualSubjects  []string `json:"actualSubjects"` // Empty if the schema ID couldn't be resolved
+	MessageCount    int      `json:"messageCount"`   // Number of sampled messages referencing this schema ID
+	Error           string   `json:"error,omitempty"`
+}
+
+type schemaReference struct {
+	SchemaID uint32
+	IsKey    bool
+}
+
+// CheckSchemaSubjects samples recent messages of a topic and reports all schema IDs which have not been
+// registered with the subject that is expected for the topic. Such IDs usually indicate a producer that has
+// been configured with the wrong schema or serializer.
+func (s *Service) CheckSchemaSubjects(ctx context.Context, topicName string, sampleSize uint16) (*SchemaSubjectReport, error) {
+	if s.kafkaSvc.SchemaService == nil {
+		return nil, fmt.Errorf("schema registry is not configured")
+	}
+
+	messages, err := s.sampleRecentMessages(ctx, topicName, sampleSize)
+	if err != nil {
+		return nil, err
+	}
+
+	subjectsByID := make(map[uint32][]string)
+	errorsByID := make(map[uint32]string)
+	for ref := range countSchemaReferences(messages) {
+		if _, exists := subjectsByID[ref.SchemaID]; exists {
+			continue
+		}
+		subjects, err := s.kafkaSvc.SchemaService.GetSubjectsBySchemaID(ref.SchemaID)
+		if err != nil {
+			s.logger.Debug("failed to get subjects for schema id", zap.Uint32("schema_id", ref.SchemaID), zap.Error(err))
+			errorsByID[ref.SchemaID] = err.Error()
+			subjects = make([]string, 0)
+		}
+		subjectsByID[ref.SchemaID] = subjects
+	}
+
+	return &SchemaSubjectReport{
+		TopicName:  topicName,
+		SampleSize: len(messages),
+		Mismatches: findSchemaSubjectMismatches(topicName, messages, subjectsByID, errorsByID),
+	}, nil
+}
+
+func countSchemaReferences(messages []*kafka.TopicMessage) map[schemaReference]int {
+	refs := make(map[schemaReference]int)
+	for _, msg := range messages {
+		if msg.KeySchema != nil {
+			refs[schemaReference{SchemaID: msg.KeySchema.ID, IsKey: true}]++
+		}
+		if msg.ValueSchema != nil {
+			refs[schemaReference{SchemaID: msg.ValueSchema.ID, IsKey: false}]++
+		}
+	}
+	return refs
+}
+
+func findSchemaSubjectMismatches(topicName string, messages []*kafka.TopicMessage, subjectsByID map[uint32][]string, errorsByID map[uint32]string) []*SchemaSubjectMismatch {
+	mismatches := make([]*SchemaSubjectMismatch, 0)
+	for ref, count := range countSchemaReferences(messages) {
+		expectedSubject := topicName + "-value"
+		if ref.IsKey {
+			expectedSubject = topicName + "-key"
+		}
+
+		subjects := subjectsByID[ref.SchemaID]
+		isExpected := false
+		for _, subject := range subjects {
+			if subject == expectedSubject {
+				isExpected = true
+				break
+			}
+		}
+		if isExpected {
+			continue
+		}
+
+		mismatches = append(mismatches, &SchemaSubjectMismatch{
+			SchemaID:        ref.SchemaID,
+			IsKey:           ref.IsKey,
+			ExpectedSubject: expectedSubject,
+			ActualSubjects:  subjects,
+			MessageCount:    count,
+			Error:           errorsByID[ref.SchemaID],
+		})
+	}
+
+	sort.Slice(mismatches, func(i, j int) bool {
+		if mismatches[i].SchemaID == mismatches[j].SchemaID {
+			return mismatches[i].IsKey
+		}
+		return mismatches[i].SchemaID < mismatches[j].SchemaID
+	})
+	return mismatches
+}
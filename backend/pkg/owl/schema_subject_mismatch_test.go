@@ -0,0 +1,26 @@
+package owl
+
+import (
+	"testing"
+
+	"github.com/cloudhut/kowl/backend/pkg/kafka"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindSchemaSubjectMismatches(t *testing.T) {
+	messages := []*kafka.TopicMessage{
+		{Offset: 1, ValueSchema: &kafka.SchemaInfo{ID: 10}},
+		{Offset: 2, ValueSchema: &kafka.SchemaInfo{ID: 10}},
+		{Offset: 3, ValueSchema: &kafka.SchemaInfo{ID: 21}},
+		{Offset: 4}, // Not serialized in the wire format
+	}
+	subjectsByID := map[uint32][]string{
+		10: {"orders-value"},
+		21: {"payments-value"},
+	}
+
+	mismatches := findSchemaSubjectMismatches("orders", messages, subjectsByID, nil)
+	assert.Equal(t, []*SchemaSubjectMismatch{
+		{SchemaID: 21, IsKey: false, ExpectedSubject: "orders-value", ActualSubjects: []string{"payments-value"}, MessageCount: 1},
+	}, mismatches)
+}
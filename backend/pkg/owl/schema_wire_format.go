@@ -0,0 +1,52 @@
+package owl
+
+import (
+	"fmt"
+
+	"github.com/cloudhut/kowl/backend/pkg/kafka"
+)
+
+// WireFormatConfig configures per topic wire formats in which schema registry IDs are embedded in the messages, for
+// topics whose wire format can't be detected reliably. The wire format of all other topics is detected.
+type WireFormatConfig struct {
+	Topics []TopicWireFormat `yaml:"topics"`
+}
+
+// TopicWireFormat is the wire format (confluent or apicurio) which is used for all keys and values of a topic
+type TopicWireFormat struct {
+	Topic  string `yaml:"topic"`
+	Format string `yaml:"format"`
+}
+
+// SetDefaults for the wire format config
+func (c *WireFormatConfig) SetDefaults() {
+	c.Topics = make([]TopicWireFormat, 0)
+}
+
+// Validate the wire format config
+func (c *WireFormatConfig) Validate() error {
+	seen := make(map[string]struct{}, len(c.Topics))
+	for i, topic := range c.Topics {
+		if topic.Topic == "" {
+			return fmt.Errorf("wire format at index %v has no topic", i)
+		}
+		if _, exists := seen[topic.Topic]; exists {
+			return fmt.Errorf("wire format for topic '%v' is configured more than once", topic.Topic)
+		}
+		seen[topic.Topic] = struct{}{}
+		if !kafka.IsValidWireFormat(topic.Format) {
+			return fmt.Errorf("wire format '%v' for topic '%v' is not supported", topic.Format, topic.Topic)
+		}
+	}
+
+	return nil
+}
+
+// formatsByTopic returns the configured wire formats keyed by topic name
+func (c *WireFormatConfig) formatsByTopic() map[string]string {
+	res := make(map[string]string, len(c.Topics))
+	for _, topic := range c.Topics {
+		res[topic.Topic] = topic.Format
+	}
+	return res
+}
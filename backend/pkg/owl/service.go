@@ -10,12 +10,72 @@ import (
 type Service struct {
 	kafkaSvc *kafka.Service
 	logger   *zap.Logger
+
+	groupStates    *groupStateTracker
+	consumeQuotas  *consumeQuotas
+	sessionFormats *sessionFormats
+	groupBaselines *groupBaselines
+	bookmarks      *messageBookmarks
+	lagRules       LagRulesConfig
+	healthScore    HealthScoreConfig
+	coercionRules  map[string][]*kafka.CoercionRule
+	brokerLimits   BrokerLimitsConfig
+	wireFormats    map[string]string
+	innerCodecs    map[string]string
+	processors     messageProcessors
+	decoders       []kafka.MessageDecoder
+
+	// clusterName is empty for the default cluster, additional clusters are named once they are added to the Clusters
+	clusterName string
+
+	// waterMarkCache is nil if the water mark cache is disabled
+	waterMarkCache *waterMarkCache
+
+	// lagSampler, lagHistory and leaderHistory are nil if the lag sampler is disabled
+	lagSampler    *lagSampler
+	lagHistory    *lagHistory
+	leaderHistory *leaderHistory
 }
 
-// NewService for the Owl package
-func NewService(kafkaSvc *kafka.Service, logger *zap.Logger) *Service {
-	return &Service{
-		kafkaSvc: kafkaSvc,
-		logger:   logger,
+// NewService for the Owl package. The given decoders are consulted, in order, to decode message values before the
+// built-in decoders, so that custom serialization formats can be supported.
+func NewService(cfg Config, kafkaSvc *kafka.Service, logger *zap.Logger, decoders ...kafka.MessageDecoder) *Service {
+	svc := &Service{
+		kafkaSvc:       kafkaSvc,
+		logger:         logger,
+		groupStates:    newGroupStateTracker(),
+		consumeQuotas:  newConsumeQuotas(cfg.ConsumeQuota),
+		sessionFormats: newSessionFormats(),
+		groupBaselines: newGroupBaselines(),
+		bookmarks:      newMessageBookmarks(),
+		lagRules:       cfg.LagRules,
+		healthScore:    cfg.HealthScore,
+		coercionRules:  cfg.JSONCoercion.rulesByTopic(),
+		brokerLimits:   cfg.BrokerLimits,
+		wireFormats:    cfg.WireFormat.formatsByTopic(),
+		innerCodecs:    cfg.InnerCompression.codecsByTopic(),
+		processors:     newMessageProcessors(cfg.MessageProcessing),
+		decoders:       decoders,
+	}
+
+	if cfg.WaterMarkCache.Enabled {
+		svc.waterMarkCache = newWaterMarkCache(cfg.WaterMarkCache)
 	}
+
+	if cfg.LagSampler.Enabled {
+		svc.lagHistory = newLagHistory(cfg.LagSampler.Retention)
+		svc.leaderHistory = newLeaderHistory(cfg.LagSampler.Retention)
+		svc.lagSampler = &lagSampler{
+			cfg:     cfg.LagSampler,
+			logger:  logger.With(zap.String("source", "lag_sampler")),
+			breaker: newSamplerBreaker(cfg.LagSampler.FailureThreshold),
+			history: svc.lagHistory,
+			sample:  svc.sampleConsumerGroupLags,
+
+			leaderHistory: svc.leaderHistory,
+			sampleLeaders: svc.samplePartitionLeaders,
+		}
+	}
+
+	return svc
 }
@@ -0,0 +1,130 @@
+package owl
+
+import (
+	"sync"
+	"time"
+
+	"github.com/cloudhut/kowl/backend/pkg/kafka"
+)
+
+// sessionFormatTTL is the idle duration after which a session's remembered formats are forgotten
+const sessionFormatTTL = 30 * time.Minute
+
+// maxSessionFormats caps the number of remembered session topics, the least recently used one is evicted once the
+// cap is reached, so that clients which create a new session for every request can't grow the map without bounds
+const maxSessionFormats = 10000
+
+// sessionFormats remembers the key and value formats which have been detected for a topic within a browse session,
+// so that subsequent pages don't have to detect the format for every message again and are rendered consistently.
+type sessionFormats struct {
+	mutex      sync.Mutex
+	formats    map[sessionTopic]*sessionFormat
+	maxEntries int
+}
+
+type sessionTopic struct {
+	sessionID string
+	topicName string
+}
+
+type sessionFormat struct {
+	keyFormat   string
+	valueFormat string
+	lastUsed    time.Time
+}
+
+func newSessionFormats() *sessionFormats {
+	return &sessionFormats{formats: make(map[sessionTopic]*sessionFormat), maxEntries: maxSessionFormats}
+}
+
+// resolve returns the key and value formats which shall be used for a request. Explicitly requested formats
+// always win and clear the remembered formats, so that the detection starts over once the override is removed.
+func (f *sessionFormats) resolve(sessionID string, topicName string, keyFormat string, valueFormat string, now time.Time) (string, string) {
+	if sessionID == "" {
+		return keyFormat, valueFormat
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.prune(now)
+
+	st := sessionTopic{sessionID: sessionID, topicName: topicName}
+	if keyFormat != kafka.MessageFormatAuto || valueFormat != kafka.MessageFormatAuto {
+		delete(f.formats, st)
+		return keyFormat, valueFormat
+	}
+
+	remembered, exists := f.formats[st]
+	if !exists {
+		return kafka.MessageFormatAuto, kafka.MessageFormatAuto
+	}
+	remembered.lastUsed = now
+	return remembered.keyFormat, remembered.valueFormat
+}
+
+// remember stores the detected formats of a consumed message. Messages which don't match the remembered formats are
+// detected again, the latest detection replaces the remembered format. Empty formats (e.g. null values) are ignored.
+// Formats which would never be detected again (binary) and values produced by a custom decoder are remembered as
+// auto, because forcing them on subsequent pages would render other messages wrongly or skip the custom decoders.
+func (f *sessionFormats) remember(sessionID string, topicName string, msg *kafka.TopicMessage, now time.Time) {
+	keyFormat, isKeyKnown := rememberedFormat(msg.KeyType, false)
+	valueFormat, isValueKnown := rememberedFormat(msg.ValueType, msg.ValueEncoding != "")
+	if sessionID == "" || (!isKeyKnown && !isValueKnown) {
+		return
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	st := sessionTopic{sessionID: sessionID, topicName: topicName}
+	remembered, exists := f.formats[st]
+	if !exists {
+		if len(f.formats) >= f.maxEntries {
+			f.prune(now)
+		}
+		if len(f.formats) >= f.maxEntries {
+			f.evictLeastRecentlyUsed()
+		}
+		remembered = &sessionFormat{}
+		f.formats[st] = remembered
+	}
+	if isKeyKnown {
+		remembered.keyFormat = keyFormat
+	}
+	if isValueKnown {
+		remembered.valueFormat = valueFormat
+	}
+	remembered.lastUsed = now
+}
+
+// rememberedFormat returns the format which shall be remembered for a reported value type and false if the type is
+// unknown (e.g. null values) and the remembered format shall be kept.
+func rememberedFormat(vType string, isCustomDecoded bool) (string, bool) {
+	if vType == "" {
+		return "", false
+	}
+	if isCustomDecoded || !kafka.IsRedetectedMessageFormat(vType) {
+		return kafka.MessageFormatAuto, true
+	}
+	return vType, true
+}
+
+func (f *sessionFormats) evictLeastRecentlyUsed() {
+	var oldest sessionTopic
+	var oldestUse time.Time
+	isFirst := true
+	for st, format := range f.formats {
+		if isFirst || format.lastUsed.Before(oldestUse) {
+			oldest, oldestUse, isFirst = st, format.lastUsed, false
+		}
+	}
+	delete(f.formats, oldest)
+}
+
+func (f *sessionFormats) prune(now time.Time) {
+	for st, format := range f.formats {
+		if now.Sub(format.lastUsed) > sessionFormatTTL {
+			delete(f.formats, st)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package owl
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cloudhut/kowl/backend/pkg/kafka"
+)
+
+func TestSessionFormats(t *testing.T) {
+	f := newSessionFormats()
+	now := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	// First page: formats are detected and remembered
+	keyFormat, valueFormat := f.resolve("session-1", "orders", "", "", now)
+	assert.Equal(t, "", keyFormat)
+	assert.Equal(t, "", valueFormat)
+	f.remember("session-1", "orders", &kafka.TopicMessage{KeyType: "text", ValueType: "json"}, now)
+
+	// Second page reuses the detected formats
+	keyFormat, valueFormat = f.resolve("session-1", "orders", "", "", now.Add(time.Minute))
+	assert.Equal(t, "text", keyFormat)
+	assert.Equal(t, "json", valueFormat)
+
+	// A message which doesn't match the remembered format is detected again and replaces it
+	f.remember("session-1", "orders", &kafka.TopicMessage{ValueType: "text"}, now.Add(time.Minute))
+	keyFormat, valueFormat = f.resolve("session-1", "orders", "", "", now.Add(time.Minute))
+	assert.Equal(t, "text", keyFormat)
+	assert.Equal(t, "text", valueFormat)
+
+	// Other sessions and topics are not affected
+	_, valueFormat = f.resolve("session-2", "orders", "", "", now)
+	assert.Equal(t, "", valueFormat)
+
+	// An explicit override wins and clears the remembered formats
+	_, valueFormat = f.resolve("session-1", "orders", "", "binary", now.Add(2*time.Minute))
+	assert.Equal(t, "binary", valueFormat)
+	_, valueFormat = f.resolve("session-1", "orders", "", "", now.Add(3*time.Minute))
+	assert.Equal(t, "", valueFormat)
+}
+
+func TestSessionFormats_EvictsLeastRecentlyUsed(t *testing.T) {
+	f := newSessionFormats()
+	f.maxEntries = 2
+	now := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	f.remember("session-1", "orders", &kafka.TopicMessage{KeyType: "text", ValueType: "json"}, now)
+	f.remember("session-2", "orders", &kafka.TopicMessage{KeyType: "text", ValueType: "json"}, now.Add(time.Second))
+	f.resolve("session-1", "orders", "", "", now.Add(2*time.Second))
+	f.remember("session-3", "orders", &kafka.TopicMessage{KeyType: "text", ValueType: "json"}, now.Add(3*time.Second))
+
+	assert.Len(t, f.formats, 2)
+	_, valueFormat := f.resolve("session-2", "orders", "", "", now.Add(4*time.Second))
+	assert.Equal(t, "", valueFormat)
+	_, valueFormat = f.resolve("session-1", "orders", "", "", now.Add(4*time.Second))
+	assert.Equal(t, "json", valueFormat)
+}
+
+func TestSessionFormats_RemembersBinaryAsAuto(t *testing.T) {
+	f := newSessionFormats()
+	now := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	f.remember("session-1", "orders", &kafka.TopicMessage{KeyType: "text", ValueType: "json"}, now)
+	f.remember("session-1", "orders", &kafka.TopicMessage{KeyType: "binary", ValueType: "binary"}, now)
+
+	// Binary would never fall back to the detection, therefore the following messages are detected again
+	keyFormat, valueFormat := f.resolve("session-1", "orders", "", "", now.Add(time.Minute))
+	assert.Equal(t, kafka.MessageFormatAuto, keyFormat)
+	assert.Equal(t, kafka.MessageFormatAuto, valueFormat)
+
+	// Null values keep the remembered formats
+	f.remember("session-1", "orders", &kafka.TopicMessage{KeyType: "text", ValueType: "json"}, now)
+	f.remember("session-1", "orders", &kafka.TopicMessage{KeyType: "text", ValueType: ""}, now)
+	_, valueFormat = f.resolve("session-1", "orders", "", "", now.Add(time.Minute))
+	assert.Equal(t, "json", valueFormat)
+}
+
+func TestSessionFormats_RemembersCustomDecodedValuesAsAuto(t *testing.T) {
+	f := newSessionFormats()
+	now := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	// Custom decoders only run for auto detected values, remembering the reported json format would skip them
+	f.remember("session-1", "orders", &kafka.TopicMessage{KeyType: "text", ValueType: "json", ValueEncoding: "protobuf"}, now)
+	keyFormat, valueFormat := f.resolve("session-1", "orders", "", "", now.Add(time.Minute))
+	assert.Equal(t, "text", keyFormat)
+	assert.Equal(t, kafka.MessageFormatAuto, valueFormat)
+}
@@ -0,0 +1,117 @@
+package owl
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/cloudhut/kowl/backend/pkg/kafka"
+)
+
+const (
+	ClockSkewDirectionAhead  = "ahead"
+	ClockSkewDirectionBehind = "behind"
+
+	defaultClockSkewThreshold = time.Minute
+)
+
+// TopicClockSkew reports partitions whose newest producer assigned timestamps deviate from the current time
+type TopicClockSkew struct {
+	TopicName     string                `json:"topicName"`
+	TimestampType string                `json:"timestampType"`
+	ThresholdMs   int64                 `json:"thresholdMs"`
+	HasSkew       bool                  `json:"hasSkew"` // True if at least one partition has been flagged
+	Partitions    []*PartitionClockSkew `json:"partitions"`
+}
+
+// PartitionClockSkew compares the timestamp of the newest record in the partition with the current time.
+// Timestamps ahead of the current time are a clear sign of a producer clock skew and get flagged. Timestamps
+// behind are reported, but not flagged, because they can't be told apart from partitions that are not written to.
+type PartitionClockSkew struct {
+	PartitionID     int32     `json:"partitionId"`
+	NewestTimestamp time.Time `json:"newestTimestamp"`
+	SkewMs          int64     `json:"skewMs"`    // Positive if the producer's clock is ahead
+	Direction       string    `json:"direction"` // Only set if the skew exceeds the threshold
+	IsFlagged       bool      `json:"isFlagged"`
+}
+
+// DetectTopicClockSkew compares the newest record timestamp of each partition against the current time in order
+// to detect producer clock skews, which would otherwise be misdiagnosed as (negative) time lag. Topics using
+// LogAppendTime are not affected, because their timestamps are assigned by the brokers.
+func (s *Service) DetectTopicClockSkew(ctx context.Context, topicName string, threshold time.Duration) (*TopicClockSkew, error) {
+	if threshold <= 0 {
+		threshold = defaultClockSkewThreshold
+	}
+
+	timestampType, err := s.getTopicTimestampType(topicName)
+	if err != nil {
+		return nil, err
+	}
+	if timestampType == kafka.TimestampTypeLogAppendTime {
+		return &TopicClockSkew{
+			TopicName:     topicName,
+			TimestampType: timestampType,
+			ThresholdMs:   threshold.Milliseconds(),
+			Partitions:    make([]*PartitionClockSkew, 0),
+		}, nil
+	}
+
+	partitionIDs, err := s.kafkaSvc.Client.Partitions(topicName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get partitions: %w", err)
+	}
+	waterMarks, err := s.kafkaSvc.WaterMarks(topicName, partitionIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get watermarks: %w", err)
+	}
+
+	newestTimestamps := make(map[int32]time.Time, len(waterMarks))
+	for partitionID, waterMark := range waterMarks {
+		if waterMark.High <= waterMark.Low {
+			continue
+		}
+		ts, exists, err := s.kafkaSvc.FetchRecordTimestamp(ctx, topicName, partitionID, waterMark.High-1)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch newest record of partition %v: %w", partitionID, err)
+		}
+		if exists {
+			newestTimestamps[partitionID] = ts
+		}
+	}
+
+	report := evaluateClockSkew(newestTimestamps, time.Now(), threshold)
+	report.TopicName = topicName
+	report.TimestampType = timestampType
+	return report, nil
+}
+
+func evaluateClockSkew(newestTimestamps map[int32]time.Time, now time.Time, threshold time.Duration) *TopicClockSkew {
+	report := &TopicClockSkew{
+		ThresholdMs: threshold.Milliseconds(),
+		Partitions:  make([]*PartitionClockSkew, 0, len(newestTimestamps)),
+	}
+
+	for partitionID, ts := range newestTimestamps {
+		skew := ts.Sub(now)
+		p := &PartitionClockSkew{
+			PartitionID:     partitionID,
+			NewestTimestamp: ts,
+			SkewMs:          skew.Milliseconds(),
+		}
+		switch {
+		case skew > threshold:
+			p.Direction = ClockSkewDirectionAhead
+			p.IsFlagged = true
+			report.HasSkew = true
+		case skew < -threshold:
+			p.Direction = ClockSkewDirectionBehind
+		}
+		report.Partitions = append(report.Partitions, p)
+	}
+	sort.Slice(report.Partitions, func(i, j int) bool {
+		return report.Partitions[i].PartitionID < report.Partitions[j].PartitionID
+	})
+
+	return report
+}
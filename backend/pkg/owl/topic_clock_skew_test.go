@@ -0,0 +1,32 @@
+package owl
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvaluateClockSkew(t *testing.T) {
+	now := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+	timestamps := map[int32]time.Time{
+		0: now.Add(-2 * time.Second),
+		1: now.Add(2 * time.Hour), // Producer clock is ahead
+		2: now.Add(-3 * time.Hour),
+	}
+
+	report := evaluateClockSkew(timestamps, now, time.Minute)
+	require.Len(t, report.Partitions, 3)
+	assert.True(t, report.HasSkew)
+
+	assert.False(t, report.Partitions[0].IsFlagged)
+	assert.Empty(t, report.Partitions[0].Direction)
+
+	assert.True(t, report.Partitions[1].IsFlagged)
+	assert.Equal(t, ClockSkewDirectionAhead, report.Partitions[1].Direction)
+	assert.Equal(t, (2 * time.Hour).Milliseconds(), report.Partitions[1].SkewMs)
+
+	assert.False(t, report.Partitions[2].IsFlagged)
+	assert.Equal(t, ClockSkewDirectionBehind, report.Partitions[2].Direction)
+}
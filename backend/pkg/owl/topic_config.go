@@ -10,9 +10,10 @@ type TopicConfigs struct {
 
 // TopicConfigEntry is a key value pair of a config property with it's value
 type TopicConfigEntry struct {
-	Name      string `json:"name"`
-	Value     string `json:"value"`
-	IsDefault bool   `json:"isDefault"`
+	Name       string `json:"name"`
+	Value      string `json:"value"`
+	IsDefault  bool   `json:"isDefault"`
+	IsReadOnly bool   `json:"isReadOnly"`
 }
 
 // GetConfigEntryByName returns the TopicConfigEntry for a given config name (e. g. "cleanup.policy") or nil if
@@ -56,9 +57,10 @@ func (s *Service) GetTopicsConfigs(topicNames []string, configNames []string) (m
 		entries := make([]*TopicConfigEntry, len(res.Configs))
 		for j, cfg := range res.Configs {
 			entries[j] = &TopicConfigEntry{
-				Name:      cfg.Name,
-				Value:     cfg.Value,
-				IsDefault: cfg.Default,
+				Name:       cfg.Name,
+				Value:      cfg.Value,
+				IsDefault:  cfg.Default,
+				IsReadOnly: cfg.ReadOnly,
 			}
 		}
 
@@ -0,0 +1,131 @@
+package owl
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/Shopify/sarama"
+)
+
+// describeConfigsChunkSize is the maximum number of topics described with a single DescribeConfigs request, so that
+// requests for large clusters don't exceed the broker's request size limits
+const describeConfigsChunkSize = 100
+
+// TopicConfigOverrides are all config entries of a topic which have been set explicitly and differ from the default
+type TopicConfigOverrides struct {
+	TopicName string            `json:"topicName"`
+	Overrides map[string]string `json:"overrides"`
+	Error     string            `json:"error,omitempty"` // Set if the topic's configs could not be described
+}
+
+// DescribeAllTopicConfigs returns the config overrides of all topics in the cluster. The topics are described in
+// chunks, errors of single topics are returned as part of their result rather than failing the whole audit.
+func (s *Service) DescribeAllTopicConfigs(_ context.Context) ([]*TopicConfigOverrides, error) {
+	configs, err := s.describeAllTopicConfigs()
+	if err != nil {
+		return nil, err
+	}
+
+	return topicConfigOverrides(configs), nil
+}
+
+// describeAllTopicConfigs returns all config entries (including the defaults) of all topics in the cluster
+func (s *Service) describeAllTopicConfigs() ([]*describedTopicConfigs, error) {
+	topics, err := s.kafkaSvc.ListTopics()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list topics: %w", err)
+	}
+
+	topicNames := make([]string, 0, len(topics))
+	for _, topic := range topics {
+		if topic.Err != sarama.ErrNoError {
+			continue
+		}
+		topicNames = append(topicNames, topic.Name)
+	}
+
+	return describeTopicConfigsChunked(topicNames, describeConfigsChunkSize, func(names []string) (*sarama.DescribeConfigsResponse, error) {
+		return s.kafkaSvc.DescribeTopicsConfigs(names, []string{})
+	})
+}
+
+// FindTopicsByConfig returns the names of all topics which have overridden the given config with the given value,
+// e.g. all topics with cleanup.policy=compact.
+func FindTopicsByConfig(overrides []*TopicConfigOverrides, configName string, value string) []string {
+	res := make([]string, 0)
+	for _, topic := range overrides {
+		if v, exists := topic.Overrides[configName]; exists && v == value {
+			res = append(res, topic.TopicName)
+		}
+	}
+	return res
+}
+
+// describedTopicConfigs are the described config entries of a topic, or the error why they couldn't be described
+type describedTopicConfigs struct {
+	Configs *TopicConfigs
+	Error   string
+}
+
+func describeTopicConfigsChunked(topicNames []string, chunkSize int, describe func(topicNames []string) (*sarama.DescribeConfigsResponse, error)) ([]*describedTopicConfigs, error) {
+	res := make([]*describedTopicConfigs, 0, len(topicNames))
+	for start := 0; start < len(topicNames); start += chunkSize {
+		end := start + chunkSize
+		if end > len(topicNames) {
+			end = len(topicNames)
+		}
+
+		response, err := describe(topicNames[start:end])
+		if err != nil {
+			return nil, fmt.Errorf("failed to describe topic configs: %w", err)
+		}
+
+		for _, resource := range response.Resources {
+			topic := &describedTopicConfigs{Configs: &TopicConfigs{TopicName: resource.Name}}
+			if resource.ErrorMsg != "" || resource.ErrorCode != 0 {
+				topic.Error = resource.ErrorMsg
+				if topic.Error == "" {
+					topic.Error = sarama.KError(resource.ErrorCode).Error()
+				}
+				res = append(res, topic)
+				continue
+			}
+
+			topic.Configs.ConfigEntries = make([]*TopicConfigEntry, len(resource.Configs))
+			for i, entry := range resource.Configs {
+				topic.Configs.ConfigEntries[i] = &TopicConfigEntry{
+					Name:       entry.Name,
+					Value:      entry.Value,
+					IsDefault:  entry.Default,
+					IsReadOnly: entry.ReadOnly,
+				}
+			}
+			res = append(res, topic)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Configs.TopicName < res[j].Configs.TopicName })
+
+	return res, nil
+}
+
+// topicConfigOverrides reduces the described configs to the entries which have been set explicitly
+func topicConfigOverrides(configs []*describedTopicConfigs) []*TopicConfigOverrides {
+	res := make([]*TopicConfigOverrides, len(configs))
+	for i, topic := range configs {
+		overrides := &TopicConfigOverrides{
+			TopicName: topic.Configs.TopicName,
+			Overrides: make(map[string]string),
+			Error:     topic.Error,
+		}
+		for _, entry := range topic.Configs.ConfigEntries {
+			if entry.IsDefault || entry.IsReadOnly {
+				continue
+			}
+			overrides.Overrides[entry.Name] = entry.Value
+		}
+		res[i] = overrides
+	}
+
+	return res
+}
@@ -0,0 +1,73 @@
+package owl
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/Shopify/sarama"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDescribeTopicConfigsChunked(t *testing.T) {
+	topicNames := make([]string, 250)
+	for i := range topicNames {
+		topicNames[i] = fmt.Sprintf("topic-%03d", i)
+	}
+
+	chunkSizes := make([]int, 0)
+	describe := func(names []string) (*sarama.DescribeConfigsResponse, error) {
+		chunkSizes = append(chunkSizes, len(names))
+		res := &sarama.DescribeConfigsResponse{}
+		for _, name := range names {
+			switch name {
+			case "topic-007":
+				res.Resources = append(res.Resources, &sarama.ResourceResponse{
+					Name: name,
+					Configs: []*sarama.ConfigEntry{
+						{Name: "cleanup.policy", Value: "compact"},
+						{Name: "retention.ms", Value: "604800000", Default: true},
+						{Name: "message.format.version", Value: "2.4", ReadOnly: true},
+					},
+				})
+			case "topic-123":
+				res.Resources = append(res.Resources, &sarama.ResourceResponse{
+					Name:    name,
+					Configs: []*sarama.ConfigEntry{{Name: "cleanup.policy", Value: "compact"}, {Name: "retention.ms", Value: "1000"}},
+				})
+			case "topic-200":
+				res.Resources = append(res.Resources, &sarama.ResourceResponse{
+					Name:      name,
+					ErrorCode: int16(sarama.ErrTopicAuthorizationFailed),
+				})
+			default:
+				res.Resources = append(res.Resources, &sarama.ResourceResponse{
+					Name:    name,
+					Configs: []*sarama.ConfigEntry{{Name: "cleanup.policy", Value: "delete", Default: true}},
+				})
+			}
+		}
+		return res, nil
+	}
+
+	configs, err := describeTopicConfigsChunked(topicNames, 100, describe)
+	require.NoError(t, err)
+	overrides := topicConfigOverrides(configs)
+	assert.Equal(t, []int{100, 100, 50}, chunkSizes)
+	require.Len(t, overrides, 250)
+
+	assert.Equal(t, map[string]string{"cleanup.policy": "compact"}, overrides[7].Overrides)
+	assert.Equal(t, map[string]string{"cleanup.policy": "compact", "retention.ms": "1000"}, overrides[123].Overrides)
+	assert.Empty(t, overrides[0].Overrides)
+	assert.Equal(t, sarama.ErrTopicAuthorizationFailed.Error(), overrides[200].Error)
+
+	assert.Equal(t, []string{"topic-007", "topic-123"}, FindTopicsByConfig(overrides, "cleanup.policy", "compact"))
+}
+
+func TestDescribeTopicConfigsChunked_RequestError(t *testing.T) {
+	describe := func(names []string) (*sarama.DescribeConfigsResponse, error) {
+		return nil, fmt.Errorf("broker not available")
+	}
+	_, err := describeTopicConfigsChunked([]string{"orders"}, 100, describe)
+	assert.Error(t, err)
+}
@@ -0,0 +1,169 @@
+package owl
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// ConfigPolicyOperator defines how a topic's config value is compared against the rule's value
+type ConfigPolicyOperator string
+
+const (
+	ConfigPolicyOperatorEquals       ConfigPolicyOperator = "eq"
+	ConfigPolicyOperatorForbidden    ConfigPolicyOperator = "neq"
+	ConfigPolicyOperatorGreaterEqual ConfigPolicyOperator = "gte"
+	ConfigPolicyOperatorGreater      ConfigPolicyOperator = "gt"
+	ConfigPolicyOperatorLessEqual    ConfigPolicyOperator = "lte"
+	ConfigPolicyOperatorLess         ConfigPolicyOperator = "lt"
+)
+
+// ConfigPolicy is a governance baseline all topics are expected to conform to
+type ConfigPolicy struct {
+	Rules []*ConfigPolicyRule `json:"rules"`
+}
+
+// ConfigPolicyRule is a single requirement of a ConfigPolicy, e.g. min.insync.replicas gte 2. Numeric operators
+// (gte, gt, lte, lt) require both the rule value and the topic's config value to be numbers.
+type ConfigPolicyRule struct {
+	ConfigName string               `json:"configName"`
+	Operator   ConfigPolicyOperator `json:"operator"`
+	Value      string               `json:"value"`
+}
+
+// ConfigPolicyViolation is a rule which is not satisfied by a topic's effective config value
+type ConfigPolicyViolation struct {
+	Rule        *ConfigPolicyRule `json:"rule"`
+	ActualValue string            `json:"actualValue"`
+	Reason      string            `json:"reason"`
+}
+
+// TopicPolicyViolations are all violated rules of a single topic
+type TopicPolicyViolations struct {
+	TopicName  string                   `json:"topicName"`
+	Violations []*ConfigPolicyViolation `json:"violations"`
+	Error      string                   `json:"error,omitempty"` // Set if the topic's configs could not be described
+}
+
+// Validate checks that all rules have a config name, a known operator and numeric values for numeric comparisons
+func (p *ConfigPolicy) Validate() error {
+	if len(p.Rules) == 0 {
+		return fmt.Errorf("policy must contain at least one rule")
+	}
+
+	for i, rule := range p.Rules {
+		if rule.ConfigName == "" {
+			return fmt.Errorf("rule %d: config name must be set", i)
+		}
+		switch rule.Operator {
+		case ConfigPolicyOperatorEquals, ConfigPolicyOperatorForbidden:
+		case ConfigPolicyOperatorGreaterEqual, ConfigPolicyOperatorGreater, ConfigPolicyOperatorLessEqual, ConfigPolicyOperatorLess:
+			if _, err := strconv.ParseFloat(rule.Value, 64); err != nil {
+				return fmt.Errorf("rule %d: operator '%v' requires a numeric value, got '%v'", i, rule.Operator, rule.Value)
+			}
+		default:
+			return fmt.Errorf("rule %d: unknown operator '%v'", i, rule.Operator)
+		}
+	}
+
+	return nil
+}
+
+// FindTopicConfigViolations evaluates the policy against the effective config values (including defaults) of all
+// topics and returns the topics which violate at least one rule or whose configs could not be described.
+func (s *Service) FindTopicConfigViolations(_ context.Context, policy *ConfigPolicy) ([]*TopicPolicyViolations, error) {
+	if err := policy.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config policy: %w", err)
+	}
+
+	configs, err := s.describeAllTopicConfigs()
+	if err != nil {
+		return nil, err
+	}
+
+	return evaluateConfigPolicy(configs, policy), nil
+}
+
+func evaluateConfigPolicy(configs []*describedTopicConfigs, policy *ConfigPolicy) []*TopicPolicyViolations {
+	res := make([]*TopicPolicyViolations, 0)
+	for _, topic := range configs {
+		if topic.Error != "" {
+			res = append(res, &TopicPolicyViolations{
+				TopicName:  topic.Configs.TopicName,
+				Violations: []*ConfigPolicyViolation{},
+				Error:      topic.Error,
+			})
+			continue
+		}
+
+		violations := make([]*ConfigPolicyViolation, 0)
+		for _, rule := range policy.Rules {
+			if v := evaluateConfigPolicyRule(topic.Configs, rule); v != nil {
+				violations = append(violations, v)
+			}
+		}
+		if len(violations) == 0 {
+			continue
+		}
+		sort.SliceStable(violations, func(i, j int) bool {
+			return violations[i].Rule.ConfigName < violations[j].Rule.ConfigName
+		})
+		res = append(res, &TopicPolicyViolations{TopicName: topic.Configs.TopicName, Violations: violations})
+	}
+
+	return res
+}
+
+// evaluateConfigPolicyRule returns a violation if the topic's config value does not satisfy the rule or nil otherwise
+func evaluateConfigPolicyRule(configs *TopicConfigs, rule *ConfigPolicyRule) *ConfigPolicyViolation {
+	entry := configs.GetConfigEntryByName(rule.ConfigName)
+	if entry == nil {
+		if rule.Operator == ConfigPolicyOperatorForbidden {
+			return nil
+		}
+		return &ConfigPolicyViolation{Rule: rule, Reason: "config is not set"}
+	}
+
+	violation := &ConfigPolicyViolation{Rule: rule, ActualValue: entry.Value}
+	switch rule.Operator {
+	case ConfigPolicyOperatorEquals:
+		if entry.Value == rule.Value {
+			return nil
+		}
+		violation.Reason = fmt.Sprintf("value must be '%v'", rule.Value)
+		return violation
+	case ConfigPolicyOperatorForbidden:
+		if entry.Value != rule.Value {
+			return nil
+		}
+		violation.Reason = fmt.Sprintf("value '%v' is forbidden", rule.Value)
+		return violation
+	}
+
+	actual, err := strconv.ParseFloat(entry.Value, 64)
+	if err != nil {
+		violation.Reason = "value is not numeric"
+		return violation
+	}
+	expected, _ := strconv.ParseFloat(rule.Value, 64)
+
+	var isSatisfied bool
+	var comparison string
+	switch rule.Operator {
+	case ConfigPolicyOperatorGreaterEqual:
+		isSatisfied, comparison = actual >= expected, ">="
+	case ConfigPolicyOperatorGreater:
+		isSatisfied, comparison = actual > expected, ">"
+	case ConfigPolicyOperatorLessEqual:
+		isSatisfied, comparison = actual <= expected, "<="
+	case ConfigPolicyOperatorLess:
+		isSatisfied, comparison = actual < expected, "<"
+	}
+	if isSatisfied {
+		return nil
+	}
+	violation.Reason = fmt.Sprintf("value must be %v %v", comparison, rule.Value)
+
+	return violation
+}
@@ -0,0 +1,54 @@
+package owl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newPolicyTestConfigs(topicName string, entries map[string]string) *describedTopicConfigs {
+	configs := &TopicConfigs{TopicName: topicName}
+	for name, value := range entries {
+		configs.ConfigEntries = append(configs.ConfigEntries, &TopicConfigEntry{Name: name, Value: value})
+	}
+	return &describedTopicConfigs{Configs: configs}
+}
+
+func TestEvaluateConfigPolicy(t *testing.T) {
+	policy := &ConfigPolicy{Rules: []*ConfigPolicyRule{
+		{ConfigName: "min.insync.replicas", Operator: ConfigPolicyOperatorGreaterEqual, Value: "2"},
+		{ConfigName: "unclean.leader.election.enable", Operator: ConfigPolicyOperatorForbidden, Value: "true"},
+	}}
+	require.NoError(t, policy.Validate())
+
+	configs := []*describedTopicConfigs{
+		newPolicyTestConfigs("billing", map[string]string{"min.insync.replicas": "2", "unclean.leader.election.enable": "false"}),
+		newPolicyTestConfigs("orders", map[string]string{"min.insync.replicas": "1", "unclean.leader.election.enable": "false"}),
+		newPolicyTestConfigs("payments", map[string]string{"min.insync.replicas": "3", "unclean.leader.election.enable": "true"}),
+		{Configs: &TopicConfigs{TopicName: "secret"}, Error: "TOPIC_AUTHORIZATION_FAILED"},
+	}
+
+	res := evaluateConfigPolicy(configs, policy)
+	require.Len(t, res, 3)
+
+	assert.Equal(t, "orders", res[0].TopicName)
+	require.Len(t, res[0].Violations, 1)
+	assert.Equal(t, policy.Rules[0], res[0].Violations[0].Rule)
+	assert.Equal(t, "1", res[0].Violations[0].ActualValue)
+	assert.Equal(t, "value must be >= 2", res[0].Violations[0].Reason)
+
+	assert.Equal(t, "payments", res[1].TopicName)
+	require.Len(t, res[1].Violations, 1)
+	assert.Equal(t, policy.Rules[1], res[1].Violations[0].Rule)
+
+	assert.Equal(t, "secret", res[2].TopicName)
+	assert.Equal(t, "TOPIC_AUTHORIZATION_FAILED", res[2].Error)
+}
+
+func TestConfigPolicy_Validate(t *testing.T) {
+	assert.Error(t, (&ConfigPolicy{}).Validate())
+	assert.Error(t, (&ConfigPolicy{Rules: []*ConfigPolicyRule{{ConfigName: "retention.ms", Operator: "gte", Value: "a week"}}}).Validate())
+	assert.Error(t, (&ConfigPolicy{Rules: []*ConfigPolicyRule{{ConfigName: "retention.ms", Operator: "~"}}}).Validate())
+	assert.NoError(t, (&ConfigPolicy{Rules: []*ConfigPolicyRule{{ConfigName: "cleanup.policy", Operator: "eq", Value: "compact"}}}).Validate())
+}
@@ -0,0 +1,86 @@
+package owl
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// TopicConsumerCoverage describes which partitions of a topic are consumed by at least one member of any group
+type TopicConsumerCoverage struct {
+	TopicName             string                       `json:"topicName"`
+	PartitionCount        int                          `json:"partitionCount"`
+	CoveredPartitionCount int                          `json:"coveredPartitionCount"`
+	Coverage              float64                      `json:"coverage"` // Fraction of the covered partitions (0-1)
+	UncoveredPartitionIDs []int32                      `json:"uncoveredPartitionIds"`
+	Partitions            []*PartitionConsumerCoverage `json:"partitions"`
+}
+
+// PartitionConsumerCoverage lists all groups which have a member that the partition is assigned to
+type PartitionConsumerCoverage struct {
+	PartitionID int32    `json:"partitionId"`
+	GroupIDs    []string `json:"groupIds"`
+	IsCovered   bool     `json:"isCovered"`
+}
+
+// GetTopicConsumerCoverage reports for each partition of the given topic whether it's currently assigned to an
+// active member of any consumer group. Partitions without an owner in any group are flagged as uncovered.
+func (s *Service) GetTopicConsumerCoverage(ctx context.Context, topicName string) (*TopicConsumerCoverage, error) {
+	partitionIDs, err := s.kafkaSvc.Client.Partitions(topicName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get partitions: %w", err)
+	}
+
+	groups, err := s.GetConsumerGroupsOverview(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get consumer groups overview: %w", err)
+	}
+
+	return newTopicConsumerCoverage(topicName, partitionIDs, groups), nil
+}
+
+func newTopicConsumerCoverage(topicName string, partitionIDs []int32, groups []*ConsumerGroupOverview) *TopicConsumerCoverage {
+	groupIDsByPartition := make(map[int32]map[string]struct{})
+	for _, group := range groups {
+		for _, member := range group.Members {
+			for _, assignment := range member.Assignments {
+				if assignment.TopicName != topicName {
+					continue
+				}
+				for _, partitionID := range assignment.PartitionIDs {
+					if _, exists := groupIDsByPartition[partitionID]; !exists {
+						groupIDsByPartition[partitionID] = make(map[string]struct{})
+					}
+					groupIDsByPartition[partitionID][group.GroupID] = struct{}{}
+				}
+			}
+		}
+	}
+
+	res := &TopicConsumerCoverage{
+		TopicName:             topicName,
+		PartitionCount:        len(partitionIDs),
+		UncoveredPartitionIDs: make([]int32, 0),
+		Partitions:            make([]*PartitionConsumerCoverage, 0, len(partitionIDs)),
+	}
+	for _, partitionID := range partitionIDs {
+		p := &PartitionConsumerCoverage{
+			PartitionID: partitionID,
+			GroupIDs:    sortedKeys(groupIDsByPartition[partitionID]),
+		}
+		p.IsCovered = len(p.GroupIDs) > 0
+		if p.IsCovered {
+			res.CoveredPartitionCount++
+		} else {
+			res.UncoveredPartitionIDs = append(res.UncoveredPartitionIDs, partitionID)
+		}
+		res.Partitions = append(res.Partitions, p)
+	}
+	sort.Slice(res.Partitions, func(i, j int) bool { return res.Partitions[i].PartitionID < res.Partitions[j].PartitionID })
+	sort.Slice(res.UncoveredPartitionIDs, func(i, j int) bool { return res.UncoveredPartitionIDs[i] < res.UncoveredPartitionIDs[j] })
+	if res.PartitionCount > 0 {
+		res.Coverage = float64(res.CoveredPartitionCount) / float64(res.PartitionCount)
+	}
+
+	return res
+}
@@ -0,0 +1,39 @@
+package owl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewTopicConsumerCoverage(t *testing.T) {
+	groups := []*ConsumerGroupOverview{
+		{
+			GroupID: "shipping",
+			Members: []*GroupMemberDescription{
+				{ID: "a", Assignments: []*GroupMemberAssignment{{TopicName: "orders", PartitionIDs: []int32{0, 1}}}},
+				{ID: "b", Assignments: []*GroupMemberAssignment{{TopicName: "payments", PartitionIDs: []int32{2}}}},
+			},
+		},
+		{
+			GroupID: "billing",
+			Members: []*GroupMemberDescription{
+				{ID: "c", Assignments: []*GroupMemberAssignment{{TopicName: "orders", PartitionIDs: []int32{1, 3}}}},
+			},
+		},
+		{GroupID: "empty-group"},
+	}
+
+	res := newTopicConsumerCoverage("orders", []int32{3, 2, 1, 0}, groups)
+	assert.Equal(t, 4, res.PartitionCount)
+	assert.Equal(t, 3, res.CoveredPartitionCount)
+	assert.InDelta(t, 0.75, res.Coverage, 0.0001)
+
+	// Partition 2 of orders is not consumed by any group, it's only assigned for the payments topic
+	assert.Equal(t, []int32{2}, res.UncoveredPartitionIDs)
+	require.Len(t, res.Partitions, 4)
+	assert.Equal(t, &PartitionConsumerCoverage{PartitionID: 1, GroupIDs: []string{"billing", "shipping"}, IsCovered: true}, res.Partitions[1])
+	assert.False(t, res.Partitions[2].IsCovered)
+	assert.Empty(t, res.Partitions[2].GroupIDs)
+}
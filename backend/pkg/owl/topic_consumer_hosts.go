@@ -0,0 +1,80 @@
+package owl
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// TopicConsumerHost is a client host which consumes a topic, along with the groups and clients it is consuming with
+type TopicConsumerHost struct {
+	Host      string   `json:"host"`
+	GroupIDs  []string `json:"groupIds"`
+	ClientIDs []string `json:"clientIds"`
+}
+
+// ListTopicConsumerHosts returns a deduplicated list of all client hosts which have partitions of the given topic
+// assigned in any consumer group. Members without host information are ignored.
+func (s *Service) ListTopicConsumerHosts(ctx context.Context, topicName string) ([]*TopicConsumerHost, error) {
+	groups, err := s.GetConsumerGroupsOverview(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get consumer groups overview: %w", err)
+	}
+
+	return aggregateTopicConsumerHosts(groups, topicName), nil
+}
+
+func aggregateTopicConsumerHosts(groups []*ConsumerGroupOverview, topicName string) []*TopicConsumerHost {
+	groupIDsByHost := make(map[string]map[string]struct{})
+	clientIDsByHost := make(map[string]map[string]struct{})
+
+	for _, group := range groups {
+		for _, member := range group.Members {
+			// Kafka reports client hosts with a leading slash (e.g. "/10.0.0.1")
+			host := strings.TrimPrefix(member.ClientHost, "/")
+			if host == "" || !isAssignedToTopic(member, topicName) {
+				continue
+			}
+
+			if _, exists := groupIDsByHost[host]; !exists {
+				groupIDsByHost[host] = make(map[string]struct{})
+				clientIDsByHost[host] = make(map[string]struct{})
+			}
+			groupIDsByHost[host][group.GroupID] = struct{}{}
+			if member.ClientID != "" {
+				clientIDsByHost[host][member.ClientID] = struct{}{}
+			}
+		}
+	}
+
+	res := make([]*TopicConsumerHost, 0, len(groupIDsByHost))
+	for host, groupIDs := range groupIDsByHost {
+		res = append(res, &TopicConsumerHost{
+			Host:      host,
+			GroupIDs:  sortedKeys(groupIDs),
+			ClientIDs: sortedKeys(clientIDsByHost[host]),
+		})
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Host < res[j].Host })
+
+	return res
+}
+
+func isAssignedToTopic(member *GroupMemberDescription, topicName string) bool {
+	for _, assignment := range member.Assignments {
+		if assignment.TopicName == topicName && len(assignment.PartitionIDs) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys(set map[string]struct{}) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
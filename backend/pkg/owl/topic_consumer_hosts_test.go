@@ -0,0 +1,34 @@
+package owl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAggregateTopicConsumerHosts(t *testing.T) {
+	ordersAssignment := []*GroupMemberAssignment{{TopicName: "orders", PartitionIDs: []int32{0, 1}}}
+	groups := []*ConsumerGroupOverview{
+		{
+			GroupID: "shipping",
+			Members: []*GroupMemberDescription{
+				{ID: "a", ClientID: "shipping-svc", ClientHost: "/10.0.0.1", Assignments: ordersAssignment},
+				{ID: "b", ClientID: "shipping-svc", ClientHost: "", Assignments: ordersAssignment},
+			},
+		},
+		{
+			GroupID: "billing",
+			Members: []*GroupMemberDescription{
+				{ID: "c", ClientID: "billing-svc", ClientHost: "/10.0.0.1", Assignments: ordersAssignment},
+				{ID: "d", ClientID: "billing-svc", ClientHost: "/10.0.0.2", Assignments: []*GroupMemberAssignment{{TopicName: "invoices", PartitionIDs: []int32{0}}}},
+			},
+		},
+	}
+
+	hosts := aggregateTopicConsumerHosts(groups, "orders")
+	require.Len(t, hosts, 1)
+	assert.Equal(t, "10.0.0.1", hosts[0].Host)
+	assert.Equal(t, []string{"billing", "shipping"}, hosts[0].GroupIDs)
+	assert.Equal(t, []string{"billing-svc", "shipping-svc"}, hosts[0].ClientIDs)
+}
@@ -0,0 +1,79 @@
+package owl
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/Shopify/sarama"
+)
+
+// CoPartitioningReport describes whether a set of topics which are supposed to be co-partitioned (e.g. for joins
+// in Kafka Streams) have the same number of partitions.
+type CoPartitioningReport struct {
+	IsCoPartitioned bool `json:"isCoPartitioned"`
+
+	// ExpectedPartitionCount is the partition count most of the topics have
+	ExpectedPartitionCount int32                  `json:"expectedPartitionCount"`
+	Topics                 []*CoPartitioningTopic `json:"topics"`
+}
+
+// CoPartitioningTopic is a topic along with its partition count
+type CoPartitioningTopic struct {
+	TopicName      string `json:"topicName"`
+	PartitionCount int32  `json:"partitionCount"`
+	IsOutlier      bool   `json:"isOutlier"` // True if the partition count differs from the expected count
+}
+
+// CheckCoPartitioning reports whether all given topics have the same partition count. Topics whose partition
+// count differs from the most common count are flagged as outliers.
+func (s *Service) CheckCoPartitioning(ctx context.Context, topicNames []string) (*CoPartitioningReport, error) {
+	if len(topicNames) < 2 {
+		return nil, fmt.Errorf("at least two topics are required to check co-partitioning")
+	}
+
+	metadata, err := s.kafkaSvc.DescribeTopics(topicNames)
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe topics: %w", err)
+	}
+
+	partitionCounts := make(map[string]int32, len(metadata))
+	for _, topic := range metadata {
+		if topic.Err != sarama.ErrNoError {
+			return nil, fmt.Errorf("failed to describe topic '%v': %w", topic.Name, topic.Err)
+		}
+		partitionCounts[topic.Name] = int32(len(topic.Partitions))
+	}
+
+	return checkCoPartitioning(partitionCounts), nil
+}
+
+func checkCoPartitioning(partitionCounts map[string]int32) *CoPartitioningReport {
+	// The most common partition count is expected, ties are resolved in favour of the higher count
+	occurrences := make(map[int32]int)
+	for _, count := range partitionCounts {
+		occurrences[count]++
+	}
+	var expected int32
+	for count, n := range occurrences {
+		if n > occurrences[expected] || (n == occurrences[expected] && count > expected) {
+			expected = count
+		}
+	}
+
+	report := &CoPartitioningReport{
+		IsCoPartitioned:        len(occurrences) <= 1,
+		ExpectedPartitionCount: expected,
+		Topics:                 make([]*CoPartitioningTopic, 0, len(partitionCounts)),
+	}
+	for topicName, count := range partitionCounts {
+		report.Topics = append(report.Topics, &CoPartitioningTopic{
+			TopicName:      topicName,
+			PartitionCount: count,
+			IsOutlier:      count != expected,
+		})
+	}
+	sort.Slice(report.Topics, func(i, j int) bool { return report.Topics[i].TopicName < report.Topics[j].TopicName })
+
+	return report
+}
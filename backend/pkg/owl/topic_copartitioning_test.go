@@ -0,0 +1,20 @@
+package owl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckCoPartitioning(t *testing.T) {
+	report := checkCoPartitioning(map[string]int32{"orders": 12, "customers": 12, "payments": 8})
+	assert.False(t, report.IsCoPartitioned)
+	assert.Equal(t, int32(12), report.ExpectedPartitionCount)
+	assert.Equal(t, []*CoPartitioningTopic{
+		{TopicName: "customers", PartitionCount: 12, IsOutlier: false},
+		{TopicName: "orders", PartitionCount: 12, IsOutlier: false},
+		{TopicName: "payments", PartitionCount: 8, IsOutlier: true},
+	}, report.Topics)
+
+	assert.True(t, checkCoPartitioning(map[string]int32{"orders": 6, "customers": 6}).IsCoPartitioned)
+}
@@ -0,0 +1,66 @@
+package owl
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// TopicFanOut is a topic along with all consumer groups which have committed offsets on it. Topics with a high
+// fan-out have a bigger blast radius when they are changed.
+type TopicFanOut struct {
+	TopicName  string   `json:"topicName"`
+	GroupCount int      `json:"groupCount"`
+	GroupIDs   []string `json:"groupIds"`
+}
+
+// GetTopicsFanOut returns the number of consuming groups for every topic which is consumed by at least one
+// group, sorted by the group count. The index is built from a single bulk offset fetch for all groups.
+func (s *Service) GetTopicsFanOut(ctx context.Context) ([]*TopicFanOut, error) {
+	groups, err := s.kafkaSvc.ListConsumerGroups(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list consumer groups: %w", err)
+	}
+
+	offsets, err := s.kafkaSvc.ListConsumerGroupOffsetsBulk(ctx, groups)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list consumer group offsets: %w", err)
+	}
+
+	offsetsByGroup := make(map[string]map[string]partitionOffsets, len(offsets))
+	for group, offset := range offsets {
+		offsetsByGroup[group] = convertOffsets(offset)
+	}
+
+	return buildTopicFanOut(offsetsByGroup), nil
+}
+
+// buildTopicFanOut inverts the group -> topic offsets into a topic -> groups index. Groups only count as consumer
+// if they have a valid offset on at least one of the topic's partitions.
+func buildTopicFanOut(offsetsByGroup map[string]map[string]partitionOffsets) []*TopicFanOut {
+	groupsByTopic := make(map[string][]string)
+	for group, topicOffsets := range offsetsByGroup {
+		for topic, pOffsets := range topicOffsets {
+			for _, offset := range pOffsets {
+				if offset >= 0 {
+					groupsByTopic[topic] = append(groupsByTopic[topic], group)
+					break
+				}
+			}
+		}
+	}
+
+	res := make([]*TopicFanOut, 0, len(groupsByTopic))
+	for topic, groupIDs := range groupsByTopic {
+		sort.Strings(groupIDs)
+		res = append(res, &TopicFanOut{TopicName: topic, GroupCount: len(groupIDs), GroupIDs: groupIDs})
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].GroupCount == res[j].GroupCount {
+			return res[i].TopicName < res[j].TopicName
+		}
+		return res[i].GroupCount > res[j].GroupCount
+	})
+
+	return res
+}
@@ -0,0 +1,20 @@
+package owl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildTopicFanOut(t *testing.T) {
+	offsetsByGroup := map[string]map[string]partitionOffsets{
+		"billing":  {"orders": {0: 10}, "payments": {0: 5, 1: 7}},
+		"shipping": {"orders": {0: 12, 1: 3}},
+		"search":   {"orders": {0: 8}, "payments": {0: -1}}, // No valid offset on payments
+	}
+
+	assert.Equal(t, []*TopicFanOut{
+		{TopicName: "orders", GroupCount: 3, GroupIDs: []string{"billing", "search", "shipping"}},
+		{TopicName: "payments", GroupCount: 1, GroupIDs: []string{"billing"}},
+	}, buildTopicFanOut(offsetsByGroup))
+}
@@ -0,0 +1,40 @@
+package owl
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/cloudhut/kowl/backend/pkg/kafka"
+)
+
+// ListFirstMessagePerPartition returns the oldest retained message of each non empty partition, which helps to
+// check the oldest data and to diagnose retention issues. All partitions are consumed concurrently.
+func (s *Service) ListFirstMessagePerPartition(ctx context.Context, topicName string) ([]*kafka.TopicMessage, error) {
+	partitionIDs, err := s.kafkaSvc.Client.Partitions(topicName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get partitions: %w", err)
+	}
+	marks, err := s.kafkaSvc.WaterMarks(topicName, partitionIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get watermarks: %w", err)
+	}
+
+	return s.scanChunk(ctx, ScanTopicRequest{TopicName: topicName}, firstMessageRanges(marks))
+}
+
+// firstMessageRanges returns a range starting at the low water mark for each non empty partition. The range spans
+// the whole partition, because the first retained message may be beyond the low water mark on compacted topics,
+// but only a single message is consumed.
+func firstMessageRanges(marks map[int32]*kafka.WaterMark) []*scanRange {
+	ranges := make([]*scanRange, 0, len(marks))
+	for partitionID, mark := range marks {
+		if mark.High <= mark.Low {
+			continue
+		}
+		ranges = append(ranges, &scanRange{PartitionID: partitionID, From: mark.Low, To: mark.High, MaxMessages: 1})
+	}
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i].PartitionID < ranges[j].PartitionID })
+
+	return ranges
+}
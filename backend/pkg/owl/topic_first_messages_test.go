@@ -0,0 +1,21 @@
+package owl
+
+import (
+	"testing"
+
+	"github.com/cloudhut/kowl/backend/pkg/kafka"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFirstMessageRanges(t *testing.T) {
+	marks := map[int32]*kafka.WaterMark{
+		0: {PartitionID: 0, Low: 120, High: 500},
+		1: {PartitionID: 1, Low: 30, High: 30}, // Empty
+		2: {PartitionID: 2, Low: 0, High: 1},
+	}
+
+	assert.Equal(t, []*scanRange{
+		{PartitionID: 0, From: 120, To: 500, MaxMessages: 1},
+		{PartitionID: 2, From: 0, To: 1, MaxMessages: 1},
+	}, firstMessageRanges(marks))
+}
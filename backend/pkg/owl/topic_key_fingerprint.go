@@ -0,0 +1,122 @@
+package owl
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/cloudhut/kowl/backend/pkg/kafka"
+)
+
+const (
+	// defaultFingerprintSampleSize is the number of recent messages sampled from each topic if no size is requested
+	defaultFingerprintSampleSize uint16 = 200
+	// maxFingerprintSampleSize bounds the number of messages sampled from each topic
+	maxFingerprintSampleSize uint16 = 500
+)
+
+// TopicKeyFingerprintComparison compares the keys of the most recent messages of two topics, e.g. a topic and its
+// mirror. It's a spot check, because only samples are compared: a key that is missing in one of the samples may
+// still exist in the other topic, if it has been written outside of the sampled window.
+type TopicKeyFingerprintComparison struct {
+	SourceTopic string `json:"sourceTopic"`
+	TargetTopic string `json:"targetTopic"`
+	SampleSize  uint16 `json:"sampleSize"`
+
+	SourceKeyCount   int `json:"sourceKeyCount"`
+	TargetKeyCount   int `json:"targetKeyCount"`
+	MatchingKeyCount int `json:"matchingKeyCount"` // Keys whose latest values are identical in both samples
+
+	KeysMissingInTarget []string `json:"keysMissingInTarget"`
+	KeysMissingInSource []string `json:"keysMissingInSource"`
+
+	// DivergingKeys exist in both samples, but with different latest values. This usually indicates that one of the
+	// topics is behind on this key.
+	DivergingKeys []string `json:"divergingKeys"`
+
+	IsMatch bool `json:"isMatch"`
+}
+
+// CompareTopicKeyFingerprints samples the most recent messages of both topics and compares their key sets along with
+// the latest value of each key. Messages without a key are ignored. The sample size defaults to 200 messages per
+// topic and must not exceed 500.
+func (s *Service) CompareTopicKeyFingerprints(ctx context.Context, sourceTopic string, targetTopic string, sampleSize uint16) (*TopicKeyFingerprintComparison, error) {
+	if sampleSize > maxFingerprintSampleSize {
+		return nil, fmt.Errorf("sample size must not exceed %d", maxFingerprintSampleSize)
+	}
+	if sampleSize == 0 {
+		sampleSize = defaultFingerprintSampleSize
+	}
+
+	source, err := s.sampleRecentMessages(ctx, sourceTopic, sampleSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sample source topic: %w", err)
+	}
+	target, err := s.sampleRecentMessages(ctx, targetTopic, sampleSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sample target topic: %w", err)
+	}
+
+	res := compareKeyFingerprints(keyFingerprints(source), keyFingerprints(target))
+	res.SourceTopic = sourceTopic
+	res.TargetTopic = targetTopic
+	res.SampleSize = sampleSize
+
+	return res, nil
+}
+
+// keyFingerprints returns the latest message of each key
+func keyFingerprints(messages []*kafka.TopicMessage) map[string]*kafka.TopicMessage {
+	res := make(map[string]*kafka.TopicMessage)
+	for _, msg := range messages {
+		if len(msg.Key.Value) == 0 {
+			continue
+		}
+		key := string(msg.Key.Value)
+		if latest, exists := res[key]; !exists || isNewerMessage(msg, latest) {
+			res[key] = msg
+		}
+	}
+	return res
+}
+
+func compareKeyFingerprints(source map[string]*kafka.TopicMessage, target map[string]*kafka.TopicMessage) *TopicKeyFingerprintComparison {
+	res := &TopicKeyFingerprintComparison{
+		SourceKeyCount:      len(source),
+		TargetKeyCount:      len(target),
+		KeysMissingInTarget: make([]string, 0),
+		KeysMissingInSource: make([]string, 0),
+		DivergingKeys:       make([]string, 0),
+	}
+
+	for _, key := range sortedMessageKeys(source) {
+		targetMsg, exists := target[key]
+		if !exists {
+			res.KeysMissingInTarget = append(res.KeysMissingInTarget, key)
+			continue
+		}
+		if bytes.Equal(source[key].Value.Value, targetMsg.Value.Value) {
+			res.MatchingKeyCount++
+		} else {
+			res.DivergingKeys = append(res.DivergingKeys, key)
+		}
+	}
+	for _, key := range sortedMessageKeys(target) {
+		if _, exists := source[key]; !exists {
+			res.KeysMissingInSource = append(res.KeysMissingInSource, key)
+		}
+	}
+	res.IsMatch = len(res.KeysMissingInTarget) == 0 && len(res.KeysMissingInSource) == 0 && len(res.DivergingKeys) == 0
+
+	return res
+}
+
+func sortedMessageKeys(messages map[string]*kafka.TopicMessage) []string {
+	keys := make([]string, 0, len(messages))
+	for key := range messages {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,45 @@
+package owl
+
+import (
+	"testing"
+
+	"github.com/cloudhut/kowl/backend/pkg/kafka"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompareKeyFingerprints(t *testing.T) {
+	newMessage := func(key string, offset int64, value string) *kafka.TopicMessage {
+		return &kafka.TopicMessage{
+			Offset: offset,
+			Key:    kafka.DirectEmbedding{Value: []byte(key)},
+			Value:  kafka.DirectEmbedding{Value: []byte(value)},
+		}
+	}
+	source := []*kafka.TopicMessage{
+		newMessage("a", 0, "v1"),
+		newMessage("b", 1, "v1"),
+		newMessage("a", 2, "v2"),
+		newMessage("c", 3, "v1"),
+		newMessage("d", 4, "v1"),
+		newMessage("", 5, "no-key"),
+	}
+	// The mirror misses key c and is behind on key d
+	target := []*kafka.TopicMessage{
+		newMessage("a", 10, "v1"),
+		newMessage("b", 11, "v1"),
+		newMessage("a", 12, "v2"),
+		newMessage("d", 13, "v0"),
+	}
+
+	res := compareKeyFingerprints(keyFingerprints(source), keyFingerprints(target))
+	assert.False(t, res.IsMatch)
+	assert.Equal(t, 4, res.SourceKeyCount)
+	assert.Equal(t, 3, res.TargetKeyCount)
+	assert.Equal(t, 2, res.MatchingKeyCount)
+	assert.Equal(t, []string{"c"}, res.KeysMissingInTarget)
+	assert.Empty(t, res.KeysMissingInSource)
+	assert.Equal(t, []string{"d"}, res.DivergingKeys)
+
+	identical := compareKeyFingerprints(keyFingerprints(target), keyFingerprints(target))
+	assert.True(t, identical.IsMatch)
+}
@@ -0,0 +1,82 @@
+package owl
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// TopicLagConcentration is the summed lag of all consumer groups on a single topic
+type TopicLagConcentration struct {
+	Topic     string   `json:"topic"`
+	SummedLag int64    `json:"summedLag"`
+	LagShare  float64  `json:"lagShare"` // Topic's lag relative to the cluster wide lag (0-1)
+	GroupIDs  []string `json:"groupIds"` // Groups lagging on this topic
+}
+
+// ClusterLagConcentration shows where the consumer lag of the whole cluster lives
+type ClusterLagConcentration struct {
+	TotalLag   int64                    `json:"totalLag"` // Summed lag of all groups on all topics
+	TopicCount int                      `json:"topicCount"`
+	Topics     []*TopicLagConcentration `json:"topics"`
+}
+
+// GetClusterLagConcentration aggregates the lag of all consumer groups by topic and returns the topN topics which
+// hold the most lag, sorted by their summed lag. All topics are returned if topN is 0 or less. TotalLag and
+// TopicCount always cover all topics.
+func (s *Service) GetClusterLagConcentration(ctx context.Context, topN int) (*ClusterLagConcentration, error) {
+	groups, err := s.kafkaSvc.ListConsumerGroups(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list consumer groups: %w", err)
+	}
+	lags, err := s.getConsumerGroupLags(ctx, groups)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get consumer group lags: %w", err)
+	}
+
+	return concentrateLagByTopic(lags, topN), nil
+}
+
+func concentrateLagByTopic(lags map[string]*ConsumerGroupLag, topN int) *ClusterLagConcentration {
+	byTopic := make(map[string]*TopicLagConcentration)
+	groupsByTopic := make(map[string]map[string]struct{})
+	var totalLag int64
+
+	for groupID, lag := range lags {
+		for _, topicLag := range lag.TopicLags {
+			concentration, exists := byTopic[topicLag.Topic]
+			if !exists {
+				concentration = &TopicLagConcentration{Topic: topicLag.Topic}
+				byTopic[topicLag.Topic] = concentration
+				groupsByTopic[topicLag.Topic] = make(map[string]struct{})
+			}
+			concentration.SummedLag += topicLag.SummedLag
+			totalLag += topicLag.SummedLag
+			if topicLag.SummedLag > 0 {
+				groupsByTopic[topicLag.Topic][groupID] = struct{}{}
+			}
+		}
+	}
+
+	topics := make([]*TopicLagConcentration, 0, len(byTopic))
+	for topic, concentration := range byTopic {
+		concentration.GroupIDs = sortedKeys(groupsByTopic[topic])
+		if totalLag > 0 {
+			concentration.LagShare = float64(concentration.SummedLag) / float64(totalLag)
+		}
+		topics = append(topics, concentration)
+	}
+	sort.Slice(topics, func(i, j int) bool {
+		if topics[i].SummedLag == topics[j].SummedLag {
+			return topics[i].Topic < topics[j].Topic
+		}
+		return topics[i].SummedLag > topics[j].SummedLag
+	})
+
+	res := &ClusterLagConcentration{TotalLag: totalLag, TopicCount: len(topics), Topics: topics}
+	if topN > 0 && topN < len(topics) {
+		res.Topics = topics[:topN]
+	}
+
+	return res
+}
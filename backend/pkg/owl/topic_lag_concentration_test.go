@@ -0,0 +1,36 @@
+package owl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConcentrateLagByTopic(t *testing.T) {
+	lags := map[string]*ConsumerGroupLag{
+		"billing": {GroupID: "billing", TopicLags: []*TopicLag{
+			{Topic: "orders", SummedLag: 400},
+			{Topic: "payments", SummedLag: 500},
+		}},
+		"audit": {GroupID: "audit", TopicLags: []*TopicLag{
+			{Topic: "orders", SummedLag: 300},
+			{Topic: "payments", SummedLag: 0},
+			{Topic: "users", SummedLag: 100},
+		}},
+	}
+
+	res := concentrateLagByTopic(lags, 2)
+	assert.Equal(t, int64(1300), res.TotalLag)
+	assert.Equal(t, 3, res.TopicCount)
+	assert.Len(t, res.Topics, 2)
+
+	// Orders holds the most lag across groups, although payments has the highest lag of a single group
+	assert.Equal(t, "orders", res.Topics[0].Topic)
+	assert.Equal(t, int64(700), res.Topics[0].SummedLag)
+	assert.InDelta(t, 700.0/1300.0, res.Topics[0].LagShare, 0.0001)
+	assert.Equal(t, []string{"audit", "billing"}, res.Topics[0].GroupIDs)
+	assert.Equal(t, "payments", res.Topics[1].Topic)
+	assert.Equal(t, []string{"billing"}, res.Topics[1].GroupIDs)
+
+	assert.Len(t, concentrateLagByTopic(lags, 0).Topics, 3)
+}
@@ -0,0 +1,112 @@
+package owl
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/Shopify/sarama"
+	"gopkg.in/yaml.v2"
+)
+
+// TopicManifest is a declarative description of one or more topics, which is suitable to be stored in version
+// control and to be re-applied against a cluster.
+type TopicManifest struct {
+	Topics []*TopicManifestEntry `yaml:"topics" json:"topics"`
+}
+
+// TopicManifestEntry describes a single topic with all it's non default config entries
+type TopicManifestEntry struct {
+	Name              string            `yaml:"name" json:"name"`
+	Partitions        int32             `yaml:"partitions" json:"partitions"`
+	ReplicationFactor int16             `yaml:"replicationFactor" json:"replicationFactor"`
+	Configs           map[string]string `yaml:"configs,omitempty" json:"configs,omitempty"`
+}
+
+// ExportTopicManifest returns a YAML manifest which describes the partition count, replication factor and all non
+// default configs of the given topics. Read-only configs are excluded, as they can not be re-applied.
+func (s *Service) ExportTopicManifest(ctx context.Context, topicNames []string) ([]byte, error) {
+	metadata, err := s.kafkaSvc.DescribeTopics(topicNames)
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe topics: %w", err)
+	}
+	for _, topic := range metadata {
+		if topic.Err != sarama.ErrNoError {
+			return nil, fmt.Errorf("failed to describe topic '%v': %w", topic.Name, topic.Err)
+		}
+	}
+
+	configs, err := s.GetTopicsConfigs(topicNames, []string{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe topic configs: %w", err)
+	}
+
+	manifest := buildTopicManifest(metadata, configs)
+	out, err := yaml.Marshal(manifest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal topic manifest: %w", err)
+	}
+
+	return out, nil
+}
+
+// buildTopicManifest merges the topic metadata with the topic configs. Topics are sorted by name so that exported
+// manifests are diffable.
+func buildTopicManifest(metadata []*sarama.TopicMetadata, configs map[string]*TopicConfigs) *TopicManifest {
+	manifest := &TopicManifest{Topics: make([]*TopicManifestEntry, 0, len(metadata))}
+	for _, topic := range metadata {
+		entry := &TopicManifestEntry{
+			Name:       topic.Name,
+			Partitions: int32(len(topic.Partitions)),
+		}
+		if len(topic.Partitions) > 0 {
+			entry.ReplicationFactor = int16(len(topic.Partitions[0].Replicas))
+		}
+
+		if cfg, ok := configs[topic.Name]; ok {
+			for _, cfgEntry := range cfg.ConfigEntries {
+				if cfgEntry.IsDefault || cfgEntry.IsReadOnly {
+					continue
+				}
+				if entry.Configs == nil {
+					entry.Configs = make(map[string]string)
+				}
+				entry.Configs[cfgEntry.Name] = cfgEntry.Value
+			}
+		}
+
+		manifest.Topics = append(manifest.Topics, entry)
+	}
+	sort.Slice(manifest.Topics, func(i, j int) bool { return manifest.Topics[i].Name < manifest.Topics[j].Name })
+
+	return manifest
+}
+
+// parseTopicManifest parses a YAML (or JSON) encoded topic manifest. Each topic must have a unique name and at least
+// one partition, a replication factor of 0 uses the brokers' default replication factor.
+func parseTopicManifest(manifest []byte) (*TopicManifest, error) {
+	var parsed TopicManifest
+	err := yaml.UnmarshalStrict(manifest, &parsed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse topic manifest: %w", err)
+	}
+
+	names := make(map[string]struct{}, len(parsed.Topics))
+	for _, topic := range parsed.Topics {
+		if topic.Name == "" {
+			return nil, fmt.Errorf("topic manifest contains a topic without a name")
+		}
+		if _, exists := names[topic.Name]; exists {
+			return nil, fmt.Errorf("topic manifest contains topic '%v' more than once", topic.Name)
+		}
+		names[topic.Name] = struct{}{}
+		if topic.Partitions <= 0 {
+			return nil, fmt.Errorf("topic '%v' must have at least one partition", topic.Name)
+		}
+		if topic.ReplicationFactor < 0 {
+			return nil, fmt.Errorf("replication factor of topic '%v' must not be negative", topic.Name)
+		}
+	}
+
+	return &parsed, nil
+}
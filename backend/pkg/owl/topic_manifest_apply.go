@@ -0,0 +1,309 @@
+package owl
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/Shopify/sarama"
+	"go.uber.org/zap"
+)
+
+const (
+	// TopicManifestActionCreate creates a topic which does not exist yet
+	TopicManifestActionCreate = "create"
+	// TopicManifestActionAlterConfigs changes config entries which differ from the manifest
+	TopicManifestActionAlterConfigs = "alterConfigs"
+	// TopicManifestActionCreatePartitions increases the partition count of an existing topic
+	TopicManifestActionCreatePartitions = "createPartitions"
+)
+
+// TopicManifestAction is a single change which is required to reconcile a topic towards the manifest
+type TopicManifestAction struct {
+	TopicName   string `json:"topicName"`
+	Type        string `json:"type"`
+	Description string `json:"description"`
+
+	// Topic is the manifest entry for create actions
+	Topic *TopicManifestEntry `json:"-"`
+	// ChangedConfigs are the config entries which will be set by alterConfigs actions
+	ChangedConfigs map[string]string `json:"changedConfigs,omitempty"`
+	// AllConfigs is the complete set of dynamic configs for alterConfigs actions, because AlterConfigs is not incremental
+	AllConfigs map[string]string `json:"-"`
+	// PartitionCount is the new total partition count for createPartitions actions
+	PartitionCount int32 `json:"partitionCount,omitempty"`
+
+	// addedReplicas is the number of partition replicas which will be added to the brokers by the action
+	addedReplicas int
+}
+
+// ApplyTopicManifestResult contains all planned (dry run) or applied actions along with all changes that have been
+// refused, because they are destructive or would exceed the partition limit of a broker. Warnings don't prevent the
+// manifest from being applied.
+type ApplyTopicManifestResult struct {
+	DryRun         bool                   `json:"dryRun"`
+	Actions        []*TopicManifestAction `json:"actions"`
+	RefusedChanges []string               `json:"refusedChanges"`
+	Warnings       []string               `json:"warnings"`
+}
+
+// ApplyTopicManifest reconciles the cluster towards the given manifest. Missing topics will be created, configs which
+// differ will be altered and partitions will be added where the manifest specifies more partitions. Config entries
+// which are not part of the manifest are left untouched and topics without replication factor are created with the
+// broker's default replication factor. Destructive changes (decreasing the partition count or
+// changing the replication factor, which would require a reassignment) are refused and nothing will be applied. The
+// same applies if the added partitions would push a broker beyond the configured partition limit (BrokerLimits).
+// In dry run mode the planned actions are returned without applying them.
+func (s *Service) ApplyTopicManifest(ctx context.Context, manifest []byte, dryRun bool) (*ApplyTopicManifestResult, error) {
+	desired, err := parseTopicManifest(manifest)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := s.getCurrentTopicManifest(desired)
+	if err != nil {
+		return nil, err
+	}
+
+	result := planTopicManifest(desired, current)
+	result.DryRun = dryRun
+	if addedReplicas := result.addedReplicas(); addedReplicas > 0 {
+		limits, err := s.GetBrokerPartitionLimits(ctx, addedReplicas)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check the partition limits: %w", err)
+		}
+		result.checkPartitionLimits(limits)
+	}
+	if dryRun {
+		return result, nil
+	}
+	if len(result.RefusedChanges) > 0 {
+		return result, fmt.Errorf("refusing to apply the topic manifest, because it contains %v refused change(s)", len(result.RefusedChanges))
+	}
+
+	for _, action := range result.Actions {
+		err := s.applyTopicManifestAction(action)
+		if err != nil {
+			return result, fmt.Errorf("failed to apply action '%v' for topic '%v': %w", action.Type, action.TopicName, err)
+		}
+		s.logger.Info("applied topic manifest action",
+			zap.String("topic_name", action.TopicName),
+			zap.String("action", action.Type),
+			zap.String("description", action.Description))
+	}
+
+	return result, nil
+}
+
+// topicManifestState is the current state of the cluster which is required to plan a topic manifest
+type topicManifestState struct {
+	// Topics are all existing topics which are part of the desired manifest, their configs are the dynamic configs
+	Topics map[string]*TopicManifestEntry
+	// DynamicConfigs are all config entries which have been set for the existing topics, sensitive values are nil
+	DynamicConfigs map[string]map[string]*string
+	// DefaultReplicationFactor of the brokers, it's only described if a topic without replication factor is created
+	DefaultReplicationFactor int16
+}
+
+// getCurrentTopicManifest returns the current state of all existing topics which are part of the desired manifest.
+func (s *Service) getCurrentTopicManifest(desired *TopicManifest) (*topicManifestState, error) {
+	topicNames := make([]string, len(desired.Topics))
+	for i, topic := range desired.Topics {
+		topicNames[i] = topic.Name
+	}
+
+	metadata, err := s.kafkaSvc.DescribeTopics(topicNames)
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe topics: %w", err)
+	}
+	existing := make([]*sarama.TopicMetadata, 0, len(metadata))
+	existingNames := make([]string, 0, len(metadata))
+	for _, topic := range metadata {
+		if topic.Err == sarama.ErrUnknownTopicOrPartition {
+			continue
+		}
+		if topic.Err != sarama.ErrNoError {
+			return nil, fmt.Errorf("failed to describe topic '%v': %w", topic.Name, topic.Err)
+		}
+		existing = append(existing, topic)
+		existingNames = append(existingNames, topic.Name)
+	}
+
+	state := &topicManifestState{
+		Topics:         make(map[string]*TopicManifestEntry, len(existing)),
+		DynamicConfigs: make(map[string]map[string]*string),
+	}
+	if len(existingNames) > 0 {
+		state.DynamicConfigs, err = s.kafkaSvc.DescribeTopicsDynamicConfigs(existingNames)
+		if err != nil {
+			return nil, fmt.Errorf("failed to describe topic configs: %w", err)
+		}
+	}
+
+	// AlterConfigs replaces all dynamic configs, hence the current state is compared against them
+	for _, topic := range buildTopicManifest(existing, nil).Topics {
+		for name, value := range state.DynamicConfigs[topic.Name] {
+			if value == nil {
+				continue
+			}
+			if topic.Configs == nil {
+				topic.Configs = make(map[string]string)
+			}
+			topic.Configs[name] = *value
+		}
+		state.Topics[topic.Name] = topic
+	}
+
+	for _, topic := range desired.Topics {
+		if _, exists := state.Topics[topic.Name]; !exists && topic.ReplicationFactor == 0 {
+			state.DefaultReplicationFactor, err = s.kafkaSvc.DefaultReplicationFactor()
+			if err != nil {
+				return nil, fmt.Errorf("failed to describe the default replication factor: %w", err)
+			}
+			break
+		}
+	}
+
+	return state, nil
+}
+
+// planTopicManifest compares the desired manifest with the current state and returns all actions which are necessary
+// to reconcile the cluster towards the manifest. Topics without replication factor use the broker's default.
+func planTopicManifest(desired *TopicManifest, state *topicManifestState) *ApplyTopicManifestResult {
+	result := &ApplyTopicManifestResult{
+		Actions:        make([]*TopicManifestAction, 0),
+		RefusedChanges: make([]string, 0),
+		Warnings:       make([]string, 0),
+	}
+
+	for _, topic := range desired.Topics {
+		existing, exists := state.Topics[topic.Name]
+		if !exists {
+			created := *topic
+			if created.ReplicationFactor == 0 {
+				created.ReplicationFactor = state.DefaultReplicationFactor
+			}
+			result.Actions = append(result.Actions, &TopicManifestAction{
+				TopicName:   topic.Name,
+				Type:        TopicManifestActionCreate,
+				Description: fmt.Sprintf("create topic with %v partitions and replication factor %v", created.Partitions, created.ReplicationFactor),
+				Topic:       &created,
+
+				addedReplicas: int(created.Partitions) * int(created.ReplicationFactor),
+			})
+			continue
+		}
+
+		// Replication factor changes require a partition reassignment which is out of scope
+		if topic.ReplicationFactor != 0 && topic.ReplicationFactor != existing.ReplicationFactor {
+			result.RefusedChanges = append(result.RefusedChanges, fmt.Sprintf("topic '%v': changing the replication factor from %v to %v requires a partition reassignment",
+				topic.Name, existing.ReplicationFactor, topic.ReplicationFactor))
+		}
+
+		switch {
+		case topic.Partitions < existing.Partitions:
+			result.RefusedChanges = append(result.RefusedChanges, fmt.Sprintf("topic '%v': decreasing the partition count from %v to %v is not possible",
+				topic.Name, existing.Partitions, topic.Partitions))
+		case topic.Partitions > existing.Partitions:
+			result.Actions = append(result.Actions, &TopicManifestAction{
+				TopicName:      topic.Name,
+				Type:           TopicManifestActionCreatePartitions,
+				Description:    fmt.Sprintf("increase partition count from %v to %v", existing.Partitions, topic.Partitions),
+				PartitionCount: topic.Partitions,
+
+				addedReplicas: int(topic.Partitions-existing.Partitions) * int(existing.ReplicationFactor),
+			})
+		}
+
+		changedConfigs := make(map[string]string)
+		for name, value := range topic.Configs {
+			if currentValue, ok := existing.Configs[name]; !ok || currentValue != value {
+				changedConfigs[name] = value
+			}
+		}
+		if len(changedConfigs) == 0 {
+			continue
+		}
+
+		// All other dynamic configs must be sent along, because AlterConfigs is not incremental
+		dynamicConfigs := state.DynamicConfigs[topic.Name]
+		allConfigs := make(map[string]string, len(dynamicConfigs)+len(changedConfigs))
+		for name, value := range dynamicConfigs {
+			if _, isChanged := changedConfigs[name]; isChanged {
+				continue
+			}
+			if value == nil {
+				result.RefusedChanges = append(result.RefusedChanges, fmt.Sprintf("topic '%v': altering configs would reset the sensitive config entry '%v', which can't be described",
+					topic.Name, name))
+				continue
+			}
+			allConfigs[name] = *value
+		}
+		changedNames := make([]string, 0, len(changedConfigs))
+		for name, value := range changedConfigs {
+			allConfigs[name] = value
+			changedNames = append(changedNames, name)
+		}
+		sort.Strings(changedNames)
+
+		result.Actions = append(result.Actions, &TopicManifestAction{
+			TopicName:      topic.Name,
+			Type:           TopicManifestActionAlterConfigs,
+			Description:    fmt.Sprintf("alter config entries %v", changedNames),
+			ChangedConfigs: changedConfigs,
+			AllConfigs:     allConfigs,
+		})
+	}
+
+	return result
+}
+
+// addedReplicas returns the number of partition replicas which will be added to the brokers by all actions
+func (r *ApplyTopicManifestResult) addedReplicas() int {
+	added := 0
+	for _, action := range r.Actions {
+		added += action.addedReplicas
+	}
+	return added
+}
+
+// checkPartitionLimits refuses the manifest if a broker would exceed the partition limit after applying it and
+// warns about brokers which would be approaching the limit
+func (r *ApplyTopicManifestResult) checkPartitionLimits(limits *BrokerPartitionLimits) {
+	for _, broker := range limits.Brokers {
+		switch {
+		case broker.IsExceedingLimit:
+			r.RefusedChanges = append(r.RefusedChanges, fmt.Sprintf("broker %v would host about %v partition replicas, which exceeds the limit of %v",
+				broker.BrokerID, broker.ProjectedReplicas, limits.MaxPartitionsPerBroker))
+		case broker.IsApproachingLimit:
+			r.Warnings = append(r.Warnings, fmt.Sprintf("broker %v would host about %v partition replicas, which is %.0f%% of the limit of %v",
+				broker.BrokerID, broker.ProjectedReplicas, broker.Utilization, limits.MaxPartitionsPerBroker))
+		}
+	}
+}
+
+func (s *Service) applyTopicManifestAction(action *TopicManifestAction) error {
+	switch action.Type {
+	case TopicManifestActionCreate:
+		return s.kafkaSvc.CreateTopic(action.TopicName, &sarama.TopicDetail{
+			NumPartitions:     action.Topic.Partitions,
+			ReplicationFactor: action.Topic.ReplicationFactor,
+			ConfigEntries:     toConfigEntries(action.Topic.Configs),
+		})
+	case TopicManifestActionAlterConfigs:
+		return s.kafkaSvc.AlterTopicConfigs(action.TopicName, toConfigEntries(action.AllConfigs))
+	case TopicManifestActionCreatePartitions:
+		return s.kafkaSvc.CreatePartitions(action.TopicName, action.PartitionCount)
+	}
+
+	return fmt.Errorf("unknown topic manifest action '%v'", action.Type)
+}
+
+func toConfigEntries(configs map[string]string) map[string]*string {
+	entries := make(map[string]*string, len(configs))
+	for name, value := range configs {
+		v := value
+		entries[name] = &v
+	}
+	return entries
+}
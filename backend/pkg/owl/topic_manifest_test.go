@@ -0,0 +1,201 @@
+package owl
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Shopify/sarama"
+	"github.com/cloudhut/kowl/backend/pkg/kafka"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v2"
+)
+
+func TestTopicManifest_RoundTrip(t *testing.T) {
+	metadata := []*sarama.TopicMetadata{
+		{
+			Name: "orders",
+			Partitions: []*sarama.PartitionMetadata{
+				{ID: 0, Replicas: []int32{0, 1, 2}},
+				{ID: 1, Replicas: []int32{1, 2, 0}},
+			},
+		},
+	}
+	configs := map[string]*TopicConfigs{
+		"orders": {
+			TopicName: "orders",
+			ConfigEntries: []*TopicConfigEntry{
+				{Name: "cleanup.policy", Value: "compact", IsDefault: false},
+				{Name: "retention.ms", Value: "604800000", IsDefault: true},
+				{Name: "message.format.version", Value: "2.4-IV1", IsReadOnly: true},
+			},
+		},
+	}
+
+	manifest := buildTopicManifest(metadata, configs)
+	expected := &TopicManifest{Topics: []*TopicManifestEntry{
+		{Name: "orders", Partitions: 2, ReplicationFactor: 3, Configs: map[string]string{"cleanup.policy": "compact"}},
+	}}
+	assert.Equal(t, expected, manifest)
+
+	out, err := yaml.Marshal(manifest)
+	require.NoError(t, err)
+	parsed, err := parseTopicManifest(out)
+	require.NoError(t, err)
+	assert.Equal(t, manifest, parsed)
+}
+
+func TestParseTopicManifest_Invalid(t *testing.T) {
+	invalid := map[string]string{
+		"missing name":                "topics:\n  - partitions: 3\n",
+		"duplicate name":              "topics:\n  - name: orders\n    partitions: 3\n  - name: orders\n    partitions: 6\n",
+		"missing partitions":          "topics:\n  - name: orders\n",
+		"negative partitions":         "topics:\n  - name: orders\n    partitions: -1\n",
+		"negative replication factor": "topics:\n  - name: orders\n    partitions: 3\n    replicationFactor: -1\n",
+		"unknown field":               "topics:\n  - name: orders\n    partitions: 3\n    replicas: 3\n",
+	}
+	for name, manifest := range invalid {
+		_, err := parseTopicManifest([]byte(manifest))
+		assert.Error(t, err, name)
+	}
+
+	// A replication factor of 0 uses the brokers' default
+	parsed, err := parseTopicManifest([]byte("topics:\n  - name: orders\n    partitions: 3\n"))
+	require.NoError(t, err)
+	assert.Equal(t, []*TopicManifestEntry{{Name: "orders", Partitions: 3}}, parsed.Topics)
+}
+
+func TestPlanTopicManifest(t *testing.T) {
+	retentionMs, cleanupPolicy := "86400000", "delete"
+	state := &topicManifestState{
+		Topics: map[string]*TopicManifestEntry{
+			"orders":   {Name: "orders", Partitions: 6, ReplicationFactor: 3, Configs: map[string]string{"retention.ms": retentionMs, "cleanup.policy": cleanupPolicy}},
+			"payments": {Name: "payments", Partitions: 12, ReplicationFactor: 3},
+		},
+		DynamicConfigs: map[string]map[string]*string{
+			"orders":   {"retention.ms": &retentionMs, "cleanup.policy": &cleanupPolicy},
+			"payments": {"sasl.jaas.config": nil},
+		},
+		DefaultReplicationFactor: 2,
+	}
+	desired := &TopicManifest{Topics: []*TopicManifestEntry{
+		{Name: "orders", Partitions: 6, ReplicationFactor: 3, Configs: map[string]string{"retention.ms": "604800000"}},
+		{Name: "shipments", Partitions: 3},
+	}}
+
+	result := planTopicManifest(desired, state)
+	require.Len(t, result.Actions, 2)
+	assert.Empty(t, result.RefusedChanges)
+
+	alter := result.Actions[0]
+	assert.Equal(t, TopicManifestActionAlterConfigs, alter.Type)
+	assert.Equal(t, map[string]string{"retention.ms": "604800000"}, alter.ChangedConfigs)
+	// Existing overrides must be kept, because AlterConfigs is not incremental
+	assert.Equal(t, map[string]string{"retention.ms": "604800000", "cleanup.policy": "delete"}, alter.AllConfigs)
+
+	// Topics without replication factor are created with the broker's default
+	create := result.Actions[1]
+	assert.Equal(t, TopicManifestActionCreate, create.Type)
+	assert.Equal(t, "shipments", create.TopicName)
+	assert.Equal(t, int16(2), create.Topic.ReplicationFactor)
+	assert.Equal(t, 6, result.addedReplicas())
+
+	// Decreasing partitions must be refused
+	desired = &TopicManifest{Topics: []*TopicManifestEntry{{Name: "payments", Partitions: 6, ReplicationFactor: 3}}}
+	result = planTopicManifest(desired, state)
+	assert.Empty(t, result.Actions)
+	assert.Len(t, result.RefusedChanges, 1)
+
+	// Altering configs must be refused if it would reset a sensitive override
+	desired = &TopicManifest{Topics: []*TopicManifestEntry{{Name: "payments", Partitions: 12, Configs: map[string]string{"retention.ms": "1000"}}}}
+	result = planTopicManifest(desired, state)
+	assert.Len(t, result.RefusedChanges, 1)
+}
+
+func TestService_ApplyTopicManifest(t *testing.T) {
+	broker := sarama.NewMockBroker(t, 1)
+	defer broker.Close()
+
+	// The topic "orders" has an unrelated override (cleanup.policy) which is not part of the manifest
+	configsResponse := &sarama.DescribeConfigsResponse{
+		Version: 1,
+		Resources: []*sarama.ResourceResponse{
+			{
+				Type: sarama.TopicResource,
+				Name: "orders",
+				Configs: []*sarama.ConfigEntry{
+					{Name: "retention.ms", Value: "86400000", Source: sarama.SourceTopic},
+					{Name: "cleanup.policy", Value: "compact,delete", Source: sarama.SourceTopic},
+					{Name: "segment.bytes", Value: "536870912", Source: sarama.SourceStaticBroker},
+					{Name: "max.message.bytes", Value: "1048588", Source: sarama.SourceDefault},
+				},
+			},
+			{
+				Type:    sarama.BrokerResource,
+				Name:    "1",
+				Configs: []*sarama.ConfigEntry{{Name: "default.replication.factor", Value: "1", Source: sarama.SourceStaticBroker}},
+			},
+		},
+	}
+	broker.SetHandlerByMap(map[string]sarama.MockResponse{
+		"MetadataRequest": sarama.NewMockMetadataResponse(t).
+			SetBroker(broker.Addr(), broker.BrokerID()).
+			SetController(broker.BrokerID()).
+			SetLeader("orders", 0, broker.BrokerID()),
+		"DescribeConfigsRequest": sarama.NewMockWrapper(configsResponse),
+		"AlterConfigsRequest":    sarama.NewMockAlterConfigsResponse(t),
+		"CreateTopicsRequest":    sarama.NewMockCreateTopicsResponse(t),
+	})
+
+	cfg := sarama.NewConfig()
+	cfg.Version = sarama.V2_0_0_0
+	client, err := sarama.NewClient([]string{broker.Addr()}, cfg)
+	require.NoError(t, err)
+	defer client.Close()
+	// Topics are described using any connected broker
+	_, err = client.Controller()
+	require.NoError(t, err)
+
+	svc := NewService(Config{BrokerLimits: BrokerLimitsConfig{MaxPartitionsPerBroker: 100, WarningThreshold: 80}},
+		&kafka.Service{Client: client, Logger: zap.NewNop()}, zap.NewNop())
+	manifest := []byte(`
+topics:
+  - name: orders
+    partitions: 1
+    replicationFactor: 1
+    configs:
+      retention.ms: "604800000"
+  - name: shipments
+    partitions: 3
+`)
+	result, err := svc.ApplyTopicManifest(context.Background(), manifest, false)
+	require.NoError(t, err)
+	require.Len(t, result.Actions, 2)
+	assert.Empty(t, result.RefusedChanges)
+
+	var alterReq *sarama.AlterConfigsRequest
+	var createReq *sarama.CreateTopicsRequest
+	for _, rr := range broker.History() {
+		switch req := rr.Request.(type) {
+		case *sarama.AlterConfigsRequest:
+			alterReq = req
+		case *sarama.CreateTopicsRequest:
+			createReq = req
+		}
+	}
+
+	// The unrelated override survives, broker level configs don't become topic overrides
+	require.NotNil(t, alterReq)
+	require.Len(t, alterReq.Resources, 1)
+	entries := make(map[string]string)
+	for name, value := range alterReq.Resources[0].ConfigEntries {
+		entries[name] = *value
+	}
+	assert.Equal(t, map[string]string{"retention.ms": "604800000", "cleanup.policy": "compact,delete"}, entries)
+
+	require.NotNil(t, createReq)
+	require.Contains(t, createReq.TopicDetails, "shipments")
+	assert.Equal(t, int32(3), createReq.TopicDetails["shipments"].NumPartitions)
+	assert.Equal(t, int16(1), createReq.TopicDetails["shipments"].ReplicationFactor)
+}
@@ -0,0 +1,88 @@
+package owl
+
+import (
+	"context"
+	"math"
+	"sort"
+)
+
+// maxMessageSizeSample is the maximum number of messages which are consumed to compute a size distribution
+const maxMessageSizeSample = 1000
+
+// MessageSizeDistribution describes the size distribution of keys and values in a sample of recent messages
+type MessageSizeDistribution struct {
+	TopicName  string     `json:"topicName"`
+	SampleSize int        `json:"sampleSize"`
+	Keys       *SizeStats `json:"keys"`
+	Values     *SizeStats `json:"values"`
+}
+
+// SizeStats are statistics about a set of sizes in bytes
+type SizeStats struct {
+	Min  int     `json:"min"`
+	Max  int     `json:"max"`
+	Mean float64 `json:"mean"`
+	P50  int     `json:"p50"`
+	P95  int     `json:"p95"`
+	P99  int     `json:"p99"`
+}
+
+// GetMessageSizeDistribution samples up to sampleSize recent messages across all partitions of a topic and returns
+// the size distribution of their keys and values. The sample size is capped at 1000 messages.
+func (s *Service) GetMessageSizeDistribution(ctx context.Context, topicName string, sampleSize uint16) (*MessageSizeDistribution, error) {
+	if sampleSize == 0 || sampleSize > maxMessageSizeSample {
+		sampleSize = maxMessageSizeSample
+	}
+
+	messages, err := s.sampleRecentMessages(ctx, topicName, sampleSize)
+	if err != nil {
+		return nil, err
+	}
+
+	keySizes := make([]int, len(messages))
+	valueSizes := make([]int, len(messages))
+	for i, msg := range messages {
+		keySizes[i] = msg.KeySize
+		valueSizes[i] = msg.Size
+	}
+
+	return &MessageSizeDistribution{
+		TopicName:  topicName,
+		SampleSize: len(messages),
+		Keys:       newSizeStats(keySizes),
+		Values:     newSizeStats(valueSizes),
+	}, nil
+}
+
+func newSizeStats(sizes []int) *SizeStats {
+	if len(sizes) == 0 {
+		return &SizeStats{}
+	}
+
+	sorted := make([]int, len(sizes))
+	copy(sorted, sizes)
+	sort.Ints(sorted)
+
+	sum := 0
+	for _, size := range sorted {
+		sum += size
+	}
+
+	return &SizeStats{
+		Min:  sorted[0],
+		Max:  sorted[len(sorted)-1],
+		Mean: float64(sum) / float64(len(sorted)),
+		P50:  percentile(sorted, 50),
+		P95:  percentile(sorted, 95),
+		P99:  percentile(sorted, 99),
+	}
+}
+
+// percentile returns the p-th percentile of the sorted values using the nearest-rank method
+func percentile(sorted []int, p float64) int {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
@@ -0,0 +1,22 @@
+package owl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewSizeStats(t *testing.T) {
+	// 1..100 bytes in reverse order, so that sorting is required
+	sizes := make([]int, 100)
+	for i := range sizes {
+		sizes[i] = 100 - i
+	}
+
+	stats := newSizeStats(sizes)
+	assert.Equal(t, &SizeStats{Min: 1, Max: 100, Mean: 50.5, P50: 50, P95: 95, P99: 99}, stats)
+	assert.Equal(t, 100, sizes[0], "input must not be modified")
+
+	assert.Equal(t, &SizeStats{Min: 42, Max: 42, Mean: 42, P50: 42, P95: 42, P99: 42}, newSizeStats([]int{42}))
+	assert.Equal(t, &SizeStats{}, newSizeStats(nil))
+}
@@ -0,0 +1,102 @@
+package owl
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"go.uber.org/zap"
+)
+
+// OrphanTopic is a topic without any consumer group that has committed offsets on it
+type OrphanTopic struct {
+	TopicName      string `json:"topicName"`
+	PartitionCount int    `json:"partitionCount"`
+
+	// LastWriteTimestamp is the newest record timestamp across all partitions, only set if requested. It's nil if
+	// the topic does not contain any records.
+	LastWriteTimestamp *time.Time `json:"lastWriteTimestamp,omitempty"`
+}
+
+// ListOrphanTopics returns all non internal topics which are not consumed by any consumer group and may therefore be
+// abandoned. If withLastWrite is set, the timestamp of each topic's newest record is fetched as well, which is a
+// stronger signal but requires one fetch per partition.
+func (s *Service) ListOrphanTopics(ctx context.Context, withLastWrite bool) ([]*OrphanTopic, error) {
+	topics, err := s.kafkaSvc.ListTopics()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list topics: %w", err)
+	}
+
+	fanOut, err := s.GetTopicsFanOut(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	orphans := findOrphanTopics(topics, fanOut)
+	if !withLastWrite {
+		return orphans, nil
+	}
+
+	for _, orphan := range orphans {
+		ts, err := s.getLastWriteTimestamp(ctx, orphan.TopicName)
+		if err != nil {
+			s.logger.Warn("failed to get last write timestamp of orphan topic", zap.String("topic", orphan.TopicName), zap.Error(err))
+			continue
+		}
+		orphan.LastWriteTimestamp = ts
+	}
+
+	return orphans, nil
+}
+
+func findOrphanTopics(topics []*sarama.TopicMetadata, fanOut []*TopicFanOut) []*OrphanTopic {
+	consumedTopics := make(map[string]struct{}, len(fanOut))
+	for _, t := range fanOut {
+		consumedTopics[t.TopicName] = struct{}{}
+	}
+
+	res := make([]*OrphanTopic, 0)
+	for _, topic := range topics {
+		if topic.IsInternal || strings.HasPrefix(topic.Name, "_") {
+			continue
+		}
+		if _, isConsumed := consumedTopics[topic.Name]; isConsumed {
+			continue
+		}
+		res = append(res, &OrphanTopic{TopicName: topic.Name, PartitionCount: len(topic.Partitions)})
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].TopicName < res[j].TopicName })
+
+	return res
+}
+
+// getLastWriteTimestamp returns the newest timestamp of all partitions' last records or nil if the topic is empty
+func (s *Service) getLastWriteTimestamp(ctx context.Context, topicName string) (*time.Time, error) {
+	partitionIDs, err := s.kafkaSvc.Client.Partitions(topicName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get partitions: %w", err)
+	}
+	waterMarks, err := s.kafkaSvc.WaterMarks(topicName, partitionIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get watermarks: %w", err)
+	}
+
+	var lastWrite *time.Time
+	for partitionID, waterMark := range waterMarks {
+		if waterMark.High <= waterMark.Low {
+			continue
+		}
+		ts, exists, err := s.kafkaSvc.FetchRecordTimestamp(ctx, topicName, partitionID, waterMark.High-1)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch last record of partition %v: %w", partitionID, err)
+		}
+		if exists && (lastWrite == nil || ts.After(*lastWrite)) {
+			lastWrite = &ts
+		}
+	}
+
+	return lastWrite, nil
+}
@@ -0,0 +1,23 @@
+package owl
+
+import (
+	"testing"
+
+	"github.com/Shopify/sarama"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindOrphanTopics(t *testing.T) {
+	topics := []*sarama.TopicMetadata{
+		{Name: "orders", Partitions: make([]*sarama.PartitionMetadata, 3)},
+		{Name: "legacy-events", Partitions: make([]*sarama.PartitionMetadata, 6)},
+		{Name: "__consumer_offsets", IsInternal: true},
+		{Name: "_schemas"},
+	}
+	fanOut := []*TopicFanOut{{TopicName: "orders", GroupCount: 1, GroupIDs: []string{"billing"}}}
+
+	orphans := findOrphanTopics(topics, fanOut)
+	require.Len(t, orphans, 1)
+	assert.Equal(t, &OrphanTopic{TopicName: "legacy-events", PartitionCount: 6}, orphans[0])
+}
@@ -14,6 +14,7 @@ type TopicOverview struct {
 	PartitionCount    int    `json:"partitionCount"`
 	ReplicationFactor int    `json:"replicationFactor"`
 	CleanupPolicy     string `json:"cleanupPolicy"`
+	TimestampType     string `json:"timestampType"` // CreateTime or LogAppendTime
 	LogDirSize        int64  `json:"logDirSize"`
 
 	// What actions the logged in user is allowed to run on this topic
@@ -46,7 +47,7 @@ func (s *Service) GetTopicsOverview() ([]*TopicOverview, error) {
 		topicNames[i] = topic.Name
 	}
 
-	configs, err := s.GetTopicsConfigs(topicNames, []string{"cleanup.policy"})
+	configs, err := s.GetTopicsConfigs(topicNames, []string{"cleanup.policy", configTimestampType})
 	if err != nil {
 		return nil, err
 	}
@@ -66,6 +67,7 @@ func (s *Service) GetTopicsOverview() ([]*TopicOverview, error) {
 				policy = entry.Value
 			}
 		}
+		timestampType := timestampTypeFromConfigs(configs[topic.Name])
 
 		res[i] = &TopicOverview{
 			TopicName:         topic.Name,
@@ -73,6 +75,7 @@ func (s *Service) GetTopicsOverview() ([]*TopicOverview, error) {
 			PartitionCount:    len(topic.Partitions),
 			ReplicationFactor: len(topic.Partitions[0].Replicas),
 			CleanupPolicy:     policy,
+			TimestampType:     timestampType,
 			LogDirSize:        size,
 		}
 	}
@@ -0,0 +1,107 @@
+package owl
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// PartitionIncreaseImpact lists the groups which consume a topic but have not yet been assigned all partitions that
+// were added by a recent partition increase
+type PartitionIncreaseImpact struct {
+	TopicName              string                          `json:"topicName"`
+	PreviousPartitionCount int32                           `json:"previousPartitionCount"`
+	PartitionCount         int                             `json:"partitionCount"`
+	NewPartitionIDs        []int32                         `json:"newPartitionIds"`
+	AffectedGroups         []*PartitionIncreaseGroupImpact `json:"affectedGroups"`
+}
+
+// PartitionIncreaseGroupImpact is a group whose assignments don't cover all new partitions of the topic yet
+type PartitionIncreaseGroupImpact struct {
+	GroupID string `json:"groupId"`
+	State   string `json:"state"`
+
+	// UnassignedPartitionIDs are the new partitions which are not assigned to any member of the group. Messages
+	// which are produced to these partitions are not consumed until the group rebalances and, depending on the
+	// consumers' auto.offset.reset, may be skipped entirely.
+	UnassignedPartitionIDs []int32 `json:"unassignedPartitionIds"`
+}
+
+// GetPartitionIncreaseImpact returns the groups which consume the given topic, but whose members are not assigned
+// all partitions that have been added since the topic had previousPartitionCount partitions. Partitions are always
+// appended, so that all partition IDs greater than or equal to previousPartitionCount are considered new. Only
+// groups with at least one member that is assigned a partition of the topic are checked, groups without active
+// members will be assigned all partitions once they rejoin.
+func (s *Service) GetPartitionIncreaseImpact(ctx context.Context, topicName string, previousPartitionCount int32) (*PartitionIncreaseImpact, error) {
+	if previousPartitionCount < 1 {
+		return nil, fmt.Errorf("previous partition count must be at least 1")
+	}
+
+	partitionIDs, err := s.kafkaSvc.Client.Partitions(topicName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get partitions: %w", err)
+	}
+	if int(previousPartitionCount) >= len(partitionIDs) {
+		return nil, fmt.Errorf("topic has %d partitions, which is not more than the previous partition count (%d)", len(partitionIDs), previousPartitionCount)
+	}
+
+	groups, err := s.GetConsumerGroupsOverview(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get consumer groups overview: %w", err)
+	}
+
+	return newPartitionIncreaseImpact(topicName, previousPartitionCount, partitionIDs, groups), nil
+}
+
+func newPartitionIncreaseImpact(topicName string, previousPartitionCount int32, partitionIDs []int32, groups []*ConsumerGroupOverview) *PartitionIncreaseImpact {
+	res := &PartitionIncreaseImpact{
+		TopicName:              topicName,
+		PreviousPartitionCount: previousPartitionCount,
+		PartitionCount:         len(partitionIDs),
+		NewPartitionIDs:        make([]int32, 0),
+		AffectedGroups:         make([]*PartitionIncreaseGroupImpact, 0),
+	}
+	for _, partitionID := range partitionIDs {
+		if partitionID >= previousPartitionCount {
+			res.NewPartitionIDs = append(res.NewPartitionIDs, partitionID)
+		}
+	}
+	sort.Slice(res.NewPartitionIDs, func(i, j int) bool { return res.NewPartitionIDs[i] < res.NewPartitionIDs[j] })
+
+	for _, group := range groups {
+		assigned := make(map[int32]struct{})
+		isConsumingTopic := false
+		for _, member := range group.Members {
+			for _, assignment := range member.Assignments {
+				if assignment.TopicName != topicName {
+					continue
+				}
+				isConsumingTopic = true
+				for _, partitionID := range assignment.PartitionIDs {
+					assigned[partitionID] = struct{}{}
+				}
+			}
+		}
+		if !isConsumingTopic {
+			continue
+		}
+
+		unassigned := make([]int32, 0)
+		for _, partitionID := range res.NewPartitionIDs {
+			if _, exists := assigned[partitionID]; !exists {
+				unassigned = append(unassigned, partitionID)
+			}
+		}
+		if len(unassigned) == 0 {
+			continue
+		}
+		res.AffectedGroups = append(res.AffectedGroups, &PartitionIncreaseGroupImpact{
+			GroupID:                group.GroupID,
+			State:                  group.State,
+			UnassignedPartitionIDs: unassigned,
+		})
+	}
+	sort.Slice(res.AffectedGroups, func(i, j int) bool { return res.AffectedGroups[i].GroupID < res.AffectedGroups[j].GroupID })
+
+	return res
+}
@@ -0,0 +1,43 @@
+package owl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewPartitionIncreaseImpact(t *testing.T) {
+	groups := []*ConsumerGroupOverview{
+		{
+			// Hasn't rebalanced since the topic has been increased from 3 to 4 partitions
+			GroupID: "shipping",
+			State:   "Stable",
+			Members: []*GroupMemberDescription{
+				{ID: "a", Assignments: []*GroupMemberAssignment{{TopicName: "orders", PartitionIDs: []int32{0, 1}}}},
+				{ID: "b", Assignments: []*GroupMemberAssignment{{TopicName: "orders", PartitionIDs: []int32{2}}}},
+			},
+		},
+		{
+			GroupID: "billing",
+			State:   "Stable",
+			Members: []*GroupMemberDescription{
+				{ID: "c", Assignments: []*GroupMemberAssignment{{TopicName: "orders", PartitionIDs: []int32{0, 1, 2, 3}}}},
+			},
+		},
+		{
+			GroupID: "payments",
+			State:   "Stable",
+			Members: []*GroupMemberDescription{
+				{ID: "d", Assignments: []*GroupMemberAssignment{{TopicName: "payments", PartitionIDs: []int32{3}}}},
+			},
+		},
+		{GroupID: "empty-group", State: "Empty"},
+	}
+
+	res := newPartitionIncreaseImpact("orders", 3, []int32{3, 2, 1, 0}, groups)
+	assert.Equal(t, 4, res.PartitionCount)
+	assert.Equal(t, []int32{3}, res.NewPartitionIDs)
+	assert.Equal(t, []*PartitionIncreaseGroupImpact{
+		{GroupID: "shipping", State: "Stable", UnassignedPartitionIDs: []int32{3}},
+	}, res.AffectedGroups)
+}
@@ -1,6 +1,10 @@
 package owl
 
-import "fmt"
+import (
+	"fmt"
+
+	"github.com/cloudhut/kowl/backend/pkg/kafka"
+)
 
 // TopicPartition consists of some (not all) information about a partition of a topic.
 // Only data relevant to the 'partition table' in the frontend is included.
@@ -23,6 +27,27 @@ func (s *Service) ListTopicPartitions(topicName string) ([]TopicPartition, error
 		return nil, err
 	}
 
+	return convertWaterMarks(partitions, waterMarks), nil
+}
+
+// ListTopicPartitionsFromReplica returns the partitions in the topic along with their watermarks as seen by the
+// given replica broker. Partitions that are not hosted by this broker report the leader's watermarks.
+func (s *Service) ListTopicPartitionsFromReplica(topicName string, brokerID int32) ([]TopicPartition, error) {
+	partitions, err := s.kafkaSvc.ListPartitions(topicName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get partitions for topic '%v': %v", topicName, err)
+	}
+
+	waterMarks, err := s.kafkaSvc.WaterMarksFromReplica(topicName, partitions, brokerID)
+	if err != nil {
+		return nil, err
+	}
+
+	return convertWaterMarks(partitions, waterMarks), nil
+}
+
+func convertWaterMarks(partitions []int32, waterMarks map[int32]*kafka.WaterMark) []TopicPartition {
+
 	// Create result array
 	topicPartitions := make([]TopicPartition, len(partitions))
 	for i, p := range waterMarks {
@@ -30,5 +55,5 @@ func (s *Service) ListTopicPartitions(topicName string) ([]TopicPartition, error
 		topicPartitions[i] = TopicPartition{ID: p.PartitionID, WaterMarkLow: w.Low, WaterMarkHigh: w.High}
 	}
 
-	return topicPartitions, nil
+	return topicPartitions
 }
@@ -0,0 +1,92 @@
+package owl
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/Shopify/sarama"
+)
+
+// TopicRackDistribution describes how the replicas of each partition are spread across the broker racks
+type TopicRackDistribution struct {
+	TopicName           string                       `json:"topicName"`
+	IsRackInfoAvailable bool                         `json:"isRackInfoAvailable"`
+	Error               string                       `json:"error,omitempty"` // E.g. "rack info unavailable"
+	SingleRackCount     int                          `json:"singleRackCount"` // Number of partitions at risk
+	Partitions          []*PartitionRackDistribution `json:"partitions"`
+}
+
+// PartitionRackDistribution is a partition along with its replicas bucketed by rack
+type PartitionRackDistribution struct {
+	PartitionID    int32              `json:"partitionId"`
+	ReplicasByRack map[string][]int32 `json:"replicasByRack"` // Brokers without a rack are listed with an empty rack
+	IsSingleRack   bool               `json:"isSingleRack"`   // True if all replicas are located in the same rack
+}
+
+// GetTopicRackDistribution reports the rack distribution of a topic's replicas and flags all partitions whose
+// replicas are all located in a single rack even though the cluster spans multiple racks.
+func (s *Service) GetTopicRackDistribution(ctx context.Context, topicName string) (*TopicRackDistribution, error) {
+	cluster, err := s.kafkaSvc.DescribeCluster()
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe cluster: %w", err)
+	}
+
+	topics, err := s.kafkaSvc.DescribeTopics([]string{topicName})
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe topic: %w", err)
+	}
+	if len(topics) != 1 {
+		return nil, fmt.Errorf("expected metadata for one topic, but got %v", len(topics))
+	}
+	if topics[0].Err != sarama.ErrNoError {
+		return nil, fmt.Errorf("failed to describe topic: %w", topics[0].Err)
+	}
+
+	rackByBrokerID := make(map[int32]string, len(cluster.Brokers))
+	for _, broker := range cluster.Brokers {
+		rackByBrokerID[broker.ID()] = broker.Rack()
+	}
+
+	return newTopicRackDistribution(topics[0], rackByBrokerID), nil
+}
+
+func newTopicRackDistribution(topic *sarama.TopicMetadata, rackByBrokerID map[int32]string) *TopicRackDistribution {
+	res := &TopicRackDistribution{
+		TopicName:  topic.Name,
+		Partitions: make([]*PartitionRackDistribution, 0, len(topic.Partitions)),
+	}
+
+	clusterRacks := make(map[string]struct{})
+	for _, rack := range rackByBrokerID {
+		if rack != "" {
+			clusterRacks[rack] = struct{}{}
+		}
+	}
+	if len(clusterRacks) == 0 {
+		res.Error = "rack info unavailable"
+		return res
+	}
+	res.IsRackInfoAvailable = true
+
+	for _, partition := range topic.Partitions {
+		replicasByRack := make(map[string][]int32)
+		for _, brokerID := range partition.Replicas {
+			rack := rackByBrokerID[brokerID]
+			replicasByRack[rack] = append(replicasByRack[rack], brokerID)
+		}
+
+		p := &PartitionRackDistribution{
+			PartitionID:    partition.ID,
+			ReplicasByRack: replicasByRack,
+			IsSingleRack:   len(replicasByRack) == 1 && len(clusterRacks) > 1,
+		}
+		if p.IsSingleRack {
+			res.SingleRackCount++
+		}
+		res.Partitions = append(res.Partitions, p)
+	}
+	sort.Slice(res.Partitions, func(i, j int) bool { return res.Partitions[i].PartitionID < res.Partitions[j].PartitionID })
+
+	return res
+}
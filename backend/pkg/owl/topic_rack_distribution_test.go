@@ -0,0 +1,30 @@
+package owl
+
+import (
+	"testing"
+
+	"github.com/Shopify/sarama"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewTopicRackDistribution(t *testing.T) {
+	topic := &sarama.TopicMetadata{
+		Name: "orders",
+		Partitions: []*sarama.PartitionMetadata{
+			{ID: 0, Replicas: []int32{1, 2, 3}},
+			{ID: 1, Replicas: []int32{1, 4}},
+		},
+	}
+	racks := map[int32]string{1: "eu-west-1a", 2: "eu-west-1b", 3: "eu-west-1c", 4: "eu-west-1a"}
+
+	distribution := newTopicRackDistribution(topic, racks)
+	assert.True(t, distribution.IsRackInfoAvailable)
+	assert.Equal(t, 1, distribution.SingleRackCount)
+	assert.False(t, distribution.Partitions[0].IsSingleRack)
+	assert.True(t, distribution.Partitions[1].IsSingleRack)
+	assert.Equal(t, map[string][]int32{"eu-west-1a": {1, 4}}, distribution.Partitions[1].ReplicasByRack)
+
+	noRacks := newTopicRackDistribution(topic, map[int32]string{1: "", 2: "", 3: "", 4: ""})
+	assert.False(t, noRacks.IsRackInfoAvailable)
+	assert.Equal(t, "rack info unavailable", noRacks.Error)
+}
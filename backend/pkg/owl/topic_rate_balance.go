@@ -0,0 +1,80 @@
+package owl
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// TopicRateBalance compares the produce rate of a topic with the consume rate of a consumer group.
+type TopicRateBalance struct {
+	Topic       string  `json:"topic"`
+	ProduceRate float64 `json:"produceRate"` // Messages per second, based on the high water mark deltas
+	ConsumeRate float64 `json:"consumeRate"` // Messages per second, based on the committed offset deltas
+
+	// Balance is ConsumeRate - ProduceRate. A negative balance means the group falls behind, even if the current lag
+	// is still low, while a positive balance means the group is catching up.
+	Balance    float64 `json:"balance"`
+	CurrentLag int64   `json:"currentLag"`
+	WindowMs   int64   `json:"windowMs"` // Time span between the oldest and newest sample that have been compared
+}
+
+// GetConsumerGroupRateBalance returns the produce vs consume rate balance for all topics the group has committed offsets
+// for. The rates are calculated from the lag samples which have been recorded within the given window.
+func (s *Service) GetConsumerGroupRateBalance(groupID string, window time.Duration) ([]*TopicRateBalance, error) {
+	if s.lagHistory == nil {
+		return nil, ErrLagHistoryDisabled
+	}
+
+	samples := s.lagHistory.get(groupID, time.Now().Add(-window))
+	if len(samples) < 2 {
+		return nil, fmt.Errorf("at least two lag samples are required within the window, but got %v", len(samples))
+	}
+
+	return calculateRateBalance(samples[0], samples[len(samples)-1]), nil
+}
+
+// calculateRateBalance compares the oldest and newest sample. Only partitions which are part of both samples are
+// considered, so that newly consumed partitions don't distort the rates.
+func calculateRateBalance(oldest *LagSample, newest *LagSample) []*TopicRateBalance {
+	seconds := newest.Timestamp.Sub(oldest.Timestamp).Seconds()
+	res := make([]*TopicRateBalance, 0, len(newest.Lag.TopicLags))
+	if seconds <= 0 {
+		return res
+	}
+
+	for _, newTopicLag := range newest.Lag.TopicLags {
+		oldTopicLag := oldest.Lag.GetTopicLag(newTopicLag.Topic)
+		if oldTopicLag == nil {
+			continue
+		}
+		oldPartitions := make(map[int32]PartitionLag, len(oldTopicLag.PartitionLags))
+		for _, p := range oldTopicLag.PartitionLags {
+			oldPartitions[p.PartitionID] = p
+		}
+
+		var produced, consumed int64
+		for _, p := range newTopicLag.PartitionLags {
+			old, exists := oldPartitions[p.PartitionID]
+			if !exists {
+				continue
+			}
+			produced += p.HighWaterMark - old.HighWaterMark
+			consumed += p.CommittedOffset - old.CommittedOffset
+		}
+
+		produceRate := float64(produced) / seconds
+		consumeRate := float64(consumed) / seconds
+		res = append(res, &TopicRateBalance{
+			Topic:       newTopicLag.Topic,
+			ProduceRate: produceRate,
+			ConsumeRate: consumeRate,
+			Balance:     consumeRate - produceRate,
+			CurrentLag:  newTopicLag.SummedLag,
+			WindowMs:    newest.Timestamp.Sub(oldest.Timestamp).Milliseconds(),
+		})
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Topic < res[j].Topic })
+
+	return res
+}
@@ -0,0 +1,50 @@
+package owl
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newRateBalanceSample(ts time.Time, topic string, highWaterMark int64, committedOffset int64) *LagSample {
+	return &LagSample{
+		Timestamp: ts,
+		Lag: &ConsumerGroupLag{
+			GroupID: "billing",
+			TopicLags: []*TopicLag{{
+				Topic:     topic,
+				SummedLag: highWaterMark - committedOffset,
+				PartitionLags: []PartitionLag{
+					{PartitionID: 0, Lag: highWaterMark - committedOffset, HighWaterMark: highWaterMark, CommittedOffset: committedOffset},
+				},
+			}},
+		},
+	}
+}
+
+func TestCalculateRateBalance(t *testing.T) {
+	start := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+	end := start.Add(10 * time.Second)
+
+	// Producer writes 100 msg/s, consumer reads 50 msg/s
+	fallingBehind := calculateRateBalance(
+		newRateBalanceSample(start, "orders", 1000, 990),
+		newRateBalanceSample(end, "orders", 2000, 1490),
+	)
+	require.Len(t, fallingBehind, 1)
+	assert.Equal(t, 100.0, fallingBehind[0].ProduceRate)
+	assert.Equal(t, 50.0, fallingBehind[0].ConsumeRate)
+	assert.Equal(t, -50.0, fallingBehind[0].Balance)
+	assert.Equal(t, int64(10000), fallingBehind[0].WindowMs)
+
+	// Producer writes 10 msg/s, consumer catches up with 60 msg/s
+	catchingUp := calculateRateBalance(
+		newRateBalanceSample(start, "orders", 1000, 0),
+		newRateBalanceSample(end, "orders", 1100, 600),
+	)
+	require.Len(t, catchingUp, 1)
+	assert.Equal(t, 50.0, catchingUp[0].Balance)
+	assert.Equal(t, int64(500), catchingUp[0].CurrentLag)
+}
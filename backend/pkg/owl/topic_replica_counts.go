@@ -0,0 +1,88 @@
+package owl
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/Shopify/sarama"
+)
+
+// TopicReplicaCounts compares the replica and in-sync replica counts of each partition against the topic's
+// replication factor
+type TopicReplicaCounts struct {
+	TopicName string `json:"topicName"`
+
+	// ReplicationFactor is the replica count most of the partitions have. Kafka doesn't store a topic's replication
+	// factor, hence it's derived from the partitions, which may deviate after failed reassignments.
+	ReplicationFactor int                      `json:"replicationFactor"`
+	FlaggedPartitions int                      `json:"flaggedPartitions"`
+	Partitions        []*PartitionReplicaCount `json:"partitions"` // Sorted by partition id
+}
+
+// PartitionReplicaCount is the expected, actual and in-sync replica count of a partition
+type PartitionReplicaCount struct {
+	PartitionID      int32 `json:"partitionId"`
+	ExpectedReplicas int   `json:"expectedReplicas"`
+	ActualReplicas   int   `json:"actualReplicas"`
+	InSyncReplicas   int   `json:"inSyncReplicas"`
+
+	// IsUnderReplicated is true if fewer replicas than the replication factor are in sync
+	IsUnderReplicated bool `json:"isUnderReplicated"`
+	// IsMissingReplicas is true if the partition has fewer replicas than the replication factor at all
+	IsMissingReplicas bool `json:"isMissingReplicas"`
+}
+
+// GetTopicReplicaCounts returns per partition the expected, actual and in-sync replica count of the given topic and
+// flags partitions which are under-replicated or have fewer replicas than the topic's replication factor.
+func (s *Service) GetTopicReplicaCounts(_ context.Context, topicName string) (*TopicReplicaCounts, error) {
+	metadata, err := s.kafkaSvc.DescribeTopics([]string{topicName})
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe topic: %w", err)
+	}
+	if len(metadata) != 1 {
+		return nil, fmt.Errorf("expected exactly one topic metadata, but got %d", len(metadata))
+	}
+	if metadata[0].Err != sarama.ErrNoError {
+		return nil, fmt.Errorf("failed to describe topic '%v': %w", topicName, metadata[0].Err)
+	}
+
+	return newTopicReplicaCounts(metadata[0]), nil
+}
+
+func newTopicReplicaCounts(topic *sarama.TopicMetadata) *TopicReplicaCounts {
+	// The most common replica count is expected, ties are resolved in favour of the higher count
+	occurrences := make(map[int]int)
+	for _, partition := range topic.Partitions {
+		occurrences[len(partition.Replicas)]++
+	}
+	expected := 0
+	for count, n := range occurrences {
+		if n > occurrences[expected] || (n == occurrences[expected] && count > expected) {
+			expected = count
+		}
+	}
+
+	res := &TopicReplicaCounts{
+		TopicName:         topic.Name,
+		ReplicationFactor: expected,
+		Partitions:        make([]*PartitionReplicaCount, 0, len(topic.Partitions)),
+	}
+	for _, partition := range topic.Partitions {
+		p := &PartitionReplicaCount{
+			PartitionID:       partition.ID,
+			ExpectedReplicas:  expected,
+			ActualReplicas:    len(partition.Replicas),
+			InSyncReplicas:    len(partition.Isr),
+			IsUnderReplicated: len(partition.Isr) < expected,
+			IsMissingReplicas: len(partition.Replicas) < expected,
+		}
+		if p.IsUnderReplicated || p.IsMissingReplicas {
+			res.FlaggedPartitions++
+		}
+		res.Partitions = append(res.Partitions, p)
+	}
+	sort.Slice(res.Partitions, func(i, j int) bool { return res.Partitions[i].PartitionID < res.Partitions[j].PartitionID })
+
+	return res
+}
@@ -0,0 +1,28 @@
+package owl
+
+import (
+	"testing"
+
+	"github.com/Shopify/sarama"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewTopicReplicaCounts(t *testing.T) {
+	topic := &sarama.TopicMetadata{
+		Name: "orders",
+		Partitions: []*sarama.PartitionMetadata{
+			{ID: 2, Replicas: []int32{3, 1}, Isr: []int32{3, 1}}, // Left behind by a failed reassignment
+			{ID: 0, Replicas: []int32{1, 2, 3}, Isr: []int32{1, 2, 3}},
+			{ID: 1, Replicas: []int32{2, 3, 1}, Isr: []int32{2}},
+		},
+	}
+
+	res := newTopicReplicaCounts(topic)
+	assert.Equal(t, 3, res.ReplicationFactor)
+	assert.Equal(t, 2, res.FlaggedPartitions)
+	assert.Equal(t, []*PartitionReplicaCount{
+		{PartitionID: 0, ExpectedReplicas: 3, ActualReplicas: 3, InSyncReplicas: 3},
+		{PartitionID: 1, ExpectedReplicas: 3, ActualReplicas: 3, InSyncReplicas: 1, IsUnderReplicated: true},
+		{PartitionID: 2, ExpectedReplicas: 3, ActualReplicas: 2, InSyncReplicas: 2, IsUnderReplicated: true, IsMissingReplicas: true},
+	}, res.Partitions)
+}
@@ -0,0 +1,311 @@
+package owl
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/Shopify/sarama"
+	"github.com/cloudhut/kowl/backend/pkg/kafka"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultScanBudget int64 = 10000
+	maxScanBudget     int64 = 100000
+)
+
+// ScanTopicRequest scans a chunk of a topic for messages which pass the filter code. The scan is started by
+// passing an empty cursor and continued by passing the cursor of the previous response.
+type ScanTopicRequest struct {
+	TopicName             string
+	FilterInterpreterCode string
+	Cursor                string // Empty to start a new scan
+	Budget                int64  // Number of offsets to scan in this call (0 = default, at most 100k)
+
+	// stopAtFirstMatch cancels the scan of the chunk once a message has matched
+	stopAtFirstMatch bool
+}
+
+// ScanTopicResponse contains all matches found in the scanned chunk along with the cursor for the next chunk
+type ScanTopicResponse struct {
+	Matches        []*kafka.TopicMessage `json:"matches"`
+	Cursor         string                `json:"cursor"`
+	IsComplete     bool                  `json:"isComplete"`
+	ScannedOffsets int64                 `json:"scannedOffsets"`
+	TotalOffsets   int64                 `json:"totalOffsets"`
+}
+
+// topicScanCursor tracks the progress of a scan. The end of each partition is fixed at the high water mark at
+// the time the scan has been started, so that the scan terminates even if new messages are produced.
+type topicScanCursor struct {
+	Partitions map[int32]*partitionScanPosition `json:"partitions"`
+}
+
+type partitionScanPosition struct {
+	Start int64 `json:"start"`
+	Next  int64 `json:"next"`
+	End   int64 `json:"end"` // Exclusive
+}
+
+// scanRange is a range of offsets [From, To) which is scanned on a single partition
+type scanRange struct {
+	PartitionID int32
+	From        int64
+	To          int64
+	MaxMessages int64 // Optional, stop after this many messages instead of scanning the whole range
+}
+
+// ScanTopic scans the next chunk of a topic and returns all messages which pass the filter code. Repeated calls
+// with the returned cursor scan each offset exactly once until the response is complete. Offsets which have been
+// deleted by retention in the meantime are considered as scanned.
+func (s *Service) ScanTopic(ctx context.Context, req ScanTopicRequest) (*ScanTopicResponse, error) {
+	cursor, err := s.resolveTopicScanCursor(req)
+	if err != nil {
+		return nil, err
+	}
+
+	budget := scanBudget(req.Budget)
+	chunk := cursor.nextChunk(budget)
+	matches, err := s.scanChunk(ctx, req, chunk)
+	if err != nil {
+		return nil, err
+	}
+	cursor.advance(chunk)
+
+	encodedCursor, err := cursor.encode()
+	if err != nil {
+		return nil, err
+	}
+	scanned, total := cursor.progress()
+
+	return &ScanTopicResponse{
+		Matches:        matches,
+		Cursor:         encodedCursor,
+		IsComplete:     scanned == total,
+		ScannedOffsets: scanned,
+		TotalOffsets:   total,
+	}, nil
+}
+
+// scanChunk consumes all given offset ranges concurrently and returns all messages which pass the filter code
+func (s *Service) scanChunk(ctx context.Context, req ScanTopicRequest, chunk []*scanRange) ([]*kafka.TopicMessage, error) {
+	if len(chunk) == 0 {
+		return make([]*kafka.TopicMessage, 0), nil
+	}
+
+	consumer, err := sarama.NewConsumerFromClient(s.kafkaSvc.Client)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't create consumer: %w", err)
+	}
+	defer consumer.Close()
+
+	return s.consumeScanChunk(ctx, consumer, req, chunk)
+}
+
+// consumeScanChunk consumes the offset ranges with the given consumer. If the request stops at the first match, all
+// partition consumers are cancelled as soon as one of them has found a match and only that match is returned.
+func (s *Service) consumeScanChunk(ctx context.Context, consumer sarama.Consumer, req ScanTopicRequest, chunk []*scanRange) ([]*kafka.TopicMessage, error) {
+	matches := make([]*kafka.TopicMessage, 0)
+	collector := &messageCollector{messages: make([]*kafka.TopicMessage, 0)}
+	doneCh := make(chan struct{}, len(chunk))
+	messageCh := make(chan *kafka.TopicMessage)
+	childCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	for _, r := range chunk {
+		maxMessages := r.To - r.From
+		if r.MaxMessages > 0 {
+			maxMessages = r.MaxMessages
+		}
+		if req.stopAtFirstMatch {
+			maxMessages = 1
+		}
+		pConsumer := kafka.PartitionConsumer{
+			Logger:    s.logger.With(zap.String("topic", req.TopicName), zap.Int32("partition_id", r.PartitionID)),
+			DoneCh:    doneCh,
+			MessageCh: messageCh,
+			Progress:  collector,
+			Consumer:  consumer,
+			TopicName: req.TopicName,
+			Req: &kafka.PartitionConsumeRequest{
+				PartitionID:     r.PartitionID,
+				StartOffset:     r.From,
+				EndOffset:       r.To - 1,
+				MaxMessageCount: maxMessages,
+			},
+			FilterInterpreterCode: req.FilterInterpreterCode,
+			SchemaService:         s.kafkaSvc.SchemaService,
+			WireFormat:            s.wireFormats[req.TopicName],
+			InnerCompression:      s.innerCodecs[req.TopicName],
+			Decoders:              s.decoders,
+			Processors:            s.processors.forTopic(req.TopicName),
+			LocateCorruptedBatch:  s.corruptedBatchLocator(req.TopicName, r.PartitionID),
+		}
+		go pConsumer.Run(childCtx)
+	}
+
+	// Partition consumers only stop once they've seen a message beyond their end offset, which can happen to be
+	// part of the next chunk if offsets are missing due to compaction.
+	endOffsets := make(map[int32]int64, len(chunk))
+	for _, r := range chunk {
+		endOffsets[r.PartitionID] = r.To
+	}
+
+	completedWorkers := 0
+	for completedWorkers < len(chunk) {
+		select {
+		case msg := <-messageCh:
+			if msg.Offset >= endOffsets[msg.PartitionID] || (req.stopAtFirstMatch && len(matches) > 0) {
+				continue
+			}
+			matches = append(matches, msg)
+			if req.stopAtFirstMatch {
+				// The other partition consumers are still awaited, so that the consumer isn't closed while they run
+				cancel()
+			}
+		case <-doneCh:
+			completedWorkers++
+		case <-ctx.Done():
+			return nil, fmt.Errorf("scan has been cancelled: %w", ctx.Err())
+		}
+	}
+
+	collector.mutex.Lock()
+	defer collector.mutex.Unlock()
+	if collector.errMsg != "" {
+		return nil, fmt.Errorf("failed to scan topic: %v", collector.errMsg)
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].PartitionID == matches[j].PartitionID {
+			return matches[i].Offset < matches[j].Offset
+		}
+		return matches[i].PartitionID < matches[j].PartitionID
+	})
+	return matches, nil
+}
+
+// scanBudget returns the number of offsets to scan in a single call, based on the requested budget
+func scanBudget(budget int64) int64 {
+	if budget <= 0 {
+		return defaultScanBudget
+	}
+	if budget > maxScanBudget {
+		return maxScanBudget
+	}
+	return budget
+}
+
+// resolveTopicScanCursor returns a new cursor for the topic if the request doesn't continue a scan, otherwise the
+// decoded cursor of the request moved past the offsets which have been deleted in the meantime
+func (s *Service) resolveTopicScanCursor(req ScanTopicRequest) (*topicScanCursor, error) {
+	partitionIDs, err := s.kafkaSvc.ListPartitions(req.TopicName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get partitions: %w", err)
+	}
+	marks, err := s.kafkaSvc.WaterMarks(req.TopicName, partitionIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get watermarks: %w", err)
+	}
+
+	if req.Cursor == "" {
+		return newTopicScanCursor(marks), nil
+	}
+	cursor, err := decodeTopicScanCursor(req.Cursor)
+	if err != nil {
+		return nil, err
+	}
+	cursor.skipDeletedOffsets(marks)
+	return cursor, nil
+}
+
+func newTopicScanCursor(marks map[int32]*kafka.WaterMark) *topicScanCursor {
+	cursor := &topicScanCursor{Partitions: make(map[int32]*partitionScanPosition, len(marks))}
+	for pID, mark := range marks {
+		cursor.Partitions[pID] = &partitionScanPosition{Start: mark.Low, Next: mark.Low, End: mark.High}
+	}
+	return cursor
+}
+
+func decodeTopicScanCursor(encoded string) (*topicScanCursor, error) {
+	decoded, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode scan cursor: %w", err)
+	}
+	cursor := &topicScanCursor{}
+	if err := json.Unmarshal(decoded, cursor); err != nil {
+		return nil, fmt.Errorf("failed to decode scan cursor: %w", err)
+	}
+	return cursor, nil
+}
+
+func (c *topicScanCursor) encode() (string, error) {
+	encoded, err := json.Marshal(c)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode scan cursor: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(encoded), nil
+}
+
+// skipDeletedOffsets moves the cursor forward on partitions whose oldest offsets have been deleted meanwhile
+func (c *topicScanCursor) skipDeletedOffsets(marks map[int32]*kafka.WaterMark) {
+	for pID, pos := range c.Partitions {
+		mark, exists := marks[pID]
+		if !exists || pos.Next >= mark.Low {
+			continue
+		}
+		pos.Next = mark.Low
+		if pos.Next > pos.End {
+			pos.Next = pos.End
+		}
+	}
+}
+
+// nextChunk splits the budget evenly across all partitions that have not been fully scanned yet
+func (c *topicScanCursor) nextChunk(budget int64) []*scanRange {
+	pending := make([]int32, 0, len(c.Partitions))
+	for pID, pos := range c.Partitions {
+		if pos.Next < pos.End {
+			pending = append(pending, pID)
+		}
+	}
+	sort.Slice(pending, func(i, j int) bool { return pending[i] < pending[j] })
+
+	chunk := make([]*scanRange, 0, len(pending))
+	for i, pID := range pending {
+		if budget <= 0 {
+			break
+		}
+		share := budget / int64(len(pending)-i)
+		if share < 1 {
+			share = 1
+		}
+
+		pos := c.Partitions[pID]
+		to := pos.Next + share
+		if to > pos.End {
+			to = pos.End
+		}
+		chunk = append(chunk, &scanRange{PartitionID: pID, From: pos.Next, To: to})
+		budget -= to - pos.Next
+	}
+
+	return chunk
+}
+
+func (c *topicScanCursor) advance(chunk []*scanRange) {
+	for _, r := range chunk {
+		c.Partitions[r.PartitionID].Next = r.To
+	}
+}
+
+func (c *topicScanCursor) progress() (scanned int64, total int64) {
+	for _, pos := range c.Partitions {
+		scanned += pos.Next - pos.Start
+		total += pos.End - pos.Start
+	}
+	return scanned, total
+}
@@ -0,0 +1,73 @@
+package owl
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Shopify/sarama"
+	"github.com/cloudhut/kowl/backend/pkg/kafka"
+)
+
+// FirstMatchResponse contains the first message found which passes the filter code, or the progress of the search
+// if no message within the budget has matched
+type FirstMatchResponse struct {
+	IsFound bool                `json:"isFound"`
+	Match   *kafka.TopicMessage `json:"match,omitempty"` // Its partition and offset locate the match
+
+	// Cursor continues the search with the next chunk, it's only set if no message has matched and the topic has not
+	// been searched completely yet
+	Cursor     string `json:"cursor,omitempty"`
+	IsComplete bool   `json:"isComplete"`
+
+	// ScannedOffsets doesn't include the chunk in which the match has been found, because its partitions are
+	// cancelled at different offsets
+	ScannedOffsets int64 `json:"scannedOffsets"`
+	TotalOffsets   int64 `json:"totalOffsets"`
+}
+
+// FindFirstMatch answers whether any message passes the filter code. Unlike ScanTopic, which returns all matches of
+// the scanned chunk, all partitions stop as soon as the first match has been found. If no message matches, at most
+// the budget of offsets is scanned; the returned cursor continues the search where it has stopped.
+func (s *Service) FindFirstMatch(ctx context.Context, req ScanTopicRequest) (*FirstMatchResponse, error) {
+	cursor, err := s.resolveTopicScanCursor(req)
+	if err != nil {
+		return nil, err
+	}
+
+	consumer, err := sarama.NewConsumerFromClient(s.kafkaSvc.Client)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't create consumer: %w", err)
+	}
+	defer consumer.Close()
+
+	return s.findFirstMatch(ctx, consumer, req, cursor)
+}
+
+func (s *Service) findFirstMatch(ctx context.Context, consumer sarama.Consumer, req ScanTopicRequest, cursor *topicScanCursor) (*FirstMatchResponse, error) {
+	req.stopAtFirstMatch = true
+	chunk := cursor.nextChunk(scanBudget(req.Budget))
+	if len(chunk) > 0 {
+		matches, err := s.consumeScanChunk(ctx, consumer, req, chunk)
+		if err != nil {
+			return nil, err
+		}
+		if len(matches) > 0 {
+			scanned, total := cursor.progress()
+			return &FirstMatchResponse{IsFound: true, Match: matches[0], ScannedOffsets: scanned, TotalOffsets: total}, nil
+		}
+		cursor.advance(chunk)
+	}
+
+	res := &FirstMatchResponse{}
+	res.ScannedOffsets, res.TotalOffsets = cursor.progress()
+	res.IsComplete = res.ScannedOffsets == res.TotalOffsets
+	if !res.IsComplete {
+		encodedCursor, err := cursor.encode()
+		if err != nil {
+			return nil, err
+		}
+		res.Cursor = encodedCursor
+	}
+
+	return res, nil
+}
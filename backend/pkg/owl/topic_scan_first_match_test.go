@@ -0,0 +1,81 @@
+package owl
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/Shopify/sarama"
+	"github.com/Shopify/sarama/mocks"
+	"github.com/cloudhut/kowl/backend/pkg/kafka"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// unconsumedCountingConsumer records the number of messages which haven't been consumed when the partition consumer
+// is closed, because the mock drains them on close
+type unconsumedCountingConsumer struct {
+	*mocks.Consumer
+	unconsumed int
+}
+
+type unconsumedCountingPartitionConsumer struct {
+	sarama.PartitionConsumer
+	parent *unconsumedCountingConsumer
+}
+
+func (c *unconsumedCountingConsumer) ConsumePartition(topic string, partition int32, offset int64) (sarama.PartitionConsumer, error) {
+	pConsumer, err := c.Consumer.ConsumePartition(topic, partition, offset)
+	if err != nil {
+		return nil, err
+	}
+	return &unconsumedCountingPartitionConsumer{PartitionConsumer: pConsumer, parent: c}, nil
+}
+
+func (p *unconsumedCountingPartitionConsumer) Close() error {
+	p.parent.unconsumed = len(p.Messages())
+	return p.PartitionConsumer.Close()
+}
+
+func TestFindFirstMatch(t *testing.T) {
+	svc := &Service{kafkaSvc: &kafka.Service{}, logger: zap.NewNop()}
+	marks := map[int32]*kafka.WaterMark{0: {PartitionID: 0, Low: 0, High: 1000}}
+	req := ScanTopicRequest{TopicName: "orders", FilterInterpreterCode: "return value.id == 3", Budget: 50}
+
+	// The mock consumer assigns the offsets 1, 2, 3 ... to the yielded messages
+	newConsumer := func(ids int) *unconsumedCountingConsumer {
+		consumer := mocks.NewConsumer(t, sarama.NewConfig())
+		pConsumer := consumer.ExpectConsumePartition("orders", 0, 0)
+		for id := 1; id <= ids; id++ {
+			pConsumer.YieldMessage(&sarama.ConsumerMessage{Value: []byte(fmt.Sprintf(`{"id":%d}`, id))})
+		}
+		return &unconsumedCountingConsumer{Consumer: consumer}
+	}
+
+	// The scan stops at the first match, the following messages are not consumed anymore
+	consumer := newConsumer(100)
+	res, err := svc.findFirstMatch(context.Background(), consumer, req, newTopicScanCursor(marks))
+	require.NoError(t, err)
+	assert.True(t, res.IsFound)
+	require.NotNil(t, res.Match)
+	assert.Equal(t, int32(0), res.Match.PartitionID)
+	assert.Equal(t, int64(3), res.Match.Offset)
+	assert.Equal(t, 97, consumer.unconsumed)
+	assert.Empty(t, res.Cursor)
+	require.NoError(t, consumer.Close())
+
+	// Without a match the scan stops once the budget of 50 offsets has been scanned
+	req.FilterInterpreterCode = "return value.id == 500"
+	consumer = newConsumer(100)
+	res, err = svc.findFirstMatch(context.Background(), consumer, req, newTopicScanCursor(marks))
+	require.NoError(t, err)
+	assert.False(t, res.IsFound)
+	assert.Nil(t, res.Match)
+	assert.Equal(t, int64(50), res.ScannedOffsets)
+	assert.Equal(t, int64(1000), res.TotalOffsets)
+	assert.False(t, res.IsComplete)
+	assert.NotEmpty(t, res.Cursor)
+	assert.Equal(t, 51, consumer.unconsumed) // Offsets 50 to 100 are beyond the budget
+	require.NoError(t, consumer.Close())
+}
@@ -0,0 +1,57 @@
+package owl
+
+import (
+	"testing"
+
+	"github.com/cloudhut/kowl/backend/pkg/kafka"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTopicScanCursor_CoversTopicExactlyOnce(t *testing.T) {
+	marks := map[int32]*kafka.WaterMark{
+		0: {PartitionID: 0, Low: 0, High: 1000},
+		1: {PartitionID: 1, Low: 250, High: 300},
+		2: {PartitionID: 2, Low: 40, High: 40}, // Empty partition
+	}
+	cursor := newTopicScanCursor(marks)
+
+	scannedCount := make(map[int32]map[int64]int)
+	var lastScanned int64
+	calls := 0
+	for {
+		encoded, err := cursor.encode()
+		require.NoError(t, err)
+		cursor, err = decodeTopicScanCursor(encoded)
+		require.NoError(t, err)
+
+		chunk := cursor.nextChunk(300)
+		for _, r := range chunk {
+			if scannedCount[r.PartitionID] == nil {
+				scannedCount[r.PartitionID] = make(map[int64]int)
+			}
+			for offset := r.From; offset < r.To; offset++ {
+				scannedCount[r.PartitionID][offset]++
+			}
+		}
+		cursor.advance(chunk)
+		calls++
+
+		scanned, total := cursor.progress()
+		assert.Equal(t, int64(1050), total)
+		assert.True(t, scanned > lastScanned || scanned == total)
+		lastScanned = scanned
+		if scanned == total {
+			break
+		}
+		require.Less(t, calls, 10, "scan must complete")
+	}
+
+	assert.Equal(t, 4, calls)
+	for pID, mark := range marks {
+		assert.Len(t, scannedCount[pID], int(mark.High-mark.Low))
+		for offset, count := range scannedCount[pID] {
+			assert.Equal(t, 1, count, "offset %v of partition %v must be scanned exactly once", offset, pID)
+		}
+	}
+}
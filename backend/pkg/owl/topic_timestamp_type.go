@@ -0,0 +1,31 @@
+package owl
+
+import (
+	"fmt"
+
+	"github.com/cloudhut/kowl/backend/pkg/kafka"
+)
+
+const configTimestampType = "message.timestamp.type"
+
+// getTopicTimestampType returns the topic's message.timestamp.type (CreateTime or LogAppendTime)
+func (s *Service) getTopicTimestampType(topicName string) (string, error) {
+	configs, err := s.GetTopicConfigs(topicName, []string{configTimestampType})
+	if err != nil {
+		return "", fmt.Errorf("failed to get topic configs: %w", err)
+	}
+
+	return timestampTypeFromConfigs(configs), nil
+}
+
+// timestampTypeFromConfigs returns the configured timestamp type, which defaults to CreateTime if not set
+func timestampTypeFromConfigs(configs *TopicConfigs) string {
+	if configs == nil {
+		return kafka.TimestampTypeCreateTime
+	}
+	entry := configs.GetConfigEntryByName(configTimestampType)
+	if entry == nil || entry.Value == "" {
+		return kafka.TimestampTypeCreateTime
+	}
+	return entry.Value
+}
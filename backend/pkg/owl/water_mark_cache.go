@@ -0,0 +1,251 @@
+package owl
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"go.uber.org/zap"
+)
+
+// waterMarkWarmupChunkSize is the number of topics whose high water marks are fetched at once during the warmup,
+// so that a cancellation is noticed between chunks
+const waterMarkWarmupChunkSize = 50
+
+// WaterMarkCacheConfig for caching the partitions' high water marks which are required to calculate group lags
+type WaterMarkCacheConfig struct {
+	Enabled bool          `yaml:"enabled"`
+	TTL     time.Duration `yaml:"ttl"` // How long cached high water marks are served before they are fetched again
+
+	// Warmup pre-fetches the high water marks of the WarmupTopics (all topics if empty) in the background on
+	// startup, so that the first lag request doesn't have to fetch them.
+	Warmup       bool     `yaml:"warmup"`
+	WarmupTopics []string `yaml:"warmupTopics"`
+}
+
+// SetDefaults for the water mark cache config
+func (c *WaterMarkCacheConfig) SetDefaults() {
+	c.Enabled = false
+	c.TTL = 30 * time.Second
+	c.Warmup = false
+}
+
+// Validate the water mark cache config
+func (c *WaterMarkCacheConfig) Validate() error {
+	if c.Warmup && !c.Enabled {
+		return fmt.Errorf("water mark warmup requires the water mark cache to be enabled")
+	}
+	if !c.Enabled {
+		return nil
+	}
+	if c.TTL <= 0 {
+		return fmt.Errorf("water mark cache ttl must be greater than 0")
+	}
+
+	return nil
+}
+
+// waterMarkCache caches the high water marks of all partitions of a topic
+type waterMarkCache struct {
+	cfg WaterMarkCacheConfig
+
+	mutex   sync.RWMutex
+	entries map[string]*cachedHighWaterMarks
+
+	// warmupDone is closed once the warmup has finished, isWarm is only set if it has completed
+	warmupDone chan struct{}
+	isWarm     bool
+}
+
+type cachedHighWaterMarks struct {
+	waterMarks map[int32]int64
+	fetchedAt  time.Time
+}
+
+func newWaterMarkCache(cfg WaterMarkCacheConfig) *waterMarkCache {
+	return &waterMarkCache{
+		cfg:        cfg,
+		entries:    make(map[string]*cachedHighWaterMarks),
+		warmupDone: make(chan struct{}),
+	}
+}
+
+// get returns the cached high water marks of the requested partitions if all of them are cached and not expired
+func (c *waterMarkCache) get(topic string, partitionIDs []int32, now time.Time) (map[int32]int64, bool) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	entry, exists := c.entries[topic]
+	if !exists || now.Sub(entry.fetchedAt) > c.cfg.TTL {
+		return nil, false
+	}
+
+	res := make(map[int32]int64, len(partitionIDs))
+	for _, partitionID := range partitionIDs {
+		waterMark, exists := entry.waterMarks[partitionID]
+		if !exists {
+			// Partitions have been added since the water marks have been cached
+			return nil, false
+		}
+		res[partitionID] = waterMark
+	}
+
+	return res, true
+}
+
+func (c *waterMarkCache) set(waterMarks map[string]map[int32]int64, now time.Time) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	for topic, partitionWaterMarks := range waterMarks {
+		c.entries[topic] = &cachedHighWaterMarks{waterMarks: partitionWaterMarks, fetchedAt: now}
+	}
+}
+
+// warmup fetches the high water marks of the listed topics chunk by chunk and caches them. Failed chunks are skipped,
+// as the warmup is best effort. The warmup is marked as done on every path, but it's only marked as warm if the
+// topics could be listed and it hasn't been cancelled.
+func (c *waterMarkCache) warmup(ctx context.Context, listTopics func() ([]string, error), logger *zap.Logger, fetch func(topics []string) (map[string]map[int32]int64, error)) error {
+	defer close(c.warmupDone)
+
+	topics, err := listTopics()
+	if err != nil {
+		return fmt.Errorf("failed to list topics: %w", err)
+	}
+
+	for start := 0; start < len(topics); start += waterMarkWarmupChunkSize {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		end := start + waterMarkWarmupChunkSize
+		if end > len(topics) {
+			end = len(topics)
+		}
+		waterMarks, err := fetch(topics[start:end])
+		if err != nil {
+			logger.Warn("failed to warm up water marks", zap.Strings("topics", topics[start:end]), zap.Error(err))
+			continue
+		}
+		c.set(waterMarks, time.Now())
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	c.mutex.Lock()
+	c.isWarm = true
+	c.mutex.Unlock()
+	return nil
+}
+
+// StartWaterMarkWarmup pre-fetches the high water marks of the configured topics in the background if the warmup
+// is enabled. It returns immediately, WaterMarkWarmupDone is closed once the warmup has finished.
+func (s *Service) StartWaterMarkWarmup(ctx context.Context) {
+	if s.waterMarkCache == nil || !s.waterMarkCache.cfg.Warmup {
+		return
+	}
+
+	go func() {
+		err := s.waterMarkCache.warmup(ctx, s.waterMarkWarmupTopics, s.logger, s.fetchHighWaterMarks)
+		if err != nil {
+			s.logger.Warn("water mark warmup has not completed", zap.Error(err))
+			return
+		}
+		s.logger.Info("water mark warmup completed")
+	}()
+}
+
+// waterMarkWarmupTopics returns the configured warmup topics or all topics if none are configured
+func (s *Service) waterMarkWarmupTopics() ([]string, error) {
+	if len(s.waterMarkCache.cfg.WarmupTopics) > 0 {
+		return s.waterMarkCache.cfg.WarmupTopics, nil
+	}
+
+	metadata, err := s.kafkaSvc.ListTopics()
+	if err != nil {
+		return nil, err
+	}
+	topics := make([]string, 0, len(metadata))
+	for _, topic := range metadata {
+		if topic.Err == sarama.ErrNoError {
+			topics = append(topics, topic.Name)
+		}
+	}
+	return topics, nil
+}
+
+// WaterMarkWarmupDone returns a channel which is closed once the water mark warmup has finished. It's nil if the
+// water mark cache is disabled.
+func (s *Service) WaterMarkWarmupDone() <-chan struct{} {
+	if s.waterMarkCache == nil {
+		return nil
+	}
+	return s.waterMarkCache.warmupDone
+}
+
+// IsWaterMarkWarmupComplete returns true if the water mark warmup has completed
+func (s *Service) IsWaterMarkWarmupComplete() bool {
+	done := s.WaterMarkWarmupDone()
+	if done == nil {
+		return false
+	}
+
+	select {
+	case <-done:
+		s.waterMarkCache.mutex.RLock()
+		defer s.waterMarkCache.mutex.RUnlock()
+		return s.waterMarkCache.isWarm
+	default:
+		return false
+	}
+}
+
+// fetchHighWaterMarks fetches the high water marks of all partitions of the given topics
+func (s *Service) fetchHighWaterMarks(topics []string) (map[string]map[int32]int64, error) {
+	topicPartitions := make(map[string][]int32, len(topics))
+	for _, topic := range topics {
+		partitionIDs, err := s.kafkaSvc.Client.Partitions(topic)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get partitions of topic '%v': %w", topic, err)
+		}
+		topicPartitions[topic] = partitionIDs
+	}
+
+	return s.kafkaSvc.HighWaterMarks(topicPartitions)
+}
+
+// highWaterMarks returns the high water marks of the requested partitions. They are served from the water mark
+// cache if it's enabled, only topics which are not cached are fetched.
+func (s *Service) highWaterMarks(topicPartitions map[string][]int32) (map[string]map[int32]int64, error) {
+	if s.waterMarkCache == nil {
+		return s.kafkaSvc.HighWaterMarks(topicPartitions)
+	}
+
+	now := time.Now()
+	res := make(map[string]map[int32]int64, len(topicPartitions))
+	missing := make(map[string][]int32)
+	for topic, partitionIDs := range topicPartitions {
+		if waterMarks, ok := s.waterMarkCache.get(topic, partitionIDs, now); ok {
+			res[topic] = waterMarks
+			continue
+		}
+		missing[topic] = partitionIDs
+	}
+	if len(missing) == 0 {
+		return res, nil
+	}
+
+	fetched, err := s.kafkaSvc.HighWaterMarks(missing)
+	if err != nil {
+		return nil, err
+	}
+	s.waterMarkCache.set(fetched, now)
+	for topic, waterMarks := range fetched {
+		res[topic] = waterMarks
+	}
+
+	return res, nil
+}
@@ -0,0 +1,117 @@
+package owl
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newWarmupTestTopics(count int) []string {
+	topics := make([]string, count)
+	for i := range topics {
+		topics[i] = fmt.Sprintf("topic-%03d", i)
+	}
+	return topics
+}
+
+func TestWaterMarkCache_Warmup(t *testing.T) {
+	cache := newWaterMarkCache(WaterMarkCacheConfig{Enabled: true, TTL: time.Minute, Warmup: true})
+	topics := newWarmupTestTopics(120)
+
+	fetchedChunks := 0
+	fetch := func(topics []string) (map[string]map[int32]int64, error) {
+		fetchedChunks++
+		res := make(map[string]map[int32]int64, len(topics))
+		for _, topic := range topics {
+			res[topic] = map[int32]int64{0: 100, 1: 200}
+		}
+		return res, nil
+	}
+
+	err := cache.warmup(context.Background(), func() ([]string, error) { return topics, nil }, zap.NewNop(), fetch)
+	require.NoError(t, err)
+	assert.Equal(t, 3, fetchedChunks)
+
+	select {
+	case <-cache.warmupDone:
+	default:
+		t.Fatal("warmup has not been marked as done")
+	}
+	assert.True(t, cache.isWarm)
+
+	waterMarks, ok := cache.get("topic-119", []int32{0, 1}, time.Now())
+	require.True(t, ok)
+	assert.Equal(t, map[int32]int64{0: 100, 1: 200}, waterMarks)
+
+	// Expired entries and partitions which have been added in the meantime are not served from the cache
+	_, ok = cache.get("topic-119", []int32{0, 1}, time.Now().Add(2*time.Minute))
+	assert.False(t, ok)
+	_, ok = cache.get("topic-119", []int32{0, 1, 2}, time.Now())
+	assert.False(t, ok)
+}
+
+func TestWaterMarkCache_WarmupCancelled(t *testing.T) {
+	cache := newWaterMarkCache(WaterMarkCacheConfig{Enabled: true, TTL: time.Minute, Warmup: true})
+	topics := newWarmupTestTopics(120)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	fetchedChunks := 0
+	fetch := func(topics []string) (map[string]map[int32]int64, error) {
+		fetchedChunks++
+		cancel() // Service shuts down while the first chunk is being fetched
+		return map[string]map[int32]int64{topics[0]: {0: 100}}, nil
+	}
+
+	err := cache.warmup(ctx, func() ([]string, error) { return topics, nil }, zap.NewNop(), fetch)
+	assert.Equal(t, context.Canceled, err)
+	assert.Equal(t, 1, fetchedChunks)
+
+	// Waiters are released, but the cancelled warmup is not marked as warm
+	select {
+	case <-cache.warmupDone:
+	default:
+		t.Fatal("cancelled warmup has not been marked as done")
+	}
+	assert.False(t, cache.isWarm)
+
+	_, ok := cache.get("topic-000", []int32{0}, time.Now())
+	assert.True(t, ok)
+	_, ok = cache.get("topic-100", []int32{0}, time.Now())
+	assert.False(t, ok)
+}
+
+func TestWaterMarkCache_WarmupListTopicsFailed(t *testing.T) {
+	cache := newWaterMarkCache(WaterMarkCacheConfig{Enabled: true, TTL: time.Minute, Warmup: true})
+	listTopics := func() ([]string, error) { return nil, fmt.Errorf("no available broker") }
+	fetch := func(topics []string) (map[string]map[int32]int64, error) {
+		t.Fatal("no water marks must be fetched")
+		return nil, nil
+	}
+
+	err := cache.warmup(context.Background(), listTopics, zap.NewNop(), fetch)
+	assert.Error(t, err)
+	select {
+	case <-cache.warmupDone:
+	default:
+		t.Fatal("failed warmup has not been marked as done")
+	}
+	assert.False(t, cache.isWarm)
+}
+
+func TestWaterMarkCacheConfig_Validate(t *testing.T) {
+	cfg := WaterMarkCacheConfig{}
+	cfg.SetDefaults()
+	assert.NoError(t, cfg.Validate())
+
+	cfg.Warmup = true
+	assert.Error(t, cfg.Validate())
+
+	cfg.Enabled = true
+	assert.NoError(t, cfg.Validate())
+}
@@ -0,0 +1,251 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// codeSubjectNotFound is the error code the schema registry returns if the requested subject does not exist
+	codeSubjectNotFound = 40401
+	// codeSchemaNotFound is the error code the schema registry returns if the requested schema does not exist
+	codeSchemaNotFound = 40403
+	// codeSubjectCompatibilityNotConfigured is returned if no compatibility level has been set for the subject
+	codeSubjectCompatibilityNotConfigured = 40408
+	// codeNotFound is returned for unknown endpoints, e.g. by registries which don't support schema references
+	codeNotFound = 404
+)
+
+const (
+	// apicurioCompatPath is the path under which Apicurio serves the Confluent compatible REST API
+	apicurioCompatPath = "/apis/ccompat/v6"
+	// apicurioAPIPath is the path of Apicurio's own REST API, which is required to look up schemas by global ID
+	apicurioAPIPath = "/apis/registry/v2"
+)
+
+// Client for the Schema Registry's REST API
+type Client struct {
+	cfg        Config
+	httpClient *http.Client
+}
+
+// SchemaResponse is the response of the schema registry's /schemas/ids/{id} endpoint
+type SchemaResponse struct {
+	Schema     string `json:"schema"`
+	SchemaType string `json:"schemaType"` // Empty for AVRO schemas
+}
+
+// SubjectVersion is a subject along with the version under which a schema has been registered
+type SubjectVersion struct {
+	Subject string `json:"subject"`
+	Version int    `json:"version"`
+}
+
+// SubjectSchema is the response of the schema registry's /subjects/{subject}/versions/{version} endpoint
+type SubjectSchema struct {
+	Subject    string `json:"subject"`
+	Version    int    `json:"version"`
+	ID         uint32 `json:"id"`
+	Schema     string `json:"schema"`
+	SchemaType string `json:"schemaType"` // Empty for AVRO schemas
+}
+
+// CompatibilityResponse is the response of the schema registry's /config endpoints
+type CompatibilityResponse struct {
+	CompatibilityLevel string `json:"compatibilityLevel"`
+}
+
+// RestError is the error format that is returned by the schema registry
+type RestError struct {
+	ErrorCode int    `json:"error_code"`
+	Message   string `json:"message"`
+}
+
+func (e *RestError) Error() string {
+	return fmt.Sprintf("schema registry returned error code %v: %v", e.ErrorCode, e.Message)
+}
+
+func newClient(cfg Config) *Client {
+	return &Client{
+		cfg:        cfg,
+		httpClient: &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// GetSchemaByID returns the schema string identified by the input ID. Apicurio registries are asked for the
+// artifact with the given global ID, which is the ID that Apicurio's serializers embed in the messages.
+func (c *Client) GetSchemaByID(id uint32) (*SchemaResponse, error) {
+	if c.cfg.Type == RegistryTypeApicurio {
+		return c.getApicurioSchemaByGlobalID(id)
+	}
+
+	var res SchemaResponse
+	err := c.get(fmt.Sprintf("/schemas/ids/%d", id), &res)
+	if err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+// GetSubjectVersionsByID returns all subject versions which are registered with the given schema ID.
+func (c *Client) GetSubjectVersionsByID(id uint32) ([]*SubjectVersion, error) {
+	var res []*SubjectVersion
+	err := c.get(fmt.Sprintf("/schemas/ids/%d/versions", id), &res)
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// GetSubjectVersions returns all versions which have been registered under the given subject.
+func (c *Client) GetSubjectVersions(subject string) ([]int, error) {
+	var res []int
+	err := c.get(fmt.Sprintf("/subjects/%s/versions", url.PathEscape(subject)), &res)
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// GetSubjectSchema returns the schema which has been registered under the given subject and version.
+func (c *Client) GetSubjectSchema(subject string, version int) (*SubjectSchema, error) {
+	var res SubjectSchema
+	err := c.get(fmt.Sprintf("/subjects/%s/versions/%d", url.PathEscape(subject), version), &res)
+	if err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+// GetReferencedBy returns the IDs of all schemas which reference the given subject version.
+func (c *Client) GetReferencedBy(subject string, version int) ([]uint32, error) {
+	var res []uint32
+	err := c.get(fmt.Sprintf("/subjects/%s/versions/%d/referencedby", url.PathEscape(subject), version), &res)
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// GetSubjectCompatibility returns the compatibility level which has been configured for the given subject.
+func (c *Client) GetSubjectCompatibility(subject string) (*CompatibilityResponse, error) {
+	var res CompatibilityResponse
+	err := c.get(fmt.Sprintf("/config/%s", url.PathEscape(subject)), &res)
+	if err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+// GetGlobalCompatibility returns the global compatibility level which applies to all subjects without their own.
+func (c *Client) GetGlobalCompatibility() (*CompatibilityResponse, error) {
+	var res CompatibilityResponse
+	err := c.get("/config", &res)
+	if err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+// getApicurioSchemaByGlobalID returns the schema of the artifact version with the given global ID. Apicurio returns
+// the raw schema, its type is reported in a header.
+func (c *Client) getApicurioSchemaByGlobalID(id uint32) (*SchemaResponse, error) {
+	res, err := c.send(fmt.Sprintf("%v/ids/globalIds/%d", apicurioAPIPath, id), "*/*")
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusNotFound {
+		// Apicurio's error codes are plain HTTP status codes
+		return nil, &RestError{ErrorCode: codeSchemaNotFound, Message: fmt.Sprintf("no artifact with global ID %v", id)}
+	}
+	if res.StatusCode >= 300 {
+		return nil, decodeResponse(res, nil)
+	}
+
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema registry response: %w", err)
+	}
+
+	schemaType := res.Header.Get("X-Registry-ArtifactType")
+	if schemaType == "AVRO" {
+		// Omitted for Avro schemas, like the Confluent API does
+		schemaType = ""
+	}
+
+	return &SchemaResponse{Schema: string(body), SchemaType: schemaType}, nil
+}
+
+// get sends a GET request to the first schema registry URL which is reachable and decodes the response into v
+func (c *Client) get(path string, v interface{}) error {
+	if c.cfg.Type == RegistryTypeApicurio {
+		path = apicurioCompatPath + path
+	}
+
+	res, err := c.send(path, "application/vnd.schemaregistry.v1+json")
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	return decodeResponse(res, v)
+}
+
+// send sends a GET request to the first schema registry URL which is reachable. The caller must close the
+// response body.
+func (c *Client) send(path string, accept string) (*http.Response, error) {
+	var lastErr error
+	for _, registryURL := range c.cfg.URLs {
+		req, err := http.NewRequest(http.MethodGet, strings.TrimSuffix(registryURL, "/")+path, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create schema registry request: %w", err)
+		}
+		req.Header.Set("Accept", accept)
+		if c.cfg.Username != "" {
+			req.SetBasicAuth(c.cfg.Username, c.cfg.Password)
+		}
+
+		res, err := c.httpClient.Do(req)
+		if err != nil {
+			// Try the next URL, the registry might be unreachable on this one
+			lastErr = err
+			continue
+		}
+
+		return res, nil
+	}
+
+	return nil, fmt.Errorf("failed to reach any schema registry url: %w", lastErr)
+}
+
+func decodeResponse(res *http.Response, v interface{}) error {
+	if res.StatusCode >= 300 {
+		restErr := &RestError{}
+		err := json.NewDecoder(res.Body).Decode(restErr)
+		if err != nil {
+			return fmt.Errorf("schema registry returned unexpected status code %v", res.StatusCode)
+		}
+		return restErr
+	}
+
+	err := json.NewDecoder(res.Body).Decode(v)
+	if err != nil {
+		return fmt.Errorf("failed to decode schema registry response: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,49 @@
+package schema
+
+import (
+	"flag"
+	"fmt"
+)
+
+const (
+	// RegistryTypeConfluent is the Confluent Schema Registry or any registry implementing its REST API
+	RegistryTypeConfluent = "confluent"
+	// RegistryTypeApicurio is the Apicurio Registry, whose URLs are the registry's base URL (without /apis/...)
+	RegistryTypeApicurio = "apicurio"
+)
+
+// Config for using a (Confluent or Apicurio) Schema Registry
+type Config struct {
+	Enabled bool     `yaml:"enabled"`
+	URLs    []string `yaml:"urls"`
+	Type    string   `yaml:"type"` // Defaults to confluent if empty
+
+	// Basic Auth
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+}
+
+// RegisterFlags registers all sensitive Schema Registry settings as flag
+func (c *Config) RegisterFlags(f *flag.FlagSet) {
+	f.StringVar(&c.Password, "kafka.schemaRegistry.password", "", "Basic auth password for the schema registry")
+}
+
+// Validate the schema registry config
+func (c *Config) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+
+	if len(c.URLs) == 0 {
+		return fmt.Errorf("schema registry is enabled but no URL is configured")
+	}
+
+	switch c.Type {
+	case "", RegistryTypeConfluent, RegistryTypeApicurio:
+	default:
+		return fmt.Errorf("schema registry type '%v' is not supported, must be either '%v' or '%v'",
+			c.Type, RegistryTypeConfluent, RegistryTypeApicurio)
+	}
+
+	return nil
+}
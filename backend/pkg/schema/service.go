@@ -0,0 +1,261 @@
+package schema
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
+)
+
+// Service for fetching schemas from the schema registry. Schemas are immutable, so that all fetched schemas
+// are cached for the lifetime of the service.
+type Service struct {
+	registry *Client
+	logger   *zap.Logger
+
+	cacheMutex sync.RWMutex
+	schemaByID map[uint32]*SchemaResponse
+	// missByID caches the failed lookups of schema IDs, so that messages referencing unknown schema IDs don't
+	// cause a registry request each
+	missByID map[uint32]*cachedSchemaMiss
+
+	// versionsByID caches the subject versions of a schema ID for the schema version annotation. Concurrent
+	// lookups of the same schema ID share a single registry request.
+	versionsMutex sync.Mutex
+	versionsByID  map[uint32]*cachedSubjectVersions
+	versionsGroup singleflight.Group
+}
+
+// subjectVersionsRefreshInterval is the minimum interval between two lookups of a schema ID's subject versions, if
+// the requested subject was not part of the cached versions. Schema IDs can be registered under further subjects.
+const subjectVersionsRefreshInterval = time.Minute
+
+// schemaMissTTL is the duration for which failed schema lookups are cached. Misses are not cached permanently,
+// because the schema may be registered later on or the registry may have been unavailable.
+const schemaMissTTL = 10 * time.Second
+
+type cachedSchemaMiss struct {
+	err       error
+	expiresAt time.Time
+}
+
+type cachedSubjectVersions struct {
+	versions  []*SubjectVersion
+	err       error // Failed lookups are cached for the schemaMissTTL
+	fetchedAt time.Time
+}
+
+// NewService to access the schema registry
+func NewService(cfg Config, logger *zap.Logger) *Service {
+	return &Service{
+		registry:     newClient(cfg),
+		logger:       logger,
+		schemaByID:   make(map[uint32]*SchemaResponse),
+		missByID:     make(map[uint32]*cachedSchemaMiss),
+		versionsByID: make(map[uint32]*cachedSubjectVersions),
+	}
+}
+
+// IsApicurio returns true if the registry is an Apicurio registry, which resolves Apicurio's global IDs instead of
+// Confluent's schema IDs
+func (s *Service) IsApicurio() bool {
+	return s.registry.cfg.Type == RegistryTypeApicurio
+}
+
+// GetSchemaByID returns the (cached) schema for the given schema ID. Failed lookups return the cached error until
+// it expires.
+func (s *Service) GetSchemaByID(id uint32) (*SchemaResponse, error) {
+	s.cacheMutex.RLock()
+	cached, ok := s.schemaByID[id]
+	miss, isMissed := s.missByID[id]
+	s.cacheMutex.RUnlock()
+	if ok {
+		return cached, nil
+	}
+	if isMissed && time.Now().Before(miss.expiresAt) {
+		return nil, miss.err
+	}
+
+	schema, err := s.registry.GetSchemaByID(id)
+	if err != nil {
+		s.cacheMutex.Lock()
+		s.missByID[id] = &cachedSchemaMiss{err: err, expiresAt: time.Now().Add(schemaMissTTL)}
+		s.cacheMutex.Unlock()
+		return nil, err
+	}
+
+	s.cacheMutex.Lock()
+	s.schemaByID[id] = schema
+	delete(s.missByID, id)
+	s.cacheMutex.Unlock()
+
+	return schema, nil
+}
+
+// GetSubjectsBySchemaID returns the names of all subjects which the given schema ID has been registered with.
+// The result is not cached, because the same schema can be registered under additional subjects at any time.
+func (s *Service) GetSubjectsBySchemaID(id uint32) ([]string, error) {
+	versions, err := s.registry.GetSubjectVersionsByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	subjects := make([]string, len(versions))
+	for i, version := range versions {
+		subjects[i] = version.Subject
+	}
+	return subjects, nil
+}
+
+// GetSchemaVersion returns the subject version under which the schema ID has been registered with the given
+// subject. If the schema ID is not registered under that subject, but under exactly one other subject, that
+// subject's version is returned. Nil is returned if the version is ambiguous or unknown. The mappings are cached.
+func (s *Service) GetSchemaVersion(id uint32, subject string) (*SubjectVersion, error) {
+	s.versionsMutex.Lock()
+	cached, exists := s.versionsByID[id]
+	s.versionsMutex.Unlock()
+	if exists {
+		if cached.err != nil {
+			if time.Since(cached.fetchedAt) < schemaMissTTL {
+				return nil, cached.err
+			}
+		} else {
+			if version := findSubjectVersion(cached.versions, subject); version != nil {
+				return version, nil
+			}
+			if time.Since(cached.fetchedAt) < subjectVersionsRefreshInterval {
+				return nil, nil
+			}
+		}
+	}
+
+	versions, err, _ := s.versionsGroup.Do(strconv.FormatUint(uint64(id), 10), func() (interface{}, error) {
+		versions, err := s.registry.GetSubjectVersionsByID(id)
+		s.versionsMutex.Lock()
+		s.versionsByID[id] = &cachedSubjectVersions{versions: versions, err: err, fetchedAt: time.Now()}
+		s.versionsMutex.Unlock()
+		return versions, err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return findSubjectVersion(versions.([]*SubjectVersion), subject), nil
+}
+
+func findSubjectVersion(versions []*SubjectVersion, subject string) *SubjectVersion {
+	for _, version := range versions {
+		if version.Subject == subject {
+			return version
+		}
+	}
+	if len(versions) == 1 {
+		return versions[0]
+	}
+	return nil
+}
+
+// SubjectHistory is the compatibility level of a subject along with all schema versions registered under it
+type SubjectHistory struct {
+	Subject            string
+	CompatibilityLevel string // E.g. BACKWARD, FORWARD_TRANSITIVE, FULL or NONE
+	// IsGlobalCompatibility is true if no level has been set for the subject, so that the global level applies
+	IsGlobalCompatibility bool
+	Versions              []*SubjectSchema // Sorted by version, which is the order of registration
+}
+
+// GetSubjectHistory returns the subject's compatibility level and all its schema versions. Subjects without any
+// registered schemas return an empty list of versions.
+func (s *Service) GetSubjectHistory(subject string) (*SubjectHistory, error) {
+	res := &SubjectHistory{Subject: subject, Versions: make([]*SubjectSchema, 0)}
+
+	compatibility, err := s.registry.GetSubjectCompatibility(subject)
+	if err != nil && isRestError(err, codeSubjectNotFound, codeSubjectCompatibilityNotConfigured) {
+		res.IsGlobalCompatibility = true
+		compatibility, err = s.registry.GetGlobalCompatibility()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get compatibility level: %w", err)
+	}
+	res.CompatibilityLevel = compatibility.CompatibilityLevel
+
+	versions, err := s.registry.GetSubjectVersions(subject)
+	if err != nil {
+		if isRestError(err, codeSubjectNotFound) {
+			return res, nil
+		}
+		return nil, fmt.Errorf("failed to get subject versions: %w", err)
+	}
+	sort.Ints(versions)
+
+	for _, version := range versions {
+		schema, err := s.registry.GetSubjectSchema(subject, version)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get schema of version %v: %w", version, err)
+		}
+		res.Versions = append(res.Versions, schema)
+	}
+
+	return res, nil
+}
+
+// ErrReferencesNotSupported is returned if the schema registry does not support schema references
+var ErrReferencesNotSupported = errors.New("schema registry does not support schema references")
+
+// GetSubjectReferencedBy returns the IDs of all schemas referencing a version of the given subject, keyed by the
+// referenced version. Versions which are not referenced are omitted. Subjects without any registered schemas return
+// an empty map.
+func (s *Service) GetSubjectReferencedBy(subject string) (map[int][]uint32, error) {
+	res := make(map[int][]uint32)
+	versions, err := s.registry.GetSubjectVersions(subject)
+	if err != nil {
+		if isRestError(err, codeSubjectNotFound) {
+			return res, nil
+		}
+		return nil, fmt.Errorf("failed to get subject versions: %w", err)
+	}
+
+	for _, version := range versions {
+		ids, err := s.registry.GetReferencedBy(subject, version)
+		if err != nil {
+			if isRestError(err, codeNotFound) {
+				return nil, ErrReferencesNotSupported
+			}
+			return nil, fmt.Errorf("failed to get references of version %v: %w", version, err)
+		}
+		if len(ids) > 0 {
+			res[version] = ids
+		}
+	}
+
+	return res, nil
+}
+
+// GetSubjectVersionsBySchemaID returns all subject versions which the given schema ID has been registered with.
+func (s *Service) GetSubjectVersionsBySchemaID(id uint32) ([]*SubjectVersion, error) {
+	return s.registry.GetSubjectVersionsByID(id)
+}
+
+// IsSchemaNotFound returns true if the given error was returned because the registry does not know the schema
+func IsSchemaNotFound(err error) bool {
+	return isRestError(err, codeSchemaNotFound)
+}
+
+// isRestError returns true if the given error has been returned by the registry with one of the given error codes
+func isRestError(err error, codes ...int) bool {
+	var restErr *RestError
+	if !errors.As(err, &restErr) {
+		return false
+	}
+	for _, code := range codes {
+		if restErr.ErrorCode == code {
+			return true
+		}
+	}
+	return false
+}